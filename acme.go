@@ -0,0 +1,29 @@
+package router
+
+import "net/http"
+
+// acmeChallengePath is the pattern of the HTTP-01 challenge responses of ACME (RFC 8555).
+const acmeChallengePath = "/.well-known/acme-challenge/{token}"
+
+// ACMEChallenge registers the handler of ACME HTTP-01 challenges at
+// /.well-known/acme-challenge/{token}, such as the handler of autocert.Manager.HTTPHandler or a
+// file server of the webroot of certbot. Certificate issuance and renewal must not be blocked by
+// the policies of the application, so the route bypasses the router's global middleware (such as
+// authentication added with Use) and RouterOptions.MaxInFlight. Its static prefix is matched
+// ahead of the parameter and regular expression routes of the router, so catch-all routes do not
+// capture the challenges either.
+//
+// The handler receives the request unchanged; the token is also available through GetParams.
+//
+// 例: r.ACMEChallenge(manager.HTTPHandler(nil))
+func (r *Router) ACMEChallenge(handler http.Handler) *Route {
+	route := r.Get(acmeChallengePath, func(w http.ResponseWriter, req *http.Request) error {
+		handler.ServeHTTP(w, req)
+		return nil
+	}).WithoutConcurrencyLimit()
+	route.bare = true
+	if handler == nil {
+		route.err = &RouterError{Code: ErrNilHandler, Message: "ACME challenge handler cannot be nil"}
+	}
+	return route
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestACMEChallenge tests that ACME challenges bypass the global middleware and catch-all routes
+func TestACMEChallenge(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			if req.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return nil
+			}
+			return next(w, req)
+		}
+	})
+	r.Get("/{a}/{b}/{c}", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("catch-all"))
+		return nil
+	})
+	r.ACMEChallenge(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, _ := GetParams(req.Context()).Get("token")
+		w.Write([]byte(token + ".thumbprint"))
+	}))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/acme-challenge/abc123", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "abc123.thumbprint" {
+		t.Errorf("Expected the challenge response, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Other routes still pass through the middleware
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x/y/z", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	// A nil handler is reported by Build
+	r2 := NewRouter()
+	defer r2.cache.stop()
+	r2.ACMEChallenge(nil)
+	if err := r2.Build(); err == nil {
+		t.Error("Expected Build to report the nil handler")
+	}
+}
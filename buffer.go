@@ -0,0 +1,99 @@
+package router
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// WithBufferedResponse enables buffered mode for the route.
+// In buffered mode the handler writes its status, headers and body into memory, and the response
+// is only sent when the handler returns nil. If the handler returns an error, the buffered response
+// is discarded so that the error handler can write a clean error response instead of appending
+// to a half-written body.
+//
+// The whole body is held in memory, so buffered mode is not suitable for large or streaming
+// responses; Flush does nothing while the response is buffered.
+func (r *Route) WithBufferedResponse() *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.bufferResponse = true
+	return r
+}
+
+// IsBufferedResponse reports whether the route buffers its response.
+func (r *Route) IsBufferedResponse() bool {
+	return r.bufferResponse
+}
+
+// responseBuffer holds a buffered response until it is committed or discarded.
+type responseBuffer struct {
+	header http.Header
+	status int // 0 until the handler sets a status or writes
+	body   bytes.Buffer
+}
+
+// startBuffering makes subsequent writes go into a buffer.
+// Headers already set on the underlying ResponseWriter (by the router or outer handlers) are kept.
+func (rw *responseWriter) startBuffering() {
+	rw.buffer = &responseBuffer{header: rw.ResponseWriter.Header().Clone()}
+}
+
+// commit sends the buffered response to the underlying ResponseWriter.
+// It sends nothing if the timeout path has claimed the response in the meantime.
+func (rw *responseWriter) commit() {
+	b := rw.buffer
+	if b == nil {
+		return
+	}
+	rw.buffer = nil
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.timedOut {
+		return
+	}
+
+	// Declared trailers are set after the body, so that net/http sends them as trailers
+	trailers := declaredTrailers(b.header)
+	header := rw.ResponseWriter.Header()
+	clear(header)
+	for k, v := range b.header {
+		if !slices.Contains(trailers, k) {
+			header[k] = v
+		}
+	}
+	if b.status != 0 {
+		rw.writeHeader(b.status)
+	}
+	if b.body.Len() > 0 {
+		rw.writeBody(b.body.Bytes())
+	}
+	for _, k := range trailers {
+		if v, ok := b.header[k]; ok {
+			header[k] = v
+		}
+	}
+}
+
+// declaredTrailers returns the canonical keys declared in the Trailer header.
+func declaredTrailers(header http.Header) []string {
+	var trailers []string
+	for _, v := range header.Values("Trailer") {
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				trailers = append(trailers, http.CanonicalHeaderKey(k))
+			}
+		}
+	}
+	return trailers
+}
+
+// discard drops the buffered response, leaving the underlying ResponseWriter untouched.
+func (rw *responseWriter) discard() {
+	rw.buffer = nil
+}
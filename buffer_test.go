@@ -0,0 +1,59 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestBufferedResponse tests that a buffered route only sends its response when the handler succeeds
+func TestBufferedResponse(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.SetErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {
+		http.Error(w, "clean error", http.StatusInternalServerError)
+	})
+
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		w.Header().Set("X-Partial", "yes")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "partial body")
+		if req.URL.Query().Get("fail") != "" {
+			return errors.New("failed after writing")
+		}
+		return nil
+	}
+	r.Get("/buffered", handler).WithBufferedResponse()
+	r.Get("/direct", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		url     string
+		status  int
+		body    string
+		partial string
+	}{
+		{"/buffered", http.StatusCreated, "partial body", "yes"},
+		{"/buffered?fail=1", http.StatusInternalServerError, "clean error\n", ""},
+		{"/direct?fail=1", http.StatusCreated, "partial body", "yes"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.status, w.Code)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.url, tt.body, w.Body.String())
+		}
+		if got := w.Header().Get("X-Partial"); got != tt.partial {
+			t.Errorf("%s: expected X-Partial %q, got %q", tt.url, tt.partial, got)
+		}
+	}
+}
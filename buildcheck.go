@@ -0,0 +1,54 @@
+package router
+
+import (
+	"slices"
+	"strings"
+)
+
+// BuildCheck is a policy that is checked for every route at Build.
+// It returns an error describing the violation, or nil if the route complies.
+type BuildCheck func(RouteInfo) error
+
+// AddBuildCheck registers a policy that Build checks for every route it registers, so that
+// organization rules are enforced automatically, for example:
+//   - all POST routes must have a body size limit,
+//   - all /internal routes must carry the metadata audience=internal.
+//
+// Build runs the checks before registering any route and fails with an ErrBuildCheck error
+// listing every violation.
+//
+// 例: r.AddBuildCheck(func(ri router.RouteInfo) error { ... })
+func (r *Router) AddBuildCheck(check BuildCheck) {
+	if check == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buildChecks = append(r.buildChecks, check)
+}
+
+// runBuildChecks checks the routes against the registered policies.
+func (r *Router) runBuildChecks(routes []*Route) error {
+	r.mu.RLock()
+	checks := slices.Clone(r.buildChecks)
+	r.mu.RUnlock()
+	if len(checks) == 0 {
+		return nil
+	}
+
+	var violations []string
+	for _, route := range routes {
+		info := route.info()
+
+		for _, check := range checks {
+			if err := check(info); err != nil {
+				violations = append(violations, info.Method+" "+info.Pattern+": "+err.Error())
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &RouterError{Code: ErrBuildCheck, Message: strings.Join(violations, "; ")}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestBuildCheck tests enforcing route policies at Build
+func TestBuildCheck(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.AddBuildCheck(func(ri RouteInfo) error {
+		if !strings.HasPrefix(ri.Pattern, "/internal") {
+			return nil
+		}
+		if audience, _ := ri.Route.Meta("audience"); audience != "internal" {
+			return errors.New("internal routes must carry audience=internal")
+		}
+		return nil
+	})
+	r.AddBuildCheck(func(ri RouteInfo) error {
+		if ri.Method == http.MethodPost && !ri.HasErrorHandler {
+			return errors.New("POST routes must have an error handler")
+		}
+		return nil
+	})
+
+	internal := r.Group("/internal").WithMeta("audience", "internal")
+	internal.Get("/metrics", handler)
+	r.Get("/internal/debug", handler)
+	r.Post("/orders/{id}", handler)
+	if err := r.Build(); err == nil {
+		t.Fatal("Expected policy violations")
+	} else {
+		var routerErr *RouterError
+		if !errors.As(err, &routerErr) || routerErr.Code != ErrBuildCheck {
+			t.Fatalf("Expected a build check error, got %v", err)
+		}
+		for _, want := range []string{"GET /internal/debug: internal routes", "POST /orders/{id}: POST routes"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected %q in %v", want, err)
+			}
+		}
+		if strings.Contains(err.Error(), "/internal/metrics") {
+			t.Errorf("Expected the group route to comply, got %v", err)
+		}
+	}
+
+	// Nothing is registered when a check fails
+	if _, ok := r.Match(http.MethodGet, "/internal/metrics"); ok {
+		t.Error("Expected no route to be registered")
+	}
+
+	// Compliant routes build
+	r2 := NewRouter()
+	defer r2.cache.stop()
+	r2.AddBuildCheck(func(ri RouteInfo) error { return nil })
+	r2.Get("/users/{id}", handler)
+	if err := r2.Build(); err != nil {
+		t.Errorf("Expected Build to succeed, got %v", err)
+	}
+}
@@ -26,12 +26,24 @@ type cache struct {
 type cacheShard struct {
 	sync.RWMutex
 	entries map[uint64]*cacheEntry
+
+	// Usage counters (see Stats), kept per shard so that lookups do not contend on one counter
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
 }
 
+// cacheEntry is a cached match. An entry is shared by every request that hits it, so all of its
+// fields except timestamp are immutable once the entry is stored in a shard: set replaces the
+// entry instead of modifying it, and the params map must not be modified by readers.
+// timestamp is the time of the last access, which lookups record under the shard's read lock
+// while eviction and cleanup compare it under the write lock, so it is only ever accessed
+// atomically. It is the only state lookups mutate.
 type cacheEntry struct {
 	handler   HandlerFunc
-	timestamp int64
-	hits      uint32
+	route     *Route
+	stats     *routeStats
+	timestamp atomic.Int64 // Time of the last access in Unix nanoseconds
 	params    map[string]string
 }
 
@@ -62,6 +74,11 @@ func (c *cache) get(key uint64) (HandlerFunc, bool) {
 }
 
 func (c *cache) set(key uint64, h HandlerFunc, params map[string]string) {
+	c.setRoute(key, h, nil, nil, params)
+}
+
+// setRoute stores a handler together with the route definition that produced it and its usage statistics.
+func (c *cache) setRoute(key uint64, h HandlerFunc, route *Route, stats *routeStats, params map[string]string) {
 	if h == nil {
 		return
 	}
@@ -72,33 +89,58 @@ func (c *cache) set(key uint64, h HandlerFunc, params map[string]string) {
 		var oldestKey uint64
 		oldestTimestamp := int64(1<<63 - 1)
 		for k, entry := range sh.entries {
-			if entry.timestamp < oldestTimestamp {
-				oldestTimestamp = entry.timestamp
+			if ts := entry.timestamp.Load(); ts < oldestTimestamp {
+				oldestTimestamp = ts
 				oldestKey = k
 			}
 		}
 		delete(sh.entries, oldestKey)
+		sh.evictions.Add(1)
 	}
-	sh.entries[key] = &cacheEntry{
-		handler:   h,
-		timestamp: time.Now().UnixNano(),
-		hits:      0,
-		params:    params,
+	e := &cacheEntry{
+		handler: h,
+		route:   route,
+		stats:   stats,
+		params:  params,
 	}
+	e.timestamp.Store(time.Now().UnixNano())
+	sh.entries[key] = e
 	sh.Unlock()
 }
 
 func (c *cache) getWithParams(key uint64) (HandlerFunc, map[string]string, bool) {
+	e, ok := c.getEntry(key)
+	if !ok {
+		return nil, nil, false
+	}
+	return e.handler, e.params, true
+}
+
+// getRoute retrieves the handler, the route definition, and the usage statistics from the cache.
+func (c *cache) getRoute(key uint64) (HandlerFunc, *Route, *routeStats, bool) {
+	e, ok := c.getEntry(key)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	return e.handler, e.route, e.stats, true
+}
+
+// getEntry retrieves an entry and updates its access timestamp.
+// The entry is read after the shard's lock is released, which is safe because it is immutable
+// apart from the timestamp (see cacheEntry).
+func (c *cache) getEntry(key uint64) (*cacheEntry, bool) {
 	sh := c.shards[key&shardMask]
 	sh.RLock()
 	e, ok := sh.entries[key]
 	sh.RUnlock()
 
 	if !ok {
-		return nil, nil, false
+		sh.misses.Add(1)
+		return nil, false
 	}
-	atomic.StoreInt64(&e.timestamp, time.Now().UnixNano())
-	return e.handler, e.params, true
+	sh.hits.Add(1)
+	e.timestamp.Store(time.Now().UnixNano())
+	return e, true
 }
 
 func (c *cache) cleanupLoop() {
@@ -124,8 +166,9 @@ func (c *cache) cleanup() {
 	for _, sh := range c.shards {
 		sh.Lock()
 		for k, e := range sh.entries {
-			if e.timestamp < threshold {
+			if e.timestamp.Load() < threshold {
 				delete(sh.entries, k)
+				sh.evictions.Add(1)
 			}
 		}
 		sh.Unlock()
@@ -153,3 +196,46 @@ func (c *cache) GetParams(key uint64) (map[string]string, bool) {
 	_, params, found := c.getWithParams(key)
 	return params, found
 }
+
+// Get implements RouteCache.
+func (c *cache) Get(key uint64) (CachedRoute, bool) {
+	e, ok := c.getEntry(key)
+	if !ok {
+		return CachedRoute{}, false
+	}
+	return CachedRoute{handler: e.handler, route: e.route, stats: e.stats, params: e.params}, true
+}
+
+// Set implements RouteCache.
+func (c *cache) Set(key uint64, entry CachedRoute) {
+	c.setRoute(key, entry.handler, entry.route, entry.stats, entry.params)
+}
+
+// Invalidate implements RouteCache.
+func (c *cache) Invalidate() {
+	for _, sh := range c.shards {
+		sh.Lock()
+		clear(sh.entries)
+		sh.Unlock()
+	}
+}
+
+// Stats implements RouteCache.
+func (c *cache) Stats() CacheStats {
+	var stats CacheStats
+	for _, sh := range c.shards {
+		sh.RLock()
+		stats.Entries += len(sh.entries)
+		sh.RUnlock()
+		stats.Hits += sh.hits.Load()
+		stats.Misses += sh.misses.Load()
+		stats.Evictions += sh.evictions.Load()
+	}
+	return stats
+}
+
+// Close implements RouteCache by stopping the cleanup loop.
+func (c *cache) Close() error {
+	c.stop()
+	return nil
+}
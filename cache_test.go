@@ -2,6 +2,7 @@ package router
 
 import (
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 )
@@ -154,7 +155,7 @@ func TestCacheCleanup(t *testing.T) {
 	shard.Lock()
 	entry := shard.entries[key]
 	if entry != nil {
-		entry.timestamp = time.Now().Add(-2 * defaultExpiration).UnixNano()
+		entry.timestamp.Store(time.Now().Add(-2 * defaultExpiration).UnixNano())
 	}
 	shard.Unlock()
 
@@ -215,7 +216,7 @@ func TestCacheTimestamp(t *testing.T) {
 	entry := shard.entries[key]
 	initialTimestamp := int64(0)
 	if entry != nil {
-		initialTimestamp = entry.timestamp
+		initialTimestamp = entry.timestamp.Load()
 	}
 	shard.RUnlock()
 
@@ -230,7 +231,7 @@ func TestCacheTimestamp(t *testing.T) {
 	entry = shard.entries[key]
 	finalTimestamp := int64(0)
 	if entry != nil {
-		finalTimestamp = entry.timestamp
+		finalTimestamp = entry.timestamp.Load()
 	}
 	shard.RUnlock()
 
@@ -239,3 +240,35 @@ func TestCacheTimestamp(t *testing.T) {
 		t.Errorf("cache timestamp was not updated. Initial: %d, Final: %d", initialTimestamp, finalTimestamp)
 	}
 }
+
+// TestCacheConcurrentAccess tests lookups concurrent with stores, eviction, and cleanup,
+// which touch the timestamps of shared entries (run with -race)
+func TestCacheConcurrentAccess(t *testing.T) {
+	cache := newCache()
+	defer cache.stop()
+
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 3 * maxEntriesPerShard {
+				key := uint64(g*shardCount + i)
+				cache.set(key, handler, nil)
+				cache.get(key / 2)
+				if i%1024 == 0 {
+					cache.cleanup()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := cache.Stats().Entries; n > defaultCacheMaxEntries {
+		t.Errorf("Expected at most %d entries, got %d", defaultCacheMaxEntries, n)
+	}
+}
@@ -0,0 +1,49 @@
+package router
+
+// WithCacheControl sets the Cache-Control header of the successful responses (2xx and 304 Not
+// Modified) of the route, unless the handler sets its own. Error responses are not affected.
+// It complements middleware such as ETag generation and response caches, which read the header.
+//
+// 例: r.Get("/products", listProducts).WithCacheControl("public, max-age=300")
+func (r *Route) WithCacheControl(value string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.cacheControl = value
+	return r
+}
+
+// NoStore marks the responses of the route as not storable by any cache,
+// which is a shortcut for WithCacheControl("no-store").
+func (r *Route) NoStore() *Route {
+	return r.WithCacheControl("no-store")
+}
+
+// GetCacheControl returns the Cache-Control value of the route.
+// If the route has no value of its own, the value of its group is returned ("" if neither is set).
+func (r *Route) GetCacheControl() string {
+	if r.cacheControl != "" || r.group == nil {
+		return r.cacheControl
+	}
+	return r.group.GetCacheControl()
+}
+
+// WithCacheControl sets the Cache-Control header of the successful responses of the routes of
+// the group and its child groups. Routes can override it with Route.WithCacheControl.
+func (g *Group) WithCacheControl(value string) *Group {
+	g.cacheControl = value
+	return g
+}
+
+// GetCacheControl returns the Cache-Control value of the group, including a value inherited
+// from parent groups ("" if none is set).
+func (g *Group) GetCacheControl() string {
+	for current := g; current != nil; current = current.parent {
+		if current.cacheControl != "" {
+			return current.cacheControl
+		}
+	}
+	return ""
+}
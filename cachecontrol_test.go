@@ -0,0 +1,62 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCacheControl tests the Cache-Control header of routes
+func TestCacheControl(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		switch req.URL.Query().Get("case") {
+		case "own":
+			w.Header().Set("Cache-Control", "private")
+		case "error":
+			return errors.New("failed")
+		case "notmodified":
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		case "notfound":
+			w.WriteHeader(http.StatusNotFound)
+			return nil
+		}
+		w.Write([]byte("ok"))
+		return nil
+	}
+	r.Get("/products", handler).WithCacheControl("public, max-age=300")
+	r.Get("/account", handler).NoStore()
+	r.Get("/buffered", handler).WithCacheControl("max-age=60").WithBufferedResponse()
+	r.Get("/plain", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method string
+		url    string
+		want   string
+	}{
+		{http.MethodGet, "/products", "public, max-age=300"},
+		{http.MethodHead, "/products", "public, max-age=300"},
+		{http.MethodGet, "/products?case=own", "private"},
+		{http.MethodGet, "/products?case=error", ""},
+		{http.MethodGet, "/products?case=notmodified", "public, max-age=300"},
+		{http.MethodGet, "/products?case=notfound", ""},
+		{http.MethodGet, "/account", "no-store"},
+		{http.MethodGet, "/buffered", "max-age=60"},
+		{http.MethodGet, "/buffered?case=error", ""},
+		{http.MethodGet, "/plain", ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+		if got := w.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s %s: expected Cache-Control %q, got %q", tt.method, tt.url, tt.want, got)
+		}
+	}
+}
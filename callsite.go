@@ -0,0 +1,64 @@
+package router
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// packagePrefix is the prefix of the function names of this package in stack frames.
+var packagePrefix = reflect.TypeOf((*Router)(nil)).Elem().PkgPath() + "."
+
+// callSite returns the file:line of the code that called into the router, skipping the frames of
+// this package (except its tests), so that Get, Group.Route, etc. report the application code.
+func callSite() string {
+	var pcs [16]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			return filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// recordsSites reports whether routes registered with the router record their call site.
+func (r *Router) recordsSites() bool {
+	return r != nil && r.recordSites
+}
+
+// details describes where the route was defined for error messages: its group chain and, with
+// RouterOptions.RecordRouteSites, its call site, such as " [group /api > /api/v1, defined at main.go:42]".
+// It returns "" if neither is known.
+func (r *Route) details() string {
+	var parts []string
+	if r.group != nil {
+		var prefixes []string
+		for g := r.group; g != nil; g = g.parent {
+			prefixes = append(prefixes, g.prefix)
+		}
+		slices.Reverse(prefixes)
+		parts = append(parts, "group "+strings.Join(prefixes, " > "))
+	}
+	if r.site != "" {
+		parts = append(parts, "defined at "+r.site)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// overrideHint tells whether an override strategy would have resolved a duplicate route, for error messages.
+func (r *Router) overrideHint() string {
+	if r.override.overrides() {
+		return "; OverrideStrategy " + r.override.String() + " is set, but Strict rejects overrides"
+	}
+	return "; enabling RouterOptions.AllowRouteOverride would let the later route replace the earlier one (see also OverrideStrategy)"
+}
@@ -0,0 +1,56 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestDuplicateRouteSites tests that duplicate route errors describe both registrations
+func TestDuplicateRouteSites(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	opts := defaultRouterOptions()
+	opts.RecordRouteSites = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	v1 := r.Group("/api").Group("/v1")
+	v1.Get("/users", handler)
+	r.Get("/api/v1/users", handler)
+	err := r.Build()
+	if !IsDuplicate(err) {
+		t.Fatalf("Expected a duplicate route error, got %v", err)
+	}
+	msg := err.Error()
+	if sites := regexp.MustCompile(`defined at callsite_test\.go:\d+`).FindAllString(msg, -1); len(sites) != 2 || sites[0] == sites[1] {
+		t.Errorf("Expected both registration sites, got %q", msg)
+	}
+	if !strings.Contains(msg, "group /api > /api/v1") {
+		t.Errorf("Expected the group chain, got %q", msg)
+	}
+	if !strings.Contains(msg, "AllowRouteOverride would") {
+		t.Errorf("Expected a hint about AllowRouteOverride, got %q", msg)
+	}
+
+	// Strict mode rejects the override that AllowRouteOverride would allow
+	opts.AllowRouteOverride = true
+	opts.Strict = true
+	s := NewRouterWithOptions(opts)
+	defer s.cache.stop()
+	s.Get("/items", handler)
+	s.Get("/items", handler)
+	if err := s.Build(); err == nil || strings.Count(err.Error(), "callsite_test.go:") != 2 {
+		t.Errorf("Expected both registration sites in the strict mode error, got %v", err)
+	}
+
+	// Without the option, no call site is recorded
+	plain := NewRouter()
+	defer plain.cache.stop()
+	plain.Get("/items", handler)
+	plain.Get("/items", handler)
+	if err := plain.Build(); err == nil || strings.Contains(err.Error(), "defined at") {
+		t.Errorf("Expected a duplicate error without call sites, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrNext is returned by a handler composed with Chain or FirstOf to pass the request on to the
+// next handler. If no handler of the composition handles the request, the composition returns
+// ErrNext, and the router responds as if no route had matched (404 or the not-found handler).
+var ErrNext = errors.New("router: pass the request to the next handler")
+
+// Chain composes handlers that run in order, so that simple pre-checks can be written as plain
+// handlers instead of MiddlewareFuncs. A handler continues the chain by returning ErrNext;
+// any other result (nil after writing a response, or an error) ends the chain and is returned.
+//
+// 例: r.Get("/admin", router.Chain(requireAdmin, rateLimit, showAdmin))
+func Chain(handlers ...HandlerFunc) HandlerFunc {
+	return firstHandled(handlers)
+}
+
+// FirstOf composes alternative handlers: each is tried in order until one does not return ErrNext,
+// for example to serve a file if it exists and fall back to a generated page otherwise.
+// The result of that handler is returned.
+//
+// 例: r.Get("/docs/{page}", router.FirstOf(serveFile, renderPage))
+func FirstOf(handlers ...HandlerFunc) HandlerFunc {
+	return firstHandled(handlers)
+}
+
+// firstHandled calls the handlers in order until one does not return ErrNext.
+func firstHandled(handlers []HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		for _, h := range handlers {
+			if err := h(w, r); !errors.Is(err, ErrNext) {
+				return err
+			}
+		}
+		return ErrNext
+	}
+}
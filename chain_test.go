@@ -0,0 +1,72 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestChainAndFirstOf tests composing handlers that pass requests on with ErrNext
+func TestChainAndFirstOf(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	requireToken := func(w http.ResponseWriter, req *http.Request) error {
+		if req.Header.Get("Authorization") == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return nil
+		}
+		return ErrNext
+	}
+	failOn := func(w http.ResponseWriter, req *http.Request) error {
+		if req.URL.Query().Get("fail") != "" {
+			return errors.New("check failed")
+		}
+		return ErrNext
+	}
+	respond := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			w.Write([]byte(body))
+			return nil
+		}
+	}
+	only := func(page string, h HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			if p, _ := GetParams(req.Context()).Get("page"); p != page {
+				return ErrNext
+			}
+			return h(w, req)
+		}
+	}
+	r.Get("/admin", Chain(requireToken, failOn, respond("admin")))
+	r.Get("/docs/{page}", FirstOf(only("intro", respond("file")), only("guide", respond("generated"))))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		url    string
+		token  string
+		status int
+		body   string
+	}{
+		{"/admin", "", http.StatusUnauthorized, "unauthorized\n"},
+		{"/admin", "secret", http.StatusOK, "admin"},
+		{"/admin?fail=1", "secret", http.StatusInternalServerError, "Internal Server Error\n"},
+		{"/docs/intro", "", http.StatusOK, "file"},
+		{"/docs/guide", "", http.StatusOK, "generated"},
+		{"/docs/other", "", http.StatusNotFound, "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", tt.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.url, tt.status, tt.body, w.Code, w.Body.String())
+		}
+	}
+}
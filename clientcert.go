@@ -0,0 +1,84 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+)
+
+// ErrClientCertRequired is the cause of a ClientCertError for requests that were not served over
+// TLS or did not present a client certificate.
+var ErrClientCertRequired = errors.New("client certificate required")
+
+// ClientCertError is the error of requests rejected by Route.WithClientCert. It is returned
+// wrapped in a StatusError with status 403 Forbidden.
+type ClientCertError struct {
+	Subject string // Subject of the client certificate ("" if none was presented)
+	Err     error  // Error returned by the verify function, or ErrClientCertRequired
+}
+
+// Error returns the reason of the rejection.
+func (e *ClientCertError) Error() string {
+	if e.Subject == "" {
+		return "client certificate rejected: " + e.Err.Error()
+	}
+	return "client certificate " + e.Subject + " rejected: " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the rejection.
+func (e *ClientCertError) Unwrap() error {
+	return e.Err
+}
+
+// clientCertPolicy requires a verified TLS client certificate for a route.
+type clientCertPolicy struct {
+	verify func(*tls.ConnectionState) error // Accepts or rejects the connection state of a request
+}
+
+// clientCertKey is the context key for the verified client certificate.
+type clientCertKey struct{}
+
+// WithClientCert restricts the route to clients presenting a TLS client certificate that verify
+// accepts, so that mTLS-only endpoints are enforced at the router even when the listener requests
+// but does not require client certificates (tls.RequestClientCert or tls.VerifyClientCertIfGiven).
+// verify receives the connection state of the request and returns an error to reject it, for
+// example when the subject of the certificate is not an allowed service.
+//
+// Requests without TLS or without a client certificate are rejected without calling verify.
+// Rejections are 403 Forbidden with a StatusError wrapping a ClientCertError, rendered like the
+// errors of WithIPAllow. The accepted certificate is available through ClientCertificate.
+// A nil verify is reported by Build.
+//
+// 例: r.Post("/internal/sync", sync).WithClientCert(func(cs *tls.ConnectionState) error { return allowService(cs.PeerCertificates[0]) })
+func (r *Route) WithClientCert(verify func(*tls.ConnectionState) error) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.clientCert = &clientCertPolicy{verify: verify}
+	return r
+}
+
+// check returns a handler that rejects requests without an accepted client certificate.
+func (p *clientCertPolicy) check(route *Route, next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+			return route.reject(w, &StatusError{Status: http.StatusForbidden, Err: &ClientCertError{Err: ErrClientCertRequired}})
+		}
+		leaf := req.TLS.PeerCertificates[0]
+		if err := p.verify(req.TLS); err != nil {
+			return route.reject(w, &StatusError{Status: http.StatusForbidden, Err: &ClientCertError{Subject: leaf.Subject.String(), Err: err}})
+		}
+		return next(w, req.WithContext(context.WithValue(req.Context(), clientCertKey{}, leaf)))
+	}
+}
+
+// ClientCertificate returns the client certificate accepted by Route.WithClientCert for the
+// request. Returns nil for routes without a client certificate requirement.
+func ClientCertificate(ctx context.Context) *x509.Certificate {
+	cert, _ := ctx.Value(clientCertKey{}).(*x509.Certificate)
+	return cert
+}
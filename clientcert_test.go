@@ -0,0 +1,91 @@
+package router
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestWithClientCert tests enforcing client certificates on a route
+func TestWithClientCert(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	errUnknownService := errors.New("unknown service")
+	var accepted string
+	r.Post("/internal/sync", func(w http.ResponseWriter, req *http.Request) error {
+		accepted = ClientCertificate(req.Context()).Subject.CommonName
+		return nil
+	}).WithClientCert(func(cs *tls.ConnectionState) error {
+		if cs.PeerCertificates[0].Subject.CommonName != "billing" {
+			return errUnknownService
+		}
+		return nil
+	})
+	var rejection *ClientCertError
+	r.Post("/internal/detailed", func(w http.ResponseWriter, req *http.Request) error {
+		return nil
+	}).WithClientCert(func(cs *tls.ConnectionState) error {
+		return errUnknownService
+	}).WithErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {
+		errors.As(err, &rejection)
+		http.Error(w, err.Error(), http.StatusForbidden)
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	withCert := func(path, name string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if name != "" {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: name}}}}
+		}
+		return req
+	}
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"plain HTTP", httptest.NewRequest(http.MethodPost, "/internal/sync", nil), http.StatusForbidden},
+		{"no certificate", func() *http.Request {
+			req := withCert("/internal/sync", "")
+			req.TLS = &tls.ConnectionState{}
+			return req
+		}(), http.StatusForbidden},
+		{"rejected certificate", withCert("/internal/sync", "search"), http.StatusForbidden},
+		{"accepted certificate", withCert("/internal/sync", "billing"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, tt.req)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+	}
+	if accepted != "billing" {
+		t.Errorf("Expected the accepted certificate in the context, got %q", accepted)
+	}
+
+	// The error handler receives the details of the rejection
+	r.ServeHTTP(httptest.NewRecorder(), withCert("/internal/detailed", "search"))
+	if rejection == nil || rejection.Subject != "CN=search" || !errors.Is(rejection, errUnknownService) {
+		t.Errorf("Expected a ClientCertError for CN=search, got %v", rejection)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), withCert("/internal/detailed", ""))
+	if !errors.Is(rejection, ErrClientCertRequired) {
+		t.Errorf("Expected ErrClientCertRequired, got %v", rejection)
+	}
+
+	// A nil verify function is reported by Build
+	r2 := NewRouter()
+	defer r2.cache.stop()
+	r2.Get("/", func(w http.ResponseWriter, req *http.Request) error { return nil }).WithClientCert(nil)
+	if err := r2.Build(); err == nil {
+		t.Error("Expected Build to report the nil verify function")
+	}
+}
@@ -0,0 +1,182 @@
+package router
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// ErrIPForbidden is the error of requests rejected by Route.WithIPAllow or Route.WithIPDeny.
+// It is returned wrapped in a StatusError with status 403 Forbidden.
+var ErrIPForbidden = errors.New("client IP address is not allowed")
+
+// trustedProxiesKey is the context key for the trusted proxies of the router.
+type trustedProxiesKey struct{}
+
+// ClientIP returns the IP address of the client of the request. If the connection comes from a
+// trusted proxy (see RouterOptions.TrustedProxies), the X-Forwarded-For header is read from right
+// to left, skipping trusted proxies, and the first untrusted address is the client. Otherwise the
+// address of the connection is returned, so that clients cannot spoof their address.
+func ClientIP(r *http.Request) string {
+	peer := remoteIP(r.RemoteAddr)
+	proxies, _ := r.Context().Value(trustedProxiesKey{}).([]netip.Prefix)
+	if len(proxies) == 0 || !peer.IsValid() || !containsIP(proxies, peer) {
+		if peer.IsValid() {
+			return peer.String()
+		}
+		return r.RemoteAddr
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = hop.Unmap()
+		if !containsIP(proxies, client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// remoteIP parses the IP address of RemoteAddr (host:port or a bare address).
+func remoteIP(remoteAddr string) netip.Addr {
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// containsIP reports whether one of the prefixes contains the address.
+func containsIP(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPPrefix parses a CIDR prefix ("10.0.0.0/8") or a single IP address.
+func parseIPPrefix(s string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parseTrustedProxies parses RouterOptions.TrustedProxies, logging and skipping invalid entries.
+func (r *Router) parseTrustedProxies(entries []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		prefix, err := parseIPPrefix(entry)
+		if err != nil {
+			r.logf(slog.LevelWarn, "ignoring invalid trusted proxy %q: %v", entry, err)
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes
+}
+
+// prefixStrings formats the prefixes as strings (nil if there are none).
+func prefixStrings(prefixes []netip.Prefix) []string {
+	var s []string
+	for _, prefix := range prefixes {
+		s = append(s, prefix.String())
+	}
+	return s
+}
+
+// ipPolicy restricts the client addresses of a route.
+type ipPolicy struct {
+	allow []netip.Prefix // Allowed prefixes (empty allows all addresses that are not denied)
+	deny  []netip.Prefix // Denied prefixes, which take precedence over allowed ones
+	err   error          // First invalid prefix, reported by Build
+}
+
+// WithIPAllow restricts the route to clients whose address (see ClientIP) is in one of the CIDR
+// prefixes or addresses, for example admin endpoints or webhooks from published IP ranges.
+// Other clients are rejected with a StatusError wrapping ErrIPForbidden, which the error handler of
+// the route or group, the 403 error page, or the router's error handler renders; without any of
+// them, a plain 403 Forbidden is sent. Invalid prefixes are reported by Build.
+//
+// 例: r.Post("/hooks/github", onPush).WithIPAllow("192.30.252.0/22", "185.199.108.0/22")
+func (r *Route) WithIPAllow(cidrs ...string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	if r.ipPolicy == nil {
+		r.ipPolicy = &ipPolicy{}
+	}
+	r.ipPolicy.allow = r.ipPolicy.add(r.ipPolicy.allow, cidrs)
+	return r
+}
+
+// WithIPDeny rejects clients whose address is in one of the CIDR prefixes or addresses with
+// 403 Forbidden, even if WithIPAllow allows them. Invalid prefixes are reported by Build.
+func (r *Route) WithIPDeny(cidrs ...string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	if r.ipPolicy == nil {
+		r.ipPolicy = &ipPolicy{}
+	}
+	r.ipPolicy.deny = r.ipPolicy.add(r.ipPolicy.deny, cidrs)
+	return r
+}
+
+// clone returns a copy of the policy (nil for a nil policy).
+func (p *ipPolicy) clone() *ipPolicy {
+	if p == nil {
+		return nil
+	}
+	return &ipPolicy{allow: slices.Clone(p.allow), deny: slices.Clone(p.deny), err: p.err}
+}
+
+// add parses the entries into prefixes, recording the first invalid entry.
+func (p *ipPolicy) add(prefixes []netip.Prefix, entries []string) []netip.Prefix {
+	for _, entry := range entries {
+		prefix, err := parseIPPrefix(entry)
+		if err != nil {
+			if p.err == nil {
+				p.err = &RouterError{Code: ErrInvalidPattern, Message: "invalid IP prefix: " + entry}
+			}
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes
+}
+
+// check returns a handler that rejects requests from clients the policy does not allow.
+func (p *ipPolicy) check(route *Route, next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		addr, err := netip.ParseAddr(ClientIP(r))
+		if err != nil || containsIP(p.deny, addr) || (len(p.allow) > 0 && !containsIP(p.allow, addr)) {
+			return route.reject(w, &StatusError{Status: http.StatusForbidden, Err: ErrIPForbidden})
+		}
+		return next(w, r)
+	}
+}
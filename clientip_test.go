@@ -0,0 +1,129 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestClientIP tests resolving the client address behind trusted proxies
+func TestClientIP(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.1"}
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	r.Get("/ip", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte(ClientIP(req)))
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"203.0.113.9:1234", "", "203.0.113.9"},
+		{"203.0.113.9:1234", "198.51.100.1", "203.0.113.9"},                   // Untrusted peers cannot spoof
+		{"10.1.1.1:1234", "198.51.100.1", "198.51.100.1"},                     // Through a trusted proxy
+		{"10.1.1.1:1234", "6.6.6.6, 198.51.100.1, 192.0.2.1", "198.51.100.1"}, // Chain of trusted proxies
+		{"10.1.1.1:1234", "10.2.2.2", "10.2.2.2"},                             // Only trusted addresses
+		{"10.1.1.1:1234", "garbage", "10.1.1.1"},
+		{"[::ffff:10.1.1.1]:1234", "2001:db8::1", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != tt.want {
+			t.Errorf("%s (%s): expected %s, got %s", tt.remoteAddr, tt.forwarded, tt.want, w.Body.String())
+		}
+	}
+
+	// Without trusted proxies, the header is ignored
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = "10.1.1.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if ip := ClientIP(req); ip != "10.1.1.1" {
+		t.Errorf("Expected the connection address, got %s", ip)
+	}
+}
+
+// TestIPAllowDeny tests restricting routes to client address ranges
+func TestIPAllowDeny(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var rejected error
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/admin", handler).WithIPAllow("10.0.0.0/8").WithIPDeny("10.6.6.0/24")
+	r.Get("/public", handler).WithIPDeny("203.0.113.0/24")
+	r.Get("/hook", handler).WithIPAllow("198.51.100.7").WithErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {
+		rejected = err
+		w.WriteHeader(http.StatusForbidden)
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		remoteAddr string
+		status     int
+	}{
+		{"/admin", "10.1.2.3:1000", http.StatusOK},
+		{"/admin", "10.6.6.6:1000", http.StatusForbidden},
+		{"/admin", "203.0.113.1:1000", http.StatusForbidden},
+		{"/public", "198.51.100.1:1000", http.StatusOK},
+		{"/public", "203.0.113.1:1000", http.StatusForbidden},
+		{"/hook", "198.51.100.7:1000", http.StatusOK},
+		{"/hook", "198.51.100.8:1000", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.RemoteAddr = tt.remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s from %s: expected %d, got %d", tt.path, tt.remoteAddr, tt.status, w.Code)
+		}
+	}
+	var statusErr *StatusError
+	if !errors.Is(rejected, ErrIPForbidden) || !errors.As(rejected, &statusErr) || statusErr.Status != http.StatusForbidden {
+		t.Errorf("Expected a typed 403 error, got %v", rejected)
+	}
+
+	// The 403 error page renders rejections
+	if err := r.SetErrorPage(http.StatusForbidden, func(w http.ResponseWriter, req *http.Request) error {
+		info, _ := GetErrorPageInfo(req.Context())
+		if errors.Is(info.Err, ErrIPForbidden) {
+			w.Write([]byte("address not allowed"))
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to set error page: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.RemoteAddr = "203.0.113.1:1000"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden || w.Body.String() != "address not allowed" {
+		t.Errorf("Expected the error page, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Invalid prefixes are reported by Build
+	r2 := NewRouter()
+	defer r2.cache.stop()
+	r2.Get("/admin", handler).WithIPAllow("10.0.0.0/33")
+	if err := r2.Build(); err == nil {
+		t.Error("Expected an error for an invalid prefix")
+	}
+}
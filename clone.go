@@ -0,0 +1,126 @@
+package router
+
+import (
+	"maps"
+	"slices"
+)
+
+// handledRoute is a route registered directly with Router.Handle.
+type handledRoute struct {
+	method  string
+	pattern string
+	handler HandlerFunc
+}
+
+// Clone returns a new router with a deep copy of the route definitions, groups, middleware,
+// handlers, and options of r. The cache, usage statistics, and registered cleanup functions
+// are not copied; cleanup remains the responsibility of the original router.
+//
+// Routes registered with Handle are registered with the clone immediately; routes defined with
+// Route/Get/... and groups are copied as definitions, so Build must be called on the clone
+// (even if r has already been built). Tests and canary processes can use Clone to derive
+// variant routers, for example with an additional debug route, without mutating the original.
+func (r *Router) Clone() (*Router, error) {
+	r.mu.RLock()
+	clone := NewRouterWithOptions(r.options())
+	clone.errorHandler = r.errorHandler
+	clone.shutdownHandler = r.shutdownHandler
+	clone.timeoutHandler = r.timeoutHandler
+	clone.notFoundHandler = r.notFoundHandler
+	clone.methodHandler = r.methodHandler
+	clone.panicHandler = r.panicHandler
+	clone.errorPages = maps.Clone(r.errorPages)
+	clone.mounts = slices.Clone(r.mounts)
+	clone.slowRequest = r.slowRequest
+	clone.hooks = r.hooks.clone()
+	clone.buildChecks = slices.Clone(r.buildChecks)
+	clone.auths = maps.Clone(r.auths)
+	clone.middleware.Store(slices.Clone(r.middleware.Load().([]MiddlewareFunc)))
+	clone.stacks = slices.Clone(r.stacks)
+	clone.tenantExtractor = r.tenantExtractor
+
+	for _, route := range r.routes {
+		clone.routes = append(clone.routes, route.copyFor(clone, nil))
+	}
+	for _, g := range r.groups {
+		clone.groups = append(clone.groups, g.bind(clone, nil))
+	}
+	handled := slices.Clone(r.handled)
+	tenants := maps.Clone(r.tenants)
+	r.mu.RUnlock()
+
+	// The clone composes the shared stacks of r, so changes to them must reach it too
+	for _, stack := range clone.stacks {
+		stack.attach(clone)
+	}
+
+	// Tenant overlays are cloned with their routes and linked to the clone
+	for tenant, overlay := range tenants {
+		overlayClone, err := overlay.Clone()
+		if err != nil {
+			clone.routeCache.Close()
+			return nil, err
+		}
+		overlayClone.tenantParent = clone
+		if clone.tenants == nil {
+			clone.tenants = make(map[string]*Router)
+		}
+		clone.tenants[tenant] = overlayClone
+	}
+
+	// Replay the routes that were registered immediately
+	for _, h := range handled {
+		if err := clone.Handle(h.method, h.pattern, h.handler); err != nil {
+			clone.routeCache.Close()
+			return nil, err
+		}
+	}
+	for _, g := range clone.allGroups() {
+		handledInGroup := g.handled
+		g.handled = nil
+		for _, route := range handledInGroup {
+			if err := g.Handle(route.method, route.subPath, route.handler); err != nil {
+				clone.routeCache.Close()
+				return nil, err
+			}
+		}
+	}
+
+	return clone, nil
+}
+
+// options returns the options the router was created with. The caller must hold r.mu.
+func (r *Router) options() RouterOptions {
+	r.timeoutMu.RLock()
+	defer r.timeoutMu.RUnlock()
+	return RouterOptions{
+		AllowRouteOverride:   r.override == OverrideLastWins,
+		OverrideStrategy:     r.override,
+		RequestTimeout:       r.requestTimeout,
+		CacheMaxEntries:      r.cacheMaxEntries,
+		NewRouteCache:        r.newRouteCache,
+		MaxPathLength:        r.maxPathLength,
+		MaxSegments:          r.maxSegments,
+		MaxHeaderCount:       r.maxHeaderCount,
+		MaxHeaderBytes:       r.maxHeaderBytes,
+		MaxBodyBytes:         r.maxBodyBytes,
+		MaxRegexEvaluations:  r.maxRegexEvals,
+		PerRequestMiddleware: r.perRequestMiddleware,
+		MaxDispatchDepth:     r.maxDispatchDepth,
+		DevMode:              r.devMode,
+		Strict:               r.strict,
+		StaticSegmentChars:   r.segmentChars,
+		RecordRouteSites:     r.recordSites,
+		Logger:               r.logger,
+		ExpectCheck:          r.expectCheck,
+		TrieInitialSize:      r.trieInitialSize,
+		TrieGrowthFactor:     r.static.growth,
+		ParamsCapacityHint:   r.paramsPool.capacity,
+		CacheByPattern:       r.patternCache != nil,
+		FirstSegmentIndex:    r.firstSegments != nil,
+		LatencyHistograms:    r.latencies,
+		MaxInFlight:          r.gate.limit(),
+		QueueTimeout:         r.gate.queueTimeout(),
+		TrustedProxies:       prefixStrings(r.proxies),
+	}
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// TestRouterClone tests deriving a variant router without mutating the original
+func TestRouterClone(t *testing.T) {
+	base := NewRouter()
+	defer base.cache.stop()
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	base.Get("/users/{id}", handler)
+	api := base.Group("/api")
+	api.Get("/status", handler)
+	if err := api.Handle(http.MethodPost, "/events", handler); err != nil {
+		t.Fatalf("Failed to register route: %v", err)
+	}
+	if err := base.Handle(http.MethodGet, "/legacy", handler); err != nil {
+		t.Fatalf("Failed to register route: %v", err)
+	}
+	if err := base.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	clone, err := base.Clone()
+	if err != nil {
+		t.Fatalf("Failed to clone router: %v", err)
+	}
+	defer clone.cache.stop()
+
+	clone.Get("/debug", handler)
+	if err := clone.Build(); err != nil {
+		t.Fatalf("Failed to build clone: %v", err)
+	}
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/users/1"},
+		{http.MethodGet, "/api/status"},
+		{http.MethodPost, "/api/events"},
+		{http.MethodGet, "/legacy"},
+		{http.MethodGet, "/debug"},
+	} {
+		if _, ok := clone.Match(tc.method, tc.path); !ok {
+			t.Errorf("Clone does not match %s %s", tc.method, tc.path)
+		}
+	}
+
+	if _, ok := base.Match(http.MethodGet, "/debug"); ok {
+		t.Error("Adding a route to the clone should not affect the original")
+	}
+	if clone.groups[0] == base.groups[0] || clone.groups[0].routes[0].router != clone {
+		t.Error("Clone should have its own copy of the groups")
+	}
+}
+
+// TestRouterCloneStack tests that changes to a shared middleware stack reach the clone
+func TestRouterCloneStack(t *testing.T) {
+	tag := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) error {
+				w.Header().Add("X-Middleware", name)
+				return next(w, req)
+			}
+		}
+	}
+
+	stack := NewMiddlewareStack(tag("logging"))
+	base := NewRouter()
+	defer base.cache.stop()
+	base.UseStack(stack)
+	base.Get("/status", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	if err := base.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	clone, err := base.Clone()
+	if err != nil {
+		t.Fatalf("Failed to clone router: %v", err)
+	}
+	defer clone.cache.stop()
+	if err := clone.Build(); err != nil {
+		t.Fatalf("Failed to build clone: %v", err)
+	}
+
+	serve := func(r *Router) []string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+		return w.Header().Values("X-Middleware")
+	}
+
+	// Serve once so that both routers cache their chains
+	for _, r := range []*Router{base, clone} {
+		serve(r)
+	}
+	stack.Use(tag("metrics"))
+	for name, r := range map[string]*Router{"original": base, "clone": clone} {
+		if got, want := serve(r), []string{"metrics", "logging"}; !slices.Equal(got, want) {
+			t.Errorf("Expected %s middleware %v, got %v", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package router
+
+import "net/http"
+
+// When returns middleware that applies mw only to requests for which predicate returns true.
+// Other requests are passed directly to the next handler.
+func When(predicate func(*http.Request) bool, mw MiddlewareFunc) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		wrapped := mw(next)
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if predicate(r) {
+				return wrapped(w, r)
+			}
+			return next(w, r)
+		}
+	}
+}
+
+// Unless returns middleware that applies mw only to requests for which predicate returns false.
+func Unless(predicate func(*http.Request) bool, mw MiddlewareFunc) MiddlewareFunc {
+	return When(func(r *http.Request) bool { return !predicate(r) }, mw)
+}
+
+// PathMatcher returns a predicate that reports whether the request path matches any of the patterns.
+// The patterns use the same syntax as routes ({param} and {param:regex}), and a path matches
+// a pattern when every segment matches, exactly as a route would.
+// An error is returned if any pattern is invalid.
+func PathMatcher(patterns ...string) (func(*http.Request) bool, error) {
+	routes, err := compilePathPatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return func(r *http.Request) bool {
+		pathSegments := splitMatchPath(normalizePath(r.URL.Path))
+		for _, route := range routes {
+			if route.matches(pathSegments) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// PathPrefixMatcher returns a predicate that reports whether the request path starts with
+// any of the patterns. Unlike strings.HasPrefix, the prefix is compared segment by segment,
+// so the pattern /api matches /api and /api/users but not /apiv2.
+// An error is returned if any pattern is invalid.
+func PathPrefixMatcher(patterns ...string) (func(*http.Request) bool, error) {
+	routes, err := compilePathPatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return func(r *http.Request) bool {
+		pathSegments := splitMatchPath(normalizePath(r.URL.Path))
+		for _, route := range routes {
+			if len(route.segments) <= len(pathSegments) && route.matches(pathSegments[:len(route.segments)]) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// MatchPath is like PathMatcher but panics if a pattern is invalid.
+// It simplifies the initialization of middleware with fixed patterns.
+func MatchPath(patterns ...string) func(*http.Request) bool {
+	predicate, err := PathMatcher(patterns...)
+	if err != nil {
+		panic(err)
+	}
+	return predicate
+}
+
+// MatchPathPrefix is like PathPrefixMatcher but panics if a pattern is invalid.
+func MatchPathPrefix(patterns ...string) func(*http.Request) bool {
+	predicate, err := PathPrefixMatcher(patterns...)
+	if err != nil {
+		panic(err)
+	}
+	return predicate
+}
+
+// compilePathPatterns validates and parses patterns for path predicates.
+// Patterns are parsed by the reference matcher, which evaluates them the same way as routes.
+func compilePathPatterns(patterns []string) ([]referenceRoute, error) {
+	m := &referenceMatcher{}
+	for _, pattern := range patterns {
+		if err := validatePattern(pattern); err != nil {
+			return nil, err
+		}
+		if err := m.add(pattern, 0); err != nil {
+			return nil, err
+		}
+	}
+	return m.routes, nil
+}
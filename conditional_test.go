@@ -0,0 +1,70 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestWhenUnless tests the conditional middleware combinators
+func TestWhenUnless(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	tag := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) error {
+				w.Header().Add("X-Middleware", name)
+				return next(w, req)
+			}
+		}
+	}
+
+	r.Use(When(MatchPathPrefix("/admin"), tag("auth")))
+	r.Use(Unless(MatchPath("/health", "/users/{id:[0-9]+}"), tag("log")))
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	for _, path := range []string{"/admin", "/admin/users", "/adminx", "/health", "/users/{id}"} {
+		r.Get(path, handler)
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/admin", []string{"log", "auth"}},
+		{"/admin/users", []string{"log", "auth"}},
+		{"/adminx", []string{"log"}},
+		{"/health", nil},
+		{"/users/123", nil},
+		{"/users/abc", []string{"log"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		got := w.Header().Values("X-Middleware")
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: expected middleware %v, got %v", tt.path, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: expected middleware %v, got %v", tt.path, tt.want, got)
+				break
+			}
+		}
+	}
+}
+
+// TestPathMatcherInvalidPattern tests that invalid patterns are reported
+func TestPathMatcherInvalidPattern(t *testing.T) {
+	if _, err := PathMatcher("/users/{id:[0-9}"); err == nil {
+		t.Error("Expected an error for an invalid regex pattern")
+	}
+	if _, err := PathPrefixMatcher(""); err == nil {
+		t.Error("Expected an error for an empty pattern")
+	}
+}
@@ -0,0 +1,187 @@
+package router
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsMetaKey is the metadata key under which per-route and per-group CORS policies are stored.
+const corsMetaKey = "router.cors"
+
+// CORSOptions is a Cross-Origin Resource Sharing policy.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to make cross-origin requests.
+	// "*" allows any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods lists the methods allowed in preflight requests.
+	// Default: GET, HEAD, POST
+	AllowedMethods []string
+
+	// AllowedHeaders lists the request headers allowed in preflight requests.
+	// If empty, the headers requested by the client are allowed.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists the response headers that browsers may expose to scripts.
+	ExposedHeaders []string
+
+	// AllowCredentials allows requests with credentials (cookies, HTTP authentication).
+	// With credentials, the request origin is echoed instead of "*".
+	AllowCredentials bool
+
+	// MaxAge is how long the result of a preflight request may be cached (0 means not specified).
+	MaxAge time.Duration
+}
+
+// defaultCORSMethods are the methods allowed when CORSOptions.AllowedMethods is empty.
+var defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// CORS returns middleware that applies a CORS policy to cross-origin requests and answers
+// preflight requests. Routes and groups can override the policy with WithCORS; the policy of the
+// route takes precedence over the policy of its groups, which takes precedence over opts.
+//
+// Preflight requests (OPTIONS with an Access-Control-Request-Method header) for which no OPTIONS
+// route is registered are dispatched by the router to the middleware of the requested route,
+// so the middleware must be registered with Router.Use to answer them.
+func CORS(opts CORSOptions) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return next(w, r)
+			}
+
+			policy := &opts
+			if value, ok := RouteMeta(r.Context(), corsMetaKey); ok {
+				policy = value.(*CORSOptions)
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := isPreflight(r)
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if !policy.allowsOrigin(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return nil
+				}
+				return next(w, r)
+			}
+
+			if slices.Contains(policy.AllowedOrigins, "*") && !policy.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if policy.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if len(policy.ExposedHeaders) > 0 {
+					h.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+				return next(w, r)
+			}
+
+			// Answer the preflight request
+			methods := policy.AllowedMethods
+			if len(methods) == 0 {
+				methods = defaultCORSMethods
+			}
+			if slices.Contains(methods, r.Header.Get("Access-Control-Request-Method")) {
+				h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				if len(policy.AllowedHeaders) > 0 {
+					h.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+				} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+					h.Set("Access-Control-Allow-Headers", requested)
+				}
+				if policy.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge/time.Second)))
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+	}
+}
+
+// allowsOrigin reports whether the policy allows the origin.
+func (o *CORSOptions) allowsOrigin(origin string) bool {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPreflight reports whether the request is a CORS preflight request.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// WithCORS sets a CORS policy for the route that takes precedence over the group and global policy
+// applied by the CORS middleware. It is useful for single endpoints such as webhooks or public
+// widgets that need a looser or stricter policy than their group.
+func (r *Route) WithCORS(opts CORSOptions) *Route {
+	return r.WithMeta(corsMetaKey, &opts)
+}
+
+// WithCORS sets a CORS policy for the routes of the group and its child groups
+// that takes precedence over the global policy applied by the CORS middleware.
+func (g *Group) WithCORS(opts CORSOptions) *Group {
+	return g.WithMeta(corsMetaKey, &opts)
+}
+
+// servePreflight dispatches a CORS preflight request for which no OPTIONS route exists
+// to the router middleware of the route for the requested method, with a handler that
+// answers 204 No Content. The route is matched like ServeHTTP matches requests, in the tenant
+// overlay (nil if none) first, and only while it is active. It returns false if the request is
+// not a preflight request or no route exists for the requested method.
+func (r *Router) servePreflight(rw *responseWriter, req *http.Request, overlay *Router) bool {
+	if !isPreflight(req) {
+		return false
+	}
+
+	method := req.Header.Get("Access-Control-Request-Method")
+	var match routeMatch
+	found := false
+	if overlay != nil {
+		match, found = overlay.findRoute(method, req.URL.Path)
+	}
+	if !found && (r.firstSegments == nil || r.firstSegments.allows(methodToUint8(method), req.URL.Path)) {
+		match, found = r.findRoute(method, req.URL.Path)
+	}
+	route := match.route
+	if !found || !route.activeAt(time.Now()) {
+		return false
+	}
+	if route != nil {
+		req = req.WithContext(contextWithRoute(req.Context(), route))
+	}
+
+	h := r.buildMiddlewareChain(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+	err := callRecovering(h, rw, req)
+	if pe := (*panicError)(nil); errors.As(err, &pe) {
+		r.logf(slog.LevelError, "Handler panic: %s %s: %v\n%s", req.Method, req.URL.Path, pe.value, pe.stack)
+	}
+	if err != nil && !rw.written.Load() {
+		r.serveError(rw, req, route, err)
+	}
+	return true
+}
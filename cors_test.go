@@ -0,0 +1,157 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestCORSOverrides tests the CORS middleware with per-group and per-route policies
+func TestCORSOverrides(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Use(CORS(CORSOptions{AllowedOrigins: []string{"https://app.example.com"}}))
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	api := r.Group("/api").WithCORS(CORSOptions{
+		AllowedOrigins:   []string{"https://admin.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPut},
+		AllowCredentials: true,
+		MaxAge:           time.Minute,
+	})
+	api.Get("/users/{id}", handler)
+	api.Put("/users/{id}", handler)
+	api.Get("/widget", handler).WithCORS(CORSOptions{AllowedOrigins: []string{"*"}})
+	r.Get("/home", handler)
+
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		name, method, path, origin, requestMethod string
+		status                                    int
+		allowOrigin, allowMethods                 string
+	}{
+		{"global policy", http.MethodGet, "/home", "https://app.example.com", "", http.StatusOK, "https://app.example.com", ""},
+		{"global policy rejects", http.MethodGet, "/home", "https://evil.example.com", "", http.StatusOK, "", ""},
+		{"group overrides global", http.MethodGet, "/api/users/1", "https://app.example.com", "", http.StatusOK, "", ""},
+		{"group policy", http.MethodGet, "/api/users/1", "https://admin.example.com", "", http.StatusOK, "https://admin.example.com", ""},
+		{"route overrides group", http.MethodGet, "/api/widget", "https://anyone.example.com", "", http.StatusOK, "*", ""},
+		{"preflight", http.MethodOptions, "/api/users/1", "https://admin.example.com", http.MethodPut, http.StatusNoContent, "https://admin.example.com", "GET, PUT"},
+		{"preflight disallowed method", http.MethodOptions, "/api/users/1", "https://admin.example.com", http.MethodDelete, http.StatusMethodNotAllowed, "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Origin", tt.origin)
+		if tt.requestMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+			t.Errorf("%s: expected Access-Control-Allow-Origin %q, got %q", tt.name, tt.allowOrigin, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.allowMethods {
+			t.Errorf("%s: expected Access-Control-Allow-Methods %q, got %q", tt.name, tt.allowMethods, got)
+		}
+	}
+}
+
+// TestSecureHeadersOverride tests that a route can override the security header policy
+func TestSecureHeadersOverride(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Use(SecureHeaders(DefaultSecureHeaders()))
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	r.Get("/page", handler)
+	widget := DefaultSecureHeaders()
+	widget.FrameOptions = ""
+	widget.ContentSecurityPolicy = "frame-ancestors *"
+	r.Get("/widget", handler).WithSecureHeaders(widget)
+
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil))
+	if w.Header().Get("X-Frame-Options") != "DENY" || w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Expected default security headers, got %v", w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/widget", nil))
+	if w.Header().Get("X-Frame-Options") != "" || w.Header().Get("Content-Security-Policy") != "frame-ancestors *" {
+		t.Errorf("Expected route security headers, got %v", w.Header())
+	}
+}
+
+// TestCORSPreflightRouting tests that preflight requests are matched and recovered like other requests
+func TestCORSPreflightRouting(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Tenant(func(req *http.Request) string { return req.Header.Get("X-Tenant") })
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			if req.Header.Get("X-Panic") != "" {
+				panic("middleware failed")
+			}
+			return next(w, req)
+		}
+	})
+	if err := r.SetErrorPage(http.StatusInternalServerError, func(w http.ResponseWriter, req *http.Request) error {
+		_, err := w.Write([]byte("error page"))
+		return err
+	}); err != nil {
+		t.Fatalf("Failed to set error page: %v", err)
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	r.Put("/items", handler)
+	r.ForTenant("acme").Delete("/items", handler)
+	r.Patch("/promo", handler).WithActiveWindow(time.Now().Add(time.Hour), time.Time{})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		name, path, requestMethod, tenant string
+		panics                            bool
+		status                            int
+		body                              string
+	}{
+		{"route", "/items", http.MethodPut, "", false, http.StatusNoContent, ""},
+		{"tenant overlay route", "/items", http.MethodDelete, "acme", false, http.StatusNoContent, ""},
+		{"no route for the tenant", "/items", http.MethodDelete, "", false, http.StatusMethodNotAllowed, ""},
+		{"route before its window", "/promo", http.MethodPatch, "", false, http.StatusNotFound, ""},
+		{"middleware panic", "/items", http.MethodPut, "", true, http.StatusInternalServerError, "error page"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+		req.Header.Set("X-Tenant", tt.tenant)
+		if tt.panics {
+			req.Header.Set("X-Panic", "1")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.name, tt.body, w.Body.String())
+		}
+	}
+}
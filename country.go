@@ -0,0 +1,58 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ErrCountryForbidden is the error of requests rejected by Route.WithCountryPolicy.
+// It is returned wrapped in a StatusError with status 451 Unavailable For Legal Reasons,
+// or 403 Forbidden when the country of the client is unknown.
+var ErrCountryForbidden = errors.New("client country is not allowed")
+
+// countryPolicy restricts the client countries of a route.
+type countryPolicy struct {
+	allow  []string               // Allowed country codes, upper case
+	lookup func(ip string) string // Country code of a client address ("" if unknown)
+}
+
+// WithCountryPolicy restricts the route to clients in the allowed countries, so that endpoints
+// under legal or compliance restrictions are gated before the handler runs.
+// lookup returns the country code (such as ISO 3166-1 alpha-2 "JP") of a client address as
+// returned by ClientIP, or "" if it is unknown; it can query a GeoIP database such as MaxMind.
+// Codes are compared case-insensitively.
+//
+// Clients in other countries are rejected with 451 Unavailable For Legal Reasons, and clients
+// whose country is unknown with 403 Forbidden. The StatusError wraps ErrCountryForbidden and is
+// rendered like the errors of WithIPAllow. A nil lookup is reported by Build.
+//
+// 例: r.Get("/offers", offers).WithCountryPolicy([]string{"JP", "US"}, geo.Country)
+func (r *Route) WithCountryPolicy(allow []string, lookup func(ip string) string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	codes := make([]string, len(allow))
+	for i, code := range allow {
+		codes[i] = strings.ToUpper(strings.TrimSpace(code))
+	}
+	r.country = &countryPolicy{allow: codes, lookup: lookup}
+	return r
+}
+
+// check returns a handler that rejects requests from clients outside the allowed countries.
+func (p *countryPolicy) check(route *Route, next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		country := strings.ToUpper(strings.TrimSpace(p.lookup(ClientIP(r))))
+		switch {
+		case country == "":
+			return route.reject(w, &StatusError{Status: http.StatusForbidden, Err: ErrCountryForbidden})
+		case !slices.Contains(p.allow, country):
+			return route.reject(w, &StatusError{Status: http.StatusUnavailableForLegalReasons, Err: ErrCountryForbidden})
+		}
+		return next(w, r)
+	}
+}
@@ -0,0 +1,61 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCountryPolicy tests restricting routes to client countries
+func TestCountryPolicy(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	countries := map[string]string{"192.0.2.1": "jp", "198.51.100.1": "US", "203.0.113.1": "DE"}
+	lookup := func(ip string) string { return countries[ip] }
+
+	var rejected error
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/offers", handler).WithCountryPolicy([]string{"JP", "us"}, lookup)
+	r.Get("/own", handler).WithCountryPolicy([]string{"JP"}, lookup).WithErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {
+		rejected = err
+		w.WriteHeader(http.StatusTeapot)
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		remoteAddr string
+		status     int
+	}{
+		{"/offers", "192.0.2.1:1000", http.StatusOK},
+		{"/offers", "198.51.100.1:1000", http.StatusOK},
+		{"/offers", "203.0.113.1:1000", http.StatusUnavailableForLegalReasons},
+		{"/offers", "10.0.0.1:1000", http.StatusForbidden}, // Unknown country
+		{"/own", "203.0.113.1:1000", http.StatusTeapot},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.RemoteAddr = tt.remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s from %s: expected %d, got %d", tt.path, tt.remoteAddr, tt.status, w.Code)
+		}
+	}
+	var statusErr *StatusError
+	if !errors.Is(rejected, ErrCountryForbidden) || !errors.As(rejected, &statusErr) || statusErr.Status != http.StatusUnavailableForLegalReasons {
+		t.Errorf("Expected a typed 451 error, got %v", rejected)
+	}
+
+	// A nil lookup is reported by Build
+	r2 := NewRouter()
+	defer r2.cache.stop()
+	r2.Get("/offers", handler).WithCountryPolicy([]string{"JP"}, nil)
+	if err := r2.Build(); err == nil {
+		t.Error("Expected an error for a nil lookup")
+	}
+}
@@ -0,0 +1,138 @@
+package router
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// devSlowMiddlewareThreshold is the time spent in middleware before the route handler
+// is called above which development mode logs a warning.
+const devSlowMiddlewareThreshold = 100 * time.Millisecond
+
+// devSampleValue is the parameter value used to generate sample paths when looking for
+// shadowed routes. It is unlikely to collide with a static segment.
+const devSampleValue = "__router_sample__"
+
+// devErrorHandler is the default error handler in development mode.
+// It renders a 500 page with the error chain and, for panics, the stack trace.
+func devErrorHandler(w http.ResponseWriter, req *http.Request, err error) {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><title>500 Internal Server Error</title></head><body>\n")
+	b.WriteString("<h1>500 Internal Server Error</h1>\n")
+	fmt.Fprintf(&b, "<p>%s %s</p>\n", html.EscapeString(req.Method), html.EscapeString(req.URL.Path))
+	if route := routeFromContext(req.Context()); route != nil {
+		fmt.Fprintf(&b, "<p>Route: %s</p>\n", html.EscapeString(route.method+" "+route.fullPath()))
+	}
+
+	b.WriteString("<h2>Error</h2>\n<ul>\n")
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		fmt.Fprintf(&b, "<li><code>%s</code></li>\n", html.EscapeString(e.Error()))
+	}
+	b.WriteString("</ul>\n")
+
+	var pe *panicError
+	if errors.As(err, &pe) {
+		fmt.Fprintf(&b, "<h2>Stack trace</h2>\n<pre>%s</pre>\n", html.EscapeString(string(pe.stack)))
+	}
+	b.WriteString("</body></html>\n")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprint(w, b.String())
+}
+
+// logRouteTable prints the routes of the router, sorted by pattern and method.
+func (r *Router) logRouteTable(routes []*Route) {
+	specs := make([]RouteSpec, 0, len(routes)+len(r.handled))
+	for _, route := range routes {
+		specs = append(specs, RouteSpec{Method: route.method, Pattern: route.fullPath()})
+	}
+	for _, h := range r.handled {
+		specs = append(specs, RouteSpec{Method: h.method, Pattern: normalizePath(h.pattern)})
+	}
+	slices.SortFunc(specs, func(a, b RouteSpec) int {
+		return cmp.Or(strings.Compare(a.Pattern, b.Pattern), strings.Compare(a.Method, b.Method))
+	})
+
+	r.logf(slog.LevelInfo, "Routes (%d):", len(specs))
+	for _, spec := range specs {
+		r.logf(slog.LevelInfo, "  %-7s %s", spec.Method, spec.Pattern)
+	}
+}
+
+// shadowedRoutes returns a warning for each dynamic route that can never be matched, because
+// a sample path generated from its pattern is matched by another route.
+// Segments with a regular expression cannot be sampled, so routes that have one are skipped.
+func (r *Router) shadowedRoutes(routes []*Route) []string {
+	var warnings []string
+	for _, route := range routes {
+		pattern := normalizePath(route.fullPath())
+		segments := parseSegments(pattern)
+		if isAllStatic(segments) {
+			continue
+		}
+
+		sample := make([]string, len(segments))
+		sampleable := true
+		for i, seg := range segments {
+			switch {
+			case !isDynamicSeg(seg):
+				sample[i] = seg
+			case strings.IndexByte(seg, ':') > 0:
+				sampleable = false
+			default:
+				sample[i] = devSampleValue
+			}
+		}
+		if !sampleable {
+			continue
+		}
+
+		matched, ok := r.Match(route.method, "/"+strings.Join(sample, "/"))
+		if ok && matched != pattern {
+			warnings = append(warnings, fmt.Sprintf("route %s %s is shadowed by %s", route.method, pattern, matched))
+		}
+	}
+	return warnings
+}
+
+// reportDevDiagnostics logs the route table and shadowed routes after a successful Build.
+func (r *Router) reportDevDiagnostics(routes []*Route) {
+	r.logRouteTable(routes)
+	for _, warning := range r.shadowedRoutes(routes) {
+		r.logf(slog.LevelWarn, "%s", warning)
+	}
+}
+
+// setDevMatchHeaders describes the match of the request in response headers, so that the route
+// that handled a request can be seen in the browser without access to the logs.
+// They are only set in development mode.
+func setDevMatchHeaders(h http.Header, match routeMatch, elapsed time.Duration) {
+	if match.stats != nil {
+		h.Set("X-Router-Matched", match.stats.pattern)
+	} else if match.route != nil {
+		h.Set("X-Router-Matched", match.route.fullPath())
+	}
+	h.Set("X-Router-Source", match.source.String())
+	h.Set("X-Router-Elapsed-Match", elapsed.String())
+}
+
+// warnSlowMiddleware logs a warning if the middleware of the request took longer than
+// devSlowMiddlewareThreshold before the route handler was called.
+func (r *Router) warnSlowMiddleware(req *http.Request, pattern string, start time.Time, tracker *timeoutTracker) {
+	if tracker.handlerStart.IsZero() {
+		return
+	}
+	if elapsed := tracker.handlerStart.Sub(start); elapsed > devSlowMiddlewareThreshold {
+		r.logf(slog.LevelWarn, "slow middleware: %s %s (route %s) spent %v before the handler",
+			req.Method, req.URL.Path, pattern, elapsed)
+	}
+}
@@ -0,0 +1,141 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDevMode tests the diagnostics enabled by development mode
+func TestDevMode(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	opts := defaultRouterOptions()
+	opts.DevMode = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		id, _ := GetParams(req.Context()).Get("id")
+		w.Write([]byte("user " + id))
+		return nil
+	})
+	r.Get("/users/{id}/posts", func(w http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	// Shadowed by /users/{id}/posts, which has the same shape and was registered first
+	r.Get("/users/{name}/posts", func(w http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	r.Get("/panic/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		panic("something <broke>")
+	})
+	r.Get("/fail/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		return errors.New("database unavailable")
+	})
+	r.Get("/slow/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		return nil
+	}).WithMiddleware(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			time.Sleep(devSlowMiddlewareThreshold + 20*time.Millisecond)
+			return next(w, req)
+		}
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// The route table is printed on Build
+	for _, line := range []string{"Routes (6):", "GET     /users/{id}", "GET     /slow/{id}"} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("Expected route table line %q in log output:\n%s", line, logs.String())
+		}
+	}
+
+	// Shadowed routes are reported on Build
+	if !strings.Contains(logs.String(), "route GET /users/{name}/posts is shadowed by /users/{id}/posts") {
+		t.Errorf("Expected a shadowed route warning in log output:\n%s", logs.String())
+	}
+
+	// Routes are matched without the cache, with parameters
+	for _, id := range []string{"1", "2", "1"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/"+id, nil))
+		if w.Body.String() != "user "+id {
+			t.Errorf("Expected body %q, got %q", "user "+id, w.Body.String())
+		}
+		if w.Header().Get("X-Router-Matched") != "/users/{id}" || w.Header().Get("X-Router-Source") != "dynamic" {
+			t.Errorf("Expected match headers, got %v", w.Header())
+		}
+		if _, err := time.ParseDuration(w.Header().Get("X-Router-Elapsed-Match")); err != nil {
+			t.Errorf("Expected the match duration, got %q", w.Header().Get("X-Router-Elapsed-Match"))
+		}
+	}
+	if _, _, _, found := r.cache.getRoute(generateRouteKey(methodToUint8(http.MethodGet), "/users/1")); found {
+		t.Error("Expected the route cache to be bypassed in development mode")
+	}
+
+	// Panics render a 500 page with the stack trace
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic/1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "panic: something &lt;broke&gt;") || !strings.Contains(body, "Stack trace") {
+		t.Errorf("Expected an escaped panic page with a stack trace, got:\n%s", body)
+	}
+
+	// Errors render a 500 page with the error
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail/1", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "database unavailable") {
+		t.Errorf("Expected an error page, got %d:\n%s", w.Code, w.Body.String())
+	}
+
+	// Slow middleware is reported
+	logs.Reset()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow/1", nil))
+	if !strings.Contains(logs.String(), "slow middleware: GET /slow/1") {
+		t.Errorf("Expected a slow middleware warning, got %q", logs.String())
+	}
+}
+
+// TestDevModeDisabled tests that development diagnostics are off by default
+func TestDevModeDisabled(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		return errors.New("database unavailable")
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no log output, got %q", logs.String())
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if strings.Contains(w.Body.String(), "database unavailable") {
+		t.Errorf("Expected the error to be hidden, got %q", w.Body.String())
+	}
+	if w.Header().Get("X-Router-Matched") != "" {
+		t.Error("Expected no match headers outside development mode")
+	}
+	if _, _, _, found := r.cache.getRoute(generateRouteKey(methodToUint8(http.MethodGet), "/users/1")); !found {
+		t.Error("Expected the route to be cached")
+	}
+}
@@ -0,0 +1,53 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxDispatchDepth is the default maximum number of nested Dispatch calls per request.
+const defaultMaxDispatchDepth = 10
+
+type dispatchDepthKey struct{}
+
+// Dispatch re-runs route matching for a different path within the same request, without an
+// external redirect. It is intended for alias handling and for rendering error pages with
+// regular routes. The path may include a query string, which replaces the query of the request;
+// otherwise the query is kept. The method, headers, and body of the request are unchanged.
+//
+// Parameters and the matched route of the current request are reset before matching, so the
+// dispatched handler only sees its own parameters. Dispatch returns an error without writing a
+// response if the nesting exceeds RouterOptions.MaxDispatchDepth; the response of the dispatched
+// request, including 404 and errors, is otherwise handled as for any request.
+//
+// 例: return r.Dispatch(w, req, "/docs/latest")
+func (r *Router) Dispatch(w http.ResponseWriter, req *http.Request, path string) error {
+	ctx := req.Context()
+	depth, _ := ctx.Value(dispatchDepthKey{}).(int)
+	if depth >= r.maxDispatchDepth {
+		return &RouterError{Code: ErrInternalError, Message: fmt.Sprintf("dispatch depth exceeded (%d): %s", r.maxDispatchDepth, path)}
+	}
+
+	ctx = context.WithValue(ctx, dispatchDepthKey{}, depth+1)
+	ctx = contextWithParams(ctx, nil)
+	ctx = contextWithRoute(ctx, nil)
+
+	dispatched := req.Clone(ctx)
+	path, query, hasQuery := strings.Cut(path, "?")
+	dispatched.URL.Path = normalizePath(path)
+	dispatched.URL.RawPath = ""
+	if hasQuery {
+		dispatched.URL.RawQuery = query
+	}
+
+	r.ServeHTTP(w, dispatched)
+	return nil
+}
+
+// DispatchDepth returns the number of Dispatch calls that led to the current request (0 if none).
+func DispatchDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(dispatchDepthKey{}).(int)
+	return depth
+}
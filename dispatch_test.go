@@ -0,0 +1,67 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestDispatch tests re-dispatching a request to a different path
+func TestDispatch(t *testing.T) {
+	r := NewRouterWithOptions(RouterOptions{MaxDispatchDepth: 3})
+	defer r.cache.stop()
+
+	var dispatchErr error
+	r.Get("/docs/{version}/{page}", func(w http.ResponseWriter, req *http.Request) error {
+		params := GetParams(req.Context())
+		version, _ := params.Get("version")
+		page, _ := params.Get("page")
+		fmt.Fprintf(w, "%s %s %s depth=%d", version, page, req.URL.Query().Get("q"), DispatchDepth(req.Context()))
+		return nil
+	})
+	r.Get("/latest/{page}", func(w http.ResponseWriter, req *http.Request) error {
+		page, _ := GetParams(req.Context()).Get("page")
+		return r.Dispatch(w, req, "/docs/v2/"+page)
+	})
+	r.Get("/loop/{n}", func(w http.ResponseWriter, req *http.Request) error {
+		err := r.Dispatch(w, req, req.URL.Path)
+		if err != nil {
+			dispatchErr = err
+		}
+		return err
+	})
+	r.Get("/search/{term}", func(w http.ResponseWriter, req *http.Request) error {
+		return r.Dispatch(w, req, "/docs/v1/search?q=go")
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		body string
+	}{
+		{"/latest/intro?q=x", "v2 intro x depth=1"},
+		{"/search/anything", "v1 search go depth=1"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.url, tt.body, w.Body.String())
+		}
+	}
+
+	// Dispatch loops stop at the maximum depth
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/loop/1", nil))
+	var routerErr *RouterError
+	if !errors.As(dispatchErr, &routerErr) {
+		t.Errorf("Expected a dispatch depth error, got %v", dispatchErr)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -10,9 +11,11 @@ type segmentType uint8
 
 // Constants defining segment types
 const (
-	staticSegment segmentType = iota // Static segment (normal string)
-	paramSegment                     // Parameter segment ({name} format)
-	regexSegment                     // Regular expression segment ({name:pattern} format)
+	staticSegment   segmentType = iota // Static segment (normal string)
+	paramSegment                       // Parameter segment ({name} format, or {} for an anonymous wildcard)
+	regexSegment                       // Regular expression segment ({name:pattern} format)
+	matcherSegment                     // Segment with a registered matcher ({name:matcher} format, see RegisterSegmentMatcher)
+	catchAllSegment                    // Catch-all segment ({*name} format) matching the rest of the path
 )
 
 // node represents a segment of a URL path.
@@ -20,24 +23,38 @@ const (
 // to efficiently manage route matching.
 type node struct {
 	segment     string         // Path segment this node represents
+	pattern     string         // Full route pattern (set only on nodes that have a handler)
 	handler     HandlerFunc    // Handler function associated with this node
+	route       *Route         // Route definition associated with this node (nil for routes registered with Handle)
+	stats       *routeStats    // Usage statistics of the route (set only on nodes that have a handler)
 	children    []*node        // List of child nodes
 	segmentType segmentType    // Segment type (static, parameter, regular expression)
 	regex       *regexp.Regexp // Regular expression pattern (used only when segType is regex)
+	custom      SegmentMatcher // Registered segment matcher (used only when segType is matcher)
+
+	classMatcher *byteClassMatcher // Hand-rolled matcher for simple regular expressions (nil if not applicable)
+	priority     int               // Priority among overlapping dynamic siblings (higher is tried first)
+	prioritySet  bool              // Whether a priority has been assigned by a route
 }
 
 // newNode creates and returns a new node.
 // It parses the pattern and sets the appropriate segment type.
-// It will panic if the regular expression pattern is invalid.
-func newNode(pattern string) *node {
+// It returns an error if the regular expression pattern is invalid.
+func newNode(pattern string) (*node, error) {
 	n := &node{
 		segment:  pattern,
 		children: make([]*node, 0, 8), // set initial capacity to 8 (sufficient for common cases)
 	}
 	if err := n.parseSegment(); err != nil {
-		panic(err)
+		return nil, err
 	}
-	return n
+	return n, nil
+}
+
+// newRootNode creates the root node of a dynamic route tree.
+// Its segment is empty, so it cannot fail to parse.
+func newRootNode() *node {
+	return &node{children: make([]*node, 0, 8)}
 }
 
 // addRoute adds a route pattern and handler to the tree.
@@ -45,29 +62,35 @@ func newNode(pattern string) *node {
 // Duplicate registration for the same path pattern results in an error.
 // Different parameter names for the same path pattern (e.g., /users/{id} and /users/{name}) also result in an error.
 // Conflicts in regular expression patterns are allowed and prioritized by registration order.
+// Static and dynamic segments can share a position (e.g., /users/admin/{tab} and /users/{id}/{tab});
+// matching tries the static segment first and falls back to the dynamic ones.
 // Using the same parameter name multiple times in the same route (e.g., /users/{id}/posts/{id}) also results in an error.
+// Anonymous segments ({}, or {:regex} with a constraint) can be repeated, since they capture no parameter.
 func (n *node) addRoute(segments []string, handler HandlerFunc) error {
+	// The full pattern is rebuilt from the segments so that the terminal node can report it
+	pattern := "/" + strings.Join(segments, "/")
+
 	// Map for checking duplicate parameter names
-	return n.addRouteWithParamCheck(segments, handler, make(map[string]struct{}))
+	return n.addRouteWithParamCheck(segments, pattern, handler, make(map[string]struct{}))
 }
 
 // addRouteWithParamCheck performs the actual route addition and checks for duplicate parameter names.
-func (n *node) addRouteWithParamCheck(segments []string, handler HandlerFunc, usedParams map[string]struct{}) error {
+func (n *node) addRouteWithParamCheck(segments []string, pattern string, handler HandlerFunc, usedParams map[string]struct{}) error {
 	// If all segments have been processed, set the handler for the current node
 	if len(segments) == 0 {
 		if n.handler != nil {
-			return &RouterError{Code: ErrInvalidPattern, Message: "duplicate pattern"}
+			return &RouterError{Code: ErrInvalidPattern, Message: "duplicate pattern", Err: ErrDuplicateRoute}
 		}
 		n.handler = handler
+		n.pattern = pattern
 		return nil
 	}
 
 	// get the current segment
 	currentSegment := segments[0]
 
-	// If it's a parameter segment, check for duplicate parameter names
-	if isDynamicSeg(currentSegment) {
-		paramName := extractParamName(currentSegment)
+	// If it's a named parameter segment, check for duplicate parameter names
+	if paramName := extractParamName(currentSegment); paramName != "" {
 		if _, exists := usedParams[paramName]; exists {
 			return &RouterError{
 				Code:    ErrInvalidPattern,
@@ -84,7 +107,10 @@ func (n *node) addRouteWithParamCheck(segments []string, handler HandlerFunc, us
 	// If a child node exists, check the segment type
 	if child != nil {
 		// Create a temporary node to get the segment type
-		tempNode := newNode(currentSegment)
+		tempNode, err := newNode(currentSegment)
+		if err != nil {
+			return err
+		}
 
 		// If the segment types are the same but the patterns are different, it's an error
 		// Example: /users/{id} and /users/{name} conflict
@@ -101,34 +127,50 @@ func (n *node) addRouteWithParamCheck(segments []string, handler HandlerFunc, us
 			}
 		}
 
-		// Check for mixing static segments and dynamic segments
-		if (tempNode.segmentType == staticSegment && (child.segmentType == paramSegment || child.segmentType == regexSegment)) ||
-			((tempNode.segmentType == paramSegment || tempNode.segmentType == regexSegment) && child.segmentType == staticSegment) {
-			return &RouterError{
-				Code:    ErrInvalidPattern,
-				Message: "conflicting segment types: static and dynamic segments cannot be mixed at the same position",
-			}
-		}
-
 		// Recursively process the remaining segments
-		return child.addRouteWithParamCheck(segments[1:], handler, usedParams)
+		return child.addRouteWithParamCheck(segments[1:], pattern, handler, usedParams)
 	}
 
 	// If no child node exists, create a new one
-	child = newNode(currentSegment)
+	child, err := newNode(currentSegment)
+	if err != nil {
+		return err
+	}
 	n.children = append(n.children, child)
+	n.sortChildren()
 
 	// Recursively process the remaining segments
-	return child.addRouteWithParamCheck(segments[1:], handler, usedParams)
+	return child.addRouteWithParamCheck(segments[1:], pattern, handler, usedParams)
+}
+
+// findRoute returns the node registered for exactly the given segments,
+// comparing pattern segments literally (regular expressions are not evaluated).
+// It returns nil if no such node exists.
+func (n *node) findRoute(segments []string) *node {
+	current := n
+	for _, seg := range segments {
+		current = current.findChild(seg)
+		if current == nil {
+			return nil
+		}
+	}
+	return current
 }
 
 // extractParamName extracts the parameter name from a parameter segment ({name} format).
+// It returns "" for anonymous segments ({} or {:regex}), which match a segment without capturing it.
+// Catch-all segments are named after the asterisk ({*filepath} is "filepath"), and {*} is named "*".
 func extractParamName(pattern string) string {
 	// Assume the pattern is in {name} format
 	if len(pattern) < 3 || pattern[0] != '{' || pattern[len(pattern)-1] != '}' {
 		return ""
 	}
 
+	// A catch-all segment is named after the asterisk
+	if pattern[1] == '*' && len(pattern) > 3 {
+		return pattern[2 : len(pattern)-1]
+	}
+
 	// If there's a colon, the part before the colon is the parameter name
 	if colonIdx := strings.IndexByte(pattern, ':'); colonIdx > 0 {
 		return pattern[1:colonIdx]
@@ -142,9 +184,20 @@ func extractParamName(pattern string) string {
 // If it matches, it returns the handler function and true; if it doesn't, it returns nil and false.
 // If parameters are extracted, they are added to params.
 func (n *node) match(path string, params *Params) (HandlerFunc, bool) {
-	// If the path is empty, return the handler for the current node
+	matchedNode, matched := n.matchNode(path, params)
+	if !matched {
+		return nil, false
+	}
+	return matchedNode.handler, true
+}
+
+// matchNode works like match but returns the matched node itself,
+// so that callers can also access the pattern registered on it.
+func (n *node) matchNode(path string, params *Params) (*node, bool) {
+	// If the path is empty, return the current node if it has a handler.
+	// A node without a handler is only an intermediate node, so other candidates must be tried.
 	if path == "" || path == "/" {
-		return n.handler, true
+		return n, n.handler != nil
 	}
 
 	// If the path starts with /, remove it
@@ -171,6 +224,9 @@ func (n *node) match(path string, params *Params) (HandlerFunc, bool) {
 	var staticMatches []*node
 	var paramMatches []*node
 	var regexMatches []*node
+	var matcherMatches []*node
+	var matcherValues []string
+	var catchAllMatches []*node
 
 	// Classify child nodes in one loop
 	for _, child := range n.children {
@@ -178,45 +234,82 @@ func (n *node) match(path string, params *Params) (HandlerFunc, bool) {
 			staticMatches = append(staticMatches, child)
 		} else if child.segmentType == paramSegment {
 			paramMatches = append(paramMatches, child)
-		} else if child.segmentType == regexSegment && child.regex.MatchString(currentSegment) {
-			regexMatches = append(regexMatches, child)
+		} else if child.segmentType == regexSegment {
+			// Stop matching when the per-request regex evaluation budget is exhausted
+			if !params.allowRegexEvaluation() {
+				return nil, false
+			}
+			if child.matchRegex(currentSegment) {
+				regexMatches = append(regexMatches, child)
+			}
+		} else if child.segmentType == matcherSegment {
+			if value, ok := child.custom.Match(currentSegment); ok {
+				matcherMatches = append(matcherMatches, child)
+				matcherValues = append(matcherValues, value)
+			}
+		} else if child.segmentType == catchAllSegment {
+			catchAllMatches = append(catchAllMatches, child)
 		}
 	}
 
 	// match static segments first
 	for _, child := range staticMatches {
-		handler, matched := child.match(remainingPath, params)
+		matchedNode, matched := child.matchNode(remainingPath, params)
 		if matched {
-			return handler, true
+			return matchedNode, true
 		}
 	}
 
 	// match parameter segments
 	for _, child := range paramMatches {
-		// Extract parameter name
-		paramName := extractParamName(child.segment)
-		// Add parameter
-		params.Add(paramName, currentSegment)
-		handler, matched := child.match(remainingPath, params)
+		// Add parameter (anonymous segments allocate no entry)
+		paramsLen := params.Len()
+		if paramName := extractParamName(child.segment); paramName != "" {
+			params.Add(paramName, currentSegment)
+		}
+		matchedNode, matched := child.matchNode(remainingPath, params)
 		if matched {
-			return handler, true
+			return matchedNode, true
 		}
-		// If no match, remove parameter (backtracking)
-		// Current implementation does not remove, uses overwrite method
+		// If no match, remove parameters added by this branch (backtracking)
+		params.truncate(paramsLen)
 	}
 
 	// match regular expression segments
 	for _, child := range regexMatches {
-		// Extract parameter name
-		paramName := extractParamName(child.segment)
-		// Add parameter
-		params.Add(paramName, currentSegment)
-		handler, matched := child.match(remainingPath, params)
+		// Add parameter (anonymous segments allocate no entry)
+		paramsLen := params.Len()
+		if paramName := extractParamName(child.segment); paramName != "" {
+			params.Add(paramName, currentSegment)
+		}
+		matchedNode, matched := child.matchNode(remainingPath, params)
 		if matched {
-			return handler, true
+			return matchedNode, true
 		}
-		// If no match, remove parameter (backtracking)
-		// Current implementation does not remove, uses overwrite method
+		// If no match, remove parameters added by this branch (backtracking)
+		params.truncate(paramsLen)
+	}
+
+	// match segments with a registered matcher, with the value returned by the matcher
+	for i, child := range matcherMatches {
+		paramsLen := params.Len()
+		if paramName := extractParamName(child.segment); paramName != "" {
+			params.Add(paramName, matcherValues[i])
+		}
+		matchedNode, matched := child.matchNode(remainingPath, params)
+		if matched {
+			return matchedNode, true
+		}
+		params.truncate(paramsLen)
+	}
+
+	// match catch-all segments last, with the rest of the path (catch-all segments are always terminal)
+	for _, child := range catchAllMatches {
+		if child.handler == nil {
+			continue
+		}
+		params.Add(extractParamName(child.segment), path)
+		return child, true
 	}
 
 	// No matching node found
@@ -241,10 +334,26 @@ func (n *node) parseSegment() error {
 		return nil
 	}
 
+	// Catch-all detection ({*name} format)
+	if pattern[1] == '*' {
+		n.segmentType = catchAllSegment
+		return nil
+	}
+
 	// Regular expression pattern detection ({name:pattern} format)
 	if colonIdx := strings.IndexByte(pattern, ':'); colonIdx > 0 {
-		n.segmentType = regexSegment
 		regexStr := pattern[colonIdx+1 : len(pattern)-1]
+		if regexStr == "" {
+			return &RouterError{Code: ErrInvalidPattern, Message: "empty regex pattern " + pattern}
+		}
+
+		// A registered matcher name takes precedence over the regular expression
+		if m := lookupSegmentMatcher(regexStr); m != nil {
+			n.segmentType = matcherSegment
+			n.custom = m
+			return nil
+		}
+		n.segmentType = regexSegment
 
 		// Compile regular expression (add ^ and $ automatically to ensure full match)
 		// If ^ and $ are already included, don't add
@@ -263,9 +372,13 @@ func (n *node) parseSegment() error {
 		if err != nil {
 			return &RouterError{
 				Code:    ErrInvalidPattern,
-				Message: "invalid regex pattern: " + regexStr + " - " + err.Error(),
+				Message: "invalid regex pattern " + regexStr,
+				Err:     err,
 			}
 		}
+
+		// Simple character classes (e.g. \d+, [a-z]+) are evaluated without the regexp engine
+		n.classMatcher = newByteClassMatcher(regexStr)
 		return nil
 	}
 
@@ -311,6 +424,8 @@ func (n *node) removeRouteInternal(segments []string, index int, paramNames map[
 		// If a handler exists, remove it and return true
 		if n.handler != nil {
 			n.handler = nil
+			n.route = nil
+			n.stats = nil
 			return true
 		}
 		return false
@@ -334,8 +449,7 @@ func (n *node) removeRouteInternal(segments []string, index int, paramNames map[
 		}
 
 		// If it's a parameter segment or regular expression segment
-		if (child.segmentType == paramSegment || child.segmentType == regexSegment) &&
-			(segment[0] == '{' && segment[len(segment)-1] == '}') {
+		if child.segmentType != staticSegment && isDynamicSeg(segment) {
 			// Recursively attempt to remove
 			removed := child.removeRouteInternal(segments, index+1, paramNames)
 
@@ -351,3 +465,41 @@ func (n *node) removeRouteInternal(segments []string, index int, paramNames map[
 	// No matching node found
 	return false
 }
+
+// setPriority assigns the priority to the dynamic segments on the path of the route.
+// If several routes with priorities share a node, the highest priority is used.
+// It returns true if at least one of those segments has an overlapping sibling
+// (another parameter or regular expression segment at the same position).
+func (n *node) setPriority(segments []string, priority int) bool {
+	overlap := false
+	current := n
+	for _, seg := range segments {
+		child := current.findChild(seg)
+		if child == nil {
+			return overlap
+		}
+
+		if child.segmentType != staticSegment && priority != 0 {
+			if !child.prioritySet || priority > child.priority {
+				child.priority = priority
+				child.prioritySet = true
+			}
+			for _, sibling := range current.children {
+				if sibling != child && sibling.segmentType == child.segmentType {
+					overlap = true
+				}
+			}
+			current.sortChildren()
+		}
+		current = child
+	}
+	return overlap
+}
+
+// sortChildren orders child nodes by priority (highest first).
+// The sort is stable, so nodes with the same priority keep their registration order.
+func (n *node) sortChildren() {
+	slices.SortStableFunc(n.children, func(a, b *node) int {
+		return b.priority - a.priority
+	})
+}
@@ -2,14 +2,15 @@ package router
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
 // TestNodeCreation はノードの作成をテストします
 func TestNodeCreation(t *testing.T) {
-	node := newNode("/users")
-	if node == nil {
-		t.Fatal("newNode returned nil")
+	node, err := newNode("/users")
+	if err != nil {
+		t.Fatalf("newNode returned an error: %v", err)
 	}
 	if node.segment != "/users" {
 		t.Errorf("Expected pattern to be '/users', got '%s'", node.segment)
@@ -27,7 +28,7 @@ func TestNodeCreation(t *testing.T) {
 
 // TestStaticRouteAddition は静的ルートの追加をテストします
 func TestStaticRouteAddition(t *testing.T) {
-	root := newNode("")
+	root := newRootNode()
 	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
 
 	err := root.addRoute([]string{"users"}, handler)
@@ -53,7 +54,7 @@ func TestStaticRouteAddition(t *testing.T) {
 
 // TestParameterRouteAddition はパラメータルートの追加をテストします
 func TestParameterRouteAddition(t *testing.T) {
-	root := newNode("")
+	root := newRootNode()
 	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
 
 	err := root.addRoute([]string{"users", "{id}"}, handler)
@@ -88,7 +89,7 @@ func TestParameterRouteAddition(t *testing.T) {
 
 // TestRegexRouteAddition は正規表現ルートの追加をテストします
 func TestRegexRouteAddition(t *testing.T) {
-	root := newNode("")
+	root := newRootNode()
 	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
 
 	err := root.addRoute([]string{"users", "{id:[0-9]+}"}, handler)
@@ -126,7 +127,7 @@ func TestRegexRouteAddition(t *testing.T) {
 
 // TestMultipleRoutes は複数のルートの追加と優先順位をテストします
 func TestMultipleRoutes(t *testing.T) {
-	root := newNode("")
+	root := newRootNode()
 	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
 
 	// Add multiple routes
@@ -207,3 +208,203 @@ func TestExtractParamName(t *testing.T) {
 		}
 	}
 }
+
+// TestMatchNodePattern はマッチしたノードがルートパターンを保持していることをテストします
+func TestMatchNodePattern(t *testing.T) {
+	root := newRootNode()
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	if err := root.addRoute([]string{"users", "{id}", "posts"}, handler); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+
+	matched, ok := root.matchNode("/users/1/posts", NewParams())
+	if !ok || matched.handler == nil {
+		t.Fatal("Route did not match")
+	}
+	if matched.pattern != "/users/{id}/posts" {
+		t.Errorf("Expected pattern to be '/users/{id}/posts', got '%s'", matched.pattern)
+	}
+}
+
+// TestNodePriority は優先度によって重複する正規表現ルートの順序が変わることをテストします
+func TestNodePriority(t *testing.T) {
+	root := newRootNode()
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	for _, segments := range [][]string{{"a", "{x:[0-9a-f]+}"}, {"a", "{y:[0-9]+}"}} {
+		if err := root.addRoute(segments, handler); err != nil {
+			t.Fatalf("Failed to add route: %v", err)
+		}
+	}
+
+	// Without priority, the first registered route wins
+	matched, ok := root.matchNode("/a/123", NewParams())
+	if !ok || matched.pattern != "/a/{x:[0-9a-f]+}" {
+		t.Fatalf("Expected /a/{x:[0-9a-f]+} to match, got %v", matched)
+	}
+
+	// With priority, the prioritized route wins
+	if !root.setPriority([]string{"a", "{y:[0-9]+}"}, 10) {
+		t.Error("Expected overlapping siblings to be detected")
+	}
+	matched, ok = root.matchNode("/a/123", NewParams())
+	if !ok || matched.pattern != "/a/{y:[0-9]+}" {
+		t.Errorf("Expected /a/{y:[0-9]+} to match, got %v", matched)
+	}
+
+	// Routes without overlapping siblings are reported
+	if err := root.addRoute([]string{"b", "{z:[0-9]+}"}, handler); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	if root.setPriority([]string{"b", "{z:[0-9]+}"}, 1) {
+		t.Error("Expected no overlapping siblings")
+	}
+}
+
+// TestStaticDynamicOverlap tests that static routes registered after overlapping dynamic routes
+// take precedence, with the dynamic routes as the fallback
+func TestStaticDynamicOverlap(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	respond := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			id, _ := GetParams(req.Context()).Get("id")
+			w.Write([]byte(body + id))
+			return nil
+		}
+	}
+	r.Get("/users/{id}", respond("user:"))
+	r.Get("/users/admin", respond("admin"))
+	r.Get("/users/{id}/{tab}", respond("tab:"))
+	r.Get("/users/admin/{tab}", respond("admin-tab"))
+	r.Get("/files/{id:[0-9]+}", respond("file:"))
+	r.Get("/files/latest", respond("latest"))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := map[string]string{
+		"/users/admin":       "admin",
+		"/users/42":          "user:42",
+		"/users/admin/posts": "admin-tab",
+		"/users/42/posts":    "tab:42",
+		"/files/latest":      "latest",
+		"/files/7":           "file:7",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != want {
+			t.Errorf("%s: expected %q, got %d %q", path, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+// TestAnonymousSegment tests that anonymous segments match one segment without capturing a parameter
+func TestAnonymousSegment(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var captured int
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		params := GetParams(req.Context())
+		captured = params.Len()
+		id, _ := params.Get("id")
+		w.Write([]byte(id))
+		return nil
+	}
+	r.Get("/api/{}/health", handler)
+	r.Get("/api/{}/{}/status", handler)
+	r.Get("/v/{:[0-9]+}/users/{id}", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+		params int
+	}{
+		{"/api/v1/health", http.StatusOK, "", 0},
+		{"/api/v2/health", http.StatusOK, "", 0},
+		{"/api/v1/eu/status", http.StatusOK, "", 0},
+		{"/v/2/users/42", http.StatusOK, "42", 1},
+		{"/v/beta/users/42", http.StatusNotFound, "", 0},
+		{"/api/health", http.StatusNotFound, "", 0},
+	}
+	for _, tt := range tests {
+		captured = -1
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, w.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		if w.Body.String() != tt.body || captured != tt.params {
+			t.Errorf("%s: expected %q with %d params, got %q with %d", tt.path, tt.body, tt.params, w.Body.String(), captured)
+		}
+	}
+
+}
+
+// TestCatchAllSegment tests that catch-all segments capture the rest of the path under their name
+func TestCatchAllSegment(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	respond := func(name string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			value, _ := GetParams(req.Context()).Get(name)
+			w.Write([]byte(name + "=" + value))
+			return nil
+		}
+	}
+	r.Get("/assets/{*filepath}", respond("filepath"))
+	r.Get("/assets/favicon.ico", respond("static"))
+	r.Get("/assets/{name}/index", respond("name"))
+	r.Get("/docs/{version}/{*page}", respond("page"))
+	r.Get("/raw/{*}", respond("*"))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/assets/css/site.css", http.StatusOK, "filepath=css/site.css"},
+		{"/assets/app.js", http.StatusOK, "filepath=app.js"},
+		{"/assets/favicon.ico", http.StatusOK, "static="},
+		{"/assets/admin/index", http.StatusOK, "name=admin"},
+		{"/assets/admin/index/more", http.StatusOK, "filepath=admin/index/more"},
+		{"/docs/v2/guide/routing", http.StatusOK, "page=guide/routing"},
+		{"/raw/a/b", http.StatusOK, "*=a/b"},
+		{"/assets", http.StatusNotFound, ""},
+		{"/docs/v2", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, w.Code)
+			continue
+		}
+		if tt.status == http.StatusOK && w.Body.String() != tt.body {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.body, w.Body.String())
+		}
+	}
+
+	// A catch-all segment must be last and cannot be constrained
+	for _, pattern := range []string{"/files/{*path}/edit", "/files/{*path:[a-z]+}"} {
+		if err := validatePattern(pattern); err == nil {
+			t.Errorf("%s: expected an invalid pattern error", pattern)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+type errorPageKey struct{}
+
+// ErrorPageInfo describes the error that an error page renders.
+type ErrorPageInfo struct {
+	Status int   // HTTP status code of the response
+	Err    error // Error returned by the handler (nil for 404)
+}
+
+// GetErrorPageInfo returns the error information in the context of an error page handler.
+func GetErrorPageInfo(ctx context.Context) (ErrorPageInfo, bool) {
+	info, ok := ctx.Value(errorPageKey{}).(ErrorPageInfo)
+	return info, ok
+}
+
+// SetErrorPage sets the handler that renders the response body for the status code,
+// so that error responses can be templated HTML or JSON without overriding each handler.
+//
+// Error pages are used for:
+//   - 404 Not Found, when no NotFoundHandler is set,
+//   - errors returned by handlers, when the route and its group have no error handler of their own.
+//     The status is taken from StatusError (see TypedHandler), context.DeadlineExceeded maps to 504,
+//     and anything else to 500. The router's error handler is used for statuses without a page.
+//
+// The page handler reads the status and error with GetErrorPageInfo. The response status is the
+// error status; success statuses written by the page are replaced with it, so a page can render
+// a regular route with Dispatch. If the page handler returns an error, a plain text response is sent.
+// A nil handler removes the page for the status.
+func (r *Router) SetErrorPage(status int, h HandlerFunc) error {
+	if status < 400 || status > 599 {
+		return &RouterError{Code: ErrInternalError, Message: fmt.Sprintf("error page status must be 4xx or 5xx: %d", status)}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h == nil {
+		delete(r.errorPages, status)
+		return nil
+	}
+	if r.errorPages == nil {
+		r.errorPages = make(map[int]HandlerFunc)
+	}
+	r.errorPages[status] = h
+	return nil
+}
+
+// serveErrorPage renders the error page for the status and reports whether one was registered.
+func (r *Router) serveErrorPage(w http.ResponseWriter, req *http.Request, status int, err error) bool {
+	r.mu.RLock()
+	page := r.errorPages[status]
+	r.mu.RUnlock()
+	if page == nil {
+		return false
+	}
+
+	info := ErrorPageInfo{Status: status, Err: err}
+	req = req.WithContext(context.WithValue(req.Context(), errorPageKey{}, info))
+	pw := &errorPageWriter{ResponseWriter: w, status: status}
+	if pageErr := page(pw, req); pageErr != nil && !pw.wroteHeader {
+		http.Error(w, http.StatusText(status), status)
+		return true
+	}
+	if !pw.wroteHeader {
+		pw.WriteHeader(status)
+	}
+	return true
+}
+
+// reject responds to a request that a policy of the route refuses.
+// The built-in error handlers would turn the error into 500, so the response is written directly
+// with the status of the error unless the error handling of the route or router renders it.
+func (r *Route) reject(w http.ResponseWriter, err *StatusError) error {
+	if r.ownErrorHandler() == nil && !r.router.rendersStatus(err.Status) {
+		http.Error(w, http.StatusText(err.Status), err.Status)
+		return nil
+	}
+	return err
+}
+
+// rendersStatus reports whether errors with the status are rendered by an error page or a custom
+// error handler of the router, rather than by the built-in error handlers, which always send 500.
+func (r *Router) rendersStatus(status int) bool {
+	if r.tenantParent != nil {
+		return r.tenantParent.rendersStatus(status)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.errorPages[status] != nil {
+		return true
+	}
+	handler := reflect.ValueOf(r.errorHandler).Pointer()
+	return handler != 0 &&
+		handler != reflect.ValueOf(defaultErrorHandler).Pointer() &&
+		handler != reflect.ValueOf(devErrorHandler).Pointer()
+}
+
+// ownErrorHandler returns the error handler set on the route or its group, without the router default.
+func (r *Route) ownErrorHandler() func(http.ResponseWriter, *http.Request, error) {
+	if r.errorHandler != nil {
+		return r.errorHandler
+	}
+	if r.group != nil {
+		return r.group.errorHandler
+	}
+	return nil
+}
+
+// errorPageWriter keeps the error status of an error page response.
+type errorPageWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader replaces success statuses with the error status.
+func (w *errorPageWriter) WriteHeader(code int) {
+	if w.wroteHeader || isInformational(code) {
+		return
+	}
+	w.wroteHeader = true
+	if code < http.StatusMultipleChoices {
+		code = w.status
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the body with the error status.
+func (w *errorPageWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(w.status)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter.
+func (w *errorPageWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
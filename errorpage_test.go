@@ -0,0 +1,88 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestErrorPage tests rendering error responses with error pages
+func TestErrorPage(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	page := func(w http.ResponseWriter, req *http.Request) error {
+		info, _ := GetErrorPageInfo(req.Context())
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<h1>%d</h1>", info.Status)
+		return nil
+	}
+	for _, status := range []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusConflict} {
+		if err := r.SetErrorPage(status, page); err != nil {
+			t.Fatalf("Failed to set error page: %v", err)
+		}
+	}
+	if err := r.SetErrorPage(http.StatusOK, page); err == nil {
+		t.Error("Expected an error for a non-error status")
+	}
+
+	// Error pages can render a regular route
+	r.Get("/errors/{status}", func(w http.ResponseWriter, req *http.Request) error {
+		status, _ := GetParams(req.Context()).Get("status")
+		fmt.Fprintf(w, "page %s", status)
+		return nil
+	})
+	if err := r.SetErrorPage(http.StatusForbidden, func(w http.ResponseWriter, req *http.Request) error {
+		return r.Dispatch(w, req, "/errors/403")
+	}); err != nil {
+		t.Fatalf("Failed to set error page: %v", err)
+	}
+
+	r.Get("/fail/{kind}", func(w http.ResponseWriter, req *http.Request) error {
+		kind, _ := GetParams(req.Context()).Get("kind")
+		switch kind {
+		case "conflict":
+			return NewStatusError(http.StatusConflict, "already exists")
+		case "forbidden":
+			return NewStatusError(http.StatusForbidden, "forbidden")
+		case "teapot":
+			return NewStatusError(http.StatusTeapot, "no page")
+		default:
+			return errors.New("boom")
+		}
+	})
+	r.Get("/own/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		return errors.New("boom")
+	}).WithErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {
+		http.Error(w, "own handler", http.StatusBadGateway)
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/missing", http.StatusNotFound, "<h1>404</h1>"},
+		{"/fail/boom", http.StatusInternalServerError, "<h1>500</h1>"},
+		{"/fail/conflict", http.StatusConflict, "<h1>409</h1>"},
+		{"/fail/forbidden", http.StatusForbidden, "page 403"},
+		{"/fail/teapot", http.StatusInternalServerError, "Internal Server Error\n"},
+		{"/own/1", http.StatusBadGateway, "own handler\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.status, w.Code)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.url, tt.body, w.Body.String())
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package router
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"unicode"
+	"strings"
+	"unicode/utf8"
 )
 
 type ErrorCode uint8
@@ -13,17 +15,56 @@ const (
 	ErrInvalidMethod
 	ErrNilHandler
 	ErrInternalError
+	ErrRouteMismatch
+	ErrBuildCheck
+	ErrFrozen
+	ErrInvalidConfig
 )
 
+// Sentinel errors that RouterErrors wrap, so that callers can branch on the kind of error with
+// errors.Is (or IsDuplicate and IsRouteNotFound) instead of parsing messages.
+var (
+	// ErrDuplicateRoute is wrapped by errors about a route, mount point, or module that is
+	// registered more than once (see RouterOptions.OverrideStrategy).
+	ErrDuplicateRoute = errors.New("duplicate route")
+	// ErrRouteNotFound is wrapped by errors about a route that does not exist.
+	ErrRouteNotFound = errors.New("route not found")
+)
+
+// RouterError is the error returned by route registration and the other operations of the router.
+// Code classifies the error, and Err holds the underlying cause, such as a regexp compile error
+// or one of the sentinel errors above, which errors.Is and errors.As see through Unwrap.
 type RouterError struct {
 	Code    ErrorCode
 	Message string
+	Err     error // Underlying cause (nil if none)
 }
 
 func (e *RouterError) Error() string {
+	// The message already describes the sentinel errors, so only other causes are appended
+	if e.Err != nil && e.Err != ErrDuplicateRoute && e.Err != ErrRouteNotFound {
+		return fmt.Sprintf("%s: %s: %v", e.Code.String(), e.Message, e.Err)
+	}
 	return fmt.Sprintf("%s: %s", e.Code.String(), e.Message)
 }
 
+// Unwrap returns the underlying cause of the error.
+func (e *RouterError) Unwrap() error {
+	return e.Err
+}
+
+// IsDuplicate reports whether err is about a route registered more than once.
+//
+// 例: if err := r.Build(); router.IsDuplicate(err) { ... }
+func IsDuplicate(err error) bool {
+	return errors.Is(err, ErrDuplicateRoute)
+}
+
+// IsRouteNotFound reports whether err is about a route that does not exist.
+func IsRouteNotFound(err error) bool {
+	return errors.Is(err, ErrRouteNotFound)
+}
+
 func (c ErrorCode) String() string {
 	switch c {
 	case ErrInvalidPattern:
@@ -34,6 +75,14 @@ func (c ErrorCode) String() string {
 		return "NilHandler"
 	case ErrInternalError:
 		return "InternalError"
+	case ErrRouteMismatch:
+		return "RouteMismatch"
+	case ErrBuildCheck:
+		return "BuildCheckFailed"
+	case ErrFrozen:
+		return "RouterFrozen"
+	case ErrInvalidConfig:
+		return "InvalidConfig"
 	default:
 		return "UnknownError"
 	}
@@ -48,27 +97,65 @@ func validateMethod(m string) error {
 	}
 }
 
-// validateStaticSegment checks if a static segment contains only
-// alphanumeric characters, hyphens, underscores, and dots.
-func validateStaticSegment(segment string) error {
-	for _, r := range segment {
-		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.' {
+// validateStaticSegment checks if a static segment contains only the characters the charset
+// allows (see SegmentCharset). Percent-encodings are checked for two hexadecimal digits.
+func validateStaticSegment(segment string, chars SegmentCharset) error {
+	for i := 0; i < len(segment); {
+		if segment[i] == '%' && chars&PermissiveSegmentChars != 0 {
+			if i+2 >= len(segment) || !isHexDigit(segment[i+1]) || !isHexDigit(segment[i+2]) {
+				return fmt.Errorf("invalid percent-encoding in static segment %q", segment)
+			}
+			i += 3
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(segment[i:])
+		if !chars.allows(r) {
 			return fmt.Errorf("invalid character %q in static segment", r)
 		}
+		i += size
 	}
 	return nil
 }
 
-// validatePattern validates the entire pattern and applies validateStaticSegment to each segment if it's static.
+// isHexDigit reports whether c is a hexadecimal digit.
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// validatePattern validates the pattern with the strict static segment charset.
 func validatePattern(p string) error {
+	return validatePatternWith(p, StrictSegmentChars)
+}
+
+// validatePatternWith validates the entire pattern and applies validateStaticSegment to each segment if it's static.
+func validatePatternWith(p string, chars SegmentCharset) error {
 	if p == "" {
 		return &RouterError{Code: ErrInvalidPattern, Message: "empty pattern"}
 	}
 	segments := parseSegments(p)
-	for _, seg := range segments {
+	for i, seg := range segments {
 		// Skip checking for dynamic segments ({param} or {param:regex})
 		if !isDynamicSeg(seg) {
-			if err := validateStaticSegment(seg); err != nil {
+			if err := validateStaticSegment(seg, chars); err != nil {
+				return &RouterError{Code: ErrInvalidPattern, Message: "invalid pattern " + p, Err: err}
+			}
+			continue
+		}
+
+		// A catch-all segment takes the rest of the path, so it must come last and cannot be constrained
+		if isCatchAllSeg(seg) {
+			if i != len(segments)-1 {
+				return &RouterError{Code: ErrInvalidPattern, Message: "catch-all segment must be the last segment: " + p}
+			}
+			if strings.IndexByte(seg, ':') > 0 {
+				return &RouterError{Code: ErrInvalidPattern, Message: "catch-all segment cannot have a regex: " + p}
+			}
+			continue
+		}
+
+		// Reject regular expressions that are too expensive to evaluate on every request
+		if colonIdx := strings.IndexByte(seg, ':'); colonIdx > 0 {
+			if err := analyzeRegex(seg[colonIdx+1 : len(seg)-1]); err != nil {
 				return err
 			}
 		}
@@ -1,6 +1,10 @@
 package router
 
 import (
+	"errors"
+	"net/http"
+	"regexp/syntax"
+	"strings"
 	"testing"
 )
 
@@ -204,3 +208,54 @@ func TestIsAllStatic(t *testing.T) {
 		}
 	}
 }
+
+// TestRouterErrorCauses tests that RouterErrors wrap their causes and sentinel errors
+func TestRouterErrorCauses(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	// Regular expression compile errors are wrapped
+	r := NewRouter()
+	defer r.cache.stop()
+	err := r.Handle(http.MethodGet, "/users/{id:[z-a]}", handler)
+	var syntaxErr *syntax.Error
+	if !errors.As(err, &syntaxErr) || syntaxErr.Code != syntax.ErrInvalidCharRange {
+		t.Errorf("Expected the regexp syntax error to be wrapped, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "invalid character class range") {
+		t.Errorf("Expected the cause in the message, got %q", err.Error())
+	}
+
+	// Duplicates are reported with ErrDuplicateRoute, at registration and at Build
+	for _, pattern := range []string{"/items", "/items/{id}"} {
+		if err := r.Handle(http.MethodGet, pattern, handler); err != nil {
+			t.Fatalf("Failed to register route: %v", err)
+		}
+	}
+	if err := r.Handle(http.MethodGet, "/items", handler); !IsDuplicate(err) {
+		t.Errorf("Expected ErrDuplicateRoute for a static route, got %v", err)
+	}
+	err = r.Handle(http.MethodGet, "/items/{id}", handler)
+	if !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute for a dynamic route, got %v", err)
+	}
+	var routerErr *RouterError
+	if !errors.As(err, &routerErr) || routerErr.Code != ErrInvalidPattern {
+		t.Errorf("Expected a RouterError with code ErrInvalidPattern, got %v", err)
+	}
+	if strings.Count(err.Error(), "duplicate") != 1 {
+		t.Errorf("Expected the sentinel not to be repeated in the message, got %q", err.Error())
+	}
+
+	b := NewRouter()
+	defer b.cache.stop()
+	b.Get("/a", handler)
+	b.Get("/a", handler)
+	if err := b.Build(); !IsDuplicate(err) {
+		t.Errorf("Expected Build to report ErrDuplicateRoute, got %v", err)
+	}
+
+	// Other errors are not duplicates
+	if err := r.Handle("INVALID", "/other", handler); IsDuplicate(err) || IsRouteNotFound(err) {
+		t.Errorf("Expected no sentinel error, got %v", err)
+	}
+}
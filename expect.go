@@ -0,0 +1,83 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// WithMaxBodySize limits the size of the request bodies of the route in bytes, overriding
+// RouterOptions.MaxBodyBytes. Requests whose Content-Length exceeds the limit are rejected with
+// 413 Content Too Large before the middleware run, so that clients sending
+// "Expect: 100-continue" are refused before they upload the body. Bodies of unknown length are
+// cut off at the limit with http.MaxBytesReader. A limit of 0 or less uses the router's limit.
+//
+// 例: r.Post("/uploads", upload).WithMaxBodySize(100 << 20)
+func (r *Route) WithMaxBodySize(n int64) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.maxBodyBytes = n
+	return r
+}
+
+// WithExpectCheck sets a check for the requests of the route that carry "Expect: 100-continue",
+// run after RouterOptions.ExpectCheck. Like the router's check, it runs before the middleware,
+// and a request it rejects is answered before the client sends the body. This lets large-upload
+// endpoints verify credentials without receiving an upload they would discard.
+//
+// 例: r.Put("/files/{name}", store).WithExpectCheck(func(req *http.Request) error { return auth.Verify(req) })
+func (r *Route) WithExpectCheck(check func(*http.Request) error) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.expectCheck = check
+	return r
+}
+
+// admitBody applies the body size limit and the expectation checks of the route before the
+// middleware run. It responds and returns false if the request is rejected.
+//
+// The net/http server sends "100 Continue" when the body is first read, so a request rejected
+// here is answered without the client uploading the body.
+func (r *Router) admitBody(w http.ResponseWriter, req *http.Request, route *Route) bool {
+	limit := r.maxBodyBytes
+	if route != nil && route.maxBodyBytes > 0 {
+		limit = route.maxBodyBytes
+	}
+	if limit > 0 {
+		if req.ContentLength > limit {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return false
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = http.MaxBytesReader(w, req.Body, limit)
+		}
+	}
+
+	if !strings.EqualFold(req.Header.Get("Expect"), "100-continue") {
+		return true
+	}
+	checks := []func(*http.Request) error{r.expectCheck}
+	if route != nil {
+		checks = append(checks, route.expectCheck)
+	}
+	for _, check := range checks {
+		if check == nil {
+			continue
+		}
+		if err := check(req); err != nil {
+			status := http.StatusExpectationFailed
+			if se := (*StatusError)(nil); errors.As(err, &se) {
+				status = se.Status
+			}
+			http.Error(w, http.StatusText(status), status)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// trackingBody is a request body that records whether it was read.
+type trackingBody struct {
+	io.Reader
+	read bool
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	b.read = true
+	return b.Reader.Read(p)
+}
+
+func (b *trackingBody) Close() error { return nil }
+
+// TestExpectContinue tests that requests are rejected before their body is read
+func TestExpectContinue(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.MaxBodyBytes = 16
+	opts.ExpectCheck = func(req *http.Request) error {
+		if req.Header.Get("Authorization") == "" {
+			return &StatusError{Status: http.StatusUnauthorized, Err: errors.New("missing credentials")}
+		}
+		return nil
+	}
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	upload := func(w http.ResponseWriter, req *http.Request) error {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return &StatusError{Status: http.StatusRequestEntityTooLarge, Err: err}
+		}
+		w.Write(body)
+		return nil
+	}
+	r.Post("/small", upload)
+	r.Post("/large", upload).WithMaxBodySize(64).WithExpectCheck(func(req *http.Request) error {
+		return errors.New("uploads are closed")
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		expect bool
+		auth   bool
+		status int
+		read   bool
+	}{
+		{"within limit", "/small", "hello", false, false, http.StatusOK, true},
+		{"over limit", "/small", strings.Repeat("x", 32), false, false, http.StatusRequestEntityTooLarge, false},
+		{"route limit", "/large", strings.Repeat("x", 32), false, false, http.StatusOK, true},
+		{"unauthorized expectation", "/small", "hello", true, false, http.StatusUnauthorized, false},
+		{"authorized expectation", "/small", "hello", true, true, http.StatusOK, true},
+		{"route check", "/large", "hello", true, true, http.StatusExpectationFailed, false},
+	}
+	for _, tt := range tests {
+		body := &trackingBody{Reader: strings.NewReader(tt.body)}
+		req := httptest.NewRequest(http.MethodPost, tt.path, body)
+		req.ContentLength = int64(len(tt.body))
+		if tt.expect {
+			req.Header.Set("Expect", "100-continue")
+		}
+		if tt.auth {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status || body.read != tt.read {
+			t.Errorf("%s: expected status %d (body read %v), got %d (body read %v)", tt.name, tt.status, tt.read, w.Code, body.read)
+		}
+	}
+
+	// Bodies of unknown length are cut off at the limit
+	req := httptest.NewRequest(http.MethodPost, "/small", strings.NewReader(strings.Repeat("x", 32)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code == http.StatusOK {
+		t.Errorf("Expected the body of unknown length to be cut off, got %d %q", w.Code, w.Body.String())
+	}
+}
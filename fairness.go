@@ -0,0 +1,110 @@
+package router
+
+import (
+	"net/http"
+	"net/netip"
+	"sync"
+)
+
+// FairnessOptions configures FairConcurrency.
+type FairnessOptions struct {
+	// MaxPerClient is the maximum number of requests of one client in flight at the same time.
+	MaxPerClient int
+
+	// Key identifies the client of a request, for example by API key or account.
+	// Default: ClientIP
+	Key func(*http.Request) string
+
+	// Allowlist exempts clients from the limit, such as internal callers. Entries are client
+	// keys or, for keys that are IP addresses, IP addresses and CIDR prefixes (e.g. "10.0.0.0/8").
+	Allowlist []string
+}
+
+// FairConcurrency returns middleware that limits the number of requests each client can have
+// in flight, so that one abusive client cannot occupy all worker capacity even within its rate
+// budget (a slow client that keeps many long requests open uses few tokens but many workers).
+// Requests over the limit receive 429 Too Many Requests. Clients without requests in flight
+// use no memory. It panics if MaxPerClient is less than 1.
+//
+// 例: r.Use(router.FairConcurrency(router.FairnessOptions{MaxPerClient: 8, Allowlist: []string{"10.0.0.0/8"}}))
+func FairConcurrency(opts FairnessOptions) MiddlewareFunc {
+	if opts.MaxPerClient < 1 {
+		panic("router: FairConcurrency requires MaxPerClient of at least 1")
+	}
+	key := opts.Key
+	if key == nil {
+		key = ClientIP
+	}
+	allowed := newClientAllowlist(opts.Allowlist)
+
+	var mu sync.Mutex
+	inFlight := make(map[string]int)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			client := key(r)
+			if allowed.contains(client) {
+				return next(w, r)
+			}
+
+			mu.Lock()
+			if inFlight[client] >= opts.MaxPerClient {
+				mu.Unlock()
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return nil
+			}
+			inFlight[client]++
+			mu.Unlock()
+
+			defer func() {
+				mu.Lock()
+				if inFlight[client]--; inFlight[client] == 0 {
+					delete(inFlight, client)
+				}
+				mu.Unlock()
+			}()
+			return next(w, r)
+		}
+	}
+}
+
+// clientAllowlist is a set of client keys and IP prefixes.
+type clientAllowlist struct {
+	keys     map[string]struct{}
+	prefixes []netip.Prefix
+}
+
+// newClientAllowlist parses the allowlist entries.
+func newClientAllowlist(entries []string) *clientAllowlist {
+	l := &clientAllowlist{keys: make(map[string]struct{})}
+	for _, entry := range entries {
+		if prefix, err := parseIPPrefix(entry); err == nil {
+			l.prefixes = append(l.prefixes, prefix)
+			continue
+		}
+		l.keys[entry] = struct{}{}
+	}
+	return l
+}
+
+// contains reports whether the client key is allowlisted.
+func (l *clientAllowlist) contains(client string) bool {
+	if _, ok := l.keys[client]; ok {
+		return true
+	}
+	if len(l.prefixes) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(client)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range l.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
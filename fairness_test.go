@@ -0,0 +1,70 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestFairConcurrency tests limiting the requests in flight per client
+func TestFairConcurrency(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Use(FairConcurrency(FairnessOptions{
+		MaxPerClient: 1,
+		Allowlist:    []string{"10.0.0.0/8", "192.0.2.7"},
+	}))
+
+	started := make(chan struct{}, 4)
+	unblock := make(chan struct{})
+	r.Get("/slow", func(w http.ResponseWriter, req *http.Request) error {
+		started <- struct{}{}
+		<-unblock
+		return nil
+	})
+	r.Get("/fast", func(w http.ResponseWriter, req *http.Request) error { return nil })
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	request := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Occupy the slot of an external client and of an allowlisted one
+	done := make(chan struct{})
+	for _, addr := range []string{"203.0.113.1:1000", "10.1.2.3:1000"} {
+		go func() {
+			request("/slow", addr)
+			done <- struct{}{}
+		}()
+		<-started
+	}
+
+	tests := []struct {
+		remoteAddr string
+		status     int
+	}{
+		{"203.0.113.1:2000", http.StatusTooManyRequests}, // Same client, another connection
+		{"203.0.113.2:1000", http.StatusOK},              // Another client
+		{"10.1.2.3:2000", http.StatusOK},                 // Allowlisted network
+		{"192.0.2.7:1000", http.StatusOK},                // Allowlisted address
+	}
+	for _, tt := range tests {
+		if status := request("/fast", tt.remoteAddr); status != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.remoteAddr, tt.status, status)
+		}
+	}
+
+	close(unblock)
+	<-done
+	<-done
+	if status := request("/fast", "203.0.113.1:3000"); status != http.StatusOK {
+		t.Errorf("Expected the client to be admitted after its request finished, got %d", status)
+	}
+}
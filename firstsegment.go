@@ -0,0 +1,76 @@
+package router
+
+import (
+	"maps"
+	"strings"
+	"sync/atomic"
+)
+
+// firstSegmentIndex is the set of first path segments of the registered routes.
+// Requests whose first segment is not in the set for their method cannot match any route,
+// so they are rejected without touching the trie, the trees, or the cache.
+// The index is copied on write, so lookups only load an atomic pointer.
+type firstSegmentIndex struct {
+	current atomic.Pointer[firstSegments]
+}
+
+// firstSegments is an immutable snapshot of the index.
+type firstSegments struct {
+	literal  [8]map[string]struct{} // First segments of the routes per method index, if not a parameter
+	wildcard [8]bool                // Whether a dynamic route of the method starts with a parameter
+}
+
+// newFirstSegmentIndex creates an empty index.
+func newFirstSegmentIndex() *firstSegmentIndex {
+	idx := &firstSegmentIndex{}
+	idx.current.Store(&firstSegments{})
+	return idx
+}
+
+// add records the first segment of a route pattern. The caller must serialize calls.
+func (idx *firstSegmentIndex) add(methodIndex uint8, pattern string) {
+	if methodIndex == 0 {
+		return
+	}
+	old := idx.current.Load()
+	next := &firstSegments{literal: old.literal, wildcard: old.wildcard}
+
+	segments := parseSegments(pattern)
+	if isDynamicSeg(segments[0]) {
+		next.wildcard[methodIndex-1] = true
+	} else {
+		set := maps.Clone(old.literal[methodIndex-1])
+		if set == nil {
+			set = make(map[string]struct{})
+		}
+		set[segments[0]] = struct{}{}
+		next.literal[methodIndex-1] = set
+	}
+	idx.current.Store(next)
+}
+
+// reset empties the index.
+func (idx *firstSegmentIndex) reset() {
+	idx.current.Store(&firstSegments{})
+}
+
+// allows reports whether a route of the method may match the request path.
+func (idx *firstSegmentIndex) allows(methodIndex uint8, path string) bool {
+	if methodIndex == 0 {
+		return false
+	}
+	s := idx.current.Load()
+	if s.wildcard[methodIndex-1] {
+		return true
+	}
+
+	first := strings.TrimPrefix(path, "/")
+	if i := strings.IndexByte(first, '/'); i >= 0 {
+		first = first[:i]
+	}
+	if _, ok := s.literal[methodIndex-1][first]; ok {
+		return true
+	}
+	// HEAD requests fall back to the GET routes (see Router.findRoute)
+	return methodIndex == headMethodIndex && idx.allows(getMethodIndex, path)
+}
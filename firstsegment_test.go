@@ -0,0 +1,84 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestFirstSegmentIndex tests rejecting requests whose first segment matches no route
+func TestFirstSegmentIndex(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.FirstSegmentIndex = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/", handler)
+	r.Get("/health", handler)
+	r.Get("/users/{id}", handler)
+	r.Post("/{tenant}/events", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method, path string
+		allowed      bool
+	}{
+		{http.MethodGet, "/", true},
+		{http.MethodGet, "/health", true},
+		{http.MethodPut, "/health", false}, // static routes serve only their method
+		{http.MethodHead, "/health", true}, // HEAD falls back to the GET routes
+		{http.MethodGet, "/users/1", true},
+		{http.MethodGet, "/users/", true},
+		{http.MethodGet, "/wp-admin/setup.php", false},
+		{http.MethodDelete, "/users/1", false},
+		{http.MethodPost, "/anything/events", true}, // a route starts with a parameter
+		{"PURGE", "/health", false},
+	}
+	for _, tt := range tests {
+		if got := r.firstSegments.allows(methodToUint8(tt.method), tt.path); got != tt.allowed {
+			t.Errorf("%s %s: expected allowed=%v, got %v", tt.method, tt.path, tt.allowed, got)
+		}
+	}
+
+	// Rejected requests are not found
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wp-admin/setup.php", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+// TestFirstSegmentIndexMethodNotAllowed tests that the 405 check only searches the methods the index allows
+func TestFirstSegmentIndexMethodNotAllowed(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.FirstSegmentIndex = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/health", handler)
+	r.Post("/users/{id}", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("Expected 405 allowing POST, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+
+	// With the index emptied, the routes are no longer searched
+	r.firstSegments.reset()
+	if allowed := r.allowedMethods(nil, "/users/1"); allowed != nil {
+		t.Errorf("Expected the index to reject the path before searching, got %v", allowed)
+	}
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"context"
+	"net/http"
+)
+
+// FrozenRouter is an immutable handle to a router returned by Router.Freeze.
+// It serves and inspects the router but exposes no method that changes its routes.
+type FrozenRouter struct {
+	router *Router
+}
+
+// Freeze makes the routing table of the router immutable and returns a read-only handle to it,
+// so that applications can guarantee that nothing changes the routes after startup.
+// It is called after Build. Afterwards, Handle, Register, Mount, Attach, LoadPrebuilt, and Build
+// return an error (code ErrFrozen), and Use, SetMiddleware, UseStack, AddCleanupMiddleware,
+// Group, ForTenant, and Route (including Get, Post, etc.) on the router or any of its groups
+// panic with one, since they cannot report errors and calling them is a bug. The tenant overlays are frozen with the router.
+// Freezing cannot be undone; Clone returns a mutable copy.
+func (r *Router) Freeze() *FrozenRouter {
+	r.frozen.Store(true)
+	return &FrozenRouter{router: r}
+}
+
+// Frozen reports whether the router has been frozen (see Freeze).
+// The tenant overlays of a router (see ForTenant) are frozen with it.
+func (r *Router) Frozen() bool {
+	return r.frozen.Load() || (r.tenantParent != nil && r.tenantParent.Frozen())
+}
+
+// checkMutable returns an error if the router has been frozen.
+// It accepts a nil router, for groups that are not attached to one.
+func (r *Router) checkMutable(op string) error {
+	if r != nil && r.Frozen() {
+		return &RouterError{Code: ErrFrozen, Message: op + " called on a frozen router"}
+	}
+	return nil
+}
+
+// mustBeMutable panics if the router has been frozen.
+func (r *Router) mustBeMutable(op string) {
+	if err := r.checkMutable(op); err != nil {
+		panic(err)
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (f *FrozenRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.router.ServeHTTP(w, req)
+}
+
+// Dispatch serves the request with the route of another path (see Router.Dispatch).
+func (f *FrozenRouter) Dispatch(w http.ResponseWriter, req *http.Request, path string) error {
+	return f.router.Dispatch(w, req, path)
+}
+
+// Match reports the route pattern that would handle the method and path (see Router.Match).
+func (f *FrozenRouter) Match(method, path string) (string, bool) {
+	return f.router.Match(method, path)
+}
+
+// Lookup returns the route that would handle the method and path (see Router.Lookup).
+func (f *FrozenRouter) Lookup(method, path string) (RouteInfo, Params, bool) {
+	return f.router.Lookup(method, path)
+}
+
+// Report returns the configuration of the router (see Router.Report).
+func (f *FrozenRouter) Report() RouterReport {
+	return f.router.Report()
+}
+
+// Shutdown gracefully shuts down the router (see Router.Shutdown).
+func (f *FrozenRouter) Shutdown(ctx context.Context) error {
+	return f.router.Shutdown(ctx)
+}
+
+// Compile-time check of the implementation.
+var _ Dispatcher = (*FrozenRouter)(nil)
@@ -0,0 +1,118 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestFreeze tests that a frozen router serves requests and rejects changes to its routes
+func TestFreeze(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	ok := func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("ok"))
+		return nil
+	}
+	r.Get("/items", ok)
+	g := r.Group("/api")
+	g.Get("/users", ok)
+	r.Tenant(func(req *http.Request) string { return req.Header.Get("X-Tenant") })
+	acme := r.ForTenant("acme")
+	acme.Get("/dashboard", ok)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	frozen := r.Freeze()
+	if !r.Frozen() {
+		t.Error("Expected the router to be frozen")
+	}
+
+	w := httptest.NewRecorder()
+	frozen.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("Expected the frozen router to serve, got %d %q", w.Code, w.Body.String())
+	}
+	if pattern, found := frozen.Match(http.MethodGet, "/items"); !found || pattern != "/items" {
+		t.Errorf("Expected /items to match, got %q %v", pattern, found)
+	}
+	if info, _, found := frozen.Lookup(http.MethodGet, "/api/users"); !found || info.Pattern != "/api/users" {
+		t.Errorf("Expected /api/users to be looked up, got %+v %v", info, found)
+	}
+
+	// Methods that return errors report ErrFrozen
+	isFrozen := func(err error) bool {
+		var re *RouterError
+		return errors.As(err, &re) && re.Code == ErrFrozen
+	}
+	prebuilt, err := prebuild([]RouteSpec{{Method: http.MethodGet, Pattern: "/new"}}, defaultRouterOptions())
+	if err != nil {
+		t.Fatalf("Failed to prebuild: %v", err)
+	}
+	errs := map[string]error{
+		"Router.Handle": r.Handle(http.MethodGet, "/new", ok),
+		"Group.Handle":  g.Handle(http.MethodGet, "/new", ok),
+		"Mount":         r.Mount("/sub", http.NotFoundHandler(), MountOptions{}),
+		"Build":         r.Build(),
+		"Attach":        r.Attach(NewGroup("/attached")),
+		"LoadPrebuilt":  r.LoadPrebuilt(prebuilt, []HandlerFunc{ok}),
+		"Tenant.Handle": acme.Handle(http.MethodGet, "/new", ok),
+	}
+	for name, err := range errs {
+		if !isFrozen(err) {
+			t.Errorf("%s: expected ErrFrozen, got %v", name, err)
+		}
+	}
+
+	// Methods that cannot return errors panic with ErrFrozen
+	passthrough := func(next HandlerFunc) HandlerFunc { return next }
+	stack := NewMiddlewareStack()
+	calls := map[string]func(){
+		"Router.Use":           func() { r.Use(passthrough) },
+		"Router.SetMiddleware": func() { r.SetMiddleware(passthrough) },
+		"UseStack":             func() { r.UseStack(stack) },
+		"AddCleanupMiddleware": func() { r.AddCleanupMiddleware(newCleanupMiddleware(passthrough, func() error { return nil })) },
+		"Group.SetMiddleware":  func() { g.SetMiddleware(passthrough) },
+		"Router.Get":           func() { r.Get("/new", ok) },
+		"Router.Group":         func() { r.Group("/new") },
+		"Group.Use":            func() { g.Use(passthrough) },
+		"Group.Get":            func() { g.Get("/new", ok) },
+		"Group.Group":          func() { g.Group("/new") },
+		"ForTenant":            func() { r.ForTenant("acme") },
+		"Tenant.Get":           func() { acme.Get("/new", ok) },
+	}
+	for name, call := range calls {
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !isFrozen(err) {
+					t.Errorf("%s: expected a panic with ErrFrozen, got %v", name, err)
+				}
+			}()
+			call()
+		}()
+	}
+
+	// The middleware is unchanged, and the stack was not attached
+	if n := len(r.middleware.Load().([]MiddlewareFunc)); n != 0 {
+		t.Errorf("Expected no middleware to be added to the frozen router, got %d", n)
+	}
+	if len(stack.routers) != 0 || len(r.Cleanups()) != 0 {
+		t.Error("Expected the frozen router to be left unchanged")
+	}
+
+	// The routes are unchanged
+	if _, found := frozen.Match(http.MethodGet, "/new"); found {
+		t.Error("Expected no route to be added to the frozen router")
+	}
+	for _, path := range []string{"/items", "/new"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Tenant", "acme")
+		w := httptest.NewRecorder()
+		frozen.ServeHTTP(w, req)
+		if want := map[string]int{"/items": http.StatusOK, "/new": http.StatusNotFound}[path]; w.Code != want {
+			t.Errorf("%s: expected status %d, got %d", path, want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// fuzzHandler is a handler used for fuzzing
+func fuzzHandler(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// hasInvalidRegex reports whether the pattern contains a regular expression segment that does not compile.
+// newNode panics on invalid regular expressions, so such patterns are excluded from fuzzing.
+func hasInvalidRegex(pattern string) bool {
+	for _, seg := range parseSegments(normalizePath(pattern)) {
+		if !isDynamicSeg(seg) {
+			continue
+		}
+		if colonIdx := strings.IndexByte(seg, ':'); colonIdx > 0 {
+			if _, err := regexp.Compile(seg[colonIdx+1 : len(seg)-1]); err != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FuzzParsePattern tests that pattern parsing and registration never panic
+func FuzzParsePattern(f *testing.F) {
+	f.Add("/users/{id}")
+	f.Add("/users/{id:[0-9]+}/posts/{slug}")
+	f.Add("/static/path.html")
+	f.Add("/{}")
+	f.Add("//{a}//")
+	f.Add("/a/{b:}/c")
+
+	f.Fuzz(func(t *testing.T, pattern string) {
+		if hasInvalidRegex(pattern) {
+			t.Skip()
+		}
+
+		_ = validatePattern(pattern)
+		segments := parseSegments(pattern)
+		_ = isAllStatic(segments)
+		for _, seg := range segments {
+			_ = extractParamName(seg)
+		}
+
+		r := NewRouter()
+		defer r.cache.stop()
+		if err := r.Handle(http.MethodGet, pattern, fuzzHandler); err != nil {
+			return
+		}
+
+		// A registered static route must be found by its own pattern
+		if isAllStatic(parseSegments(normalizePath(pattern))) {
+			if _, ok := r.Match(http.MethodGet, pattern); !ok {
+				t.Errorf("Registered static route %q was not found", pattern)
+			}
+		}
+	})
+}
+
+// FuzzDoubleArrayTrie tests that the trie stores and finds the same paths as a map
+func FuzzDoubleArrayTrie(f *testing.F) {
+	f.Add("/users\n/users/profile\n/posts", "/user")
+	f.Add("/a\n/b\n/c\n/ab\n/abc", "/abcd")
+	f.Add("/patho1\n/pathx1\n/pathy1\n/pathz1", "/path")
+	f.Add("\xff\x00\n\x01\x02\x03", "\xff")
+
+	f.Fuzz(func(t *testing.T, paths string, probe string) {
+		trie := newDoubleArrayTrie()
+		reference := make(map[string]bool)
+
+		for _, path := range strings.Split(paths, "\n") {
+			err := trie.Add(path, fuzzHandler)
+			if path == "" || reference[path] {
+				if err == nil {
+					t.Fatalf("Adding %q should fail", path)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("Failed to add %q: %v", path, err)
+			}
+			reference[path] = true
+		}
+
+		for path := range reference {
+			if trie.search(path) == nil {
+				t.Errorf("Path %q not found", path)
+			}
+		}
+		if found := trie.search(probe) != nil; found != reference[probe] {
+			t.Errorf("search(%q) = %v, want %v", probe, found, reference[probe])
+		}
+	})
+}
+
+// FuzzDynamicMatch tests that the Radix tree is consistent with the reference matcher
+func FuzzDynamicMatch(f *testing.F) {
+	f.Add("/users/{id}\n/users/{id}/posts\n/users/admin", "/users/123/posts")
+	f.Add("/a/{x:[0-9]+}\n/a/{y:[a-z]+}\n/a/{z}", "/a/abc")
+	f.Add("/a/{x}/b\n/a/{y:[0-9]+}/c", "/a/1/c")
+	f.Add("/a/b/{x}\n/a/{y}/c", "/a/b/c")
+	f.Add("/{a}\n/{a}/{b}\n/x/{c}", "/x/")
+
+	f.Fuzz(func(t *testing.T, patterns string, path string) {
+		root := newRootNode()
+		reference := &referenceMatcher{}
+
+		for _, pattern := range strings.Split(patterns, "\n") {
+			if hasInvalidRegex(pattern) {
+				t.Skip()
+			}
+			segments := parseSegments(normalizePath(pattern))
+			if err := root.addRoute(segments, fuzzHandler); err != nil {
+				continue
+			}
+			if err := reference.add(pattern, 0); err != nil {
+				t.Fatalf("Reference matcher rejected %q: %v", pattern, err)
+			}
+		}
+
+		params := NewParams()
+		matched, ok := root.matchNode(path, params)
+		wantPattern, wantParams, wantOK := reference.match(path)
+
+		if ok != wantOK {
+			t.Fatalf("match(%q) = %v, reference = %v", path, ok, wantOK)
+		}
+		if !ok {
+			return
+		}
+		if matched.pattern != wantPattern {
+			t.Fatalf("match(%q) = %q, reference = %q", path, matched.pattern, wantPattern)
+		}
+		if params.Len() != wantParams.Len() {
+			t.Fatalf("match(%q) params = %v, reference = %v", path, params.data, wantParams.data)
+		}
+		for i := range params.data {
+			if params.data[i] != wantParams.data[i] {
+				t.Fatalf("match(%q) params = %v, reference = %v", path, params.data, wantParams.data)
+			}
+		}
+	})
+}
@@ -0,0 +1,343 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"net/http"
+	"strconv"
+)
+
+// Prebuilt is a route table that was built ahead of time by Generate.
+// Loading it with Router.LoadPrebuilt installs the static trie and the dynamic trees as they are,
+// so large route tables do not pay the cost of inserting every route at startup.
+// The fields are exported only so that generated code can declare the table as a literal.
+type Prebuilt struct {
+	Routes []RouteSpec    // Routes of the table; handlers are passed to LoadPrebuilt in the same order
+	Base   []int32        // Base array of the static trie
+	Check  []int32        // Check array of the static trie
+	Leaves []PrebuiltLeaf // Terminal nodes of the static trie, keyed by path and method
+	Trees  []PrebuiltTree // Dynamic route tree of each method
+}
+
+// PrebuiltLeaf is a terminal node of a prebuilt static trie.
+type PrebuiltLeaf struct {
+	Node  int32 // Index of the node in the trie arrays
+	Route int32 // Index of the route in Prebuilt.Routes
+}
+
+// PrebuiltTree is the dynamic route tree of a method, flattened in depth-first order.
+// The first node is the root.
+type PrebuiltTree struct {
+	Method string
+	Nodes  []PrebuiltNode
+}
+
+// PrebuiltNode is a node of a prebuilt dynamic route tree.
+type PrebuiltNode struct {
+	Segment  string  // Path segment of the node
+	Route    int32   // Index of the route in Prebuilt.Routes (-1 for intermediate nodes)
+	Priority int     // Priority among overlapping dynamic siblings
+	Children []int32 // Indexes of the child nodes in PrebuiltTree.Nodes, in matching order
+}
+
+// prebuiltMethods lists the methods that have a dynamic route tree, in the order of methodToUint8.
+var prebuiltMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete,
+	http.MethodPatch, http.MethodHead, http.MethodOptions,
+}
+
+// Generate builds the route table for the route specs and returns Go source of package pkg that
+// declares it as a literal:
+//
+//	var PrebuiltRoutes = &router.Prebuilt{...}
+//
+// The routes are validated as they would be by Build, so the same duplicates and conflicts are
+// reported. The generated table is loaded with Router.LoadPrebuilt, with the handlers in the
+// order of the specs. This makes route tables with tens of thousands of routes start instantly.
+//
+// 例: src, err := router.Generate(specs, "routes")
+func Generate(routes []RouteSpec, pkg string) ([]byte, error) {
+	return GenerateWithOptions(routes, pkg, defaultRouterOptions())
+}
+
+// GenerateWithOptions works like Generate, but validates the routes with the options of the
+// router that will load the table, such as RouterOptions.StaticSegmentChars or
+// RouterOptions.OverrideStrategy, so that routes the router accepts can be generated.
+//
+// 例: src, err := router.GenerateWithOptions(specs, "routes", opts)
+func GenerateWithOptions(routes []RouteSpec, pkg string, opts RouterOptions) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, &RouterError{Code: ErrInternalError, Message: "invalid package name: " + pkg}
+	}
+	p, err := prebuild(routes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by router.Generate. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import \"github.com/nissy/router\"\n\n")
+	b.WriteString("// PrebuiltRoutes is the pre-built route table. Load it with Router.LoadPrebuilt.\n")
+	b.WriteString("var PrebuiltRoutes = &router.Prebuilt{\n")
+
+	b.WriteString("Routes: []router.RouteSpec{\n")
+	for _, spec := range p.Routes {
+		fmt.Fprintf(&b, "{Method: %q, Pattern: %q, Priority: %d},\n", spec.Method, spec.Pattern, spec.Priority)
+	}
+	b.WriteString("},\n")
+	writeInt32Slice(&b, "Base", p.Base)
+	writeInt32Slice(&b, "Check", p.Check)
+	b.WriteString("Leaves: []router.PrebuiltLeaf{\n")
+	for _, leaf := range p.Leaves {
+		fmt.Fprintf(&b, "{Node: %d, Route: %d},\n", leaf.Node, leaf.Route)
+	}
+	b.WriteString("},\n")
+	b.WriteString("Trees: []router.PrebuiltTree{\n")
+	for _, tree := range p.Trees {
+		fmt.Fprintf(&b, "{Method: %q, Nodes: []router.PrebuiltNode{\n", tree.Method)
+		for _, n := range tree.Nodes {
+			fmt.Fprintf(&b, "{Segment: %q, Route: %d, Priority: %d, Children: %s},\n",
+				n.Segment, n.Route, n.Priority, int32Literal(n.Children))
+		}
+		b.WriteString("}},\n")
+	}
+	b.WriteString("},\n}\n")
+
+	return format.Source(b.Bytes())
+}
+
+// writeInt32Slice writes a struct field holding an []int32 literal, 16 values per line.
+func writeInt32Slice(b *bytes.Buffer, field string, values []int32) {
+	fmt.Fprintf(b, "%s: []int32{", field)
+	for i, v := range values {
+		if i%16 == 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strconv.FormatInt(int64(v), 10))
+		b.WriteString(", ")
+	}
+	b.WriteString("\n},\n")
+}
+
+// int32Literal returns an []int32 literal on one line (nil if empty).
+func int32Literal(values []int32) string {
+	if len(values) == 0 {
+		return "nil"
+	}
+	var b bytes.Buffer
+	b.WriteString("[]int32{")
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.FormatInt(int64(v), 10))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// prebuild registers the route specs in a scratch router with the options and captures its matchers.
+func prebuild(routes []RouteSpec, opts RouterOptions) (*Prebuilt, error) {
+	r := NewRouterWithOptions(opts)
+	defer r.routeCache.Close()
+
+	// Routes are identified by method and stored pattern, the key of their statistics
+	index := make(map[string]int32, len(routes))
+	patterns := make([]string, len(routes))
+	placeholder := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	for i, spec := range routes {
+		if err := r.handle(spec.Method, spec.Pattern, placeholder, nil); err != nil {
+			return nil, err
+		}
+		pattern, err := storedPattern(spec.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		patterns[i] = pattern
+		index[spec.Method+" "+pattern] = int32(i)
+	}
+	for i, spec := range routes {
+		if spec.Priority != 0 && !r.applyRoutePriority(spec.Method, patterns[i], spec.Priority) {
+			return nil, &RouterError{
+				Code:    ErrInvalidPattern,
+				Message: "priority has no effect on route without overlapping dynamic routes: " + spec.Method + " " + spec.Pattern,
+			}
+		}
+	}
+	var missing error
+	routeIndex := func(stats *routeStats) int32 {
+		i, ok := index[stats.method+" "+stats.pattern]
+		if !ok && missing == nil {
+			missing = &RouterError{Code: ErrInternalError, Message: "prebuilt route not found among the specs: " + stats.method + " " + stats.pattern}
+		}
+		return i
+	}
+
+	// The trie arrays are truncated to the nodes in use; lookups beyond them simply fail
+	t := r.static
+	p := &Prebuilt{
+		Routes: routes,
+		Base:   t.base[:t.size],
+		Check:  t.check[:t.size],
+	}
+	for node := int32(0); node < t.size; node++ {
+		if t.handler[node] != nil {
+			p.Leaves = append(p.Leaves, PrebuiltLeaf{Node: node, Route: routeIndex(t.stats[node])})
+		}
+	}
+
+	for _, method := range prebuiltMethods {
+		root := r.dynamic[methodToUint8(method)-1]
+		if root == nil || len(root.children) == 0 {
+			continue
+		}
+		tree := PrebuiltTree{Method: method}
+		var flatten func(n *node) int32
+		flatten = func(n *node) int32 {
+			i := int32(len(tree.Nodes))
+			tree.Nodes = append(tree.Nodes, PrebuiltNode{Segment: n.segment, Route: -1, Priority: n.priority})
+			if n.handler != nil {
+				tree.Nodes[i].Route = routeIndex(n.stats)
+			}
+			children := make([]int32, 0, len(n.children))
+			for _, child := range n.children {
+				children = append(children, flatten(child))
+			}
+			tree.Nodes[i].Children = children
+			return i
+		}
+		flatten(root)
+		p.Trees = append(p.Trees, tree)
+	}
+	if missing != nil {
+		return nil, missing
+	}
+	return p, nil
+}
+
+// storedPattern returns the pattern as the router stores it and keys its statistics with:
+// normalized, with the escaped characters of static segments decoded (see handle).
+func storedPattern(pattern string) (string, error) {
+	return decodeStaticSegments(normalizePath(pattern))
+}
+
+// LoadPrebuilt installs a route table generated by Generate, replacing the static trie and the
+// dynamic trees of the router. handlers are the handlers of p.Routes, in the same order.
+// The routes are served like routes registered with Handle; routes defined with Route, Get, and
+// groups are still registered on top of the table by Build. It must be called before the router
+// serves requests, since the route cache is not invalidated.
+func (r *Router) LoadPrebuilt(p *Prebuilt, handlers []HandlerFunc) error {
+	if err := r.checkMutable("LoadPrebuilt"); err != nil {
+		return err
+	}
+	if len(handlers) != len(p.Routes) {
+		return &RouterError{Code: ErrInternalError, Message: fmt.Sprintf("prebuilt table has %d routes but %d handlers were given", len(p.Routes), len(handlers))}
+	}
+	for i, h := range handlers {
+		if h == nil {
+			return &RouterError{Code: ErrNilHandler, Message: "nil handler for prebuilt route " + p.Routes[i].Method + " " + p.Routes[i].Pattern}
+		}
+	}
+	if len(p.Base) != len(p.Check) || len(p.Base) == 0 {
+		return &RouterError{Code: ErrInternalError, Message: "malformed prebuilt static trie"}
+	}
+	validRoute := func(i int32) bool { return i >= 0 && int(i) < len(p.Routes) }
+	patterns := make([]string, len(p.Routes))
+	for i, spec := range p.Routes {
+		pattern, err := storedPattern(spec.Pattern)
+		if err != nil {
+			return err
+		}
+		patterns[i] = pattern
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Static trie
+	size := int32(len(p.Base))
+	static := &doubleArrayTrie{
+		base:    append([]int32(nil), p.Base...),
+		check:   append([]int32(nil), p.Check...),
+		handler: make([]HandlerFunc, size),
+		route:   make([]*Route, size),
+		stats:   make([]*routeStats, size),
+		size:    size,
+		growth:  r.static.growth,
+	}
+	for _, leaf := range p.Leaves {
+		if leaf.Node < 0 || leaf.Node >= size || !validRoute(leaf.Route) {
+			return &RouterError{Code: ErrInternalError, Message: "malformed prebuilt static trie"}
+		}
+		spec := p.Routes[leaf.Route]
+		// Tables generated before static routes were keyed by method have their leaves at the
+		// path itself, and would not answer any request
+		if !static.isMethodLeaf(leaf.Node, methodToUint8(spec.Method)) {
+			return &RouterError{Code: ErrInternalError, Message: "malformed prebuilt static trie (regenerate the table): " + spec.Method + " " + spec.Pattern}
+		}
+		static.handler[leaf.Node] = handlers[leaf.Route]
+		static.stats[leaf.Node] = r.routeStatsFor(spec.Method, patterns[leaf.Route])
+	}
+
+	// Dynamic trees
+	var dynamic [8]*node
+	for _, tree := range p.Trees {
+		methodIndex := methodToUint8(tree.Method)
+		if methodIndex == 0 || len(tree.Nodes) == 0 {
+			return &RouterError{Code: ErrInternalError, Message: "malformed prebuilt tree for method " + tree.Method}
+		}
+		nodes := make([]*node, len(tree.Nodes))
+		for i, pn := range tree.Nodes {
+			n := &node{segment: pn.Segment, priority: pn.Priority, prioritySet: pn.Priority != 0}
+			if err := n.parseSegment(); err != nil {
+				return err
+			}
+			if pn.Route >= 0 {
+				if !validRoute(pn.Route) {
+					return &RouterError{Code: ErrInternalError, Message: "malformed prebuilt tree for method " + tree.Method}
+				}
+				spec := p.Routes[pn.Route]
+				n.handler = handlers[pn.Route]
+				n.pattern = patterns[pn.Route]
+				n.stats = r.routeStatsFor(spec.Method, n.pattern)
+			}
+			nodes[i] = n
+		}
+		for i, pn := range tree.Nodes {
+			nodes[i].children = make([]*node, 0, len(pn.Children))
+			for _, c := range pn.Children {
+				if c <= int32(i) || int(c) >= len(nodes) {
+					return &RouterError{Code: ErrInternalError, Message: "malformed prebuilt tree for method " + tree.Method}
+				}
+				nodes[i].children = append(nodes[i].children, nodes[c])
+			}
+		}
+		dynamic[methodIndex-1] = nodes[0]
+	}
+
+	r.static = static
+	for i := range r.dynamic {
+		if dynamic[i] == nil {
+			dynamic[i] = newRootNode()
+		}
+		r.dynamic[i] = dynamic[i]
+	}
+	if r.patternCache != nil {
+		r.patternCache.reset()
+	}
+	if r.firstSegments != nil {
+		r.firstSegments.reset()
+		for i, spec := range p.Routes {
+			r.firstSegments.add(methodToUint8(spec.Method), patterns[i])
+		}
+	}
+
+	// Keep the registrations so that Clone can replay them
+	for i, spec := range p.Routes {
+		r.handled = append(r.handled, handledRoute{method: spec.Method, pattern: spec.Pattern, handler: handlers[i]})
+	}
+	return nil
+}
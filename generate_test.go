@@ -0,0 +1,172 @@
+package router
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestGenerate tests generating Go source for a prebuilt route table
+func TestGenerate(t *testing.T) {
+	specs := []RouteSpec{
+		{Method: http.MethodGet, Pattern: "/"},
+		{Method: http.MethodGet, Pattern: "/users"},
+		{Method: http.MethodGet, Pattern: "/users/{id:[0-9]+}"},
+		{Method: http.MethodPost, Pattern: "/users/{id}/posts"},
+	}
+	src, err := Generate(specs, "routes")
+	if err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "routes.go", src, 0)
+	if err != nil {
+		t.Fatalf("Generated source does not parse: %v\n%s", err, src)
+	}
+	if file.Name.Name != "routes" {
+		t.Errorf("Expected package routes, got %s", file.Name.Name)
+	}
+	if !strings.Contains(string(src), "var PrebuiltRoutes = &router.Prebuilt{") {
+		t.Errorf("Expected the route table declaration in:\n%s", src)
+	}
+
+	// Duplicates are reported as by Build
+	if _, err := Generate([]RouteSpec{
+		{Method: http.MethodGet, Pattern: "/users/{id}"},
+		{Method: http.MethodGet, Pattern: "/users/{id}"},
+	}, "routes"); err == nil {
+		t.Error("Expected an error for duplicate routes")
+	}
+	if _, err := Generate(specs, "not a package"); err == nil {
+		t.Error("Expected an error for an invalid package name")
+	}
+}
+
+// TestLoadPrebuilt tests serving a prebuilt route table
+func TestLoadPrebuilt(t *testing.T) {
+	specs := []RouteSpec{
+		{Method: http.MethodGet, Pattern: "/"},
+		{Method: http.MethodGet, Pattern: "/users"},
+		{Method: http.MethodGet, Pattern: "/items/{id:[0-9a-z]+}"},
+		{Method: http.MethodGet, Pattern: "/items/{num:[0-9]+}", Priority: 1},
+		{Method: http.MethodPost, Pattern: "/users/{id}/posts"},
+		{Method: http.MethodPost, Pattern: "/users"},
+	}
+	p, err := prebuild(specs, defaultRouterOptions())
+	if err != nil {
+		t.Fatalf("Failed to prebuild: %v", err)
+	}
+
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handlers := make([]HandlerFunc, len(specs))
+	for i, spec := range specs {
+		handlers[i] = func(w http.ResponseWriter, req *http.Request) error {
+			fmt.Fprintf(w, "%s %v", spec.Pattern, GetParams(req.Context()).data)
+			return nil
+		}
+	}
+	if err := r.LoadPrebuilt(p, handlers[:2]); err == nil {
+		t.Error("Expected an error for a missing handler")
+	}
+	// A leaf that is not keyed by its method is rejected
+	stale := *p
+	stale.Leaves = slices.Clone(p.Leaves)
+	stale.Leaves[0].Node = p.Check[p.Leaves[0].Node] - 1
+	if err := r.LoadPrebuilt(&stale, handlers); err == nil {
+		t.Error("Expected an error for a leaf without a method")
+	}
+	if err := r.LoadPrebuilt(p, handlers); err != nil {
+		t.Fatalf("Failed to load prebuilt table: %v", err)
+	}
+
+	// Routes defined on the router are registered on top of the table
+	r.Get("/health", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprint(w, "ok")
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method, url string
+		body        string
+	}{
+		{http.MethodGet, "/", "/ []"},
+		{http.MethodGet, "/users", "/users []"},
+		{http.MethodPost, "/users", "/users []"},
+		{http.MethodDelete, "/users", "Method Not Allowed\n"},
+		{http.MethodGet, "/items/42", "/items/{num:[0-9]+} [{num 42}]"},
+		{http.MethodGet, "/items/x42", "/items/{id:[0-9a-z]+} [{id x42}]"},
+		{http.MethodPost, "/users/7/posts", "/users/{id}/posts [{id 7}]"},
+		{http.MethodGet, "/health", "ok"},
+		{http.MethodGet, "/missing", "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+		if w.Body.String() != tt.body {
+			t.Errorf("%s %s: expected body %q, got %q", tt.method, tt.url, tt.body, w.Body.String())
+		}
+	}
+}
+
+// TestGenerateWithOptions tests prebuilding routes that only the options of the router accept,
+// including escaped characters, which the router stores decoded
+func TestGenerateWithOptions(t *testing.T) {
+	specs := []RouteSpec{
+		{Method: http.MethodGet, Pattern: "/@alice"},
+		{Method: http.MethodGet, Pattern: "/docs/hello%20world"},
+		{Method: http.MethodGet, Pattern: "/docs/{name}"},
+		{Method: http.MethodGet, Pattern: "/files/caf%c3%a9/{id}"},
+	}
+	if _, err := Generate(specs, "routes"); err == nil {
+		t.Error("Expected an error for characters the default options reject")
+	}
+	opts := defaultRouterOptions()
+	opts.StaticSegmentChars = PermissiveSegmentChars | UnicodeSegmentChars
+	if _, err := GenerateWithOptions(specs, "routes", opts); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	p, err := prebuild(specs, opts)
+	if err != nil {
+		t.Fatalf("Failed to prebuild: %v", err)
+	}
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+	handlers := make([]HandlerFunc, len(specs))
+	for i, spec := range specs {
+		handlers[i] = func(w http.ResponseWriter, req *http.Request) error {
+			fmt.Fprint(w, spec.Pattern)
+			return nil
+		}
+	}
+	if err := r.LoadPrebuilt(p, handlers); err != nil {
+		t.Fatalf("Failed to load prebuilt table: %v", err)
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// Every route is served by its own handler
+	for url, want := range map[string]string{
+		"/@alice":             "/@alice",
+		"/docs/hello%20world": "/docs/hello%20world",
+		"/docs/readme":        "/docs/{name}",
+		"/files/caf%C3%A9/7":  "/files/caf%c3%a9/{id}",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Body.String() != want {
+			t.Errorf("%s: expected body %q, got %q", url, want, w.Body.String())
+		}
+	}
+}
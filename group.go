@@ -1,9 +1,12 @@
 package router
 
 import (
-	"log"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -29,6 +32,33 @@ type Route struct {
 	applied      bool                                            // Whether already applied
 	timeout      time.Duration                                   // Route-specific timeout setting (uses router default if 0)
 	errorHandler func(http.ResponseWriter, *http.Request, error) // Route-specific error handler
+	priority     int                                             // Priority among overlapping dynamic routes (0 means registration order)
+	meta         map[string]any                                  // Route-specific metadata (overrides group metadata)
+	name         string                                          // Name of the route for URL generation (see WithName)
+
+	middlewareTimeout time.Duration                 // Timeout for the middleware phase (0 means disabled)
+	bufferResponse    bool                          // Whether the response is buffered until the handler succeeds
+	consumes          []string                      // Accepted request body media types (nil accepts any, see Consumes)
+	produces          []string                      // Response media types (see Produces)
+	variants          []variant                     // Handlers per representation (see WithVariant)
+	shadow            *shadowTarget                 // Handler mirrored with sampled requests (see WithShadow)
+	cacheControl      string                        // Cache-Control of successful responses (see WithCacheControl)
+	errorRetryAfter   time.Duration                 // Retry-After of 429 and 5xx responses (see WithErrorRetryAfter)
+	ungated           bool                          // Whether the route bypasses RouterOptions.MaxInFlight (see WithoutConcurrencyLimit)
+	bare              bool                          // Whether the route bypasses the global middleware (see ACMEChallenge)
+	ipPolicy          *ipPolicy                     // Allowed and denied client addresses (see WithIPAllow)
+	country           *countryPolicy                // Allowed client countries (see WithCountryPolicy)
+	window            *activeWindow                 // Period in which the route is served (see WithActiveWindow)
+	site              string                        // File and line of the registration (see RouterOptions.RecordRouteSites)
+	paramTransforms   []paramTransform              // Functions rewriting parameter values (see WithParamTransform)
+	tags              []string                      // Labels selecting the route in BuildOnly and BuildExcept (see WithTags)
+	options           RouteOptions                  // Declared policies (see With)
+	optionMiddleware  []MiddlewareFunc              // Middleware the options expand to at Build
+	maxBodyBytes      int64                         // Maximum size of the request body (0 uses the router's limit, see WithMaxBodySize)
+	expectCheck       func(*http.Request) error     // Check of "Expect: 100-continue" requests (see WithExpectCheck)
+	clientCert        *clientCertPolicy             // Verification of the TLS client certificate (see WithClientCert)
+	err               error                         // Invalid configuration of the route, reported by Build
+	chain             atomic.Pointer[composedChain] // Cached middleware chain (see Router.routeChain)
 }
 
 // WithMiddleware is used to apply specific middleware to a route.
@@ -45,6 +75,38 @@ func (r *Route) WithMiddleware(middleware ...MiddlewareFunc) *Route {
 	return r
 }
 
+// wrap wraps a handler of the route in the middleware of the route and its group.
+func (r *Route) wrap(h HandlerFunc) HandlerFunc {
+	if r.shadow != nil {
+		h = r.shadow.mirror(r.router, h)
+	}
+
+	// The handler reports when it is called so that timeouts can be attributed to a phase
+	handler := trackHandlerPhase(h)
+	if len(r.produces) > 0 {
+		handler = defaultContentType(handler, r.produces)
+	}
+	if len(r.consumes) > 0 {
+		handler = requireContentType(handler, r.consumes)
+	}
+	// The middleware the options expand to runs before the route middleware (see With)
+	if middleware := slices.Concat(r.middleware, r.optionMiddleware); len(middleware) > 0 {
+		handler = applyMiddlewareChain(handler, middleware)
+	}
+	if r.clientCert != nil {
+		handler = r.clientCert.check(r, handler)
+	}
+	if r.country != nil {
+		handler = r.country.check(r, handler)
+	}
+	if r.ipPolicy != nil {
+		handler = r.ipPolicy.check(r, handler)
+	}
+
+	// Apply the group's middleware (unless it is resolved per request)
+	return r.router.groupHandler(r.group, handler)
+}
+
 // build registers the route with the router.
 // This method must be explicitly called.
 // If duplicate routes are detected, an error is returned.
@@ -52,11 +114,37 @@ func (r *Route) build() error {
 	if r.applied {
 		return nil
 	}
-
-	// Apply middleware to the handler
-	handler := r.handler
-	if len(r.middleware) > 0 {
-		handler = applyMiddlewareChain(handler, r.middleware)
+	if r.err != nil {
+		return r.err
+	}
+	if r.ipPolicy != nil && r.ipPolicy.err != nil {
+		return r.ipPolicy.err
+	}
+	if r.country != nil && r.country.lookup == nil {
+		return &RouterError{Code: ErrNilHandler, Message: "country lookup cannot be nil: " + r.method + " " + r.fullPath()}
+	}
+	if r.clientCert != nil && r.clientCert.verify == nil {
+		return &RouterError{Code: ErrNilHandler, Message: "client certificate verify function cannot be nil: " + r.method + " " + r.fullPath()}
+	}
+	if err := r.validateParamTransforms(); err != nil {
+		return err
+	}
+	optionMiddleware, optionErr := r.expandOptions()
+	if optionErr != nil {
+		return optionErr
+	}
+	r.optionMiddleware = optionMiddleware
+
+	// Routes with variants select the representation before their middleware runs
+	var handler HandlerFunc
+	if len(r.variants) > 0 {
+		chains := make([]HandlerFunc, len(r.variants))
+		for i, v := range r.variants {
+			chains[i] = r.wrap(v.handler)
+		}
+		handler = negotiateVariant(r.variants, chains)
+	} else {
+		handler = r.wrap(r.handler)
 	}
 
 	var err error
@@ -64,16 +152,17 @@ func (r *Route) build() error {
 	// If the route does not belong to a group (created by router.Route)
 	if r.group == nil {
 		// Register route directly with the router
-		err = r.router.Handle(r.method, r.subPath, handler)
+		err = r.router.handle(r.method, r.subPath, handler, r)
 	} else {
 		// If the route belongs to a group
 		fullPath := joinPath(r.group.prefix, normalizePath(r.subPath))
-		err = r.router.Handle(r.method, fullPath, handler)
+		err = r.router.handle(r.method, fullPath, handler, r)
 	}
 
 	// If there is no error, set applied flag
 	if err == nil {
 		r.applied = true
+		r.router.routeRegistered(r.info())
 	}
 
 	return err
@@ -81,16 +170,32 @@ func (r *Route) build() error {
 
 type Group struct {
 	router       *Router
+	parent       *Group   // Parent group (nil for top-level groups)
+	children     []*Group // Child groups created with Group.Group
 	prefix       string
 	middleware   []MiddlewareFunc
 	routes       []*Route
 	timeout      time.Duration                                   // Group-specific timeout setting (uses router default if 0)
 	errorHandler func(http.ResponseWriter, *http.Request, error) // Group-specific error handler
+	meta         map[string]any                                  // Group-level metadata inherited by routes
+	module       string                                          // Name of the module that registered the group (see Router.Register)
+	requireError bool                                            // Whether the group must have an error handler (see RequireErrorHandler)
+	cacheControl string                                          // Cache-Control of successful responses, inherited by child groups (see WithCacheControl)
+	retryAfter   time.Duration                                   // Retry-After of 429 and 5xx responses, inherited by child groups (see WithErrorRetryAfter)
+	headerLimits *HeaderLimits                                   // Request header limits, inherited by child groups (see WithHeaderLimits)
+	tags         []string                                        // Labels of the routes, inherited by child groups (see WithTags)
+	handled      []*Route                                        // Routes registered immediately with Handle (replayed by Router.Clone)
+
+	// Middleware registered on this group itself (excluding middleware inherited from the parent).
+	// It is read per request when RouterOptions.PerRequestMiddleware is enabled.
+	ownMiddleware atomic.Pointer[[]MiddlewareFunc]
+	mwMu          sync.Mutex // Mutex for protecting middleware updates
 }
 
 // Group creates a new route group.
 // It returns a Group with the specified path prefix.
 func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *Group {
+	r.mustBeMutable("Group")
 	group := &Group{
 		router:       r,
 		prefix:       normalizePath(prefix),
@@ -99,6 +204,7 @@ func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *Group {
 		timeout:      0,
 		errorHandler: nil,
 	}
+	group.storeOwnMiddleware(middleware)
 
 	// Add group to the router
 	r.groups = append(r.groups, group)
@@ -106,82 +212,249 @@ func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *Group {
 	return group
 }
 
+// NewGroup creates a standalone route group that does not belong to any router.
+// Routes can be defined on it as usual, and the group can then be attached to
+// one or more routers with Router.Attach. This allows packages to export their
+// routes without depending on the application's router instance.
+func NewGroup(prefix string, middleware ...MiddlewareFunc) *Group {
+	g := &Group{
+		prefix:     normalizePath(prefix),
+		middleware: middleware,
+		routes:     make([]*Route, 0),
+	}
+	g.storeOwnMiddleware(middleware)
+	return g
+}
+
+// Attach registers a copy of the group (including its routes and child groups) with the router.
+// The same group can be attached to several routers, since each router receives its own copy.
+// Routes added to the group after Attach are not included in the copy.
+// It returns an error (code ErrFrozen) if the router has been frozen.
+func (r *Router) Attach(groups ...*Group) error {
+	if err := r.checkMutable("Attach"); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		r.groups = append(r.groups, g.bind(r, nil))
+	}
+	return nil
+}
+
+// bind returns a deep copy of the group definition that belongs to the router.
+func (g *Group) bind(r *Router, parent *Group) *Group {
+	bound := &Group{
+		router:       r,
+		parent:       parent,
+		prefix:       g.prefix,
+		middleware:   slices.Clone(g.middleware),
+		routes:       make([]*Route, 0, len(g.routes)),
+		timeout:      g.timeout,
+		errorHandler: g.errorHandler,
+		meta:         maps.Clone(g.meta),
+		module:       g.module,
+		requireError: g.requireError,
+		cacheControl: g.cacheControl,
+		retryAfter:   g.retryAfter,
+		headerLimits: g.headerLimits,
+		tags:         slices.Clone(g.tags),
+	}
+	bound.storeOwnMiddleware(slices.Clone(g.loadOwnMiddleware()))
+
+	for _, route := range g.routes {
+		bound.routes = append(bound.routes, route.copyFor(r, bound))
+	}
+	for _, route := range g.handled {
+		bound.handled = append(bound.handled, route.copyFor(r, bound))
+	}
+
+	for _, child := range g.children {
+		bound.children = append(bound.children, child.bind(r, bound))
+	}
+
+	return bound
+}
+
+// copyFor returns an unapplied deep copy of the route definition that belongs to the router and group.
+func (r *Route) copyFor(router *Router, group *Group) *Route {
+	return &Route{
+		group:        group,
+		router:       router,
+		method:       r.method,
+		subPath:      r.subPath,
+		handler:      r.handler,
+		middleware:   slices.Clone(r.middleware),
+		timeout:      r.timeout,
+		errorHandler: r.errorHandler,
+		priority:     r.priority,
+		meta:         maps.Clone(r.meta),
+		name:         r.name,
+
+		middlewareTimeout: r.middlewareTimeout,
+		bufferResponse:    r.bufferResponse,
+		consumes:          slices.Clone(r.consumes),
+		produces:          slices.Clone(r.produces),
+		variants:          slices.Clone(r.variants),
+		shadow:            r.shadow,
+		cacheControl:      r.cacheControl,
+		errorRetryAfter:   r.errorRetryAfter,
+		ungated:           r.ungated,
+		bare:              r.bare,
+		ipPolicy:          r.ipPolicy.clone(),
+		country:           r.country,
+		window:            r.window,
+		site:              r.site,
+		paramTransforms:   slices.Clone(r.paramTransforms),
+		tags:              slices.Clone(r.tags),
+		options:           r.options,
+		maxBodyBytes:      r.maxBodyBytes,
+		expectCheck:       r.expectCheck,
+		clientCert:        r.clientCert,
+		err:               r.err,
+	}
+}
+
 // Group creates a new route group.
 // The new group inherits the path prefix and middleware of the parent group and
 // applies additional path prefix and middleware.
 func (g *Group) Group(prefix string, middleware ...MiddlewareFunc) *Group {
+	g.router.mustBeMutable("Group")
+
 	// Combine parent group's middleware and new middleware
 	combinedMiddleware := make([]MiddlewareFunc, len(g.middleware)+len(middleware))
 	copy(combinedMiddleware, g.middleware)
 	copy(combinedMiddleware[len(g.middleware):], middleware)
 
-	return &Group{
+	child := &Group{
 		router:     g.router,
+		parent:     g,
 		prefix:     joinPath(g.prefix, normalizePath(prefix)),
 		middleware: combinedMiddleware,
 		routes:     make([]*Route, 0),
+		module:     g.module,
 	}
+	child.storeOwnMiddleware(middleware)
+
+	// Track the child group so that its routes are registered at Build
+	g.children = append(g.children, child)
+
+	return child
 }
 
 // Use adds new middleware to the group.
+// When RouterOptions.PerRequestMiddleware is enabled, the middleware also takes effect
+// for routes that have already been built; otherwise it only affects routes built later.
 func (g *Group) Use(middleware ...MiddlewareFunc) *Group {
+	g.router.mustBeMutable("Use")
+
+	g.mwMu.Lock()
+	defer g.mwMu.Unlock()
+
 	g.middleware = append(g.middleware, middleware...)
+
+	// Update the per-request snapshot (copy-on-write)
+	own := g.loadOwnMiddleware()
+	newOwn := make([]MiddlewareFunc, len(own)+len(middleware))
+	copy(newOwn, own)
+	copy(newOwn[len(own):], middleware)
+	g.storeOwnMiddleware(newOwn)
+	g.invalidateChains()
+
 	return g
 }
 
+// SetMiddleware replaces the middleware registered on the group itself
+// (middleware inherited from parent groups is kept).
+// Combined with RouterOptions.PerRequestMiddleware, this allows middleware
+// to be enabled or disabled while the router is serving requests.
+func (g *Group) SetMiddleware(middleware ...MiddlewareFunc) *Group {
+	g.router.mustBeMutable("SetMiddleware")
+
+	g.mwMu.Lock()
+	defer g.mwMu.Unlock()
+
+	var inherited []MiddlewareFunc
+	if g.parent != nil {
+		inherited = g.parent.middlewareSnapshot()
+	}
+	g.middleware = append(slices.Clone(inherited), middleware...)
+	g.storeOwnMiddleware(slices.Clone(middleware))
+	g.invalidateChains()
+
+	return g
+}
+
+// middlewareSnapshot returns a copy of the group's combined middleware list.
+func (g *Group) middlewareSnapshot() []MiddlewareFunc {
+	g.mwMu.Lock()
+	defer g.mwMu.Unlock()
+	return slices.Clone(g.middleware)
+}
+
+// storeOwnMiddleware atomically replaces the group's own middleware list.
+func (g *Group) storeOwnMiddleware(middleware []MiddlewareFunc) {
+	g.ownMiddleware.Store(&middleware)
+}
+
+// invalidateChains discards the middleware chains cached for requests,
+// so that the group's new middleware is picked up when it is resolved per request.
+func (g *Group) invalidateChains() {
+	if g.router != nil {
+		g.router.middlewareGen.Add(1)
+	}
+}
+
+// loadOwnMiddleware atomically loads the group's own middleware list.
+func (g *Group) loadOwnMiddleware() []MiddlewareFunc {
+	if own := g.ownMiddleware.Load(); own != nil {
+		return *own
+	}
+	return nil
+}
+
 // Handle is the implementation of routerGroup's Handle method.
 // It registers a route with the specified HTTP method, pattern, and handler function.
 // The pattern automatically includes the group's prefix,
 // and the handler function is applied the group's middleware.
 func (g *Group) Handle(method, subPath string, h HandlerFunc) error {
+	// A standalone group must be attached to a router before Handle can register routes
+	if g.router == nil {
+		return &RouterError{Code: ErrInvalidPattern, Message: "group is not attached to a router: " + g.prefix}
+	}
+	if err := g.router.checkMutable("Handle"); err != nil {
+		return err
+	}
+
 	full := joinPath(g.prefix, normalizePath(subPath))
 
+	// Keep the route definition so that the group can be resolved at request time
+	route := &Route{
+		group:   g,
+		router:  g.router,
+		method:  method,
+		subPath: normalizePath(subPath),
+		handler: h,
+		applied: true,
+	}
+
 	// Apply group's middleware to the handler
-	h = applyMiddlewareChain(h, g.middleware)
+	h = g.router.groupHandler(g, trackHandlerPhase(h))
 
-	return g.router.Handle(method, full, h)
+	if err := g.router.handle(method, full, h, route); err != nil {
+		return err
+	}
+	g.handled = append(g.handled, route)
+	g.router.routeRegistered(route.info())
+	return nil
 }
 
 // Route creates a new route but does not register it.
 // You can call WithMiddleware on the returned Route object to apply specific middleware.
-// Route duplication processing is determined by the router's allowRouteOverride option:
-// - true: The later registered route overwrites the existing route.
-// - false: If duplicate routes are detected, an error is returned (default)
+// Duplicate routes are resolved by Build according to RouterOptions.OverrideStrategy,
+// in the same way as the routes registered directly with the router.
 func (g *Group) Route(method, subPath string, h HandlerFunc, middleware ...MiddlewareFunc) *Route {
-	// Check existing routes
-	normalizedPath := normalizePath(subPath)
+	g.router.mustBeMutable("Route")
 
-	// Duplicate check
-	for i, existingRoute := range g.routes {
-		if existingRoute.method == method && existingRoute.subPath == normalizedPath {
-			// Duplicate found
-			if !g.router.allowRouteOverride {
-				// Output warning log (error is not returned - will be detected at build time unless overridden)
-				log.Printf("Warning: duplicate route definition in group: %s %s%s (will cause error at build time unless overridden)",
-					method, g.prefix, normalizedPath)
-			} else {
-				// Overwrite mode case
-				g.routes[i] = &Route{
-					group:        g,
-					router:       g.router,
-					method:       method,
-					subPath:      normalizedPath,
-					handler:      h,
-					middleware:   make([]MiddlewareFunc, 0, len(middleware)),
-					applied:      false,
-					timeout:      g.timeout,
-					errorHandler: nil,
-				}
-
-				// Add middleware
-				if len(middleware) > 0 {
-					g.routes[i].middleware = append(g.routes[i].middleware, middleware...)
-				}
-
-				return g.routes[i]
-			}
-		}
-	}
+	normalizedPath := normalizePath(subPath)
 
 	// Create new route
 	route := &Route{
@@ -200,6 +473,9 @@ func (g *Group) Route(method, subPath string, h HandlerFunc, middleware ...Middl
 	if len(middleware) > 0 {
 		route.middleware = append(route.middleware, middleware...)
 	}
+	if g.router.recordsSites() {
+		route.site = callSite()
+	}
 
 	// Add route to group
 	g.routes = append(g.routes, route)
@@ -261,6 +537,9 @@ func (g *Group) WithTimeout(timeout time.Duration) *Group {
 // If the group has no specific setting, the router's default value is returned.
 func (g *Group) GetTimeout() time.Duration {
 	if g.timeout <= 0 {
+		if g.router == nil {
+			return 0
+		}
 		return g.router.GetRequestTimeout()
 	}
 	return g.timeout
@@ -273,12 +552,23 @@ func (g *Group) WithErrorHandler(handler func(http.ResponseWriter, *http.Request
 	return g
 }
 
+// RequireErrorHandler declares that the group must have its own error handler, for example
+// because its routes return errors that the router's error handler does not know how to render.
+// Build reports a group without one as a warning, or fails in strict mode (see RouterOptions.Strict).
+func (g *Group) RequireErrorHandler() *Group {
+	g.requireError = true
+	return g
+}
+
 // GetErrorHandler returns the group's error handler.
 // If the group has no specific setting, the router's default value is returned.
 func (g *Group) GetErrorHandler() func(http.ResponseWriter, *http.Request, error) {
 	if g.errorHandler != nil {
 		return g.errorHandler
 	}
+	if g.router == nil {
+		return defaultErrorHandler
+	}
 	return g.router.GetErrorHandler() // router's GetErrorHandler returns defaultErrorHandler if nil
 }
 
@@ -317,10 +607,39 @@ func (r *Route) WithTimeout(timeout time.Duration) *Route {
 	return r
 }
 
+// WithPriority sets the priority used to break ties between overlapping dynamic routes,
+// for example two regular expression segments at the same position that can both match.
+// Routes with a higher priority are tried first; routes with the same priority are tried
+// in registration order. A value of 0 restores the default (registration order).
+// Build returns an error if the route has no overlapping dynamic sibling, because
+// a priority would have no effect there.
+func (r *Route) WithPriority(priority int) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	// set priority
+	r.priority = priority
+
+	return r
+}
+
+// fullPath returns the path of the route including the group prefix.
+func (r *Route) fullPath() string {
+	if r.group == nil {
+		return r.subPath
+	}
+	return joinPath(r.group.prefix, normalizePath(r.subPath))
+}
+
 // GetTimeout returns the route's timeout setting.
 // If the route has no specific setting, the router's default value is returned.
 func (r *Route) GetTimeout() time.Duration {
 	if r.timeout <= 0 {
+		if r.router == nil {
+			return 0
+		}
 		return r.router.GetRequestTimeout()
 	}
 	return r.timeout
@@ -350,5 +669,8 @@ func (r *Route) GetErrorHandler() func(http.ResponseWriter, *http.Request, error
 	if r.group != nil && r.group.GetErrorHandler() != nil {
 		return r.group.GetErrorHandler()
 	}
+	if r.router == nil {
+		return defaultErrorHandler
+	}
 	return r.router.GetErrorHandler() // router's GetErrorHandler returns defaultErrorHandler if nil
 }
@@ -2,6 +2,8 @@ package router
 
 import (
 	"net/http"
+	"net/http/httptest"
+	"slices"
 	"testing"
 )
 
@@ -238,3 +240,192 @@ func TestGroupHTTPMethods(t *testing.T) {
 		t.Errorf("Number of group routes is different. Expected: %d, Actual: %d", 7, len(g.routes))
 	}
 }
+
+// TestRoutePriority tests route priorities applied at build time
+func TestRoutePriority(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	api := r.Group("/api")
+	api.Get("/items/{id:[0-9a-z]+}", handler)
+	api.Get("/items/{num:[0-9]+}", handler).WithPriority(1)
+
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	if pattern, _ := r.Match(http.MethodGet, "/api/items/42"); pattern != "/api/items/{num:[0-9]+}" {
+		t.Errorf("Expected the prioritized route to match, got %s", pattern)
+	}
+	if pattern, _ := r.Match(http.MethodGet, "/api/items/x42"); pattern != "/api/items/{id:[0-9a-z]+}" {
+		t.Errorf("Expected the other route to match, got %s", pattern)
+	}
+
+	// The reference implementation agrees when it knows the priorities
+	specs := []RouteSpec{
+		{Method: http.MethodGet, Pattern: "/api/items/{id:[0-9a-z]+}"},
+		{Method: http.MethodGet, Pattern: "/api/items/{num:[0-9]+}", Priority: 1},
+	}
+	if err := r.VerifyAgainst(specs, []string{"/api/items/42", "/api/items/x42"}); err != nil {
+		t.Errorf("Expected no mismatch, got: %v", err)
+	}
+
+	// A priority without overlapping routes is rejected
+	r2 := NewRouter()
+	defer r2.cache.stop()
+	r2.Get("/users/{id}", handler).WithPriority(5)
+	if err := r2.Build(); err == nil {
+		t.Error("Expected an error for a priority without overlapping routes")
+	}
+}
+
+// TestNestedGroupRoutesBuild tests that routes of nested groups are registered at build time
+func TestNestedGroupRoutesBuild(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	r.Group("/api").Group("/v1").Get("/users/{id}", handler)
+
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	if _, ok := r.Match(http.MethodGet, "/api/v1/users/1"); !ok {
+		t.Error("Route of nested group was not registered")
+	}
+}
+
+// TestStandaloneGroupAttach tests attaching a standalone group to multiple routers
+func TestStandaloneGroupAttach(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	users := NewGroup("/users").WithMeta("module", "users")
+	users.Get("/{id}", handler).WithMeta("name", "show")
+	users.Group("/admin").Post("/reset", handler)
+
+	// Handle cannot register routes before the group is attached
+	if err := users.Handle(http.MethodGet, "/direct", handler); err == nil {
+		t.Error("Expected an error for Handle on a standalone group")
+	}
+
+	public := NewRouter()
+	defer public.cache.stop()
+	admin := NewRouter()
+	defer admin.cache.stop()
+
+	public.Attach(users)
+	admin.Attach(users)
+
+	for _, r := range []*Router{public, admin} {
+		if err := r.Build(); err != nil {
+			t.Fatalf("Failed to build router: %v", err)
+		}
+		if _, ok := r.Match(http.MethodGet, "/users/1"); !ok {
+			t.Error("Route of attached group was not registered")
+		}
+		if _, ok := r.Match(http.MethodPost, "/users/admin/reset"); !ok {
+			t.Error("Route of attached child group was not registered")
+		}
+	}
+
+	// Each router has its own copy bound to it
+	if public.groups[0] == admin.groups[0] || public.groups[0].routes[0].router != public {
+		t.Error("Attached groups should be copies bound to each router")
+	}
+	if value, ok := admin.groups[0].routes[0].Meta("module"); !ok || value != "users" {
+		t.Errorf("Expected group metadata to be copied, got %v", value)
+	}
+}
+
+// TestGroupMiddlewareApplied tests that group middleware wraps routes defined on the group.
+// As with the router middleware, middleware registered later is executed first.
+func TestGroupMiddlewareApplied(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var order []string
+	mw := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) error {
+				order = append(order, name)
+				return next(w, req)
+			}
+		}
+	}
+
+	api := r.Group("/api", mw("api"))
+	api.Group("/v1", mw("v1")).Get("/users", func(w http.ResponseWriter, r *http.Request) error {
+		order = append(order, "handler")
+		return nil
+	}).WithMiddleware(mw("route"))
+
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+	want := []string{"v1", "api", "route", "handler"}
+	if !slices.Equal(order, want) {
+		t.Errorf("Expected order %v, got %v", want, order)
+	}
+}
+
+// TestPerRequestMiddleware tests that group middleware is resolved per request
+func TestPerRequestMiddleware(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.PerRequestMiddleware = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	var order []string
+	mw := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) error {
+				order = append(order, name)
+				return next(w, req)
+			}
+		}
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		order = append(order, "handler")
+		return nil
+	}
+
+	api := r.Group("/api", mw("api"))
+	v1 := api.Group("/v1")
+	v1.Get("/users", handler)
+	if err := v1.Handle(http.MethodGet, "/posts", handler); err != nil {
+		t.Fatalf("Failed to register route: %v", err)
+	}
+
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	serve := func(path string) []string {
+		order = nil
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		return order
+	}
+
+	if got, want := serve("/api/v1/users"), []string{"api", "handler"}; !slices.Equal(got, want) {
+		t.Errorf("Expected order %v, got %v", want, got)
+	}
+
+	// Middleware added after Build takes effect immediately
+	v1.Use(mw("v1"))
+	r.Use(mw("global"))
+	for _, path := range []string{"/api/v1/users", "/api/v1/posts"} {
+		if got, want := serve(path), []string{"global", "v1", "api", "handler"}; !slices.Equal(got, want) {
+			t.Errorf("%s: expected order %v, got %v", path, want, got)
+		}
+	}
+
+	// Middleware can be disabled again
+	api.SetMiddleware()
+	r.SetMiddleware()
+	if got, want := serve("/api/v1/users"), []string{"v1", "handler"}; !slices.Equal(got, want) {
+		t.Errorf("Expected order %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,127 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// inFlightGate limits the number of requests handled concurrently (see RouterOptions.MaxInFlight).
+type inFlightGate struct {
+	slots    chan struct{} // One element per request in flight
+	timeout  time.Duration // Maximum wait for a slot
+	queued   atomic.Int64  // Number of requests waiting for a slot
+	rejected atomic.Uint64 // Number of requests rejected with 503
+}
+
+// SaturationStats is a snapshot of the concurrency limit of the router.
+type SaturationStats struct {
+	Limit    int    // Maximum number of requests in flight (0 if unlimited)
+	InFlight int    // Number of requests in flight
+	Queued   int    // Number of requests waiting for a slot
+	Rejected uint64 // Number of requests rejected because no slot became free in time
+}
+
+// Saturated reports whether all slots are in use, so that new requests have to wait.
+func (s SaturationStats) Saturated() bool {
+	return s.Limit > 0 && s.InFlight >= s.Limit
+}
+
+// newInFlightGate creates a gate with the number of slots.
+func newInFlightGate(limit int, timeout time.Duration) *inFlightGate {
+	return &inFlightGate{slots: make(chan struct{}, limit), timeout: timeout}
+}
+
+// acquire takes a slot, waiting up to the queue timeout. It returns false if no slot
+// became free in time or the request was canceled while waiting.
+func (g *inFlightGate) acquire(ctx context.Context) bool {
+	select {
+	case g.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if g.timeout <= 0 {
+		g.rejected.Add(1)
+		return false
+	}
+
+	g.queued.Add(1)
+	defer g.queued.Add(-1)
+	timer := time.NewTimer(g.timeout)
+	defer timer.Stop()
+	select {
+	case g.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	g.rejected.Add(1)
+	return false
+}
+
+// release frees the slot of a finished request.
+func (g *inFlightGate) release() {
+	<-g.slots
+}
+
+// limit returns the number of slots (0 for a nil gate).
+func (g *inFlightGate) limit() int {
+	if g == nil {
+		return 0
+	}
+	return cap(g.slots)
+}
+
+// queueTimeout returns the maximum wait for a slot (0 for a nil gate).
+func (g *inFlightGate) queueTimeout() time.Duration {
+	if g == nil {
+		return 0
+	}
+	return g.timeout
+}
+
+// Saturation returns the state of the concurrency limit (see RouterOptions.MaxInFlight).
+func (r *Router) Saturation() SaturationStats {
+	if r.gate == nil {
+		return SaturationStats{}
+	}
+	return SaturationStats{
+		Limit:    cap(r.gate.slots),
+		InFlight: len(r.gate.slots),
+		Queued:   int(r.gate.queued.Load()),
+		Rejected: r.gate.rejected.Load(),
+	}
+}
+
+// WithoutConcurrencyLimit exempts the route from RouterOptions.MaxInFlight, for example for
+// health and readiness probes, which must answer even when the router is saturated.
+func (r *Route) WithoutConcurrencyLimit() *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.ungated = true
+	return r
+}
+
+// Readiness returns a handler for a readiness probe. It responds 200 while the router accepts
+// traffic, and 503 while it is shutting down or saturated (all MaxInFlight slots in use), so that
+// load balancers send requests to other instances. Register it with WithoutConcurrencyLimit.
+//
+// 例: r.Get("/ready", r.Readiness()).WithoutConcurrencyLimit()
+func (r *Router) Readiness() HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		w.Header().Set("Cache-Control", "no-store")
+		switch {
+		case r.shuttingDown.Load():
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		case r.Saturation().Saturated():
+			http.Error(w, "saturated", http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte("ready"))
+		}
+		return nil
+	}
+}
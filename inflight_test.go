@@ -0,0 +1,107 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestMaxInFlight tests limiting the number of concurrent requests
+func TestMaxInFlight(t *testing.T) {
+	for _, queueTimeout := range []time.Duration{0, time.Second} {
+		opts := defaultRouterOptions()
+		opts.MaxInFlight = 1
+		opts.QueueTimeout = queueTimeout
+		r := NewRouterWithOptions(opts)
+
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		r.Get("/slow", func(w http.ResponseWriter, req *http.Request) error {
+			close(started)
+			<-unblock
+			return nil
+		})
+		r.Get("/fast", func(w http.ResponseWriter, req *http.Request) error { return nil })
+		r.Get("/ready", r.Readiness()).WithoutConcurrencyLimit()
+		if err := r.Build(); err != nil {
+			t.Fatalf("Failed to build router: %v", err)
+		}
+
+		// Occupy the only slot
+		slowDone := make(chan struct{})
+		go func() {
+			defer close(slowDone)
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+		<-started
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("queue %v: expected the readiness probe to report saturation, got %d", queueTimeout, w.Code)
+		}
+
+		if queueTimeout == 0 {
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+			if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+				t.Errorf("Expected an excess request to be rejected, got %d", w.Code)
+			}
+			if s := r.Saturation(); s.Limit != 1 || s.InFlight != 1 || s.Rejected != 1 || !s.Saturated() {
+				t.Errorf("Unexpected saturation %+v", s)
+			}
+			close(unblock)
+		} else {
+			// The queued request proceeds once the slot is freed
+			go func() {
+				for r.Saturation().Queued == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				close(unblock)
+			}()
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected the queued request to succeed, got %d", w.Code)
+			}
+		}
+		<-slowDone
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		if w.Code != http.StatusOK || r.Saturation().InFlight != 0 {
+			t.Errorf("queue %v: expected the router to be ready, got %d (%+v)", queueTimeout, w.Code, r.Saturation())
+		}
+		r.cache.stop()
+	}
+}
+
+// TestMaxInFlightDispatch tests that a dispatched request uses the slot of the request that dispatched it
+func TestMaxInFlightDispatch(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.MaxInFlight = 1
+	opts.QueueTimeout = 50 * time.Millisecond
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	r.Get("/a", func(w http.ResponseWriter, req *http.Request) error {
+		return r.Dispatch(w, req, "/b")
+	})
+	r.Get("/b", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("b"))
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "b" {
+		t.Errorf("Expected the dispatched request to be served, got %d %q", w.Code, w.Body.String())
+	}
+	if s := r.Saturation(); s.InFlight != 0 || s.Rejected != 0 {
+		t.Errorf("Unexpected saturation %+v", s)
+	}
+}
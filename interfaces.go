@@ -0,0 +1,37 @@
+package router
+
+import "net/http"
+
+// RouteRegistrar is the route definition API shared by *Router and *Group. Libraries that
+// define routes can accept it instead of the concrete types, so that they can be mounted on a
+// router or a group, and tested with a test double.
+//
+// The name differs from Registrar, which is the interface of the feature modules that
+// Router.Register wires to the router.
+type RouteRegistrar interface {
+	Route(method, pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Get(pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Post(pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Put(pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Delete(pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Patch(pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Head(pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Options(pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Handle(method, pattern string, h HandlerFunc) error
+	Group(prefix string, middleware ...MiddlewareFunc) *Group
+}
+
+// Dispatcher is the request handling API of *Router. Handlers and middleware that serve or
+// re-dispatch requests can accept it instead of *Router.
+type Dispatcher interface {
+	http.Handler
+	Dispatch(w http.ResponseWriter, req *http.Request, path string) error
+	Match(method, path string) (string, bool)
+}
+
+// Compile-time checks of the implementations.
+var (
+	_ RouteRegistrar = (*Router)(nil)
+	_ RouteRegistrar = (*Group)(nil)
+	_ Dispatcher     = (*Router)(nil)
+)
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// mountHealth is a library function that only depends on the interfaces.
+func mountHealth(rr RouteRegistrar) {
+	rr.Get("/health", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("ok"))
+		return nil
+	})
+}
+
+// TestInterfaces tests using the router through its interfaces
+func TestInterfaces(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	mountHealth(r)
+	mountHealth(r.Group("/admin"))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	var d Dispatcher = r
+	for _, path := range []string{"/health", "/admin/health"} {
+		if pattern, ok := d.Match(http.MethodGet, path); !ok || pattern != path {
+			t.Errorf("%s: expected a match, got %q %v", path, pattern, ok)
+		}
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Body.String() != "ok" {
+			t.Errorf("%s: expected ok, got %q", path, w.Body.String())
+		}
+	}
+}
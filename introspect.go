@@ -0,0 +1,126 @@
+package router
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// GroupInfo describes a group for auditing, for example in tests.
+type GroupInfo struct {
+	Prefix          string        // Full prefix of the group
+	Module          string        // Module that registered the group ("" if none)
+	Middleware      []string      // Names of the group middleware, including middleware inherited from parent groups
+	HasErrorHandler bool          // Whether the group has its own error handler
+	Timeout         time.Duration // Effective timeout of the group
+	Routes          []RouteInfo   // Routes defined on the group (not including child groups)
+	Group           *Group        // The group itself, e.g. for Meta lookups
+}
+
+// RouteInfo describes a route for auditing, for example in tests.
+type RouteInfo struct {
+	Method          string        // HTTP method
+	Pattern         string        // Full pattern, including the group prefix
+	Name            string        // Name of the route ("" if unnamed)
+	Middleware      []string      // Names of the group and route middleware, in execution order (global middleware excluded)
+	HasErrorHandler bool          // Whether the route or its group has its own error handler
+	Timeout         time.Duration // Effective timeout of the route
+	Consumes        []string      // Accepted request body media types (nil accepts any)
+	Produces        []string      // Response media types (nil if not declared)
+	Tags            []string      // Tags of the route and its groups (see Route.WithTags)
+	Route           *Route        // The route definition itself, e.g. for Meta lookups
+}
+
+// Groups returns all groups of the router, including nested child groups (parents before children).
+// Together with Group.Routes it allows apps to audit per-group coverage programmatically,
+// e.g. that every route below /admin runs an authentication middleware.
+//
+// 例: for _, g := range r.Groups() { if strings.HasPrefix(g.Prefix, "/admin") { ... } }
+func (r *Router) Groups() []GroupInfo {
+	r.mu.RLock()
+	groups := r.allGroups()
+	r.mu.RUnlock()
+
+	infos := make([]GroupInfo, 0, len(groups))
+	for _, g := range groups {
+		g.mwMu.Lock()
+		middleware := middlewareNames(g.middleware)
+		g.mwMu.Unlock()
+
+		infos = append(infos, GroupInfo{
+			Prefix:          g.prefix,
+			Module:          g.module,
+			Middleware:      middleware,
+			HasErrorHandler: g.errorHandler != nil,
+			Timeout:         g.GetTimeout(),
+			Routes:          g.Routes(),
+			Group:           g,
+		})
+	}
+	return infos
+}
+
+// Routes returns the routes defined on the group, not including routes of child groups.
+// Middleware names are those reported by the Go runtime for the middleware functions
+// (e.g. "example.com/app/auth.RequireUser"), so named middleware can be identified.
+func (g *Group) Routes() []RouteInfo {
+	g.mwMu.Lock()
+	groupMiddleware := slices.Clone(g.middleware)
+	g.mwMu.Unlock()
+
+	routes := slices.Concat(g.routes, g.handled)
+	infos := make([]RouteInfo, 0, len(routes))
+	for _, route := range routes {
+		infos = append(infos, newRouteInfo(route, groupMiddleware))
+	}
+	return infos
+}
+
+// info describes the route with the middleware of its group.
+func (r *Route) info() RouteInfo {
+	var groupMiddleware []MiddlewareFunc
+	if r.group != nil {
+		r.group.mwMu.Lock()
+		groupMiddleware = slices.Clone(r.group.middleware)
+		r.group.mwMu.Unlock()
+	}
+	return newRouteInfo(r, groupMiddleware)
+}
+
+// newRouteInfo describes the route, whose group (if any) has the specified middleware.
+func newRouteInfo(route *Route, groupMiddleware []MiddlewareFunc) RouteInfo {
+	return RouteInfo{
+		Method:          route.method,
+		Pattern:         route.fullPath(),
+		Name:            route.name,
+		Middleware:      middlewareNames(slices.Concat(groupMiddleware, route.middleware)),
+		HasErrorHandler: route.ownErrorHandler() != nil,
+		Timeout:         route.GetTimeout(),
+		Consumes:        route.GetConsumes(),
+		Produces:        route.GetProduces(),
+		Tags:            route.Tags(),
+		Route:           route,
+	}
+}
+
+// middlewareNames returns the function names of the middleware.
+func middlewareNames(middleware []MiddlewareFunc) []string {
+	names := make([]string, 0, len(middleware))
+	for _, mw := range middleware {
+		names = append(names, funcName(mw))
+	}
+	return names
+}
+
+// funcName returns the name of a function as reported by the Go runtime ("" for nil).
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// requireAuthForTest is a named middleware used to test auditing
+func requireAuthForTest(next HandlerFunc) HandlerFunc {
+	return next
+}
+
+// TestGroupsAndRoutes tests auditing groups and their routes
+func TestGroupsAndRoutes(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	admin := r.Group("/admin", requireAuthForTest).WithMeta("area", "admin")
+	admin.Get("/stats", handler)
+	admin.Group("/users").Post("/{id}/reset", handler).WithErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {})
+	public := r.Group("/public")
+	public.Get("/status", handler)
+	public.Get("/secret", handler, requireAuthForTest)
+
+	groups := r.Groups()
+	prefixes := make([]string, 0, len(groups))
+	for _, g := range groups {
+		prefixes = append(prefixes, g.Prefix)
+	}
+	if !slices.Equal(prefixes, []string{"/admin", "/admin/users", "/public"}) {
+		t.Fatalf("Unexpected groups: %v", prefixes)
+	}
+	if area, _ := groups[1].Group.Meta("area"); area != "admin" {
+		t.Errorf("Expected inherited group metadata, got %v", area)
+	}
+
+	// Every /admin route runs the auth middleware
+	for _, g := range groups {
+		if !strings.HasPrefix(g.Prefix, "/admin") {
+			continue
+		}
+		for _, route := range g.Routes {
+			if !slices.ContainsFunc(route.Middleware, func(name string) bool { return strings.HasSuffix(name, ".requireAuthForTest") }) {
+				t.Errorf("Route %s %s does not require auth: %v", route.Method, route.Pattern, route.Middleware)
+			}
+		}
+	}
+
+	routes := public.Routes()
+	if len(routes) != 2 || routes[0].Pattern != "/public/status" || len(routes[0].Middleware) != 0 || len(routes[1].Middleware) != 1 {
+		t.Errorf("Unexpected routes: %+v", routes)
+	}
+	if users := groups[1].Routes; len(users) != 1 || !users[0].HasErrorHandler || users[0].Pattern != "/admin/users/{id}/reset" {
+		t.Errorf("Unexpected routes: %+v", users)
+	}
+}
@@ -0,0 +1,158 @@
+package router
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// Latency histogram layout. Latencies are recorded in microseconds into log-linear buckets
+// (HDR-style): values below 8µs have a bucket each, and every power of two above is split
+// into 8 buckets, so a bucket is at most 12.5% wide. The histogram is rolling: it is made of
+// latencySlots slots of latencySlotDuration, and the oldest slot is reused as time passes.
+const (
+	latencySubBuckets   = 8
+	latencyBuckets      = 36 * latencySubBuckets // Up to 2^36µs (about 19 hours)
+	latencySlots        = 6
+	latencySlotDuration = 10 * time.Second
+)
+
+// LatencySnapshot summarizes the latencies of a route over the last minute.
+// Percentiles are accurate to 12.5% (the width of a histogram bucket).
+type LatencySnapshot struct {
+	Count  uint64        // Number of requests
+	Mean   time.Duration // Mean latency
+	P50    time.Duration // Median latency
+	P95    time.Duration // 95th percentile latency
+	P99    time.Duration // 99th percentile latency
+	Max    time.Duration // Maximum latency
+	Window time.Duration // Period covered by the snapshot
+}
+
+// latencyHistogram is a rolling latency histogram with bounded memory.
+type latencyHistogram struct {
+	mu    sync.Mutex
+	slots [latencySlots]latencySlot
+}
+
+// latencySlot holds the latencies recorded during one slot period.
+type latencySlot struct {
+	period int64 // Index of the slot period since the Unix epoch
+	count  uint64
+	sum    time.Duration
+	max    time.Duration
+	counts [latencyBuckets]uint32
+}
+
+// record adds a latency to the histogram.
+func (h *latencyHistogram) record(d time.Duration) {
+	period := time.Now().UnixNano() / int64(latencySlotDuration)
+	bucket := latencyBucket(d)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	slot := &h.slots[period%latencySlots]
+	if slot.period != period {
+		*slot = latencySlot{period: period}
+	}
+	slot.count++
+	slot.sum += d
+	slot.max = max(slot.max, d)
+	slot.counts[bucket]++
+}
+
+// snapshot merges the slots of the current window.
+func (h *latencyHistogram) snapshot() (counts [latencyBuckets]uint64, count uint64, sum, maxLatency time.Duration) {
+	period := time.Now().UnixNano() / int64(latencySlotDuration)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.slots {
+		slot := &h.slots[i]
+		if slot.count == 0 || period-slot.period >= latencySlots {
+			continue
+		}
+		count += slot.count
+		sum += slot.sum
+		maxLatency = max(maxLatency, slot.max)
+		for b, c := range slot.counts {
+			counts[b] += uint64(c)
+		}
+	}
+	return counts, count, sum, maxLatency
+}
+
+// latencyBucket returns the bucket of a latency.
+func latencyBucket(d time.Duration) int {
+	us := uint64(max(d.Microseconds(), 0))
+	if us < latencySubBuckets {
+		return int(us)
+	}
+	exponent := bits.Len64(us) - 4 // us>>exponent is in [8, 16)
+	bucket := (exponent+1)*latencySubBuckets + int(us>>exponent) - latencySubBuckets
+	return min(bucket, latencyBuckets-1)
+}
+
+// latencyBucketUpper returns the upper bound of a bucket.
+func latencyBucketUpper(bucket int) time.Duration {
+	if bucket < latencySubBuckets {
+		return time.Duration(bucket+1) * time.Microsecond
+	}
+	exponent := bucket/latencySubBuckets - 1
+	mantissa := uint64(bucket%latencySubBuckets + latencySubBuckets + 1)
+	return time.Duration(mantissa<<exponent) * time.Microsecond
+}
+
+// Latencies returns the latency percentiles of the routes with the pattern over the last minute,
+// combining all methods of the pattern. The snapshot is empty unless RouterOptions.LatencyHistograms
+// is enabled or if the pattern has not been requested recently.
+//
+// 例: snap := r.Latencies("/users/{id}"); fmt.Fprintf(w, "p99=%v", snap.P99)
+func (r *Router) Latencies(pattern string) LatencySnapshot {
+	snapshot := LatencySnapshot{Window: latencySlots * latencySlotDuration}
+
+	r.mu.RLock()
+	var histograms []*latencyHistogram
+	for _, s := range r.routeStats {
+		if s.pattern == pattern && s.latency != nil {
+			histograms = append(histograms, s.latency)
+		}
+	}
+	r.mu.RUnlock()
+
+	var counts [latencyBuckets]uint64
+	var sum time.Duration
+	for _, h := range histograms {
+		c, count, s, m := h.snapshot()
+		for b := range counts {
+			counts[b] += c[b]
+		}
+		snapshot.Count += count
+		sum += s
+		snapshot.Max = max(snapshot.Max, m)
+	}
+	if snapshot.Count == 0 {
+		return snapshot
+	}
+
+	snapshot.Mean = sum / time.Duration(snapshot.Count)
+	snapshot.P50 = latencyPercentile(&counts, snapshot.Count, 0.50, snapshot.Max)
+	snapshot.P95 = latencyPercentile(&counts, snapshot.Count, 0.95, snapshot.Max)
+	snapshot.P99 = latencyPercentile(&counts, snapshot.Count, 0.99, snapshot.Max)
+	return snapshot
+}
+
+// latencyPercentile returns the upper bound of the bucket that holds the percentile,
+// capped at the maximum latency.
+func latencyPercentile(counts *[latencyBuckets]uint64, total uint64, q float64, maxLatency time.Duration) time.Duration {
+	rank := uint64(math.Ceil(q * float64(total)))
+	var cumulative uint64
+	for b, c := range counts {
+		cumulative += c
+		if cumulative >= rank {
+			return min(latencyBucketUpper(b), maxLatency)
+		}
+	}
+	return maxLatency
+}
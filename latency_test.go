@@ -0,0 +1,66 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestLatencyBuckets tests that latencies fall into buckets with a bounded relative error
+func TestLatencyBuckets(t *testing.T) {
+	for _, d := range []time.Duration{0, 3 * time.Microsecond, 8 * time.Microsecond, 17 * time.Microsecond,
+		time.Millisecond, 123 * time.Millisecond, 7 * time.Second} {
+		upper := latencyBucketUpper(latencyBucket(d))
+		if upper <= d.Truncate(time.Microsecond) || float64(upper) > float64(d)*1.125+float64(time.Microsecond) {
+			t.Errorf("%v: unexpected bucket upper bound %v", d, upper)
+		}
+	}
+}
+
+// TestLatencies tests the latency percentiles of routes
+func TestLatencies(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.LatencyHistograms = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) error { return nil })
+	r.Post("/users/{id}", func(w http.ResponseWriter, req *http.Request) error { return nil })
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users/1", nil))
+	if snap := r.Latencies("/users/{id}"); snap.Count != 2 || snap.Window != time.Minute {
+		t.Errorf("Expected 2 requests over a minute, got %+v", snap)
+	}
+
+	// Feed a known distribution: 1ms..100ms
+	h := &latencyHistogram{}
+	for i := 1; i <= 100; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+	r.mu.Lock()
+	r.routeStatsFor(http.MethodGet, "/synthetic").latency = h
+	r.mu.Unlock()
+
+	snap := r.Latencies("/synthetic")
+	if snap.Count != 100 || snap.Max != 100*time.Millisecond {
+		t.Fatalf("Unexpected snapshot %+v", snap)
+	}
+	within := func(got, want time.Duration) bool {
+		return got >= want && float64(got) <= float64(want)*1.125
+	}
+	if !within(snap.P50, 50*time.Millisecond) || !within(snap.P95, 95*time.Millisecond) || !within(snap.P99, 99*time.Millisecond) {
+		t.Errorf("Unexpected percentiles %+v", snap)
+	}
+	if snap.Mean < 50*time.Millisecond || snap.Mean > 51*time.Millisecond {
+		t.Errorf("Unexpected mean %v", snap.Mean)
+	}
+
+	// Unknown patterns and disabled histograms have empty snapshots
+	if snap := r.Latencies("/unknown"); snap.Count != 0 {
+		t.Errorf("Expected an empty snapshot, got %+v", snap)
+	}
+}
@@ -0,0 +1,97 @@
+package router
+
+import "slices"
+
+// lifecycleHooks are the callbacks registered with OnRouteRegistered, OnBuildComplete, and OnShutdown.
+type lifecycleHooks struct {
+	routeRegistered []func(RouteInfo)
+	buildComplete   []func(BuildReport)
+	shutdown        []func()
+}
+
+// clone returns a copy of the hooks with their own slices.
+func (h lifecycleHooks) clone() lifecycleHooks {
+	return lifecycleHooks{
+		routeRegistered: slices.Clone(h.routeRegistered),
+		buildComplete:   slices.Clone(h.buildComplete),
+		shutdown:        slices.Clone(h.shutdown),
+	}
+}
+
+// OnRouteRegistered registers a function that is called for every route added to the routing
+// table: routes defined with Get, Post, Route, etc. when Build registers them, and routes added
+// immediately with Handle. Frameworks built on the router can use it to set up per-route
+// resources such as metrics without wrapping every registration method.
+// Routes registered with Router.Handle have no Route definition, so only Method and Pattern are set.
+// The function is called synchronously, in registration order; hooks of tenant overlays (see
+// ForTenant) are those of the router. A nil function is ignored.
+//
+// 例: r.OnRouteRegistered(func(ri router.RouteInfo) { metrics.Register(ri.Method, ri.Pattern) })
+func (r *Router) OnRouteRegistered(fn func(RouteInfo)) {
+	if fn == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks.routeRegistered = append(r.hooks.routeRegistered, fn)
+}
+
+// OnBuildComplete registers a function that is called with the outcome of every Build,
+// whether it succeeded or not. A nil function is ignored.
+func (r *Router) OnBuildComplete(fn func(BuildReport)) {
+	if fn == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks.buildComplete = append(r.hooks.buildComplete, fn)
+}
+
+// OnShutdown registers a function that is called once when Shutdown is first called, after the
+// router stopped admitting new requests and before it waits for the requests in flight.
+// Functions are called in reverse registration order, like deferred calls. A nil function is ignored.
+func (r *Router) OnShutdown(fn func()) {
+	if fn == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks.shutdown = append(r.hooks.shutdown, fn)
+}
+
+// routeRegistered calls the OnRouteRegistered hooks.
+func (r *Router) routeRegistered(info RouteInfo) {
+	if r.tenantParent != nil {
+		r.tenantParent.routeRegistered(info)
+		return
+	}
+	r.mu.RLock()
+	hooks := r.hooks.routeRegistered
+	r.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(info)
+	}
+}
+
+// buildCompleted calls the OnBuildComplete hooks.
+func (r *Router) buildCompleted(report BuildReport) {
+	r.mu.RLock()
+	hooks := r.hooks.buildComplete
+	r.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(report)
+	}
+}
+
+// notifyShutdown calls the OnShutdown hooks the first time it is called.
+func (r *Router) notifyShutdown() {
+	if r.shutdownNotified.Swap(true) {
+		return
+	}
+	r.mu.RLock()
+	hooks := r.hooks.shutdown
+	r.mu.RUnlock()
+	for _, fn := range slices.Backward(hooks) {
+		fn()
+	}
+}
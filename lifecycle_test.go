@@ -0,0 +1,72 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// TestLifecycleHooks tests the route registration, build, and shutdown hooks
+func TestLifecycleHooks(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var registered []string
+	r.OnRouteRegistered(func(ri RouteInfo) {
+		registered = append(registered, ri.Method+" "+ri.Pattern)
+	})
+	var builds []BuildReport
+	r.OnBuildComplete(func(br BuildReport) {
+		builds = append(builds, br)
+	})
+	var shutdowns []string
+	r.OnShutdown(func() { shutdowns = append(shutdowns, "first") })
+	r.OnShutdown(func() { shutdowns = append(shutdowns, "second") })
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	if err := r.Handle(http.MethodGet, "/health", handler); err != nil {
+		t.Fatalf("Failed to register route: %v", err)
+	}
+	r.Get("/items", handler)
+	api := r.Group("/api")
+	api.Post("/users", handler)
+	if err := api.Handle(http.MethodDelete, "/users/{id}", handler); err != nil {
+		t.Fatalf("Failed to register route: %v", err)
+	}
+
+	// Routes defined with Get, Post, etc. are registered by Build
+	want := []string{"GET /health", "DELETE /api/users/{id}"}
+	if !slices.Equal(registered, want) {
+		t.Errorf("Expected %v before Build, got %v", want, registered)
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	want = append(want, "GET /items", "POST /api/users")
+	if !slices.Equal(registered, want) {
+		t.Errorf("Expected %v after Build, got %v", want, registered)
+	}
+	if len(builds) != 1 || !builds[0].Built {
+		t.Errorf("Expected one successful build, got %+v", builds)
+	}
+
+	// Failed builds are reported too
+	r.Get("/items", handler)
+	if err := r.Build(); err == nil {
+		t.Fatal("Expected the duplicate route to fail the build")
+	}
+	if len(builds) != 2 || builds[1].Built || builds[1].Error == "" {
+		t.Errorf("Expected a failed build, got %+v", builds)
+	}
+
+	// Shutdown hooks run once, in reverse registration order
+	for range 2 {
+		if err := r.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Failed to shut down: %v", err)
+		}
+	}
+	if !slices.Equal(shutdowns, []string{"second", "first"}) {
+		t.Errorf("Expected the shutdown hooks to run once in reverse order, got %v", shutdowns)
+	}
+}
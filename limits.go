@@ -0,0 +1,71 @@
+package router
+
+import "net/http"
+
+// withinPathLimits reports whether the request path satisfies the
+// MaxPathLength and MaxSegments options of the router.
+func (r *Router) withinPathLimits(path string) bool {
+	if r.maxPathLength > 0 && len(path) > r.maxPathLength {
+		return false
+	}
+
+	if r.maxSegments > 0 {
+		// Count segments without allocating (each segment starts with a slash)
+		segments := 0
+		for i := 0; i < len(path); i++ {
+			if path[i] == '/' {
+				segments++
+				if segments > r.maxSegments {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// HeaderLimits limits the request headers of the routes of a group (see Group.WithHeaderLimits).
+// Zero fields do not limit.
+type HeaderLimits struct {
+	MaxCount int // Maximum number of header values
+	MaxBytes int // Maximum total size of the header names and values in bytes
+}
+
+// WithHeaderLimits limits the request headers of the routes of the group and its child groups.
+// Requests exceeding the limits are rejected with 431 Request Header Fields Too Large before the
+// middleware and handler run. The limits apply in addition to RouterOptions.MaxHeaderCount and
+// MaxHeaderBytes, which are checked before route matching, so a group can only tighten them.
+//
+// 例: api.WithHeaderLimits(HeaderLimits{MaxCount: 50, MaxBytes: 8 << 10})
+func (g *Group) WithHeaderLimits(limits HeaderLimits) *Group {
+	g.headerLimits = &limits
+	return g
+}
+
+// GetHeaderLimits returns the header limits of the group, including limits inherited from
+// parent groups (zero if none are set).
+func (g *Group) GetHeaderLimits() HeaderLimits {
+	for current := g; current != nil; current = current.parent {
+		if current.headerLimits != nil {
+			return *current.headerLimits
+		}
+	}
+	return HeaderLimits{}
+}
+
+// withinHeaderLimits reports whether the request header satisfies the limits.
+func withinHeaderLimits(header http.Header, limits HeaderLimits) bool {
+	if limits.MaxCount <= 0 && limits.MaxBytes <= 0 {
+		return true
+	}
+
+	count, size := 0, 0
+	for name, values := range header {
+		count += len(values)
+		for _, value := range values {
+			size += len(name) + len(value)
+		}
+	}
+	return (limits.MaxCount <= 0 || count <= limits.MaxCount) && (limits.MaxBytes <= 0 || size <= limits.MaxBytes)
+}
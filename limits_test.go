@@ -0,0 +1,92 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestPathLimits tests that long paths and paths with many segments are rejected with 414
+func TestPathLimits(t *testing.T) {
+	r := NewRouterWithOptions(RouterOptions{MaxPathLength: 32, MaxSegments: 3})
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte("OK"))
+		return err
+	}
+	r.Get("/a/b/c", handler)
+	r.Get("/a/{x}", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"Within limits", "/a/b/c", http.StatusOK},
+		{"Dynamic within limits", "/a/123", http.StatusOK},
+		{"Too many segments", "/a/b/c/d", http.StatusRequestURITooLong},
+		{"Too long", "/a/" + strings.Repeat("x", 40), http.StatusRequestURITooLong},
+		{"Not found within limits", "/b", http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d for path %s, got %d", tc.expectedStatus, tc.path, w.Code)
+			}
+		})
+	}
+}
+
+// TestHeaderLimits tests rejecting requests with too many or too large headers
+func TestHeaderLimits(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.MaxHeaderCount = 10
+	opts.MaxHeaderBytes = 1024
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/open", handler)
+	api := r.Group("/api").WithHeaderLimits(HeaderLimits{MaxCount: 3})
+	api.Get("/items", handler)
+	api.Group("/v2").Get("/items", handler)
+	r.Group("/upload").WithHeaderLimits(HeaderLimits{MaxBytes: 64}).Get("/file", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		count  int
+		value  string
+		status int
+	}{
+		{"/open", 10, "v", http.StatusOK},
+		{"/open", 11, "v", http.StatusRequestHeaderFieldsTooLarge},
+		{"/open", 1, strings.Repeat("v", 1024), http.StatusRequestHeaderFieldsTooLarge},
+		{"/api/items", 3, "v", http.StatusOK},
+		{"/api/items", 4, "v", http.StatusRequestHeaderFieldsTooLarge},
+		{"/api/v2/items", 4, "v", http.StatusRequestHeaderFieldsTooLarge}, // Inherited by child groups
+		{"/upload/file", 5, "v", http.StatusOK},
+		{"/upload/file", 1, strings.Repeat("v", 64), http.StatusRequestHeaderFieldsTooLarge},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		for range tt.count {
+			req.Header.Add("X-Test", tt.value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s with %d headers of %d bytes: expected %d, got %d", tt.path, tt.count, len(tt.value), tt.status, w.Code)
+		}
+	}
+}
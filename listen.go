@@ -0,0 +1,177 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Address prefixes understood by Server.Bind in addition to plain TCP addresses.
+const (
+	// unixAddrPrefix selects a Unix domain socket, e.g. "unix:///run/app.sock".
+	unixAddrPrefix = "unix://"
+	// systemdAddrPrefix selects a socket passed by systemd socket activation,
+	// either by name (FileDescriptorName=) or by zero-based index, e.g. "systemd://admin" or "systemd://0".
+	systemdAddrPrefix = "systemd://"
+)
+
+// listenFDsStart is the first file descriptor passed by socket activation (SD_LISTEN_FDS_START).
+const listenFDsStart = 3
+
+// UnixSocketOptions configures the socket files created for "unix://" addresses.
+type UnixSocketOptions struct {
+	// Mode is the file mode of the socket file. If 0, the mode is left as created (subject to umask).
+	Mode os.FileMode
+
+	// Chown changes the ownership of the socket file to UID and GID.
+	// A UID or GID of -1 leaves that value unchanged.
+	Chown bool
+	UID   int
+	GID   int
+}
+
+// listen opens a listener for the address.
+// Plain addresses listen on TCP; "unix://" and "systemd://" addresses are handled as described on Server.Bind.
+func (s *Server) listen(addr string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(addr, unixAddrPrefix):
+		return listenUnix(strings.TrimPrefix(addr, unixAddrPrefix), s.UnixSocket)
+	case strings.HasPrefix(addr, systemdAddrPrefix):
+		return activationListener(strings.TrimPrefix(addr, systemdAddrPrefix))
+	default:
+		return net.Listen("tcp", addr)
+	}
+}
+
+// listenUnix listens on a Unix domain socket, replacing a stale socket file left by a previous process.
+func listenUnix(path string, opts UnixSocketOptions) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("router: empty unix socket path")
+	}
+
+	// Remove a stale socket, but never a regular file that happens to be at the path
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("router: %s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mode != 0 {
+		if err := os.Chmod(path, opts.Mode); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	if opts.Chown {
+		if err := os.Chown(path, opts.UID, opts.GID); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Socket activation listeners are inherited once per process and handed out by name or index.
+var activation struct {
+	once      sync.Once
+	mu        sync.Mutex
+	listeners []net.Listener
+	names     []string
+	used      []bool
+	err       error
+}
+
+// ActivationListeners returns all listeners passed to the process by systemd socket activation
+// (LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES). It returns nil if the process was not socket activated.
+// The environment variables are unset after the first call so that child processes do not inherit them.
+func ActivationListeners() ([]net.Listener, error) {
+	activation.once.Do(func() {
+		activation.listeners, activation.names, activation.err = listenFDs(listenFDsStart)
+		activation.used = make([]bool, len(activation.listeners))
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	})
+	return activation.listeners, activation.err
+}
+
+// activationListener returns the activation listener with the given name or zero-based index.
+// Each listener can only be bound once.
+func activationListener(key string) (net.Listener, error) {
+	listeners, err := ActivationListeners()
+	if err != nil {
+		return nil, err
+	}
+
+	activation.mu.Lock()
+	defer activation.mu.Unlock()
+
+	index := -1
+	for i, name := range activation.names {
+		if name == key {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		if n, err := strconv.Atoi(key); err == nil && n >= 0 && n < len(listeners) {
+			index = n
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("router: no socket activation listener %q", key)
+	}
+	if activation.used[index] {
+		return nil, fmt.Errorf("router: socket activation listener %q is already bound", key)
+	}
+	activation.used[index] = true
+	return listeners[index], nil
+}
+
+// listenFDs converts the file descriptors described by the socket activation environment,
+// starting at fd start, into listeners. Names default to "unknown" as in sd_listen_fds_with_names.
+func listenFDs(start int) ([]net.Listener, []string, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	listeners := make([]net.Listener, 0, count)
+	listenerNames := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		fd := start + i
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(f)
+		// FileListener duplicates the descriptor (close-on-exec), so the original is no longer needed
+		f.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, nil, fmt.Errorf("router: socket activation fd %d: %w", fd, err)
+		}
+		listeners = append(listeners, l)
+		listenerNames = append(listenerNames, name)
+	}
+	return listeners, listenerNames, nil
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestListenUnix tests serving on a Unix domain socket
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+
+	r := NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		_, err := io.WriteString(w, "unix")
+		return err
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	s := NewServer().Bind("unix://"+path, r)
+	s.UnixSocket.Mode = 0o660
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ctx) }()
+	for deadline := time.Now().Add(time.Second); len(s.Addrs()) == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Socket file was not created: %v", err)
+	}
+	if fi.Mode().Perm() != 0o660 {
+		t.Errorf("Expected mode 0660, got %o", fi.Mode().Perm())
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "unix" {
+		t.Errorf("Expected body %q, got %q", "unix", body)
+	}
+
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("Serve returned an error: %v", err)
+	}
+
+	// A regular file at the socket path is never removed
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenUnix(file, UnixSocketOptions{}); err == nil {
+		t.Error("Expected an error for a regular file")
+	}
+}
+
+// TestListenFDs tests converting socket activation descriptors into listeners
+func TestListenFDs(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	f, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// listenFDs takes ownership of the descriptor, so pass a raw copy that no *os.File
+	// finalizer will close later (it may have been reused by then)
+	fd, err := syscall.Dup(int(f.Fd()))
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not activated for this process
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	if listeners, _, err := listenFDs(fd); err != nil || listeners != nil {
+		t.Errorf("Expected no listeners for another process, got %v (%v)", listeners, err)
+	}
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDNAMES", "public")
+	listeners, names, err := listenFDs(fd)
+	if err != nil {
+		t.Fatalf("Failed to convert descriptors: %v", err)
+	}
+	if len(listeners) != 1 || names[0] != "public" {
+		t.Fatalf("Expected one listener named public, got %d %v", len(listeners), names)
+	}
+	defer listeners[0].Close()
+	if listeners[0].Addr().String() != l.Addr().String() {
+		t.Errorf("Expected address %s, got %s", l.Addr(), listeners[0].Addr())
+	}
+}
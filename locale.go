@@ -0,0 +1,175 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LocaleParam is the name of the URL parameter that holds the locale
+// taken from a localized path prefix (see Locales.Handler).
+const LocaleParam = "locale"
+
+// localeKey is the context key for the negotiated locale.
+type localeKey struct{}
+
+// localeValue is the locale stored in the request context.
+type localeValue struct {
+	locale     string
+	fromPrefix bool // Whether the locale was taken from a path prefix
+}
+
+// Locales negotiates the locale of requests against a configured set of supported locales.
+// The first supported locale is the default.
+type Locales struct {
+	supported []string
+}
+
+// NewLocales creates a Locales with the supported locales (e.g. "en", "ja", "pt-BR").
+// The first locale is used when negotiation fails. It panics if no locale is given.
+func NewLocales(supported ...string) *Locales {
+	if len(supported) == 0 {
+		panic("router: NewLocales requires at least one locale")
+	}
+	return &Locales{supported: slices.Clone(supported)}
+}
+
+// Locale returns the locale negotiated for the request, or an empty string
+// if neither Locales.Middleware nor Locales.Handler processed the request.
+func Locale(ctx context.Context) string {
+	if v, ok := ctx.Value(localeKey{}).(localeValue); ok {
+		return v.locale
+	}
+	return ""
+}
+
+// Middleware returns middleware that negotiates the Accept-Language header and stores
+// the result in the request context, where Locale reads it.
+// If the locale was already taken from a path prefix by Handler, it is kept and
+// additionally exposed as the LocaleParam URL parameter.
+func (l *Locales) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			ctx := r.Context()
+			if v, ok := ctx.Value(localeKey{}).(localeValue); ok {
+				if v.fromPrefix {
+					r = r.WithContext(withLocaleParam(ctx, v.locale))
+				}
+				return next(w, r)
+			}
+
+			locale := l.Negotiate(r.Header.Get("Accept-Language"))
+			w.Header().Add("Vary", "Accept-Language")
+			return next(w, r.WithContext(context.WithValue(ctx, localeKey{}, localeValue{locale: locale})))
+		}
+	}
+}
+
+// Handler routes localized path prefixes (/en/..., /ja/...) to the same underlying patterns.
+// If the first path segment is a supported locale, it is removed from the path before the request
+// reaches next (usually the Router) and stored as the request's locale.
+// Requests without a locale prefix are passed through unchanged.
+func (l *Locales) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if len(path) > 1 && path[0] == '/' {
+			segment, rest, _ := strings.Cut(path[1:], "/")
+			if locale, ok := l.matchExact(segment); ok {
+				r2 := r.WithContext(context.WithValue(r.Context(), localeKey{}, localeValue{locale: locale, fromPrefix: true}))
+				u := *r.URL
+				u.Path = "/" + rest
+				u.RawPath = ""
+				r2.URL = &u
+				next.ServeHTTP(w, r2)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Negotiate selects the supported locale that best matches an Accept-Language header value.
+// Language ranges are tried in order of their quality values; a range matches a supported
+// locale exactly (case-insensitively) or by its primary language ("en-US" matches "en").
+// The default locale is returned if nothing matches.
+func (l *Locales) Negotiate(acceptLanguage string) string {
+	type languageRange struct {
+		tag     string
+		quality float64
+	}
+
+	var ranges []languageRange
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = v
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, languageRange{tag: tag, quality: quality})
+		}
+	}
+	slices.SortStableFunc(ranges, func(a, b languageRange) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for _, lr := range ranges {
+		if lr.tag == "*" {
+			break
+		}
+		if locale, ok := l.match(lr.tag); ok {
+			return locale
+		}
+	}
+	return l.supported[0]
+}
+
+// matchExact finds the supported locale equal to a language tag (case-insensitively).
+func (l *Locales) matchExact(tag string) (string, bool) {
+	for _, locale := range l.supported {
+		if strings.EqualFold(locale, tag) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// match finds the supported locale for a language tag,
+// first by an exact match and then by the primary language subtag.
+func (l *Locales) match(tag string) (string, bool) {
+	if locale, ok := l.matchExact(tag); ok {
+		return locale, true
+	}
+	primary, _, _ := strings.Cut(tag, "-")
+	for _, locale := range l.supported {
+		if strings.EqualFold(locale, primary) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// withLocaleParam adds the LocaleParam URL parameter to the parameters of the context.
+func withLocaleParam(ctx context.Context, locale string) context.Context {
+	if ps, ok := ctx.Value(paramsKey{}).(*Params); ok && ps != nil {
+		ps.Add(LocaleParam, locale)
+		return ctx
+	}
+	ps := NewParams()
+	ps.Add(LocaleParam, locale)
+	return contextWithParams(ctx, ps)
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestLocaleNegotiate tests Accept-Language negotiation
+func TestLocaleNegotiate(t *testing.T) {
+	l := NewLocales("en", "ja", "pt-BR")
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"ja", "ja"},
+		{"ja-JP,ja;q=0.9,en;q=0.8", "ja"},
+		{"fr;q=1.0, pt-br;q=0.5", "pt-BR"},
+		{"en;q=0.2, ja;q=0.8", "ja"},
+		{"de, *;q=0.5", "en"},
+		{"ja;q=0", "en"},
+	}
+	for _, tt := range tests {
+		if got := l.Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+// TestLocaleRouting tests the locale middleware and localized path prefixes
+func TestLocaleRouting(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	locales := NewLocales("en", "ja")
+	r.Use(locales.Middleware())
+
+	r.Get("/articles/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		param, _ := GetParams(req.Context()).Get(LocaleParam)
+		w.Header().Set("X-Locale", Locale(req.Context()))
+		w.Header().Set("X-Locale-Param", param)
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	h := locales.Handler(r)
+
+	tests := []struct {
+		path, acceptLanguage string
+		status               int
+		locale, param        string
+	}{
+		{"/ja/articles/1", "en", http.StatusOK, "ja", "ja"},
+		{"/en/articles/1", "ja", http.StatusOK, "en", "en"},
+		{"/articles/1", "ja", http.StatusOK, "ja", ""},
+		{"/fr/articles/1", "ja", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, w.Code)
+			continue
+		}
+		if w.Header().Get("X-Locale") != tt.locale || w.Header().Get("X-Locale-Param") != tt.param {
+			t.Errorf("%s: expected locale %q (param %q), got %q (param %q)", tt.path, tt.locale, tt.param,
+				w.Header().Get("X-Locale"), w.Header().Get("X-Locale-Param"))
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+)
+
+// SilentLogger returns a logger that discards every message, for RouterOptions.Logger of
+// libraries that embed the router and must not write to the application's log.
+func SilentLogger() *slog.Logger {
+	return slog.New(discardHandler{})
+}
+
+// discardHandler is a slog.Handler that discards every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// logf logs a message of the router at the given level with RouterOptions.Logger.
+// Without a logger, the message is written with the standard log package, warnings prefixed
+// with "Warning: ". It accepts a nil router, for groups that are not attached to one.
+func (r *Router) logf(level slog.Level, format string, args ...any) {
+	if r != nil && r.logger != nil {
+		r.logger.Log(context.Background(), level, fmt.Sprintf(format, args...))
+		return
+	}
+	if level == slog.LevelWarn {
+		format = "Warning: " + format
+	}
+	log.Printf(format, args...)
+}
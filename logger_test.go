@@ -0,0 +1,75 @@
+package router
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestLogger tests that the router's messages go to RouterOptions.Logger
+func TestLogger(t *testing.T) {
+	var std bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&std)
+
+	var out bytes.Buffer
+	opts := defaultRouterOptions()
+	opts.Logger = slog.New(slog.NewTextHandler(&out, nil))
+	opts.TrustedProxies = []string{"not-an-address"}
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/panic", func(w http.ResponseWriter, req *http.Request) error {
+		panic("boom")
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	// Overridden routes are logged by Build
+	dup := NewRouterWithOptions(RouterOptions{Logger: opts.Logger, OverrideStrategy: OverrideLastWins})
+	defer dup.cache.stop()
+	g := dup.Group("/api")
+	g.Get("/items", handler)
+	g.Get("/items", handler)
+	if err := dup.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	logged := out.String()
+	for _, want := range []string{
+		`level=WARN msg="ignoring invalid trusted proxy`,
+		`level=WARN msg="overriding route: GET /api/items`,
+		`level=ERROR msg="Handler panic: GET /panic: boom`,
+	} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("Expected %q in the log, got %q", want, logged)
+		}
+	}
+	if std.Len() != 0 {
+		t.Errorf("Expected nothing written with the log package, got %q", std.String())
+	}
+
+	// The silent logger discards everything
+	opts.Logger = SilentLogger()
+	s := NewRouterWithOptions(opts)
+	defer s.cache.stop()
+	s.Group("/api").Get("/items", handler)
+	if std.Len() != 0 {
+		t.Errorf("Expected the silent logger to discard messages, got %q", std.String())
+	}
+
+	// Without a logger, warnings are written with the log package
+	opts.Logger = nil
+	d := NewRouterWithOptions(opts)
+	defer d.cache.stop()
+	if !strings.Contains(std.String(), "Warning: ignoring invalid trusted proxy") {
+		t.Errorf("Expected the warning in the standard log, got %q", std.String())
+	}
+}
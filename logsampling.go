@@ -0,0 +1,35 @@
+package router
+
+import (
+	"math/rand/v2"
+	"net/http"
+)
+
+// logSamplingMetaKey is the metadata key under which the access log sampling rate of a route is stored.
+const logSamplingMetaKey = "router.log_sampling"
+
+// WithLogSampling sets the fraction of the requests of the route that are written to the access
+// log, so that noisy high-QPS routes such as health checks and polling endpoints can be sampled
+// (0 < rate < 1) or excluded (rate 0) declaratively. The rate is clamped to [0, 1].
+// Logging middleware honors the rate by calling ShouldLog for each request.
+//
+// 例: r.Get("/healthz", health).WithLogSampling(0)
+func (r *Route) WithLogSampling(rate float64) *Route {
+	return r.WithMeta(logSamplingMetaKey, min(max(rate, 0), 1))
+}
+
+// ShouldLog reports whether the request should be written to the access log, sampling the
+// requests of routes with a rate set by WithLogSampling. Requests of routes excluded with
+// WithObservability are never logged. Requests of other routes, and requests that did not match
+// a route, are always logged.
+func ShouldLog(r *http.Request) bool {
+	if !Observed(r) {
+		return false
+	}
+	value, ok := RouteMeta(r.Context(), logSamplingMetaKey)
+	if !ok {
+		return true
+	}
+	rate := value.(float64)
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestLogSampling tests sampling the access log of routes
+func TestLogSampling(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	logged := map[string]int{}
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			if ShouldLog(req) {
+				logged[req.URL.Path]++
+			}
+			return next(w, req)
+		}
+	})
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/api", handler)
+	r.Get("/healthz", handler).WithLogSampling(0)
+	r.Get("/poll", handler).WithLogSampling(0.1)
+	r.Get("/all", handler).WithLogSampling(1.5)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	for range 1000 {
+		for _, path := range []string{"/api", "/healthz", "/poll", "/all"} {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+	}
+	if logged["/api"] != 1000 || logged["/all"] != 1000 {
+		t.Errorf("Expected every request of unsampled routes to be logged, got %v", logged)
+	}
+	if logged["/healthz"] != 0 {
+		t.Errorf("Expected excluded requests not to be logged, got %d", logged["/healthz"])
+	}
+	if n := logged["/poll"]; n < 30 || n > 250 {
+		t.Errorf("Expected about 100 sampled requests, got %d", n)
+	}
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Lookup reports the route that would handle the specified method and path, with the parameters
+// extracted from the path, without executing any handler or touching the route cache.
+// It is intended for authorization prechecks, load-test tooling, and tests that only care about
+// what would match. Routes registered with Handle are described by their method and pattern only.
+// Parameter transforms (see Route.WithParamTransform) are applied like for a request.
+//
+// The route is resolved like ServeHTTP resolves it: HEAD falls back to the GET route, routes
+// outside their active window (see Route.WithActiveWindow) do not match, and a path without a
+// route matches the mount point serving it (see Mount), which is described by the method and the
+// mount pattern, with the remainder of the path as the "*" parameter. Lookup has no request to
+// identify a tenant with, so tenant overlays (see ForTenant) are only consulted by LookupRequest.
+//
+// 例: info, ps, ok := r.Lookup(http.MethodDelete, "/users/42")
+func (r *Router) Lookup(method, path string) (RouteInfo, Params, bool) {
+	return r.lookup(nil, method, path)
+}
+
+// LookupRequest works like Lookup for the method and path of the request, and also matches the
+// routes of the tenant overlay of the request first, like ServeHTTP (see Tenant and ForTenant).
+//
+// 例: info, _, ok := r.LookupRequest(req)
+func (r *Router) LookupRequest(req *http.Request) (RouteInfo, Params, bool) {
+	_, overlay := r.tenantOf(req)
+	return r.lookup(overlay, req.Method, req.URL.Path)
+}
+
+// lookup is the implementation of Lookup, matching the tenant overlay (nil if none) first.
+func (r *Router) lookup(overlay *Router, method, path string) (RouteInfo, Params, bool) {
+	params := r.paramsPool.Get()
+	defer r.paramsPool.Put(params)
+
+	var pattern string
+	var route *Route
+	ok := false
+	if overlay != nil {
+		pattern, route, ok = overlay.matchServedRoute(method, path, params)
+	}
+	if !ok {
+		params.reset()
+		pattern, route, ok = r.matchServedRoute(method, path, params)
+	}
+	if !ok {
+		m, mountParams, found := r.matchMount(path)
+		if !found {
+			return RouteInfo{}, Params{}, false
+		}
+		var ps Params
+		for _, name := range slices.Sorted(maps.Keys(mountParams)) {
+			ps.Add(name, mountParams[name])
+		}
+		return RouteInfo{Method: method, Pattern: m.pattern}, ps, true
+	}
+
+	// Routes outside their active window answer 404 before it and 410 after it
+	if !route.activeAt(time.Now()) {
+		return RouteInfo{}, Params{}, false
+	}
+
+	// The parameters are copied, since the pooled object is reused by other requests
+	found := Params{data: slices.Clone(params.data)}
+	route.transformParams(&found)
+
+	if route == nil {
+		return RouteInfo{Method: method, Pattern: pattern}, found, true
+	}
+	return route.info(), found, true
+}
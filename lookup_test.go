@@ -0,0 +1,112 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLookup tests matching a route without executing it
+func TestLookup(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	called := false
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	}
+	r.Get("/health", handler).WithName("health")
+	api := r.Group("/api").WithTags("public")
+	api.Delete("/users/{id}", handler).WithName("user.delete").WithParamTransform("id", strings.ToUpper)
+	if err := r.Handle(http.MethodPost, "/hooks/{source}", handler); err != nil {
+		t.Fatalf("Failed to register route: %v", err)
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	info, ps, ok := r.Lookup(http.MethodDelete, "/api/users/ab")
+	if !ok || info.Name != "user.delete" || info.Pattern != "/api/users/{id}" {
+		t.Fatalf("Expected the user route, got %+v (%v)", info, ok)
+	}
+	if id, _ := ps.Get("id"); id != "AB" {
+		t.Errorf("Expected the transformed parameter, got %q", id)
+	}
+	if len(info.Tags) != 1 || info.Tags[0] != "public" {
+		t.Errorf("Expected the group tags, got %v", info.Tags)
+	}
+
+	info, ps, ok = r.Lookup(http.MethodGet, "/health")
+	if !ok || info.Name != "health" || ps.Len() != 0 {
+		t.Errorf("Expected the static route, got %+v (%v)", info, ok)
+	}
+
+	info, ps, ok = r.Lookup(http.MethodPost, "/hooks/github")
+	if source, _ := ps.Get("source"); !ok || info.Pattern != "/hooks/{source}" || info.Route != nil || source != "github" {
+		t.Errorf("Expected the handled route, got %+v %q (%v)", info, source, ok)
+	}
+
+	if _, _, ok := r.Lookup(http.MethodGet, "/api/users/ab"); ok {
+		t.Error("Expected no route for another method")
+	}
+	if _, _, ok := r.Lookup("BREW", "/health"); ok {
+		t.Error("Expected no route for an unknown method")
+	}
+	if called {
+		t.Error("Expected no handler to run")
+	}
+}
+
+// TestLookupResolution tests that Lookup resolves routes like ServeHTTP
+func TestLookupResolution(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/users/{id}", handler).WithName("user")
+	r.Get("/promo/past", handler).WithActiveWindow(time.Time{}, time.Now().Add(-time.Hour))
+	r.Get("/promo/future", handler).WithActiveWindow(time.Now().Add(time.Hour), time.Time{})
+	r.Get("/promo/now", handler).WithActiveWindow(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err := r.Mount("/billing/{tenant}", http.NotFoundHandler(), MountOptions{}); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	r.Tenant(func(req *http.Request) string { return req.Header.Get("X-Tenant") })
+	r.ForTenant("acme").Get("/users/{id}", handler).WithName("acme.user")
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// HEAD falls back to the GET route
+	if info, _, ok := r.Lookup(http.MethodHead, "/users/1"); !ok || info.Name != "user" {
+		t.Errorf("Expected HEAD to match the GET route, got %+v (%v)", info, ok)
+	}
+
+	// Routes outside their active window do not match
+	for path, want := range map[string]bool{"/promo/past": false, "/promo/future": false, "/promo/now": true} {
+		if _, _, ok := r.Lookup(http.MethodGet, path); ok != want {
+			t.Errorf("%s: expected match %v, got %v", path, want, ok)
+		}
+	}
+
+	// Mount points match paths without a route
+	info, ps, ok := r.Lookup(http.MethodPost, "/billing/acme/invoices/7")
+	tenant, _ := ps.Get("tenant")
+	rest, _ := ps.Get("*")
+	if !ok || info.Pattern != "/billing/{tenant}" || tenant != "acme" || rest != "invoices/7" {
+		t.Errorf("Expected the mount point, got %+v %v (%v)", info, ps.data, ok)
+	}
+
+	// LookupRequest matches the tenant overlay first
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("X-Tenant", "acme")
+	if info, _, ok := r.LookupRequest(req); !ok || info.Name != "acme.user" {
+		t.Errorf("Expected the overlay route, got %+v (%v)", info, ok)
+	}
+	req.Header.Set("X-Tenant", "other")
+	if info, _, ok := r.LookupRequest(req); !ok || info.Name != "user" {
+		t.Errorf("Expected the router route, got %+v (%v)", info, ok)
+	}
+}
@@ -0,0 +1,87 @@
+package router
+
+import (
+	"sync"
+)
+
+// SegmentMatcher matches path segments for parameters with a named constraint, such as
+// {id:snowflake}, so that domain-specific formats can be routed without regular expressions.
+// Match reports whether the segment is accepted and returns the parameter value, which may be
+// the segment itself or a normalized form of it (for example a lower-cased UUID).
+type SegmentMatcher interface {
+	Match(segment string) (value string, ok bool)
+}
+
+// SegmentMatcherFunc is an adapter to use an ordinary function as a SegmentMatcher.
+type SegmentMatcherFunc func(segment string) (string, bool)
+
+// Match calls f(segment).
+func (f SegmentMatcherFunc) Match(segment string) (string, bool) {
+	return f(segment)
+}
+
+// segmentMatchers holds the matchers registered with RegisterSegmentMatcher.
+var segmentMatchers = struct {
+	sync.RWMutex
+	byName map[string]SegmentMatcher
+}{byName: make(map[string]SegmentMatcher)}
+
+// RegisterSegmentMatcher registers a matcher for parameters whose constraint is the given name,
+// such as {id:snowflake} for the name "snowflake". A registered name takes precedence over the
+// regular expression of the same text. Names consist of ASCII letters, digits, and underscores
+// and start with a letter.
+//
+// Matchers are shared by all routers, like the drivers of database/sql, and are typically
+// registered in an init function. A pattern is resolved when its route is registered, so the
+// matcher must be registered before that; registering a name again replaces the matcher for
+// routes registered afterwards. It returns an error for an invalid name or a nil matcher.
+// Parameters with a matcher are tried after those with a regular expression at the same position.
+//
+// 例: router.RegisterSegmentMatcher("snowflake", router.SegmentMatcherFunc(parseSnowflake))
+func RegisterSegmentMatcher(name string, m SegmentMatcher) error {
+	if m == nil {
+		return &RouterError{Code: ErrNilHandler, Message: "nil segment matcher: " + name}
+	}
+	if !isMatcherName(name) {
+		return &RouterError{Code: ErrInvalidPattern, Message: "invalid segment matcher name: " + name}
+	}
+	segmentMatchers.Lock()
+	defer segmentMatchers.Unlock()
+	segmentMatchers.byName[name] = m
+	return nil
+}
+
+// lookupSegmentMatcher returns the matcher registered for the name, or nil if there is none.
+func lookupSegmentMatcher(name string) SegmentMatcher {
+	segmentMatchers.RLock()
+	defer segmentMatchers.RUnlock()
+	return segmentMatchers.byName[name]
+}
+
+// isMatcherName reports whether the name is valid for RegisterSegmentMatcher.
+func isMatcherName(name string) bool {
+	for i, c := range []byte(name) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '_'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// matchValue reports whether a constrained parameter node accepts the segment, and returns the
+// parameter value: the segment for a regular expression, or the value of the segment matcher.
+func (n *node) matchValue(segment string) (string, bool) {
+	if n.custom != nil {
+		return n.custom.Match(segment)
+	}
+	return segment, n.matchRegex(segment)
+}
+
+// accepts reports whether a constrained parameter node accepts the segment.
+func (n *node) accepts(segment string) bool {
+	_, ok := n.matchValue(segment)
+	return ok
+}
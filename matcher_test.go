@@ -0,0 +1,96 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func init() {
+	// Snowflake IDs are decimal numbers of up to 19 digits
+	RegisterSegmentMatcher("snowflake", SegmentMatcherFunc(func(segment string) (string, bool) {
+		if segment == "" || len(segment) > 19 || strings.Trim(segment, "0123456789") != "" {
+			return "", false
+		}
+		return segment, true
+	}))
+	// UUIDs are matched case-insensitively and normalized to lower case
+	RegisterSegmentMatcher("uuid", SegmentMatcherFunc(func(segment string) (string, bool) {
+		if len(segment) != 36 || strings.Count(segment, "-") != 4 {
+			return "", false
+		}
+		return strings.ToLower(segment), true
+	}))
+}
+
+// TestSegmentMatchers tests routing with registered segment matchers
+func TestSegmentMatchers(t *testing.T) {
+	for _, byPattern := range []bool{false, true} {
+		opts := defaultRouterOptions()
+		opts.CacheByPattern = byPattern
+		r := NewRouterWithOptions(opts)
+		defer r.cache.stop()
+
+		echo := func(name string) HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) error {
+				id, _ := GetParams(req.Context()).Get("id")
+				w.Write([]byte(name + " " + id))
+				return nil
+			}
+		}
+		r.Get("/tweets/{id:snowflake}", echo("tweet"))
+		r.Get("/tweets/{id:[a-z]+}", echo("handle"))
+		r.Get("/items/{id:uuid}/edit", echo("item"))
+		if err := r.Build(); err != nil {
+			t.Fatalf("Failed to build router: %v", err)
+		}
+
+		tests := []struct {
+			path   string
+			status int
+			body   string
+		}{
+			{"/tweets/1541815603606036480", http.StatusOK, "tweet 1541815603606036480"},
+			{"/tweets/jack", http.StatusOK, "handle jack"},
+			{"/tweets/12345678901234567890", http.StatusNotFound, ""},
+			{"/items/0E8F1D2C-AAAA-4BBB-8CCC-123456789ABC/edit", http.StatusOK, "item 0e8f1d2c-aaaa-4bbb-8ccc-123456789abc"},
+			{"/items/0E8F1D2C-AAAA-4BBB-8CCC-123456789ABD/edit", http.StatusOK, "item 0e8f1d2c-aaaa-4bbb-8ccc-123456789abd"},
+			{"/items/42/edit", http.StatusNotFound, ""},
+		}
+		for _, tt := range tests {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status || (tt.body != "" && w.Body.String() != tt.body) {
+				t.Errorf("byPattern=%v %s: expected %d %q, got %d %q", byPattern, tt.path, tt.status, tt.body, w.Code, w.Body.String())
+			}
+		}
+
+		// The reference matcher agrees with the tree
+		reference := []RouteSpec{
+			{Method: http.MethodGet, Pattern: "/tweets/{id:snowflake}"},
+			{Method: http.MethodGet, Pattern: "/tweets/{id:[a-z]+}"},
+			{Method: http.MethodGet, Pattern: "/items/{id:uuid}/edit"},
+		}
+		samples := make([]string, 0, len(tests))
+		for _, tt := range tests {
+			samples = append(samples, tt.path)
+		}
+		if err := r.VerifyAgainst(reference, samples); err != nil {
+			t.Errorf("byPattern=%v: %v", byPattern, err)
+		}
+	}
+}
+
+// TestRegisterSegmentMatcher tests the validation of segment matcher registrations
+func TestRegisterSegmentMatcher(t *testing.T) {
+	m := SegmentMatcherFunc(func(segment string) (string, bool) { return segment, true })
+	for _, name := range []string{"", "1st", "has-dash", "a:b", "[0-9]+"} {
+		if err := RegisterSegmentMatcher(name, m); err == nil {
+			t.Errorf("Expected an error for the name %q", name)
+		}
+	}
+	if err := RegisterSegmentMatcher("valid_name2", nil); err == nil {
+		t.Error("Expected an error for a nil matcher")
+	}
+}
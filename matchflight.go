@@ -0,0 +1,54 @@
+package router
+
+import "sync"
+
+// matchFlight deduplicates concurrent dynamic matches of the same cache key. When a burst of
+// requests arrives for a hot path that is not cached yet, only the first request traverses the
+// dynamic tree and fills the cache; the others wait for its result instead of repeating the
+// traversal and the cache write. Calls are sharded like the route cache, so that misses of
+// different paths do not contend on one lock. The zero value is ready to use.
+type matchFlight struct {
+	shards [shardCount]matchFlightShard
+}
+
+// matchFlightShard holds the matches in progress of the keys of a shard.
+type matchFlightShard struct {
+	mu    sync.Mutex
+	calls map[uint64]*matchCall
+}
+
+// matchCall is a match in progress; its result is valid once done is closed.
+type matchCall struct {
+	done  chan struct{}
+	match routeMatch
+	found bool
+}
+
+// do returns the result of fn for the key, calling it only if no call for the key is in
+// progress, and waiting for that call otherwise. The waiting callers share the result,
+// including its parameters map, which must not be modified.
+func (f *matchFlight) do(key uint64, fn func() (routeMatch, bool)) (routeMatch, bool) {
+	sh := &f.shards[key&shardMask]
+	sh.mu.Lock()
+	if call, ok := sh.calls[key]; ok {
+		sh.mu.Unlock()
+		<-call.done
+		return call.match, call.found
+	}
+	call := &matchCall{done: make(chan struct{})}
+	if sh.calls == nil {
+		sh.calls = make(map[uint64]*matchCall)
+	}
+	sh.calls[key] = call
+	sh.mu.Unlock()
+
+	// The waiting callers are released even if fn panics (they see no match)
+	defer func() {
+		sh.mu.Lock()
+		delete(sh.calls, key)
+		sh.mu.Unlock()
+		close(call.done)
+	}()
+	call.match, call.found = fn()
+	return call.match, call.found
+}
@@ -0,0 +1,87 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestMatchFlight tests that a burst of requests for an uncached dynamic path matches it once
+func TestMatchFlight(t *testing.T) {
+	var evals atomic.Int32
+	release := make(chan struct{})
+	RegisterSegmentMatcher("flight_probe", SegmentMatcherFunc(func(segment string) (string, bool) {
+		evals.Add(1)
+		<-release
+		return segment, true
+	}))
+
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Get("/items/{id:flight_probe}", func(w http.ResponseWriter, req *http.Request) error {
+		id, _ := GetParams(req.Context()).Get("id")
+		w.Write([]byte(id))
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	const n = 16
+	var wg sync.WaitGroup
+	bodies := make([]string, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/42", nil))
+			bodies[i] = w.Body.String()
+		}()
+	}
+	for evals.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// Give the other requests time to miss the cache and wait for the first one
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := evals.Load(); got != 1 {
+		t.Errorf("Expected the path to be matched once, got %d", got)
+	}
+	for i, body := range bodies {
+		if body != "42" {
+			t.Errorf("Request %d: expected body %q, got %q", i, "42", body)
+		}
+	}
+}
+
+// TestMatchFlightPanic tests that the callers waiting for a match are released if it panics
+func TestMatchFlightPanic(t *testing.T) {
+	var f matchFlight
+	started := make(chan struct{})
+	done := make(chan bool)
+	go func() {
+		defer func() { recover() }()
+		f.do(1, func() (routeMatch, bool) {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			panic("boom")
+		})
+	}()
+	<-started
+	go func() {
+		_, found := f.do(1, func() (routeMatch, bool) { return routeMatch{}, true })
+		done <- found
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the waiting caller to be released")
+	}
+}
@@ -0,0 +1,111 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Consumes restricts the media types accepted in request bodies of the route.
+// Requests with a body whose Content-Type is not one of the types are rejected with
+// 415 Unsupported Media Type before the handler runs, so handlers do not have to check it.
+// The response lists the supported types in Accept-Post (POST) or Accept-Patch (PATCH).
+// Requests without a body are not checked. A type may end with "/*" to accept all subtypes.
+//
+// 例: r.Post("/users", create).Consumes("application/json", "application/x-www-form-urlencoded")
+func (r *Route) Consumes(mediaTypes ...string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	for _, mediaType := range mediaTypes {
+		r.consumes = append(r.consumes, strings.ToLower(strings.TrimSpace(mediaType)))
+	}
+	return r
+}
+
+// GetConsumes returns the media types accepted in request bodies of the route (nil accepts any).
+func (r *Route) GetConsumes() []string {
+	return slices.Clone(r.consumes)
+}
+
+// requireContentType rejects requests with a body whose media type is not one of the types.
+func requireContentType(next HandlerFunc, mediaTypes []string) HandlerFunc {
+	accept := strings.Join(mediaTypes, ", ")
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if hasBody(r) && !acceptsMediaType(mediaTypes, r.Header.Get("Content-Type")) {
+			switch r.Method {
+			case http.MethodPost:
+				w.Header().Set("Accept-Post", accept)
+			case http.MethodPatch:
+				w.Header().Set("Accept-Patch", accept)
+			}
+			http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+			return nil
+		}
+		return next(w, r)
+	}
+}
+
+// hasBody reports whether the request has a body (a positive or unknown length).
+func hasBody(r *http.Request) bool {
+	return r.ContentLength != 0 && r.Body != nil && r.Body != http.NoBody
+}
+
+// acceptsMediaType reports whether the Content-Type header value matches one of the media types.
+func acceptsMediaType(mediaTypes []string, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, accepted := range mediaTypes {
+		if prefix, ok := strings.CutSuffix(accepted, "/*"); ok {
+			if t, _, _ := strings.Cut(mediaType, "/"); t == prefix {
+				return true
+			}
+		} else if mediaType == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// Produces declares the media types of the responses of the route, the first being the default.
+// The Content-Type header is set to the default before the handler runs (the handler can still
+// override it), and routes that produce several types add "Vary: Accept", so that caching
+// middleware and proxies keep the representations apart. The types are also reported by
+// Router.Report and Group.Routes, e.g. for generating API documentation.
+//
+// 例: r.Get("/users/{id}", show).Produces("application/json")
+func (r *Route) Produces(mediaTypes ...string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	for _, mediaType := range mediaTypes {
+		r.produces = append(r.produces, strings.TrimSpace(mediaType))
+	}
+	return r
+}
+
+// GetProduces returns the declared response media types of the route (nil if not declared).
+func (r *Route) GetProduces() []string {
+	return slices.Clone(r.produces)
+}
+
+// defaultContentType sets the default Content-Type of the response before the handler runs.
+func defaultContentType(next HandlerFunc, mediaTypes []string) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		header := w.Header()
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", mediaTypes[0])
+		}
+		if len(mediaTypes) > 1 {
+			addVary(header, "Accept")
+		}
+		return next(w, r)
+	}
+}
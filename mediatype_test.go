@@ -0,0 +1,107 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestConsumes tests rejecting request bodies with unsupported media types
+func TestConsumes(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	r.Post("/users", handler).Consumes("application/json", "application/x-www-form-urlencoded")
+	r.Patch("/users/{id}", handler).Consumes("application/merge-patch+json")
+	r.Put("/files/{name}", handler).Consumes("image/*")
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+		header      string
+		accept      string
+	}{
+		{http.MethodPost, "/users", "application/json", "{}", http.StatusNoContent, "", ""},
+		{http.MethodPost, "/users", "Application/JSON; charset=utf-8", "{}", http.StatusNoContent, "", ""},
+		{http.MethodPost, "/users", "application/x-www-form-urlencoded", "a=1", http.StatusNoContent, "", ""},
+		{http.MethodPost, "/users", "text/plain", "hi", http.StatusUnsupportedMediaType, "Accept-Post", "application/json, application/x-www-form-urlencoded"},
+		{http.MethodPost, "/users", "", "hi", http.StatusUnsupportedMediaType, "Accept-Post", "application/json, application/x-www-form-urlencoded"},
+		{http.MethodPost, "/users", "", "", http.StatusNoContent, "", ""},
+		{http.MethodPatch, "/users/1", "application/json", "{}", http.StatusUnsupportedMediaType, "Accept-Patch", "application/merge-patch+json"},
+		{http.MethodPut, "/files/a", "image/png", "x", http.StatusNoContent, "", ""},
+		{http.MethodPut, "/files/a", "text/png", "x", http.StatusUnsupportedMediaType, "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s %s (%q): expected status %d, got %d", tt.method, tt.path, tt.contentType, tt.status, w.Code)
+		}
+		if tt.header != "" && w.Header().Get(tt.header) != tt.accept {
+			t.Errorf("%s %s: expected %s %q, got %q", tt.method, tt.path, tt.header, tt.accept, w.Header().Get(tt.header))
+		}
+	}
+}
+
+// TestProduces tests the default Content-Type of routes that declare their response types
+func TestProduces(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte(`{}`))
+		return nil
+	}).Produces("application/json")
+	r.Get("/reports/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		if req.URL.Query().Get("format") == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+		}
+		return nil
+	}).Produces("application/json", "text/csv")
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		url         string
+		contentType string
+		vary        string
+	}{
+		{"/users/1", "application/json", ""},
+		{"/reports/1", "application/json", "Accept"},
+		{"/reports/1?format=csv", "text/csv", "Accept"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: expected Content-Type %q, got %q", tt.url, tt.contentType, got)
+		}
+		if got := w.Header().Get("Vary"); got != tt.vary {
+			t.Errorf("%s: expected Vary %q, got %q", tt.url, tt.vary, got)
+		}
+	}
+
+	// The types are reported for documentation
+	for _, route := range r.Report().Routes {
+		if route.Pattern == "/reports/{id}" && len(route.Produces) != 2 {
+			t.Errorf("Expected the report to list the produced types, got %v", route.Produces)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package router
+
+import "context"
+
+type routeKey struct{}
+
+// contextWithRoute adds the matched route definition to the request context.
+func contextWithRoute(ctx context.Context, route *Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// routeFromContext retrieves the matched route definition from the context.
+// Returns nil for routes registered with Handle or outside of a request.
+func routeFromContext(ctx context.Context) *Route {
+	if ctx == nil {
+		return nil
+	}
+	route, _ := ctx.Value(routeKey{}).(*Route)
+	return route
+}
+
+// WithMeta attaches metadata to the group.
+// The metadata is inherited by all routes in the group and its child groups;
+// values set on a child group or on a route take precedence.
+func (g *Group) WithMeta(key string, value any) *Group {
+	if g.meta == nil {
+		g.meta = make(map[string]any)
+	}
+	g.meta[key] = value
+	return g
+}
+
+// Meta returns the group's metadata value for the key, including values inherited from parent groups.
+func (g *Group) Meta(key string) (any, bool) {
+	for current := g; current != nil; current = current.parent {
+		if value, ok := current.meta[key]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// WithMeta attaches metadata to the route.
+// Route metadata overrides metadata with the same key inherited from the group.
+func (r *Route) WithMeta(key string, value any) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	if r.meta == nil {
+		r.meta = make(map[string]any)
+	}
+	r.meta[key] = value
+	return r
+}
+
+// Meta returns the route's metadata value for the key.
+// If the route does not define the key, the value inherited from its group is returned.
+func (r *Route) Meta(key string) (any, bool) {
+	if value, ok := r.meta[key]; ok {
+		return value, true
+	}
+	if r.group != nil {
+		return r.group.Meta(key)
+	}
+	return nil, false
+}
+
+// RouteMeta returns the metadata value for the key of the route that matched the request.
+// It is intended for middleware that applies policies based on route or group metadata.
+// Returns false if no route definition is associated with the request.
+func RouteMeta(ctx context.Context, key string) (any, bool) {
+	route := routeFromContext(ctx)
+	if route == nil {
+		return nil, false
+	}
+	return route.Meta(key)
+}
+
+// GroupPrefix returns the full path prefix of the group of the route that matched the request,
+// without a trailing slash ("" for routes registered directly with the router). Handlers shared
+// across groups, such as those of a module mounted under several prefixes, use it to construct
+// links relative to the prefix under which they were invoked.
+//
+// 例: http.Redirect(w, req, router.GroupPrefix(req.Context())+"/login", http.StatusFound)
+func GroupPrefix(ctx context.Context) string {
+	route := routeFromContext(ctx)
+	if route == nil || route.group == nil || route.group.prefix == "/" {
+		return ""
+	}
+	return route.group.prefix
+}
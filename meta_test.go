@@ -0,0 +1,136 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGroupMeta tests that group metadata is inherited by routes and child groups
+func TestGroupMeta(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	api := r.Group("/api").WithMeta("audience", "internal").WithMeta("tenant", "acme")
+	v1 := api.Group("/v1").WithMeta("tenant", "globex")
+
+	inherited := api.Get("/users", handler)
+	overridden := api.Get("/public", handler).WithMeta("audience", "public")
+	nested := v1.Get("/items", handler)
+
+	testCases := []struct {
+		name     string
+		route    *Route
+		key      string
+		expected any
+	}{
+		{"Inherited from group", inherited, "audience", "internal"},
+		{"Overridden by route", overridden, "audience", "public"},
+		{"Inherited from parent group", nested, "audience", "internal"},
+		{"Overridden by child group", nested, "tenant", "globex"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, ok := tc.route.Meta(tc.key)
+			if !ok || value != tc.expected {
+				t.Errorf("Expected %v, got %v (found=%v)", tc.expected, value, ok)
+			}
+		})
+	}
+
+	if _, ok := inherited.Meta("missing"); ok {
+		t.Error("Found a non-existent metadata key")
+	}
+}
+
+// TestRouteMetaAtRequestTime tests that middleware can read route metadata from the request context
+func TestRouteMetaAtRequestTime(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			if audience, ok := RouteMeta(req.Context(), "audience"); ok && audience == "internal" {
+				w.WriteHeader(http.StatusForbidden)
+				return nil
+			}
+			return next(w, req)
+		}
+	})
+
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte("OK"))
+		return err
+	}
+	admin := r.Group("/admin").WithMeta("audience", "internal")
+	admin.Get("/users/{id}", handler)
+	admin.Get("/health", handler).WithMeta("audience", "public")
+	r.Get("/home", handler)
+
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	testCases := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/admin/users/1", http.StatusForbidden},
+		{"/admin/users/1", http.StatusForbidden}, // Served from the cache
+		{"/admin/health", http.StatusOK},
+		{"/home", http.StatusOK},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.expectedStatus {
+			t.Errorf("Expected status %d for path %s, got %d", tc.expectedStatus, tc.path, w.Code)
+		}
+	}
+}
+
+// TestGroupPrefix tests reading the prefix of the group that handled the request
+func TestGroupPrefix(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte(GroupPrefix(req.Context()) + "/login"))
+		return nil
+	}
+
+	// The same module handler is mounted under two prefixes
+	module := NewGroup("/account")
+	module.Get("/profile", handler)
+	r.Attach(module)
+	r.Group("/v2").Group("/account").Get("/profile", handler)
+	r.Group("/").Get("/root", handler)
+	r.Get("/direct", handler)
+	if err := r.Group("/legacy").Handle(http.MethodGet, "/profile", handler); err != nil {
+		t.Fatalf("Failed to handle route: %v", err)
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := map[string]string{
+		"/account/profile":    "/account/login",
+		"/v2/account/profile": "/v2/account/login",
+		"/legacy/profile":     "/legacy/login",
+		"/root":               "/login",
+		"/direct":             "/login",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Body.String() != want {
+			t.Errorf("%s: expected %q, got %q", path, want, w.Body.String())
+		}
+	}
+
+	if GroupPrefix(context.Background()) != "" {
+		t.Error("Expected no prefix outside of a request")
+	}
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SetMethodNotAllowedHandler sets a custom handler for requests whose path has routes, but not
+// for the request method. Such requests are answered with 405 Method Not Allowed instead of
+// 404 Not Found, and the Allow header listing the methods of the path is set before the handler
+// is called, so that the handler can read it from the response headers.
+// Without a handler, the error page for 405 (see SetErrorPage) or a plain text response is sent.
+// A nil handler restores the default response.
+//
+// 例: r.SetMethodNotAllowedHandler(func(w http.ResponseWriter, r *http.Request) { writeProblem(w, 405, w.Header().Get("Allow")) })
+func (r *Router) SetMethodNotAllowedHandler(h http.HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.methodHandler = h
+}
+
+// allowedMethods returns the methods that have a route matching the path, in the router or in
+// the tenant overlay (nil if none), in the order of methodToUint8. Routes outside their active
+// window are not counted, and methods the first-segment index rejects the path for are skipped
+// without searching (see RouterOptions.FirstSegmentIndex). All methods share one regex evaluation
+// budget (see RouterOptions.MaxRegexEvaluations); once it is spent, the search stops.
+func (r *Router) allowedMethods(overlay *Router, path string) []string {
+	path = normalizePath(path)
+	now := time.Now()
+	params := r.paramsPool.Get()
+	defer r.paramsPool.Put(params)
+	params.regexLimit = r.maxRegexEvals
+
+	var allowed []string
+	for methodIndex := uint8(1); methodIndex <= maxMethodIndex; methodIndex++ {
+		method := uint8ToMethod(methodIndex)
+		// HEAD is allowed wherever GET is, since HEAD requests fall back to the GET route
+		if methodIndex == headMethodIndex && slices.Contains(allowed, http.MethodGet) {
+			allowed = append(allowed, method)
+			continue
+		}
+		for _, candidate := range [2]*Router{overlay, r} {
+			if candidate == nil {
+				continue
+			}
+			if candidate.firstSegments != nil && !candidate.firstSegments.allows(methodIndex, path) {
+				continue
+			}
+			if h, route, _ := candidate.static.searchMethodRoute(methodIndex, path); h != nil && route.activeAt(now) {
+				allowed = append(allowed, method)
+				break
+			}
+			if candidate.dynamic[methodIndex-1] == nil {
+				continue
+			}
+			if params.regexBudgetExhausted() {
+				return allowed
+			}
+			params.truncate(0)
+			matched, ok := candidate.dynamic[methodIndex-1].matchNode(path, params)
+			if ok && matched.route.activeAt(now) {
+				allowed = append(allowed, method)
+				break
+			}
+		}
+	}
+	return allowed
+}
+
+// serveMethodNotAllowed responds to a request whose path only has routes for other methods,
+// with the custom handler if set, then the error page, then the default 405 response.
+func (r *Router) serveMethodNotAllowed(w http.ResponseWriter, req *http.Request, allowed []string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	r.mu.RLock()
+	methodHandler := r.methodHandler
+	r.mu.RUnlock()
+
+	if methodHandler != nil {
+		methodHandler(w, req)
+	} else if !r.serveErrorPage(w, req, http.StatusMethodNotAllowed, nil) {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
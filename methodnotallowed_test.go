@@ -0,0 +1,79 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// TestMethodNotAllowed tests answering paths routed for other methods with 405 and the Allow header
+func TestMethodNotAllowed(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/users/{id}", handler)
+	r.Put("/users/{id}", handler)
+	r.Delete("/users/{id:[0-9]+}", handler)
+	r.Post("/orders/{id}", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method, path string
+		status       int
+		allow        string
+	}{
+		{http.MethodPost, "/users/42", http.StatusMethodNotAllowed, "GET, PUT, DELETE, HEAD"},
+		{http.MethodPatch, "/users/abc", http.StatusMethodNotAllowed, "GET, PUT, HEAD"},
+		{http.MethodGet, "/orders/1", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodGet, "/users/42", http.StatusOK, ""},
+		{http.MethodHead, "/users/42", http.StatusOK, ""}, // HEAD falls back to the GET route
+		{http.MethodGet, "/missing/1", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.status || w.Header().Get("Allow") != tt.allow {
+			t.Errorf("%s %s: expected %d with Allow %q, got %d with %q", tt.method, tt.path, tt.status, tt.allow, w.Code, w.Header().Get("Allow"))
+		}
+	}
+
+	// The custom handler reads the allowed methods from the response headers
+	r.SetMethodNotAllowedHandler(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte("allowed: " + w.Header().Get("Allow")))
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/42", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Body.String() != "allowed: GET, PUT, DELETE, HEAD" {
+		t.Errorf("Expected the custom response, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+// TestMethodNotAllowedRegexBudget tests that the search for allowed methods shares one regex budget
+func TestMethodNotAllowedRegexBudget(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.MaxRegexEvaluations = 2
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Post("/items/{name:[a-z]+}", handler)
+	r.Put("/items/{name:[a-z]+}", handler)
+	r.Delete("/items/{name:[a-z]+}", handler)
+	r.Options("/items/{id:[0-9]+}", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// Each method costs one evaluation, so the budget runs out before the OPTIONS route is reached
+	if allowed := r.allowedMethods(nil, "/items/42"); allowed != nil {
+		t.Errorf("Expected the search to stop once the budget is spent, got %v", allowed)
+	}
+	if allowed := r.allowedMethods(nil, "/items/abc"); !slices.Equal(allowed, []string{http.MethodPost, http.MethodPut}) {
+		t.Errorf("Expected only the methods found within the budget, got %v", allowed)
+	}
+}
@@ -1,9 +1,20 @@
 package router
 
+import "slices"
+
 // MiddlewareFunc is a function type that takes a handler function and returns a new handler function.
 // It is used to insert common processing before and after request processing.
 type MiddlewareFunc func(HandlerFunc) HandlerFunc
 
+// CleanupMiddleware is middleware that holds resources (database pools, cache clients,
+// message producers, ...) which must be released when the router shuts down.
+type CleanupMiddleware interface {
+	// Middleware returns the middleware function added to the router.
+	Middleware() MiddlewareFunc
+	// Cleanup releases the resources held by the middleware.
+	Cleanup() error
+}
+
 // cleanupMiddleware is the implementation of CleanupMiddleware interface.
 type cleanupMiddleware struct {
 	mw      MiddlewareFunc
@@ -13,6 +24,8 @@ type cleanupMiddleware struct {
 // Use adds one or more middleware functions to the router.
 // Middleware functions are executed before all route handlers, allowing for common processing such as authentication and logging.
 func (r *Router) Use(mw ...MiddlewareFunc) {
+	r.mustBeMutable("Use")
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -26,11 +39,17 @@ func (r *Router) Use(mw ...MiddlewareFunc) {
 
 	// Atomic update
 	r.middleware.Store(newMiddleware)
+	r.middlewareGen.Add(1)
 }
 
 // AddCleanupMiddleware adds a cleanupable middleware to the router.
 // This middleware is cleaned up when the Shutdown method is called.
-func (r *Router) AddCleanupMiddleware(cm cleanupMiddleware) {
+// Cleanups run in reverse registration order (LIFO), like deferred calls, so middleware
+// that depends on another one (e.g. a cache client using a database pool) should be
+// added after its dependency and is cleaned up before it.
+func (r *Router) AddCleanupMiddleware(cm CleanupMiddleware) {
+	r.mustBeMutable("AddCleanupMiddleware")
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -44,12 +63,96 @@ func (r *Router) AddCleanupMiddleware(cm cleanupMiddleware) {
 
 	// Atomic update
 	r.middleware.Store(newMiddleware)
+	r.middlewareGen.Add(1)
 
 	// Add to cleanup list
-	currentCleanup := r.cleanupMws.Load().([]cleanupMiddleware)
-	newCleanup := make([]cleanupMiddleware, len(currentCleanup)+1)
+	currentCleanup := r.cleanupMws.Load().([]CleanupMiddleware)
+	newCleanup := make([]CleanupMiddleware, len(currentCleanup)+1)
 	copy(newCleanup, currentCleanup)
 	newCleanup[len(currentCleanup)] = cm
 
 	r.cleanupMws.Store(newCleanup)
 }
+
+// Cleanups returns the registered cleanupable middleware in the order in which
+// Shutdown cleans them up (reverse registration order).
+func (r *Router) Cleanups() []CleanupMiddleware {
+	cleanups := slices.Clone(r.cleanupMws.Load().([]CleanupMiddleware))
+	slices.Reverse(cleanups)
+	return cleanups
+}
+
+// SetMiddleware replaces the router's middleware list.
+// Because the list is stored atomically and read per request, this can be used to
+// enable or disable global middleware while the router is serving requests.
+// Middleware added with AddCleanupMiddleware is removed from the chain as well,
+// but its cleanup function is still called on Shutdown.
+func (r *Router) SetMiddleware(mw ...MiddlewareFunc) {
+	r.mustBeMutable("SetMiddleware")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Atomic update
+	r.middleware.Store(slices.Clone(mw))
+	r.middlewareGen.Add(1)
+}
+
+// composedChain is a middleware chain composed for a route.
+// It is valid as long as the router's middleware generation is unchanged.
+type composedChain struct {
+	generation uint64      // Middleware generation the chain was composed for
+	handler    HandlerFunc // Handler wrapped in the middleware
+}
+
+// routeChain returns the handler wrapped in the router middleware (and the group middleware
+// when it is resolved per request). The composed chain is cached on the route and is composed
+// again only when the middleware changes, which is tracked by the middleware generation.
+// Handlers registered without a Route (Router.Handle) are composed on every request, and the
+// handlers of routes that bypass the middleware (see ACMEChallenge) are returned as is.
+func (r *Router) routeChain(handler HandlerFunc, route *Route) HandlerFunc {
+	if route == nil {
+		return r.buildMiddlewareChain(handler)
+	}
+	if route.bare {
+		return handler
+	}
+
+	// The generation is loaded before the middleware lists, and writers update the lists
+	// before incrementing the generation, so a cached chain is never newer than its generation.
+	generation := r.middlewareGen.Load()
+	if cached := route.chain.Load(); cached != nil && cached.generation == generation {
+		return cached.handler
+	}
+
+	if r.perRequestMiddleware && route.group != nil {
+		handler = applyGroupMiddleware(handler, route.group)
+	}
+	h := r.buildMiddlewareChain(handler)
+	route.chain.Store(&composedChain{generation: generation, handler: h})
+	return h
+}
+
+// groupHandler applies the middleware of the group to the handler at registration time.
+// If middleware is resolved per request (RouterOptions.PerRequestMiddleware),
+// the handler is returned as is and the group's middleware is applied in ServeHTTP.
+func (r *Router) groupHandler(g *Group, h HandlerFunc) HandlerFunc {
+	if g == nil || r.perRequestMiddleware {
+		return h
+	}
+	return applyMiddlewareChain(h, g.middlewareSnapshot())
+}
+
+// applyGroupMiddleware applies the current middleware snapshots of the group and its parents.
+// The order is the same as when the group's middleware is composed at Build:
+// the chain of the root group is applied first, so middleware added later ends up outermost.
+func applyGroupMiddleware(h HandlerFunc, g *Group) HandlerFunc {
+	var chain []*Group
+	for current := g; current != nil; current = current.parent {
+		chain = append(chain, current)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = applyMiddlewareChain(h, chain[i].loadOwnMiddleware())
+	}
+	return h
+}
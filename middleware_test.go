@@ -0,0 +1,111 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// TestMiddlewareChainCache tests that the middleware chain is composed once per route
+// and composed again after the middleware changes
+func TestMiddlewareChainCache(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	composed := 0
+	counting := func(next HandlerFunc) HandlerFunc {
+		composed++
+		return next
+	}
+	r.Use(counting)
+
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	serve := func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	}
+
+	for i := 0; i < 3; i++ {
+		serve()
+	}
+	if composed != 1 {
+		t.Errorf("Expected the chain to be composed once, got %d", composed)
+	}
+
+	// Changing the middleware invalidates the cached chain
+	r.Use(func(next HandlerFunc) HandlerFunc { return next })
+	serve()
+	serve()
+	if composed != 2 {
+		t.Errorf("Expected the chain to be composed again after Use, got %d", composed)
+	}
+}
+
+// TestCleanupOrder tests that cleanupable middleware is cleaned up in reverse registration order
+func TestCleanupOrder(t *testing.T) {
+	r := NewRouter()
+
+	var order []string
+	passthrough := func(next HandlerFunc) HandlerFunc { return next }
+	for _, name := range []string{"db", "cache", "producer"} {
+		r.AddCleanupMiddleware(newCleanupMiddleware(passthrough, func() error {
+			order = append(order, name)
+			return nil
+		}))
+	}
+
+	if n := len(r.Cleanups()); n != 3 {
+		t.Fatalf("Expected 3 cleanups, got %d", n)
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shutdown router: %v", err)
+	}
+
+	want := []string{"producer", "cache", "db"}
+	if !slices.Equal(order, want) {
+		t.Errorf("Expected cleanup order %v, got %v", want, order)
+	}
+}
+
+// TestCleanupErrors tests that a failing cleanup does not skip the others and that cleanups run once
+func TestCleanupErrors(t *testing.T) {
+	r := NewRouter()
+
+	var order []string
+	errCache := errors.New("cache client")
+	passthrough := func(next HandlerFunc) HandlerFunc { return next }
+	for _, name := range []string{"db", "cache", "producer"} {
+		r.AddCleanupMiddleware(newCleanupMiddleware(passthrough, func() error {
+			order = append(order, name)
+			if name == "cache" {
+				return errCache
+			}
+			return nil
+		}))
+	}
+
+	// The drain timeout is reported together with the cleanup error
+	_, active, _ := r.beginRequest(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := r.Shutdown(ctx)
+	active.release()
+	if !errors.Is(err, errCache) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cleanup and wait errors, got %v", err)
+	}
+	if err := r.Shutdown(context.Background()); !errors.Is(err, errCache) {
+		t.Errorf("Expected a repeated Shutdown to report the cleanup error, got %v", err)
+	}
+
+	want := []string{"producer", "cache", "db"}
+	if !slices.Equal(order, want) {
+		t.Errorf("Expected cleanups %v, got %v", want, order)
+	}
+}
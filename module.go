@@ -0,0 +1,90 @@
+package router
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// Registrar is implemented by feature modules that register their routes on a group.
+// Modules are wired to a router with Router.Register.
+type Registrar interface {
+	RegisterRoutes(g *Group)
+}
+
+// NamedRegistrar is a Registrar that provides its own name for error messages.
+// Modules that do not implement it are identified by their type name.
+type NamedRegistrar interface {
+	Registrar
+	Name() string
+}
+
+// registrarName returns the name used to identify the module in error messages.
+func registrarName(m Registrar) string {
+	if named, ok := m.(NamedRegistrar); ok {
+		return named.Name()
+	}
+	return reflect.TypeOf(m).String()
+}
+
+// Register wires feature modules to the router under the given path prefix.
+// Each module receives its own group, and modules are registered in the order given,
+// so routes are added (and matched by registration order where that matters) module by module.
+// Registering the same module name twice, or two modules that define the same route,
+// returns an error naming both modules; the module that caused the error, and the modules after
+// it, are not registered. Routes are actually registered at Build.
+func (r *Router) Register(prefix string, modules ...Registrar) error {
+	if err := r.checkMutable("Register"); err != nil {
+		return err
+	}
+
+	// Collect routes already defined by modules registered earlier
+	owners := make(map[string]string)
+	for _, g := range r.allGroups() {
+		if g.module == "" {
+			continue
+		}
+		owners["module:"+g.module] = g.module
+		for _, route := range g.routes {
+			owners[route.method+":"+route.fullPath()] = g.module
+		}
+	}
+
+	for _, m := range modules {
+		name := registrarName(m)
+		if _, exists := owners["module:"+name]; exists {
+			return &RouterError{
+				Code:    ErrInvalidPattern,
+				Message: "duplicate module registration: " + strconv.Quote(name),
+				Err:     ErrDuplicateRoute,
+			}
+		}
+		owners["module:"+name] = name
+
+		g := r.Group(prefix)
+		g.module = name
+		m.RegisterRoutes(g)
+
+		// Check the routes of the module (including nested groups) against other modules
+		groups := []*Group{g}
+		for i := 0; i < len(groups); i++ {
+			groups = append(groups, groups[i].children...)
+			for _, route := range groups[i].routes {
+				routeKey := route.method + ":" + route.fullPath()
+				if owner, exists := owners[routeKey]; exists && owner != name {
+					// Detach the rejected module, so that its routes are not built
+					r.groups = slices.DeleteFunc(r.groups, func(registered *Group) bool { return registered == g })
+					return &RouterError{
+						Code: ErrInvalidPattern,
+						Message: "duplicate route definition: " + route.method + " " + route.fullPath() +
+							" (registered by module " + strconv.Quote(owner) + " and module " + strconv.Quote(name) + ")",
+						Err: ErrDuplicateRoute,
+					}
+				}
+				owners[routeKey] = name
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// testModule is a feature module used for testing Register
+type testModule struct {
+	name  string
+	paths []string
+}
+
+func (m *testModule) Name() string {
+	return m.name
+}
+
+func (m *testModule) RegisterRoutes(g *Group) {
+	for _, path := range m.paths {
+		g.Get(path, func(w http.ResponseWriter, r *http.Request) error { return nil })
+	}
+}
+
+// anonymousModule is a feature module without a name
+type anonymousModule struct{}
+
+func (anonymousModule) RegisterRoutes(g *Group) {
+	g.Get("/ping", func(w http.ResponseWriter, r *http.Request) error { return nil })
+}
+
+// TestRegisterModules tests wiring modules to a router
+func TestRegisterModules(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	users := &testModule{name: "users", paths: []string{"/users", "/users/{id}"}}
+	posts := &testModule{name: "posts", paths: []string{"/posts"}}
+	if err := r.Register("/api", users, posts, anonymousModule{}); err != nil {
+		t.Fatalf("Failed to register modules: %v", err)
+	}
+
+	// Modules are registered in order, each with its own group
+	if len(r.groups) != 3 || r.groups[0].module != "users" || r.groups[1].module != "posts" {
+		t.Fatalf("Modules were not registered in order")
+	}
+	if r.groups[2].module != "router.anonymousModule" {
+		t.Errorf("Expected the type name for an unnamed module, got %s", r.groups[2].module)
+	}
+
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	for _, path := range []string{"/api/users", "/api/users/1", "/api/posts", "/api/ping"} {
+		if _, ok := r.Match(http.MethodGet, path); !ok {
+			t.Errorf("Route %s was not registered", path)
+		}
+	}
+}
+
+// TestRegisterModulesDuplicate tests duplicate detection across modules
+func TestRegisterModulesDuplicate(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	users := &testModule{name: "users", paths: []string{"/users"}}
+	legacy := &testModule{name: "legacy", paths: []string{"/users"}}
+
+	err := r.Register("/api", users, legacy)
+	if err == nil {
+		t.Fatal("Expected an error for a duplicate route across modules")
+	}
+	if !strings.Contains(err.Error(), `module "users"`) || !strings.Contains(err.Error(), `module "legacy"`) {
+		t.Errorf("Error should name both modules, got: %v", err)
+	}
+
+	// The rejected module is not registered, so its route does not conflict at Build
+	if len(r.groups) != 1 || r.groups[0].module != "users" {
+		t.Errorf("Expected only the users module to be registered, got %d groups", len(r.groups))
+	}
+	if err := r.Build(); err != nil {
+		t.Errorf("Failed to build router: %v", err)
+	}
+
+	// Registering the same module twice is rejected
+	r2 := NewRouter()
+	defer r2.cache.stop()
+	if err := r2.Register("/api", users); err != nil {
+		t.Fatalf("Failed to register module: %v", err)
+	}
+	if err := r2.Register("/v2", users); err == nil {
+		t.Error("Expected an error for a duplicate module registration")
+	}
+
+	// Build reports duplicates with module names as well
+	r3 := NewRouter()
+	defer r3.cache.stop()
+	if err := r3.Register("/api", users); err != nil {
+		t.Fatalf("Failed to register module: %v", err)
+	}
+	r3.Group("/api").Get("/users", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	if err := r3.Build(); err == nil || !strings.Contains(err.Error(), `module "users"`) {
+		t.Errorf("Expected build error naming the module, got: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// MountOptions configures how a mounted handler receives the parameters matched by the router.
+// The parameters are always available to the mounted handler with GetParams, since the request
+// context is passed on; the options cover handlers that read them from elsewhere.
+type MountOptions struct {
+	// ParamHeaders maps parameter names of the mount pattern to request headers that are set to
+	// the parameter values, replacing any value sent by the client.
+	// 例: map[string]string{"tenant": "X-Tenant"}
+	ParamHeaders map[string]string
+
+	// Bridge translates the parameters into the conventions of the mounted framework, for example
+	// by adding them to the route context of chi or echo. It returns the request to pass on.
+	Bridge func(req *http.Request, params map[string]string) *http.Request
+}
+
+// mountPoint is a handler mounted under a path prefix.
+type mountPoint struct {
+	pattern  string   // Normalized pattern of the mount point
+	segments []string // Segments of the prefix ("{name}" for parameters)
+	handler  HandlerFunc
+}
+
+// Mount serves h, typically a sub-application written with another router such as chi or echo,
+// for all methods and all paths under the pattern. The matched prefix is stripped, so h sees
+// paths relative to the mount point; the stripped remainder is also the "*" parameter.
+// The pattern may contain parameters ({name}, without regular expressions), which are passed
+// to h as configured by opts.
+//
+// Mounted handlers run with the router's middleware, timeouts, and error handling, and are
+// matched only when no route matches, so routes can override paths of the sub-application.
+// When several mount points match, the longest prefix is used.
+//
+// 例: r.Mount("/tenants/{tenant}/app", billing, router.MountOptions{ParamHeaders: map[string]string{"tenant": "X-Tenant"}})
+func (r *Router) Mount(pattern string, h http.Handler, opts MountOptions) error {
+	if err := r.checkMutable("Mount"); err != nil {
+		return err
+	}
+	pattern = normalizePath(pattern)
+	if h == nil {
+		return &RouterError{Code: ErrNilHandler, Message: "nil handler"}
+	}
+	if err := validatePatternWith(pattern, r.segmentChars); err != nil {
+		return err
+	}
+	pattern, err := decodeStaticSegments(pattern)
+	if err != nil {
+		return err
+	}
+
+	var segments []string
+	if pattern != "/" {
+		segments = parseSegments(pattern)
+	}
+	for _, seg := range segments {
+		if isDynamicSeg(seg) && strings.Contains(seg, ":") {
+			return &RouterError{Code: ErrInvalidPattern, Message: "mount patterns do not support regular expressions: " + pattern}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.mounts {
+		if slices.Equal(m.segments, segments) {
+			return &RouterError{Code: ErrInvalidPattern, Message: "duplicate mount point: " + pattern, Err: ErrDuplicateRoute}
+		}
+	}
+	r.mounts = append(r.mounts, &mountPoint{pattern: pattern, segments: segments, handler: mountHandler(h, opts)})
+	slices.SortStableFunc(r.mounts, func(a, b *mountPoint) int {
+		return len(b.segments) - len(a.segments)
+	})
+	return nil
+}
+
+// findMount returns the mounted handler for the path, with the parameters of the mount pattern.
+func (r *Router) findMount(path string) (routeMatch, bool) {
+	m, params, ok := r.matchMount(path)
+	if !ok {
+		return routeMatch{}, false
+	}
+	return routeMatch{handler: m.handler, source: MatchFromMount, params: params}, true
+}
+
+// matchMount returns the mount point with the longest prefix of the path and its parameters.
+func (r *Router) matchMount(path string) (*mountPoint, map[string]string, bool) {
+	r.mu.RLock()
+	mounts := r.mounts
+	r.mu.RUnlock()
+	if len(mounts) == 0 {
+		return nil, nil, false
+	}
+
+	segments := parseSegments(normalizePath(path))
+	if len(segments) == 1 && segments[0] == "" {
+		segments = nil
+	}
+	for _, m := range mounts {
+		if params, ok := m.match(segments); ok {
+			return m, params, true
+		}
+	}
+	return nil, nil, false
+}
+
+// match reports whether the path segments start with the prefix of the mount point and
+// returns its parameters, including the remainder as "*".
+func (m *mountPoint) match(segments []string) (map[string]string, bool) {
+	if len(segments) < len(m.segments) {
+		return nil, false
+	}
+	params := make(map[string]string, 1)
+	for i, seg := range m.segments {
+		if isDynamicSeg(seg) {
+			if segments[i] == "" {
+				return nil, false
+			}
+			if name := extractParamName(seg); name != "" {
+				params[name] = segments[i]
+			}
+		} else if seg != segments[i] {
+			return nil, false
+		}
+	}
+	params["*"] = strings.Join(segments[len(m.segments):], "/")
+	return params, true
+}
+
+// mountHandler adapts the mounted handler: it strips the prefix and passes on the parameters.
+func mountHandler(h http.Handler, opts MountOptions) HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		params := make(map[string]string)
+		rest := ""
+		for _, entry := range GetParams(req.Context()).data {
+			if entry.key == "*" {
+				rest = entry.value
+				continue
+			}
+			params[entry.key] = entry.value
+		}
+
+		mounted := req.Clone(req.Context())
+		mounted.URL.Path = "/" + rest
+		mounted.URL.RawPath = ""
+		for name, header := range opts.ParamHeaders {
+			if value, ok := params[name]; ok {
+				mounted.Header.Set(header, value)
+			} else {
+				mounted.Header.Del(header)
+			}
+		}
+		if opts.Bridge != nil {
+			mounted = opts.Bridge(mounted, params)
+		}
+		h.ServeHTTP(w, mounted)
+		return nil
+	}
+}
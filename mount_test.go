@@ -0,0 +1,78 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mountParamsKey struct{}
+
+// TestMount tests serving a sub-application under a pattern with parameters
+func TestMount(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	app := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		bridged, _ := req.Context().Value(mountParamsKey{}).(map[string]string)
+		tenant, _ := GetParams(req.Context()).Get("tenant")
+		fmt.Fprintf(w, "%s %s header=%s bridged=%s param=%s", req.Method, req.URL.Path, req.Header.Get("X-Tenant"), bridged["tenant"], tenant)
+	})
+	if err := r.Mount("/tenants/{tenant}/app", app, MountOptions{
+		ParamHeaders: map[string]string{"tenant": "X-Tenant"},
+		Bridge: func(req *http.Request, params map[string]string) *http.Request {
+			return req.WithContext(context.WithValue(req.Context(), mountParamsKey{}, params))
+		},
+	}); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	if err := r.Mount("/admin", app, MountOptions{}); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	if err := r.Mount("/admin/tools", app, MountOptions{ParamHeaders: map[string]string{"tenant": "X-Tenant"}}); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	r.Get("/admin/status", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("router"))
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/tenants/acme/app/invoices/7", "GET /invoices/7 header=acme bridged=acme param=acme"},
+		{http.MethodPost, "/tenants/acme/app/invoices", "POST /invoices header=acme bridged=acme param=acme"},
+		{http.MethodGet, "/tenants/acme/app", "GET / header=acme bridged=acme param=acme"},
+		{http.MethodDelete, "/admin/users/1", "DELETE /users/1 header=spoofed bridged= param="},
+		{http.MethodGet, "/admin/tools/cache", "GET /cache header= bridged= param="}, // Longest prefix, header not spoofable
+		{http.MethodGet, "/admin/status", "router"},                                  // Routes take precedence
+		{http.MethodGet, "/tenants/acme", "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("X-Tenant", "spoofed")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != tt.body {
+			t.Errorf("%s %s: expected %q, got %q", tt.method, tt.path, tt.body, w.Body.String())
+		}
+	}
+
+	// Invalid mount points are rejected
+	if err := r.Mount("/admin", app, MountOptions{}); err == nil {
+		t.Error("Expected an error for a duplicate mount point")
+	}
+	if err := r.Mount("/files/{id:[0-9]+}", app, MountOptions{}); err == nil {
+		t.Error("Expected an error for a regular expression")
+	}
+	if err := r.Mount("/files", nil, MountOptions{}); err == nil {
+		t.Error("Expected an error for a nil handler")
+	}
+}
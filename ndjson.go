@@ -0,0 +1,144 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Default settings of the NDJSON encoder.
+const (
+	defaultNDJSONFlushInterval = 100 * time.Millisecond // Maximum time records are buffered
+	defaultNDJSONFlushBytes    = 32 * 1024              // Maximum number of bytes buffered
+)
+
+// errStreamingUnsupported is returned when the ResponseWriter cannot flush.
+var errStreamingUnsupported = errors.New("router: response writer does not support flushing")
+
+// Encoder writes newline-delimited JSON (NDJSON) records to a response.
+// Records are flushed to the client periodically, so large result sets can be streamed
+// without being buffered in memory. Encoder is not safe for concurrent use.
+type Encoder struct {
+	w             http.ResponseWriter
+	rc            *http.ResponseController
+	enc           *json.Encoder
+	ctx           context.Context
+	flushInterval time.Duration // Flush when this much time has passed since the last flush
+	flushBytes    int           // Flush when this many bytes have been written since the last flush
+	writeTimeout  time.Duration // Deadline for each write (0 means no deadline)
+	lastFlush     time.Time
+	pending       int  // Bytes written since the last flush
+	started       bool // Whether the response headers have been written
+}
+
+// NDJSON returns an Encoder that streams newline-delimited JSON records to w.
+// The Content-Type header is set to application/x-ndjson unless it is already set.
+// Flushing requires the ResponseWriter to support http.Flusher, which the router's
+// ResponseWriter passes through.
+func NDJSON(w http.ResponseWriter) *Encoder {
+	e := &Encoder{
+		w:             w,
+		rc:            http.NewResponseController(w),
+		ctx:           context.Background(),
+		flushInterval: defaultNDJSONFlushInterval,
+		flushBytes:    defaultNDJSONFlushBytes,
+		lastFlush:     time.Now(),
+	}
+	e.enc = json.NewEncoder(countingWriter{e})
+	return e
+}
+
+// WithContext makes the encoder stop writing once ctx is done (for example when the client
+// disconnects or the request times out). Pass the request context.
+func (e *Encoder) WithContext(ctx context.Context) *Encoder {
+	e.ctx = ctx
+	return e
+}
+
+// WithFlushInterval sets how long records may be buffered before they are flushed.
+// A value of 0 or less flushes after every record.
+func (e *Encoder) WithFlushInterval(d time.Duration) *Encoder {
+	e.flushInterval = d
+	return e
+}
+
+// WithFlushBytes sets how many bytes may be buffered before they are flushed.
+func (e *Encoder) WithFlushBytes(n int) *Encoder {
+	e.flushBytes = n
+	return e
+}
+
+// WithWriteTimeout sets a deadline for each write to the client.
+// If the client does not read fast enough (backpressure), Encode returns an error
+// instead of blocking the handler indefinitely. It requires a ResponseWriter that
+// supports write deadlines (http.ResponseController.SetWriteDeadline).
+func (e *Encoder) WithWriteTimeout(d time.Duration) *Encoder {
+	e.writeTimeout = d
+	return e
+}
+
+// Encode writes v as a single JSON record followed by a newline.
+// It returns the context error if the context is done, and flushes the
+// buffered records when the flush interval or size threshold is reached.
+func (e *Encoder) Encode(v any) error {
+	if err := e.ctx.Err(); err != nil {
+		return err
+	}
+
+	if !e.started {
+		if e.w.Header().Get("Content-Type") == "" {
+			e.w.Header().Set("Content-Type", "application/x-ndjson")
+		}
+		e.started = true
+	}
+
+	if e.writeTimeout > 0 {
+		if err := e.rc.SetWriteDeadline(time.Now().Add(e.writeTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+	}
+
+	// json.Encoder appends a newline after each value
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+
+	if e.pending >= e.flushBytes || time.Since(e.lastFlush) >= e.flushInterval {
+		return e.Flush()
+	}
+	return nil
+}
+
+// Flush sends the buffered records to the client.
+func (e *Encoder) Flush() error {
+	if err := e.rc.Flush(); err != nil {
+		if errors.Is(err, http.ErrNotSupported) {
+			return errStreamingUnsupported
+		}
+		return err
+	}
+	e.pending = 0
+	e.lastFlush = time.Now()
+	return nil
+}
+
+// Close flushes the remaining records. It does not close the underlying connection.
+func (e *Encoder) Close() error {
+	if e.pending == 0 {
+		return nil
+	}
+	return e.Flush()
+}
+
+// countingWriter writes to the encoder's ResponseWriter and counts the bytes written since the last flush.
+type countingWriter struct {
+	e *Encoder
+}
+
+func (cw countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.e.w.Write(p)
+	cw.e.pending += n
+	return n, err
+}
@@ -0,0 +1,62 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestNDJSON tests streaming newline-delimited JSON through the router
+func TestNDJSON(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Get("/events", func(w http.ResponseWriter, req *http.Request) error {
+		enc := NDJSON(w).WithContext(req.Context()).WithFlushInterval(0)
+		for i := 1; i <= 3; i++ {
+			if err := enc.Encode(map[string]int{"id": i}); err != nil {
+				return err
+			}
+		}
+		return enc.Close()
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Expected Content-Type application/x-ndjson, got %q", got)
+	}
+	if want := "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"; w.Body.String() != want {
+		t.Errorf("Expected body %q, got %q", want, w.Body.String())
+	}
+	if !w.Flushed {
+		t.Error("Expected the records to be flushed through the router")
+	}
+}
+
+// TestNDJSONCanceled tests that the encoder stops when the context is done
+func TestNDJSONCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	enc := NDJSON(w).WithContext(ctx)
+
+	if err := enc.Encode(1); err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	cancel()
+	if err := enc.Encode(2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Errorf("Failed to close: %v", err)
+	}
+	if w.Body.String() != "1\n" {
+		t.Errorf("Expected body %q, got %q", "1\n", w.Body.String())
+	}
+}
@@ -0,0 +1,40 @@
+package router
+
+import "net/http"
+
+// observabilityMetaKey is the metadata key under which WithObservability stores whether a route is observed.
+const observabilityMetaKey = "router.observability"
+
+// WithObservability includes (true, the default) or excludes (false) the route from the router's
+// built-in observability, so that high-frequency internal routes such as health checks and metrics
+// scrapes do not drown out the traffic of interest. An excluded route is not counted in Stats,
+// not recorded in Latencies, not reported to OnSlowRequest, and not logged according to ShouldLog.
+// Tracing and metrics middleware honor the setting by calling Observed.
+//
+// The setting is stored as route metadata, so it can also be set for a whole group with
+// Group.WithMeta; the route's own setting takes precedence.
+//
+// 例: r.Get("/healthz", health).WithObservability(false)
+func (r *Route) WithObservability(enabled bool) *Route {
+	return r.WithMeta(observabilityMetaKey, enabled)
+}
+
+// observed reports whether the route is included in the built-in observability (see WithObservability).
+func (r *Route) observed() bool {
+	if r == nil {
+		return true
+	}
+	value, ok := r.Meta(observabilityMetaKey)
+	if !ok {
+		return true
+	}
+	enabled, _ := value.(bool)
+	return enabled
+}
+
+// Observed reports whether the request should be traced, measured and logged, which is false
+// for the requests of routes excluded with WithObservability. Requests that did not match a
+// route are always observed.
+func Observed(r *http.Request) bool {
+	return routeFromContext(r.Context()).observed()
+}
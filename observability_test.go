@@ -0,0 +1,83 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestWithObservability tests that excluded routes are left out of the built-in observability
+func TestWithObservability(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.LatencyHistograms = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	infos := make(chan SlowRequestInfo, 4)
+	r.OnSlowRequest(0, func(info SlowRequestInfo) {
+		infos <- info
+	})
+	observed := map[string]bool{}
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			observed[req.URL.Path] = Observed(req) || ShouldLog(req)
+			return next(w, req)
+		}
+	})
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/healthz", handler).WithObservability(false)
+	r.Get("/api", handler).WithObservability(true)
+	internal := r.Group("/internal").WithMeta(observabilityMetaKey, false)
+	internal.Get("/metrics", handler)
+	internal.Get("/debug", handler).WithObservability(true)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	paths := []string{"/healthz", "/internal/metrics", "/api", "/internal/debug"}
+	for _, path := range paths {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	want := map[string]bool{"/healthz": false, "/internal/metrics": false, "/api": true, "/internal/debug": true}
+	for _, path := range paths {
+		if observed[path] != want[path] {
+			t.Errorf("%s: expected observed %v, got %v", path, want[path], observed[path])
+		}
+	}
+
+	hits := map[string]uint64{}
+	for _, s := range r.Stats() {
+		hits[s.Pattern] = s.Hits
+	}
+	for _, path := range paths {
+		var count uint64
+		if want[path] {
+			count = 1
+		}
+		if hits[path] != count {
+			t.Errorf("%s: expected %d hits, got %d", path, count, hits[path])
+		}
+		if snap := r.Latencies(path); snap.Count != count {
+			t.Errorf("%s: expected %d recorded latencies, got %d", path, count, snap.Count)
+		}
+	}
+
+	reported := map[string]bool{}
+	for range 2 {
+		select {
+		case info := <-infos:
+			reported[info.Path] = true
+		case <-time.After(time.Second):
+			t.Fatal("Slow request hook was not called")
+		}
+	}
+	select {
+	case info := <-infos:
+		reported[info.Path] = true
+	case <-time.After(20 * time.Millisecond):
+	}
+	if len(reported) != 2 || !reported["/api"] || !reported["/internal/debug"] {
+		t.Errorf("Expected only the observed routes to be reported, got %v", reported)
+	}
+}
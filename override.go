@@ -0,0 +1,75 @@
+package router
+
+// OverrideStrategy selects how duplicate route registrations are resolved
+// (see RouterOptions.OverrideStrategy).
+type OverrideStrategy uint8
+
+const (
+	// OverrideError rejects duplicate routes: Build and Handle return an error wrapping
+	// ErrDuplicateRoute.
+	OverrideError OverrideStrategy = iota
+	// OverrideLastWins replaces the earlier route with the later one and logs a warning.
+	OverrideLastWins
+	// OverrideFirstWins keeps the earlier route, ignores the later one, and logs a warning.
+	OverrideFirstWins
+	// OverridePanic panics with the error that OverrideError would return, for programs that
+	// treat a duplicate route as a programming error like a duplicate http.ServeMux pattern.
+	OverridePanic
+)
+
+// String returns the name of the strategy.
+func (s OverrideStrategy) String() string {
+	switch s {
+	case OverrideError:
+		return "error"
+	case OverrideLastWins:
+		return "last-wins"
+	case OverrideFirstWins:
+		return "first-wins"
+	case OverridePanic:
+		return "panic"
+	default:
+		return "unknown"
+	}
+}
+
+// overrideStrategyOf returns the strategy selected by the options. AllowRouteOverride selects
+// OverrideLastWins unless another strategy is set.
+func overrideStrategyOf(opts RouterOptions) OverrideStrategy {
+	if opts.OverrideStrategy == OverrideError && opts.AllowRouteOverride {
+		return OverrideLastWins
+	}
+	return opts.OverrideStrategy
+}
+
+// overrides reports whether the strategy resolves duplicate routes instead of rejecting them.
+func (s OverrideStrategy) overrides() bool {
+	return s == OverrideLastWins || s == OverrideFirstWins
+}
+
+// duplicateError returns the error about a duplicate route, or panics with it under OverridePanic.
+func (r *Router) duplicateError(err error) error {
+	if r.override == OverridePanic {
+		panic(err)
+	}
+	return err
+}
+
+// resolveDuplicate resolves a route registered again by Build under the override strategy.
+// desc and existing describe the new and the earlier registration. It returns whether the new
+// route replaces the earlier one, or the error to report.
+// Overrides are warnings, so that strict mode rejects them like any other warning.
+func (r *Router) resolveDuplicate(desc, existing string) (bool, error) {
+	switch r.override {
+	case OverrideLastWins:
+		return true, r.buildWarning("overriding route: " + desc + " (previously defined as " + existing + ")")
+	case OverrideFirstWins:
+		return false, r.buildWarning("ignoring duplicate route: " + desc + " (keeping " + existing + ")")
+	default:
+		return false, r.duplicateError(&RouterError{
+			Code:    ErrInvalidPattern,
+			Message: "duplicate route definition: " + desc + " (conflicts with " + existing + ")" + r.overrideHint(),
+			Err:     ErrDuplicateRoute,
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package router
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestOverrideStrategy tests resolving duplicate routes with each strategy
+func TestOverrideStrategy(t *testing.T) {
+	respond := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			w.Write([]byte(body))
+			return nil
+		}
+	}
+	setups := map[string]func(r *Router){
+		"direct": func(r *Router) {
+			r.Get("/items/{id}", respond("first"))
+			r.Get("/items/{id}", respond("second"))
+		},
+		"group": func(r *Router) {
+			g := r.Group("/items")
+			g.Get("/{id}", respond("first"))
+			g.Get("/{id}", respond("second"))
+		},
+		"group and direct": func(r *Router) {
+			r.Group("/items").Get("/{id}", respond("first"))
+			r.Get("/items/{id}", respond("second"))
+		},
+	}
+	tests := []struct {
+		strategy OverrideStrategy
+		body     string // Response of the built router ("" if Build fails)
+		warning  string
+	}{
+		{OverrideError, "", ""},
+		{OverrideLastWins, "second", "overriding route: GET /items/{id}"},
+		{OverrideFirstWins, "first", "ignoring duplicate route: GET /items/{id}"},
+	}
+	for name, setup := range setups {
+		for _, tt := range tests {
+			var logs bytes.Buffer
+			opts := defaultRouterOptions()
+			opts.OverrideStrategy = tt.strategy
+			opts.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+			r := NewRouterWithOptions(opts)
+			setup(r)
+			err := r.Build()
+			if tt.body == "" {
+				if !IsDuplicate(err) {
+					t.Errorf("%s/%s: expected a duplicate route error, got %v", name, tt.strategy, err)
+				}
+				r.cache.stop()
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s/%s: failed to build router: %v", name, tt.strategy, err)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+			if w.Body.String() != tt.body {
+				t.Errorf("%s/%s: expected %q, got %q", name, tt.strategy, tt.body, w.Body.String())
+			}
+			if !strings.Contains(logs.String(), tt.warning) {
+				t.Errorf("%s/%s: expected the warning %q, got %q", name, tt.strategy, tt.warning, logs.String())
+			}
+			r.cache.stop()
+		}
+	}
+
+	// AllowRouteOverride selects OverrideLastWins
+	opts := defaultRouterOptions()
+	opts.AllowRouteOverride = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+	if r.override != OverrideLastWins {
+		t.Errorf("Expected AllowRouteOverride to select %v, got %v", OverrideLastWins, r.override)
+	}
+}
+
+// TestOverrideStrategySiblings tests that overriding replaces only the identical pattern,
+// keeping distinct dynamic routes on the same segment
+func TestOverrideStrategySiblings(t *testing.T) {
+	respond := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			w.Write([]byte(body))
+			return nil
+		}
+	}
+	for _, strategy := range []OverrideStrategy{OverrideLastWins, OverrideFirstWins} {
+		opts := defaultRouterOptions()
+		opts.OverrideStrategy = strategy
+		r := NewRouterWithOptions(opts)
+		r.Get("/a/{x:[0-9]+}", respond("digits"))
+		r.Get("/a/{y:[a-z]+}", respond("letters"))
+		if err := r.Build(); err != nil {
+			t.Fatalf("%s: failed to build router: %v", strategy, err)
+		}
+		for path, want := range map[string]string{"/a/123": "digits", "/a/abc": "letters"} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if w.Code != http.StatusOK || w.Body.String() != want {
+				t.Errorf("%s: GET %s: expected %q, got %d %q", strategy, path, want, w.Code, w.Body.String())
+			}
+		}
+		r.cache.stop()
+	}
+}
+
+// TestOverrideStrategyHandle tests resolving duplicates registered with Handle
+func TestOverrideStrategyHandle(t *testing.T) {
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+
+	opts := defaultRouterOptions()
+	opts.OverrideStrategy = OverrideFirstWins
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+	for _, pattern := range []string{"/static", "/users/{id}"} {
+		for range 2 {
+			if err := r.Handle(http.MethodGet, pattern, handler); err != nil {
+				t.Errorf("%s: expected the duplicate to be ignored, got %v", pattern, err)
+			}
+		}
+	}
+
+	opts.OverrideStrategy = OverridePanic
+	p := NewRouterWithOptions(opts)
+	defer p.cache.stop()
+	for _, pattern := range []string{"/static", "/users/{id}"} {
+		if err := p.Handle(http.MethodGet, pattern, handler); err != nil {
+			t.Fatalf("Failed to register route: %v", err)
+		}
+		func() {
+			defer func() {
+				if err, ok := recover().(error); !ok || !IsDuplicate(err) {
+					t.Errorf("%s: expected a panic with a duplicate route error, got %v", pattern, err)
+				}
+			}()
+			p.Handle(http.MethodGet, pattern, handler)
+		}()
+	}
+
+	// Build panics as well
+	b := NewRouterWithOptions(opts)
+	defer b.cache.stop()
+	b.Get("/items", handler)
+	b.Group("/").Get("/items", handler)
+	defer func() {
+		if err, ok := recover().(error); !ok || !IsDuplicate(err) {
+			t.Errorf("Expected Build to panic with a duplicate route error, got %v", err)
+		}
+	}()
+	b.Build()
+}
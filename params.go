@@ -11,6 +11,10 @@ type paramsKey struct{}
 // It can store any number of parameters using a slice.
 type Params struct {
 	data []paramEntry // Slice of parameter entries
+
+	// Regex evaluation budget for the current match (see RouterOptions.MaxRegexEvaluations)
+	regexLimit int // Maximum number of evaluations (0 means no limit)
+	regexEvals int // Number of evaluations performed so far
 }
 
 type paramEntry struct {
@@ -24,16 +28,24 @@ const initialParamsCapacity = 8
 // ParamsPool manages a pool of Params objects.
 // Each router instance has its own pool to prevent interference between tests.
 type ParamsPool struct {
-	pool sync.Pool
+	pool     sync.Pool
+	capacity int // Initial capacity of new Params
 }
 
 // newParamsPool creates a new ParamsPool.
 func newParamsPool() *ParamsPool {
+	return newParamsPoolWithCapacity(initialParamsCapacity)
+}
+
+// newParamsPoolWithCapacity creates a new ParamsPool whose Params are allocated
+// with room for the specified number of parameters.
+func newParamsPoolWithCapacity(capacity int) *ParamsPool {
 	return &ParamsPool{
+		capacity: capacity,
 		pool: sync.Pool{
 			New: func() any {
 				return &Params{
-					data: make([]paramEntry, 0, initialParamsCapacity),
+					data: make([]paramEntry, 0, capacity),
 				}
 			},
 		},
@@ -55,6 +67,28 @@ func (pp *ParamsPool) Put(ps *Params) {
 func (ps *Params) reset() {
 	// Clear the slice (maintain capacity)
 	ps.data = ps.data[:0]
+	ps.regexLimit = 0
+	ps.regexEvals = 0
+}
+
+// allowRegexEvaluation counts a regular expression evaluation against the budget.
+// It returns false if the budget has been exhausted.
+func (ps *Params) allowRegexEvaluation() bool {
+	if ps.regexLimit <= 0 {
+		return true
+	}
+	ps.regexEvals++
+	return ps.regexEvals <= ps.regexLimit
+}
+
+// regexBudgetExhausted reports whether no regular expression evaluation is left in the budget.
+func (ps *Params) regexBudgetExhausted() bool {
+	return ps.regexLimit > 0 && ps.regexEvals >= ps.regexLimit
+}
+
+// truncate removes parameters added after the Params had n entries.
+func (ps *Params) truncate(n int) {
+	ps.data = ps.data[:n]
 }
 
 // Add adds a new parameter.
@@ -91,6 +125,13 @@ func PutParams(ps *Params) {
 	ps.reset()
 }
 
+// WithParams returns a copy of ctx that carries the specified parameters.
+// Handlers read them back with GetParams, which makes it possible to
+// call handlers directly in tests without going through the router.
+func WithParams(ctx context.Context, ps *Params) context.Context {
+	return contextWithParams(ctx, ps)
+}
+
 // GetParams retrieves a Params instance from the context.
 func GetParams(ctx context.Context) *Params {
 	if ctx == nil {
@@ -0,0 +1,69 @@
+package router
+
+import (
+	"slices"
+	"strings"
+)
+
+// paramTransform rewrites the value of a URL parameter of a route.
+type paramTransform struct {
+	name string              // Parameter name
+	fn   func(string) string // Function applied to the matched value
+}
+
+// WithParamTransform registers a function that rewrites the value of the named URL parameter
+// after the route matched and before its middleware and handler run, so that normalization such
+// as lower-casing a slug is not repeated in every handler. GetParams returns the transformed
+// value. Transforms of the same parameter are applied in registration order.
+//
+// Matching is not affected: the pattern and its constraints are checked against the raw value.
+// Build reports a transform of a parameter the pattern does not have, or a nil function.
+//
+// 例: r.Get("/posts/{slug}", showPost).WithParamTransform("slug", strings.ToLower)
+func (r *Route) WithParamTransform(name string, fn func(string) string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.paramTransforms = append(r.paramTransforms, paramTransform{name: name, fn: fn})
+	return r
+}
+
+// validateParamTransforms checks that every transform has a function and names a parameter of
+// the route's pattern.
+func (r *Route) validateParamTransforms() error {
+	if len(r.paramTransforms) == 0 {
+		return nil
+	}
+	var names []string
+	for _, seg := range strings.Split(r.fullPath(), "/") {
+		if name := extractParamName(seg); name != "" {
+			names = append(names, name)
+		}
+	}
+	for _, t := range r.paramTransforms {
+		if t.fn == nil {
+			return &RouterError{Code: ErrNilHandler, Message: "param transform cannot be nil: " + t.name + " of " + r.method + " " + r.fullPath()}
+		}
+		if !slices.Contains(names, t.name) {
+			return &RouterError{Code: ErrInvalidPattern, Message: "param transform of unknown parameter " + t.name + ": " + r.method + " " + r.fullPath()}
+		}
+	}
+	return nil
+}
+
+// transformParams applies the route's transforms to the parameters of a request.
+// ps must be owned by the request, since the values are rewritten in place.
+func (r *Route) transformParams(ps *Params) {
+	if r == nil {
+		return
+	}
+	for _, t := range r.paramTransforms {
+		for i := range ps.data {
+			if ps.data[i].key == t.name {
+				ps.data[i].value = t.fn(ps.data[i].value)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestParamTransform tests that parameter transforms run before the handler on both match paths
+func TestParamTransform(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var seen string
+	r.Get("/posts/{slug}/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		ps := GetParams(req.Context())
+		slug, _ := ps.Get("slug")
+		id, _ := ps.Get("id")
+		seen = slug + "/" + id
+		return nil
+	}).WithParamTransform("slug", strings.ToLower).WithParamTransform("slug", strings.TrimSpace)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// The second request is served from the cache, whose parameters must stay untransformed
+	for range 2 {
+		seen = ""
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/Hello-World%20/AbC", nil))
+		if seen != "hello-world/AbC" {
+			t.Errorf("Expected the transformed parameters, got %q", seen)
+		}
+	}
+}
+
+// TestParamTransformValidation tests that Build reports invalid transforms
+func TestParamTransformValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) string
+		code ErrorCode
+	}{
+		{"unknown", strings.ToLower, ErrInvalidPattern},
+		{"slug", nil, ErrNilHandler},
+	}
+	for _, tt := range tests {
+		r := NewRouter()
+		r.Get("/posts/{slug}", func(w http.ResponseWriter, req *http.Request) error {
+			return nil
+		}).WithParamTransform(tt.name, tt.fn)
+		err := r.Build()
+		if re, ok := err.(*RouterError); !ok || re.Code != tt.code {
+			t.Errorf("%s: expected error code %v, got %v", tt.name, tt.code, err)
+		}
+		r.cache.stop()
+	}
+}
@@ -0,0 +1,225 @@
+package router
+
+import (
+	"strings"
+	"sync"
+)
+
+// patternCache caches dynamic matches by route pattern instead of by concrete URL.
+// After the first request to a pattern, a match plan is compiled from it: the literal and
+// parameter segments by position. Later requests with the same shape, for example
+// /users/1 and /users/2 for /users/{id}, are matched by the plan without traversing the tree
+// and without adding an entry per URL to the route cache.
+//
+// A plan is only learned if no other dynamic route of the method could match a path of the
+// plan with a higher precedence, so that plans never change which route handles a request.
+type patternCache struct {
+	mu      sync.RWMutex
+	plans   [8]map[planKey][]*matchPlan // Plans per method index, by shape
+	learned map[*node]struct{}          // Nodes for which learning has been attempted
+}
+
+// planKey groups plans by segment count and first literal segment ("" if the first segment is dynamic).
+type planKey struct {
+	segments int
+	first    string
+}
+
+// matchPlan is a compiled parameter-extraction plan for a route pattern.
+type matchPlan struct {
+	node     *node         // Matched node of the route (handler, route, and statistics)
+	segments []planSegment // Segments of the pattern by position
+	first    string        // First literal segment ("" if dynamic)
+}
+
+// planSegment is a segment of a match plan.
+type planSegment struct {
+	literal string // Literal value of a static segment
+	param   string // Parameter name of a dynamic segment ("" for static and anonymous segments)
+	dynamic bool   // Whether the segment is dynamic, including anonymous segments ({})
+	matcher *node  // Node that evaluates the regular expression or segment matcher (nil if unconstrained or static)
+}
+
+// newPatternCache creates an empty pattern cache.
+func newPatternCache() *patternCache {
+	return &patternCache{learned: make(map[*node]struct{})}
+}
+
+// reset drops all plans. It is called whenever routes are registered,
+// since new routes can make existing plans ambiguous.
+func (c *patternCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plans = [8]map[planKey][]*matchPlan{}
+	c.learned = make(map[*node]struct{})
+}
+
+// match matches the path against the learned plans of the method.
+// The params map is nil if the route has no parameters.
+func (c *patternCache) match(methodIndex uint8, path string) (*node, map[string]string, bool) {
+	segments := parseSegments(path)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	plans := c.plans[methodIndex-1]
+	if plans == nil {
+		return nil, nil, false
+	}
+	for _, key := range [2]planKey{{len(segments), segments[0]}, {len(segments), ""}} {
+		for _, plan := range plans[key] {
+			if params, ok := plan.extract(segments); ok {
+				return plan.node, params, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// extract returns the parameters of the segments if they match the plan.
+func (p *matchPlan) extract(segments []string) (map[string]string, bool) {
+	for i, seg := range p.segments {
+		switch {
+		case !seg.dynamic:
+			if segments[i] != seg.literal {
+				return nil, false
+			}
+		case seg.matcher != nil:
+			if !seg.matcher.accepts(segments[i]) {
+				return nil, false
+			}
+		}
+	}
+
+	var params map[string]string
+	for i, seg := range p.segments {
+		if seg.param != "" {
+			if params == nil {
+				params = make(map[string]string, len(p.segments)-i)
+			}
+			value := segments[i]
+			if seg.matcher != nil && seg.matcher.custom != nil {
+				// Segment matchers may normalize the value
+				value, _ = seg.matcher.custom.Match(value)
+			}
+			params[seg.param] = value
+		}
+	}
+	return params, true
+}
+
+// learn compiles a plan for the matched node of the tree, unless one has already been attempted.
+func (c *patternCache) learn(methodIndex uint8, root, matched *node) {
+	c.mu.RLock()
+	_, done := c.learned[matched]
+	c.mu.RUnlock()
+	if done {
+		return
+	}
+
+	plan, err := compilePlan(matched)
+	safe := err == nil
+	if safe {
+		for _, other := range terminalNodes(root) {
+			if other != matched && plan.overlaps(parseSegments(other.pattern)) {
+				safe = false
+				break
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, done := c.learned[matched]; done {
+		return
+	}
+	c.learned[matched] = struct{}{}
+	if !safe {
+		return
+	}
+	if c.plans[methodIndex-1] == nil {
+		c.plans[methodIndex-1] = make(map[planKey][]*matchPlan)
+	}
+	key := planKey{len(plan.segments), plan.first}
+	c.plans[methodIndex-1][key] = append(c.plans[methodIndex-1][key], plan)
+}
+
+// compilePlan compiles the match plan of the pattern of a node.
+// Registered patterns have valid regular expressions, so an error is only returned for catch-all
+// patterns, whose paths have no fixed number of segments and are always matched by the tree.
+func compilePlan(n *node) (*matchPlan, error) {
+	segments := parseSegments(n.pattern)
+	if isCatchAllSeg(segments[len(segments)-1]) {
+		return nil, &RouterError{Code: ErrInternalError, Message: "no match plan for catch-all pattern " + n.pattern}
+	}
+	plan := &matchPlan{node: n, segments: make([]planSegment, len(segments))}
+	for i, seg := range segments {
+		if !isDynamicSeg(seg) {
+			plan.segments[i] = planSegment{literal: seg}
+			continue
+		}
+		plan.segments[i].param = extractParamName(seg)
+		plan.segments[i].dynamic = true
+		if strings.IndexByte(seg, ':') > 0 {
+			matcher, err := newNode(seg)
+			if err != nil {
+				return nil, err
+			}
+			plan.segments[i].matcher = matcher
+		}
+	}
+	if !plan.segments[0].dynamic {
+		plan.first = plan.segments[0].literal
+	}
+	return plan, nil
+}
+
+// overlaps reports whether a path could match both the plan and the pattern segments.
+// Patterns with a different number of segments never match the same path, except that a
+// catch-all pattern matches paths with at least as many segments; otherwise the patterns are
+// disjoint only if some position has two different literals, or a literal that the regular
+// expression of the other pattern rejects.
+func (p *matchPlan) overlaps(segments []string) bool {
+	if last := len(segments) - 1; isCatchAllSeg(segments[last]) {
+		if len(p.segments) <= last {
+			return false
+		}
+		segments = segments[:last]
+	} else if len(segments) != len(p.segments) {
+		return false
+	}
+	for i, seg := range segments {
+		own := p.segments[i]
+		switch {
+		case !isDynamicSeg(seg) && !own.dynamic:
+			if seg != own.literal {
+				return false
+			}
+		case !isDynamicSeg(seg) && own.matcher != nil:
+			if !own.matcher.accepts(seg) {
+				return false
+			}
+		case isDynamicSeg(seg) && !own.dynamic && strings.IndexByte(seg, ':') > 0:
+			// A pattern that cannot be compiled is assumed to overlap
+			if other, err := newNode(seg); err == nil && !other.accepts(own.literal) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// terminalNodes returns all nodes of the tree that have a handler.
+func terminalNodes(root *node) []*node {
+	var nodes []*node
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n.handler != nil {
+			nodes = append(nodes, n)
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return nodes
+}
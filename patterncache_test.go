@@ -0,0 +1,114 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCacheByPattern tests matching dynamic routes by learned pattern plans
+func TestCacheByPattern(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.CacheByPattern = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	var source MatchOrigin
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		source = MatchSource(req.Context())
+		params := GetParams(req.Context())
+		for _, name := range []string{"id", "slug", "post"} {
+			if value, ok := params.Get(name); ok {
+				fmt.Fprintf(w, "%s=%s ", name, value)
+			}
+		}
+		return nil
+	}
+	r.Get("/users/{id}", handler)
+	r.Get("/users/{id}/posts/{post:[0-9]+}", handler)
+	r.Get("/api/{}/health", handler)
+	// Overlapping routes are always matched by the tree
+	r.Get("/items/{id:[0-9]+}", handler)
+	r.Get("/items/{slug}", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		source MatchOrigin
+		body   string
+	}{
+		{"/users/1", MatchFromDynamic, "id=1 "},
+		{"/users/2", MatchFromPattern, "id=2 "},
+		{"/users/1/posts/7", MatchFromDynamic, "id=1 post=7 "},
+		{"/users/3/posts/8", MatchFromPattern, "id=3 post=8 "},
+		{"/users/3/posts/x", MatchNone, "404 page not found\n"},
+		{"/api/v1/health", MatchFromDynamic, ""},
+		{"/api/v2/health", MatchFromPattern, ""},
+		{"/items/1", MatchFromDynamic, "slug=1 "},
+		{"/items/2", MatchFromDynamic, "slug=2 "},
+		{"/items/abc", MatchFromDynamic, "slug=abc "},
+	}
+	for _, tt := range tests {
+		source = MatchNone
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if source != tt.source {
+			t.Errorf("%s: expected source %v, got %v", tt.path, tt.source, source)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.path, tt.body, w.Body.String())
+		}
+	}
+
+	// Concrete URLs of dynamic routes are not cached
+	if _, _, _, found := r.cache.getRoute(generateRouteKey(methodToUint8(http.MethodGet), "/users/1")); found {
+		t.Error("Expected the URL not to be cached")
+	}
+
+	// Registering a route drops the learned plans
+	r.Get("/users/{id}/avatar", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/4", nil))
+	if source != MatchFromDynamic {
+		t.Errorf("Expected the plans to be relearned, got source %v", source)
+	}
+}
+
+// TestCacheByPatternCatchAll tests that catch-all routes are matched by the tree and keep
+// precedence over learned plans of longer paths
+func TestCacheByPatternCatchAll(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.CacheByPattern = true
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	respond := func(pattern string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			w.Write([]byte(pattern))
+			return nil
+		}
+	}
+	r.Get("/static/{*filepath}", respond("/static/{*filepath}"))
+	r.Get("/{a}/{b}/{c}", respond("/{a}/{b}/{c}"))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	for _, tt := range []struct{ path, pattern string }{
+		{"/x/y/z", "/{a}/{b}/{c}"},
+		{"/x/y/w", "/{a}/{b}/{c}"},
+		{"/static/css/site.css", "/static/{*filepath}"},
+		{"/static/js/app.js", "/static/{*filepath}"},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Body.String() != tt.pattern {
+			t.Errorf("%s: expected %s, got %s", tt.path, tt.pattern, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrPollTimeout is returned by Poll when the wait elapsed before the value was ready.
+// Long-poll handlers usually answer it with an empty response such as 204 No Content,
+// after which the client polls again.
+var ErrPollTimeout = errors.New("poll wait elapsed")
+
+// Intervals between the checks of Poll, which start short so that values that become ready
+// soon are delivered promptly and back off to bound the cost of long waits.
+const (
+	minPollInterval = 10 * time.Millisecond
+	maxPollInterval = 250 * time.Millisecond
+)
+
+// Poll waits up to wait for check to report a value as ready, and returns the value. It replaces
+// the ticker loops of long-poll handlers: check is called immediately and then at intervals
+// backing off from 10ms to 250ms, and must not block.
+//
+// The route timeout (see Route.WithTimeout and RouterOptions.RequestTimeout) is suspended while
+// Poll waits, so a poll can outlast it; the timeout resumes afterwards with the budget that was
+// left when the poll began. Other deadlines of ctx still apply.
+//
+// Poll returns ErrPollTimeout when the wait elapses or the router begins shutting down, so that
+// the poll does not delay the shutdown. It returns the error of ctx when the client disconnects
+// or ctx is otherwise done.
+//
+// 例: v, err := router.Poll(req.Context(), 30*time.Second, func() (any, bool) { return inbox.Next() })
+func Poll(ctx context.Context, wait time.Duration, check func() (any, bool)) (any, error) {
+	if tc, ok := ctx.Value(timeoutContextKey{}).(*timeoutContext); ok {
+		tc.suspend()
+		defer tc.resume()
+	}
+	active, _ := ctx.Value(activeRequestKey{}).(*activeRequest)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	interval := minPollInterval
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if value, ok := check(); ok {
+			return value, nil
+		}
+		if active != nil && active.router.shuttingDown.Load() {
+			return nil, ErrPollTimeout
+		}
+
+		tick := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			tick.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+			tick.Stop()
+			return nil, ErrPollTimeout
+		case <-tick.C:
+		}
+		interval = min(interval*2, maxPollInterval)
+	}
+}
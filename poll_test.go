@@ -0,0 +1,123 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPoll tests that Poll outlasts the route timeout and returns the value once it is ready
+func TestPoll(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var suspended, resumed atomic.Bool
+	r.Get("/events", func(w http.ResponseWriter, req *http.Request) error {
+		ready := time.Now().Add(80 * time.Millisecond)
+		value, err := Poll(req.Context(), time.Second, func() (any, bool) {
+			if _, ok := req.Context().Deadline(); !ok {
+				suspended.Store(true)
+			}
+			return "event", time.Now().After(ready)
+		})
+		if err != nil {
+			return err
+		}
+		_, ok := req.Context().Deadline()
+		resumed.Store(ok)
+		w.Write([]byte(value.(string)))
+		return nil
+	}).WithTimeout(30 * time.Millisecond)
+	r.Get("/idle", func(w http.ResponseWriter, req *http.Request) error {
+		_, err := Poll(req.Context(), 50*time.Millisecond, func() (any, bool) { return nil, false })
+		if !errors.Is(err, ErrPollTimeout) {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}).WithTimeout(30 * time.Millisecond)
+	r.Get("/slow", func(w http.ResponseWriter, req *http.Request) error {
+		if _, err := Poll(req.Context(), 10*time.Millisecond, func() (any, bool) { return nil, false }); !errors.Is(err, ErrPollTimeout) {
+			return err
+		}
+		// The remaining budget of the route timeout still applies after the poll
+		<-req.Context().Done()
+		return req.Context().Err()
+	}).WithTimeout(30 * time.Millisecond)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "event" {
+		t.Errorf("Expected the polled value, got %d %q", w.Code, w.Body.String())
+	}
+	if !suspended.Load() || !resumed.Load() {
+		t.Errorf("Expected the deadline to be suspended during the poll only (suspended %v, resumed %v)", suspended.Load(), resumed.Load())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/idle", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d after the wait elapsed, got %d", http.StatusNoContent, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	start := time.Now()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected the timeout response, got %d", w.Code)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected the poll not to count against the timeout, timed out after %v", elapsed)
+	}
+}
+
+// TestPollCancel tests that Poll ends when the client disconnects or the router shuts down
+func TestPollCancel(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	started := make(chan struct{}, 1)
+	results := make(chan error, 1)
+	r.Get("/events", func(w http.ResponseWriter, req *http.Request) error {
+		started <- struct{}{}
+		_, err := Poll(req.Context(), 5*time.Second, func() (any, bool) { return nil, false })
+		results <- err
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// Client disconnect
+	ctx, cancel := context.WithCancel(context.Background())
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx))
+	<-started
+	cancel()
+	select {
+	case err := <-results:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Poll did not end on client disconnect")
+	}
+
+	// Shutdown
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events", nil))
+	<-started
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Second)
+	defer cancelShutdown()
+	if err := r.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Expected the poll to end on shutdown, got %v", err)
+	}
+	if err := <-results; !errors.Is(err, ErrPollTimeout) {
+		t.Errorf("Expected ErrPollTimeout, got %v", err)
+	}
+}
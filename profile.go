@@ -0,0 +1,107 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Profile is a reusable bundle of route configuration: middleware, default response headers,
+// a timeout, and a Cache-Control policy. Applying the same profile to routes of the same kind
+// keeps their configuration consistent. Zero fields are not applied.
+type Profile struct {
+	Name         string           // Name of the profile (informational)
+	Middleware   []MiddlewareFunc // Middleware added to the route or group
+	Headers      http.Header      // Response headers set before the handler runs (the handler can override them)
+	Timeout      time.Duration    // Request timeout
+	CacheControl string           // Cache-Control of successful responses (see Route.WithCacheControl)
+}
+
+// ProfileAPI is the profile of JSON APIs: responses are revalidated by caches, and the
+// content type is not sniffed.
+var ProfileAPI = Profile{
+	Name:         "api",
+	Headers:      http.Header{"X-Content-Type-Options": {"nosniff"}},
+	Timeout:      30 * time.Second,
+	CacheControl: "no-cache",
+}
+
+// ProfileStaticAsset is the profile of fingerprinted static assets, which caches may keep for a year.
+var ProfileStaticAsset = Profile{
+	Name:         "static-asset",
+	Headers:      http.Header{"X-Content-Type-Options": {"nosniff"}},
+	Timeout:      time.Minute,
+	CacheControl: "public, max-age=31536000, immutable",
+}
+
+// ProfileSensitive is the profile of pages with personal or secret data: responses are never
+// stored, framed, or referred to, and requests time out quickly.
+var ProfileSensitive = Profile{
+	Name: "sensitive",
+	Headers: http.Header{
+		"X-Content-Type-Options": {"nosniff"},
+		"X-Frame-Options":        {"DENY"},
+		"Referrer-Policy":        {"no-referrer"},
+	},
+	Timeout:      10 * time.Second,
+	CacheControl: "no-store",
+}
+
+// WithProfile applies the profile to the route. Settings made explicitly on the route
+// after WithProfile take precedence.
+//
+// 例: r.Get("/account", showAccount).WithProfile(router.ProfileSensitive)
+func (r *Route) WithProfile(p Profile) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.middleware = append(r.middleware, p.middleware()...)
+	if p.Timeout > 0 {
+		r.timeout = p.Timeout
+	}
+	if p.CacheControl != "" {
+		r.cacheControl = p.CacheControl
+	}
+	return r
+}
+
+// WithProfile applies the profile to the routes of the group and its child groups.
+// Routes can override the timeout and Cache-Control policy of the profile.
+//
+// 例: api := r.Group("/api").WithProfile(router.ProfileAPI)
+func (g *Group) WithProfile(p Profile) *Group {
+	if middleware := p.middleware(); len(middleware) > 0 {
+		g.Use(middleware...)
+	}
+	if p.Timeout > 0 {
+		g.WithTimeout(p.Timeout)
+	}
+	if p.CacheControl != "" {
+		g.WithCacheControl(p.CacheControl)
+	}
+	return g
+}
+
+// middleware returns the middleware of the profile, preceded by middleware that sets its headers.
+func (p Profile) middleware() []MiddlewareFunc {
+	middleware := slices.Clone(p.Middleware)
+	if len(p.Headers) > 0 {
+		middleware = slices.Insert(middleware, 0, defaultHeaders(p.Headers.Clone()))
+	}
+	return middleware
+}
+
+// defaultHeaders returns middleware that sets response headers before the handler runs.
+func defaultHeaders(headers http.Header) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			h := w.Header()
+			for k, v := range headers {
+				h[k] = slices.Clone(v)
+			}
+			return next(w, r)
+		}
+	}
+}
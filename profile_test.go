@@ -0,0 +1,69 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestProfiles tests applying profiles to routes and groups
+func TestProfiles(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var calls []string
+	tag := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) error {
+				calls = append(calls, name)
+				return next(w, req)
+			}
+		}
+	}
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		if req.URL.Query().Get("frame") != "" {
+			w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+		}
+		w.Write([]byte("ok"))
+		return nil
+	}
+
+	audited := ProfileSensitive
+	audited.Middleware = []MiddlewareFunc{tag("audit")}
+	account := r.Get("/account", handler).WithProfile(audited)
+	api := r.Group("/api").WithProfile(ProfileAPI)
+	api.Get("/users", handler)
+	api.Get("/export", handler).WithCacheControl("private, max-age=60")
+	asset := r.Get("/assets/{file}", handler).WithProfile(ProfileStaticAsset).WithTimeout(5 * time.Second)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	if account.GetTimeout() != 10*time.Second || api.GetTimeout() != 30*time.Second || asset.GetTimeout() != 5*time.Second {
+		t.Errorf("Unexpected timeouts: %v, %v, %v", account.GetTimeout(), api.GetTimeout(), asset.GetTimeout())
+	}
+
+	tests := []struct {
+		url     string
+		headers map[string]string
+	}{
+		{"/account", map[string]string{"Cache-Control": "no-store", "X-Frame-Options": "DENY", "Referrer-Policy": "no-referrer"}},
+		{"/account?frame=1", map[string]string{"X-Frame-Options": "SAMEORIGIN"}},
+		{"/api/users", map[string]string{"Cache-Control": "no-cache", "X-Content-Type-Options": "nosniff", "X-Frame-Options": ""}},
+		{"/api/export", map[string]string{"Cache-Control": "private, max-age=60"}},
+		{"/assets/app.js", map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		for k, want := range tt.headers {
+			if got := w.Header().Get(k); got != want {
+				t.Errorf("%s: expected %s %q, got %q", tt.url, k, want, got)
+			}
+		}
+	}
+	if len(calls) != 2 || calls[0] != "audit" {
+		t.Errorf("Expected the profile middleware to run for /account, got %v", calls)
+	}
+}
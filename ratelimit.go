@@ -0,0 +1,63 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitInfo is the throttling state of a client, sent with the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers of the IETF RateLimit header fields draft,
+// so that clients can pace themselves before they are rejected with 429 Too Many Requests.
+type RateLimitInfo struct {
+	Limit     int           // Requests allowed in the current window
+	Remaining int           // Requests left in the current window
+	Reset     time.Duration // Time until the window resets
+}
+
+// SetHeaders sets the RateLimit headers of the response. The reset is rounded up to whole seconds.
+// Rate limiting middleware calls it for every response, including the 429 responses.
+func (i RateLimitInfo) SetHeaders(h http.Header) {
+	reset := (i.Reset + time.Second - 1) / time.Second
+	h.Set("RateLimit-Limit", strconv.Itoa(max(i.Limit, 0)))
+	h.Set("RateLimit-Remaining", strconv.Itoa(max(i.Remaining, 0)))
+	h.Set("RateLimit-Reset", strconv.FormatInt(int64(max(reset, 0)), 10))
+}
+
+// ParseRateLimit reads the throttling state an upstream service reported in its response
+// headers, so that routes forwarding to it can relay consistent limits to their clients
+// (see RouteOptions.UpstreamRateLimit).
+// It reads the RateLimit headers of the draft and falls back to the common X-RateLimit-*
+// headers; it returns false if the limit or the remaining count is missing or invalid.
+// A missing reset is zero. Resets in the X-RateLimit-Reset header that look like Unix
+// timestamps are converted to a duration from now.
+func ParseRateLimit(h http.Header) (RateLimitInfo, bool) {
+	for _, prefix := range []string{"RateLimit-", "X-RateLimit-"} {
+		limit, err1 := strconv.Atoi(firstListValue(h.Get(prefix + "Limit")))
+		remaining, err2 := strconv.Atoi(firstListValue(h.Get(prefix + "Remaining")))
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		info := RateLimitInfo{Limit: limit, Remaining: remaining}
+		if reset, err := strconv.ParseInt(firstListValue(h.Get(prefix+"Reset")), 10, 64); err == nil && reset > 0 {
+			if now := time.Now().Unix(); reset > now {
+				// Values larger than the current Unix time are timestamps, not delays
+				info.Reset = time.Duration(reset-now) * time.Second
+			} else {
+				info.Reset = time.Duration(reset) * time.Second
+			}
+		}
+		return info, true
+	}
+	return RateLimitInfo{}, false
+}
+
+// firstListValue returns the first element of a header value with parameters or a list,
+// such as "100, 100;w=60", which some implementations of the draft send.
+func firstListValue(value string) string {
+	if i := strings.IndexAny(value, ",;"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
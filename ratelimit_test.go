@@ -0,0 +1,42 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestRateLimitHeaders tests writing and reading the RateLimit headers
+func TestRateLimitHeaders(t *testing.T) {
+	h := http.Header{}
+	RateLimitInfo{Limit: 100, Remaining: -1, Reset: 1500 * time.Millisecond}.SetHeaders(h)
+	if h.Get("RateLimit-Limit") != "100" || h.Get("RateLimit-Remaining") != "0" || h.Get("RateLimit-Reset") != "2" {
+		t.Errorf("Unexpected headers %v", h)
+	}
+
+	tests := []struct {
+		header http.Header
+		want   RateLimitInfo
+		ok     bool
+	}{
+		{h, RateLimitInfo{Limit: 100, Remaining: 0, Reset: 2 * time.Second}, true},
+		{http.Header{"Ratelimit-Limit": {"100, 100;w=60"}, "Ratelimit-Remaining": {"7"}}, RateLimitInfo{Limit: 100, Remaining: 7}, true},
+		{http.Header{"X-Ratelimit-Limit": {"60"}, "X-Ratelimit-Remaining": {"59"}, "X-Ratelimit-Reset": {"30"}}, RateLimitInfo{Limit: 60, Remaining: 59, Reset: 30 * time.Second}, true},
+		{http.Header{"X-Ratelimit-Limit": {"60"}}, RateLimitInfo{}, false},
+		{http.Header{}, RateLimitInfo{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRateLimit(tt.header)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%v: expected %+v %v, got %+v %v", tt.header, tt.want, tt.ok, got, ok)
+		}
+	}
+
+	// Unix timestamps are converted to a delay
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	got, _ := ParseRateLimit(http.Header{"X-Ratelimit-Limit": {"60"}, "X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}})
+	if got.Reset < 58*time.Second || got.Reset > time.Minute {
+		t.Errorf("Expected a reset of about a minute, got %v", got.Reset)
+	}
+}
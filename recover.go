@@ -0,0 +1,69 @@
+package router
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// panicError is the error a panic in the middleware chain or the handler is converted to.
+type panicError struct {
+	value any    // Value passed to panic
+	stack []byte // Stack trace of the panicking goroutine
+}
+
+// Error returns the panic value as an error message.
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// callRecovering calls the handler chain and converts a panic into a panicError, so that a panic
+// in any middleware or the handler is answered like an error instead of crashing the request.
+// http.ErrAbortHandler is re-raised, since it deliberately aborts the response.
+func callRecovering(h HandlerFunc, w http.ResponseWriter, req *http.Request) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			err = &panicError{value: v, stack: debug.Stack()}
+		}
+	}()
+	return h(w, req)
+}
+
+// SetPanicHandler sets the handler for panics in middleware and route handlers.
+// It receives the value passed to panic and is called only if the response has not been written.
+// Without a panic handler, a panic is handled like an error returned by the handler: the error
+// handler of the route or group, the 500 error page, or the router's error handler renders it,
+// which responds with 500 Internal Server Error by default.
+//
+// Panics are always logged with their stack trace. If the handler had already started writing the
+// response, the connection is aborted instead, so that the client does not mistake the truncated
+// response for a complete one.
+//
+// 例: r.SetPanicHandler(func(w http.ResponseWriter, r *http.Request, v any) { http.Error(w, "oops", 500) })
+func (r *Router) SetPanicHandler(h func(http.ResponseWriter, *http.Request, any)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.panicHandler = h
+}
+
+// getPanicHandler returns the panic handler of the router, or nil if none is set.
+func (r *Router) getPanicHandler() func(http.ResponseWriter, *http.Request, any) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.panicHandler
+}
+
+// recoverTimeoutHandler recovers a panic in the timeout handler, which runs on its own goroutine
+// where an unrecovered panic would terminate the process. It must be called with defer.
+func (r *Router) recoverTimeoutHandler(w *responseWriter, req *http.Request) {
+	if v := recover(); v != nil {
+		r.logf(slog.LevelError, "Timeout handler panic: %s %s: %v\n%s", req.Method, req.URL.Path, v, debug.Stack())
+		if !w.written.Load() {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	}
+}
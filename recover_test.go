@@ -0,0 +1,137 @@
+package router
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// panicking returns middleware that panics for requests with the given path.
+func panicking(path string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			if req.URL.Path == path {
+				panic("boom at " + path)
+			}
+			return next(w, req)
+		}
+	}
+}
+
+// TestPanicRecovery tests that panics at each layer of the middleware chain are recovered
+func TestPanicRecovery(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	r := NewRouter()
+	defer r.cache.stop()
+
+	ok := func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("ok"))
+		return nil
+	}
+	r.Use(panicking("/global"))
+	r.Get("/global", ok)
+	r.Get("/route", ok).WithMiddleware(panicking("/route"))
+	r.Get("/handler", func(w http.ResponseWriter, req *http.Request) error {
+		panic("boom at /handler")
+	})
+	g := r.Group("/group", panicking("/group/items"))
+	g.Get("/items", ok)
+	g.Get("/other", ok)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	for _, path := range []string{"/global", "/route", "/handler", "/group/items"} {
+		logs.Reset()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("%s: expected status %d, got %d", path, http.StatusInternalServerError, w.Code)
+		}
+		if !strings.Contains(logs.String(), "boom at "+path) || !strings.Contains(logs.String(), "goroutine") {
+			t.Errorf("%s: expected the panic to be logged with its stack, got %q", path, logs.String())
+		}
+	}
+
+	// Requests that do not panic are unaffected
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/group/other", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("Expected a regular response, got %d %q", w.Code, w.Body.String())
+	}
+
+	// The panic handler receives the panic value
+	var recovered any
+	r.SetPanicHandler(func(w http.ResponseWriter, req *http.Request, v any) {
+		recovered = v
+		http.Error(w, "recovered", http.StatusServiceUnavailable)
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/route", nil))
+	if w.Code != http.StatusServiceUnavailable || recovered != "boom at /route" {
+		t.Errorf("Expected the panic handler to respond, got %d (recovered %v)", w.Code, recovered)
+	}
+}
+
+// TestPanicAfterWrite tests that a panic after the response was started aborts the connection
+func TestPanicAfterWrite(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Get("/stream", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("partial"))
+		panic("boom")
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler, got %v", v)
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+}
+
+// TestTimeoutHandlerPanic tests that a panic in the timeout handler goroutine is recovered
+func TestTimeoutHandlerPanic(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.SetTimeoutHandler(func(w http.ResponseWriter, req *http.Request) {
+		panic("timeout handler boom")
+	})
+	r.Get("/slow", func(w http.ResponseWriter, req *http.Request) error {
+		<-req.Context().Done()
+		return req.Context().Err()
+	}).WithTimeout(20 * time.Millisecond)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// ServeHTTP waits for the timeout goroutine, so the response is complete when it returns
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if !strings.Contains(logs.String(), "Timeout handler panic") {
+		t.Errorf("Expected the panic to be logged, got %q", logs.String())
+	}
+}
@@ -0,0 +1,121 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redirectMetaKey is the metadata key under which redirect routes record their target.
+const redirectMetaKey = "router.redirect"
+
+// movedMethods are the methods redirected by Router.Moved.
+var movedMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// RedirectInfo describes a redirect route registered with Router.Moved.
+type RedirectInfo struct {
+	Target    string // Target pattern, with parameters filled in from the request path
+	Permanent bool   // Whether the move is permanent
+}
+
+// Moved registers redirects from pattern to newPattern for URL migrations.
+// Parameters of newPattern are filled in with the values captured by pattern, so every parameter
+// of newPattern must also appear in pattern (regex constraints are only needed on pattern).
+// The query string of the request is kept.
+//
+// Permanent moves answer GET and HEAD requests with 301 Moved Permanently and other methods with
+// 308 Permanent Redirect; temporary moves use 302 Found and 307 Temporary Redirect.
+// Like Route, the redirects are registered when Build is called. They can be recognized through
+// Route.Redirect or RouteMeta with the "router.redirect" key.
+//
+// 例: r.Moved("/users/{id:[0-9]+}/profile", "/members/{id}", true)
+func (r *Router) Moved(pattern, newPattern string, permanent bool) error {
+	pattern, newPattern = normalizePath(pattern), normalizePath(newPattern)
+	if err := validatePatternWith(pattern, r.segmentChars); err != nil {
+		return err
+	}
+	if err := validatePatternWith(newPattern, r.segmentChars); err != nil {
+		return err
+	}
+
+	// Every target parameter must be captured by the source pattern
+	segments := parseSegments(pattern)
+	captured := make(map[string]struct{})
+	for _, seg := range segments {
+		if isDynamicSeg(seg) {
+			captured[extractParamName(seg)] = struct{}{}
+		}
+	}
+	for _, seg := range parseSegments(newPattern) {
+		if !isDynamicSeg(seg) {
+			continue
+		}
+		name := extractParamName(seg)
+		if _, ok := captured[name]; !ok || name == "" {
+			return &RouterError{Code: ErrInvalidPattern, Message: "redirect target parameter not in source pattern: " + seg}
+		}
+	}
+
+	info := RedirectInfo{Target: newPattern, Permanent: permanent}
+	h := redirectHandler(info)
+
+	for _, method := range movedMethods {
+		r.Route(method, pattern, h).WithMeta(redirectMetaKey, info)
+	}
+	return nil
+}
+
+// Redirect returns the redirect of a route registered with Router.Moved.
+func (r *Route) Redirect() (RedirectInfo, bool) {
+	v, ok := r.Meta(redirectMetaKey)
+	if !ok {
+		return RedirectInfo{}, false
+	}
+	info, ok := v.(RedirectInfo)
+	return info, ok
+}
+
+// redirectHandler returns a handler that redirects to the target with the request parameters filled in.
+func redirectHandler(info RedirectInfo) HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		params := GetParams(req.Context())
+		segments := parseSegments(info.Target)
+		for i, seg := range segments {
+			if !isDynamicSeg(seg) {
+				continue
+			}
+			value, _ := params.Get(extractParamName(seg))
+			if isCatchAllSeg(seg) {
+				// The rest of the path keeps its slashes
+				segments[i] = (&url.URL{Path: value}).EscapedPath()
+				continue
+			}
+			segments[i] = url.PathEscape(value)
+		}
+
+		location := "/" + strings.Join(segments, "/")
+		if req.URL.RawQuery != "" {
+			location += "?" + req.URL.RawQuery
+		}
+		http.Redirect(w, req, location, redirectStatus(req.Method, info.Permanent))
+		return nil
+	}
+}
+
+// redirectStatus returns the redirect status code for the method.
+// Methods other than GET and HEAD get a status that preserves the method and body.
+func redirectStatus(method string, permanent bool) int {
+	safe := method == http.MethodGet || method == http.MethodHead
+	switch {
+	case permanent && safe:
+		return http.StatusMovedPermanently
+	case permanent:
+		return http.StatusPermanentRedirect
+	case safe:
+		return http.StatusFound
+	default:
+		return http.StatusTemporaryRedirect
+	}
+}
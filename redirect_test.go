@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestMoved tests redirects registered for moved routes
+func TestMoved(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	if err := r.Moved("/users/{id:[0-9]+}/profile", "/members/{id}", true); err != nil {
+		t.Fatalf("Failed to register redirect: %v", err)
+	}
+	if err := r.Moved("/old", "/new", false); err != nil {
+		t.Fatalf("Failed to register redirect: %v", err)
+	}
+	if err := r.Moved("/teams/{team}", "/orgs/{org}", true); err == nil {
+		t.Error("Expected an error for a target parameter missing from the source")
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method, url string
+		status      int
+		location    string
+	}{
+		{http.MethodGet, "/users/42/profile?tab=1", http.StatusMovedPermanently, "/members/42?tab=1"},
+		{http.MethodPost, "/users/42/profile", http.StatusPermanentRedirect, "/members/42"},
+		{http.MethodGet, "/old", http.StatusFound, "/new"},
+		{http.MethodPut, "/old", http.StatusTemporaryRedirect, "/new"},
+		{http.MethodGet, "/users/abc/profile", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.url, tt.status, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("%s %s: expected Location %q, got %q", tt.method, tt.url, tt.location, got)
+		}
+	}
+
+	// Redirect routes can be recognized by introspection
+	info, ok := r.routes[0].Redirect()
+	if !ok || info.Target != "/members/{id}" || !info.Permanent {
+		t.Errorf("Unexpected redirect info %+v (found %v)", info, ok)
+	}
+}
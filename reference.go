@@ -0,0 +1,181 @@
+package router
+
+import "strings"
+
+// referenceMatcher is a deliberately simple route matcher used to verify the optimized matchers.
+// It keeps the registered routes in a flat list and evaluates every route for every path,
+// so it is slow but easy to reason about. It is used for differential fuzzing and verification.
+//
+// The precedence rules are the same as the Radix tree:
+// at each segment position static segments win over parameters, and parameters win over
+// regular expressions, which win over catch-all segments; among siblings of the same kind, the one with the highest priority wins,
+// and the one registered first wins among siblings with the same priority.
+type referenceMatcher struct {
+	routes []referenceRoute
+}
+
+// referenceRoute is a single route registered with the referenceMatcher.
+type referenceRoute struct {
+	pattern  string
+	priority int     // Route priority (0 means none)
+	segments []*node // Parsed segments (only segment, segmentType, regex, and custom are used)
+}
+
+// add registers a route pattern. The pattern must already be accepted by the Radix tree.
+func (m *referenceMatcher) add(pattern string, priority int) error {
+	segments := parseSegments(normalizePath(pattern))
+	route := referenceRoute{
+		pattern:  "/" + strings.Join(segments, "/"),
+		priority: priority,
+		segments: make([]*node, len(segments)),
+	}
+	for i, seg := range segments {
+		n := &node{segment: seg}
+		if err := n.parseSegment(); err != nil {
+			return err
+		}
+		route.segments[i] = n
+	}
+	m.routes = append(m.routes, route)
+	return nil
+}
+
+// match returns the pattern of the route that should handle the path, together with its parameters.
+func (m *referenceMatcher) match(path string) (string, *Params, bool) {
+	pathSegments := splitMatchPath(path)
+
+	best := -1
+	var bestRank []int
+	for i, route := range m.routes {
+		if !route.matches(pathSegments) {
+			continue
+		}
+		rank := m.rank(i)
+		if best < 0 || compareRank(rank, bestRank) < 0 {
+			best, bestRank = i, rank
+		}
+	}
+	if best < 0 {
+		return "", nil, false
+	}
+
+	route := m.routes[best]
+	params := NewParams()
+	for i, seg := range route.segments {
+		if seg.segmentType != staticSegment {
+			value := pathSegments[i]
+			switch seg.segmentType {
+			case matcherSegment:
+				value, _ = seg.custom.Match(value)
+			case catchAllSegment:
+				value = strings.Join(pathSegments[i:], "/")
+			}
+			if name := extractParamName(seg.segment); name != "" {
+				params.Add(name, value)
+			}
+		}
+	}
+	return route.pattern, params, true
+}
+
+// matches reports whether every segment of the route matches the path segments.
+func (r referenceRoute) matches(pathSegments []string) bool {
+	// A catch-all segment matches one or more remaining segments
+	last := r.segments[len(r.segments)-1]
+	if last.segmentType == catchAllSegment {
+		if len(pathSegments) < len(r.segments) {
+			return false
+		}
+	} else if len(r.segments) != len(pathSegments) {
+		return false
+	}
+	for i, seg := range r.segments {
+		switch seg.segmentType {
+		case staticSegment:
+			if seg.segment != pathSegments[i] {
+				return false
+			}
+		case regexSegment:
+			if !seg.regex.MatchString(pathSegments[i]) {
+				return false
+			}
+		case matcherSegment:
+			if _, ok := seg.custom.Match(pathSegments[i]); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rank computes the precedence of a route as a list of (segment type, -priority, sibling order) tuples.
+// The priority of a dynamic segment is the highest priority of the routes that share the same prefix.
+// The sibling order of a segment is the index of the first route that shares the same prefix,
+// which corresponds to the order in which the Radix tree created the node.
+func (m *referenceMatcher) rank(index int) []int {
+	route := m.routes[index]
+	rank := make([]int, 0, len(route.segments)*3)
+	for i, seg := range route.segments {
+		first := -1
+		priority, prioritySet := 0, false
+		for j := range m.routes {
+			if !hasSegmentPrefix(m.routes[j], route, i+1) {
+				continue
+			}
+			if first < 0 {
+				first = j
+			}
+			if p := m.routes[j].priority; p != 0 && (!prioritySet || p > priority) {
+				priority, prioritySet = p, true
+			}
+		}
+		if seg.segmentType == staticSegment {
+			priority = 0
+		}
+		rank = append(rank, int(seg.segmentType), -priority, first)
+	}
+	return rank
+}
+
+// hasSegmentPrefix reports whether both routes have the same first n segments.
+func hasSegmentPrefix(a, b referenceRoute, n int) bool {
+	if len(a.segments) < n || len(b.segments) < n {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if a.segments[i].segment != b.segments[i].segment {
+			return false
+		}
+	}
+	return true
+}
+
+// compareRank compares two ranks lexicographically.
+func compareRank(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+// splitMatchPath splits a request path into segments in the same way as node.match walks it:
+// one leading slash is removed per segment and a trailing "/" terminates the path.
+func splitMatchPath(path string) []string {
+	var segments []string
+	for path != "" && path != "/" {
+		if path[0] == '/' {
+			path = path[1:]
+		}
+		slashIndex := strings.IndexByte(path, '/')
+		if slashIndex == -1 {
+			segments = append(segments, path)
+			path = ""
+		} else {
+			segments = append(segments, path[:slashIndex])
+			path = path[slashIndex:]
+		}
+	}
+	return segments
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"regexp/syntax"
+	"strconv"
+)
+
+// maxRegexProgramSize is the maximum number of instructions a compiled segment regex may have.
+// Go's regexp package (RE2) runs in linear time, so nested quantifiers cannot cause
+// catastrophic backtracking, but very large programs (e.g. large counted repetitions)
+// still make every evaluation expensive.
+const maxRegexProgramSize = 1000
+
+// analyzeRegex checks a segment regular expression for constructs that are expensive to evaluate.
+// Syntax errors are not reported here; they are reported when the segment is compiled.
+func analyzeRegex(expr string) error {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil
+	}
+
+	if len(prog.Inst) > maxRegexProgramSize {
+		return &RouterError{
+			Code: ErrInvalidPattern,
+			Message: "regex pattern is too complex: " + expr + " (" + strconv.Itoa(len(prog.Inst)) +
+				" instructions, limit " + strconv.Itoa(maxRegexProgramSize) + ")",
+		}
+	}
+	return nil
+}
+
+// byteClassMatcher is a hand-rolled matcher for simple regular expressions
+// such as \d+, [a-z]+, or [A-Za-z0-9_-]* that consist of a single ASCII character class
+// repeated one or more (or zero or more) times.
+type byteClassMatcher struct {
+	allowed    [256]bool // Characters accepted by the class
+	allowEmpty bool      // Whether the empty string matches (the * quantifier)
+}
+
+// match reports whether every byte of s belongs to the character class.
+func (m *byteClassMatcher) match(s string) bool {
+	if len(s) == 0 {
+		return m.allowEmpty
+	}
+	for i := 0; i < len(s); i++ {
+		if !m.allowed[s[i]] {
+			return false
+		}
+	}
+	return true
+}
+
+// newByteClassMatcher returns a byteClassMatcher equivalent to the regular expression,
+// or nil if the expression is not a simple repeated ASCII character class.
+func newByteClassMatcher(expr string) *byteClassMatcher {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	re = re.Simplify()
+
+	if (re.Op != syntax.OpPlus && re.Op != syntax.OpStar) || len(re.Sub) != 1 {
+		return nil
+	}
+
+	class := re.Sub[0]
+	m := &byteClassMatcher{allowEmpty: re.Op == syntax.OpStar}
+	switch class.Op {
+	case syntax.OpCharClass:
+		// Rune holds pairs of inclusive ranges
+		for i := 0; i+1 < len(class.Rune); i += 2 {
+			lo, hi := class.Rune[i], class.Rune[i+1]
+			if lo > 0x7f || hi > 0x7f {
+				return nil // Only ASCII classes can be matched byte by byte
+			}
+			for c := lo; c <= hi; c++ {
+				m.allowed[c] = true
+			}
+		}
+	case syntax.OpLiteral:
+		if len(class.Rune) != 1 || class.Rune[0] > 0x7f || class.Flags&syntax.FoldCase != 0 {
+			return nil
+		}
+		m.allowed[class.Rune[0]] = true
+	default:
+		return nil
+	}
+	return m
+}
+
+// matchRegex evaluates the regular expression of a regex segment.
+// The hand-rolled matcher is used when the expression is simple enough.
+func (n *node) matchRegex(segment string) bool {
+	if n.classMatcher != nil {
+		return n.classMatcher.match(segment)
+	}
+	return n.regex.MatchString(segment)
+}
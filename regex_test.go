@@ -0,0 +1,91 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestByteClassMatcher tests that hand-rolled matchers agree with the regexp engine
+func TestByteClassMatcher(t *testing.T) {
+	exprs := []string{`\d+`, `[0-9]+`, `[a-z]+`, `[A-Za-z0-9_-]*`, `\w+`}
+	inputs := []string{"", "123", "abc", "ABC", "a-b_c", "12a", "日本", "a/b", "\xff"}
+
+	for _, expr := range exprs {
+		m := newByteClassMatcher(expr)
+		if m == nil {
+			t.Errorf("Expected a hand-rolled matcher for %s", expr)
+			continue
+		}
+		re := regexp.MustCompile("^" + expr + "$")
+		for _, input := range inputs {
+			if got, want := m.match(input), re.MatchString(input); got != want {
+				t.Errorf("match(%q) for %s = %v, want %v", input, expr, got, want)
+			}
+		}
+	}
+
+	// Expressions that are not a single repeated ASCII class fall back to the regexp engine
+	for _, expr := range []string{`[a-z]+-[0-9]+`, `\d{4}`, `[^/]+`, `(foo|bar)`, `(?i)[a-z]+`} {
+		if newByteClassMatcher(expr) != nil {
+			t.Errorf("Expected no hand-rolled matcher for %s", expr)
+		}
+	}
+}
+
+// TestAnalyzeRegex tests that overly complex regular expressions are rejected
+func TestAnalyzeRegex(t *testing.T) {
+	if err := analyzeRegex(`([a-z]+-)*[a-z]+`); err != nil {
+		t.Errorf("Nested quantifiers should be accepted (RE2 runs in linear time): %v", err)
+	}
+
+	err := validatePattern("/items/{id:\\w{600}\\d{600}}")
+	if err == nil {
+		t.Fatal("Expected an error for an overly complex regex")
+	}
+	if routerErr, ok := err.(*RouterError); !ok || routerErr.Code != ErrInvalidPattern {
+		t.Errorf("Not the expected error: %v", err)
+	}
+}
+
+// TestRegexEvaluationBudget tests that matching stops with 404 when the budget is exhausted
+func TestRegexEvaluationBudget(t *testing.T) {
+	r := NewRouterWithOptions(RouterOptions{MaxRegexEvaluations: 2})
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	for _, pattern := range []string{"/a/{x:[0-9]+}", "/a/{x:[a-f]+}", "/a/{x:[g-z]+}"} {
+		if err := r.Handle(http.MethodGet, pattern, handler); err != nil {
+			t.Fatalf("Failed to register %s: %v", pattern, err)
+		}
+	}
+
+	testCases := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/a/123", http.StatusNotFound}, // All three regex children are evaluated before matching
+		{"/a/" + strings.Repeat("z", 3), http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.expectedStatus {
+			t.Errorf("Expected status %d for path %s, got %d", tc.expectedStatus, tc.path, w.Code)
+		}
+	}
+
+	// Without a budget the same paths match
+	unlimited := NewRouter()
+	defer unlimited.cache.stop()
+	for _, pattern := range []string{"/a/{x:[0-9]+}", "/a/{x:[a-f]+}", "/a/{x:[g-z]+}"} {
+		if err := unlimited.Handle(http.MethodGet, pattern, handler); err != nil {
+			t.Fatalf("Failed to register %s: %v", pattern, err)
+		}
+	}
+	if _, ok := unlimited.Match(http.MethodGet, "/a/zzz"); !ok {
+		t.Error("Expected /a/zzz to match without a budget")
+	}
+}
@@ -0,0 +1,151 @@
+package router
+
+import (
+	"slices"
+	"time"
+)
+
+// RouterReport is a machine-readable description of the router configuration.
+// It encodes to JSON, so it can be stored as a build artifact or compared in tests.
+type RouterReport struct {
+	Build          BuildReport    `json:"build"`
+	Timeout        time.Duration  `json:"timeout"`      // Default request timeout (0 means none)
+	ErrorHandler   string         `json:"errorHandler"` // Type of the router's error handler
+	Middleware     int            `json:"middleware"`   // Number of global middleware
+	Cache          CacheReport    `json:"cache"`
+	RoutesByMethod map[string]int `json:"routesByMethod"` // Number of route definitions per method
+	Routes         []RouteReport  `json:"routes"`         // Routes registered directly on the router
+	Groups         []GroupReport  `json:"groups"`
+}
+
+// BuildReport describes the outcome of the last Build.
+type BuildReport struct {
+	Built bool      `json:"built"`           // Whether the last Build succeeded
+	Error string    `json:"error,omitempty"` // Error of the last Build
+	Time  time.Time `json:"time"`            // Time of the last Build (zero if never built)
+}
+
+// CacheReport describes the route cache configuration.
+type CacheReport struct {
+	MaxEntries int  `json:"maxEntries"`
+	ByPattern  bool `json:"byPattern"` // Dynamic matches are cached by pattern (see RouterOptions.CacheByPattern)
+	Disabled   bool `json:"disabled"`  // The cache is bypassed in development mode
+	Custom     bool `json:"custom"`    // The cache is created by RouterOptions.NewRouteCache
+
+	Stats CacheStats `json:"stats"` // Usage counters of the cache
+}
+
+// GroupReport describes a group and its routes.
+type GroupReport struct {
+	Prefix             string        `json:"prefix"`
+	Timeout            time.Duration `json:"timeout"`
+	TimeoutSource      string        `json:"timeoutSource"` // "override" or "inherited"
+	ErrorHandler       string        `json:"errorHandler"`
+	ErrorHandlerSource string        `json:"errorHandlerSource"` // "override" or "inherited"
+	Middleware         int           `json:"middleware"`         // Number of middleware, including inherited group middleware
+	Routes             []RouteReport `json:"routes"`
+	Groups             []GroupReport `json:"groups,omitempty"`
+}
+
+// RouteReport describes a route.
+type RouteReport struct {
+	Method             string        `json:"method"`
+	Pattern            string        `json:"pattern"` // Full pattern, including the group prefix
+	Name               string        `json:"name,omitempty"`
+	Timeout            time.Duration `json:"timeout"`
+	TimeoutSource      string        `json:"timeoutSource"` // "override" or "inherited"
+	ErrorHandler       string        `json:"errorHandler"`
+	ErrorHandlerSource string        `json:"errorHandlerSource"` // "override" or "inherited"
+	Middleware         int           `json:"middleware"`         // Number of route-specific middleware
+	Consumes           []string      `json:"consumes,omitempty"` // Accepted request body media types
+	Produces           []string      `json:"produces,omitempty"` // Response media types
+}
+
+// Report returns the configuration of the router as a single structured document: timeouts,
+// error handlers, middleware counts, route counts per method, cache configuration, and build status.
+// It replaces TimeoutSettings and ErrorHandlerSettings with a machine-readable structure.
+//
+// 例: json.NewEncoder(os.Stdout).Encode(r.Report())
+func (r *Router) Report() RouterReport {
+	report := RouterReport{
+		Timeout:      r.GetRequestTimeout(),
+		ErrorHandler: handlerToString(r.GetErrorHandler()),
+		Middleware:   len(r.middleware.Load().([]MiddlewareFunc)),
+		Cache: CacheReport{
+			MaxEntries: r.cacheMaxEntries,
+			ByPattern:  r.patternCache != nil,
+			Disabled:   r.devMode,
+			Custom:     r.newRouteCache != nil,
+			Stats:      r.routeCache.Stats(),
+		},
+		RoutesByMethod: make(map[string]int),
+	}
+
+	// Snapshot the definitions; the reports read settings that take the lock themselves
+	r.mu.RLock()
+	report.Build = r.buildStatus
+	routes := slices.Clone(r.routes)
+	handled := slices.Clone(r.handled)
+	groups := slices.Clone(r.groups)
+	r.mu.RUnlock()
+
+	for _, route := range routes {
+		report.Routes = append(report.Routes, routeReport(route))
+		report.RoutesByMethod[route.method]++
+	}
+	for _, h := range handled {
+		report.RoutesByMethod[h.method]++
+	}
+	for _, group := range groups {
+		report.Groups = append(report.Groups, groupReport(group, report.RoutesByMethod))
+	}
+	return report
+}
+
+// groupReport describes the group and its child groups, and counts their routes per method.
+func groupReport(g *Group, routesByMethod map[string]int) GroupReport {
+	g.mwMu.Lock()
+	middleware := len(g.middleware)
+	g.mwMu.Unlock()
+
+	report := GroupReport{
+		Prefix:             g.prefix,
+		Timeout:            g.GetTimeout(),
+		TimeoutSource:      settingSource(g.timeout > 0),
+		ErrorHandler:       handlerToString(g.GetErrorHandler()),
+		ErrorHandlerSource: settingSource(g.errorHandler != nil),
+		Middleware:         middleware,
+	}
+	for _, route := range slices.Concat(g.routes, g.handled) {
+		report.Routes = append(report.Routes, routeReport(route))
+		routesByMethod[route.method]++
+	}
+	for _, child := range g.children {
+		report.Groups = append(report.Groups, groupReport(child, routesByMethod))
+	}
+	return report
+}
+
+// routeReport describes the route.
+func routeReport(route *Route) RouteReport {
+	return RouteReport{
+		Method:             route.method,
+		Pattern:            route.fullPath(),
+		Name:               route.name,
+		Timeout:            route.GetTimeout(),
+		TimeoutSource:      settingSource(route.timeout > 0),
+		ErrorHandler:       handlerToString(route.GetErrorHandler()),
+		ErrorHandlerSource: settingSource(route.errorHandler != nil),
+		Middleware:         len(route.middleware),
+		Consumes:           route.GetConsumes(),
+		Produces:           route.GetProduces(),
+	}
+}
+
+// settingSource describes whether a setting is set on the object itself.
+func settingSource(override bool) string {
+	if override {
+		return "override"
+	}
+	return "inherited"
+}
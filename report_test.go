@@ -0,0 +1,84 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestReport tests the structured configuration report
+func TestReport(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	mw := func(next HandlerFunc) HandlerFunc { return next }
+	r.SetRequestTimeout(5 * time.Second)
+	r.Use(mw)
+	r.Get("/health", handler)
+	r.Post("/users/{id}", handler).WithTimeout(time.Second)
+
+	api := r.Group("/api", mw)
+	api.Get("/items/{id}", handler, mw)
+	admin := api.Group("/admin", mw).WithErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {})
+	admin.Get("/stats", handler)
+
+	if report := r.Report(); report.Build.Built || !report.Build.Time.IsZero() {
+		t.Errorf("Expected no build before Build, got %+v", report.Build)
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	report := r.Report()
+	if !report.Build.Built || report.Build.Time.IsZero() {
+		t.Errorf("Expected a successful build, got %+v", report.Build)
+	}
+	if report.Timeout != 5*time.Second || report.Middleware != 1 || report.Cache.MaxEntries != defaultCacheMaxEntries {
+		t.Errorf("Unexpected router settings: %+v", report)
+	}
+	if report.RoutesByMethod[http.MethodGet] != 3 || report.RoutesByMethod[http.MethodPost] != 1 {
+		t.Errorf("Unexpected route counts: %v", report.RoutesByMethod)
+	}
+
+	if len(report.Routes) != 2 {
+		t.Fatalf("Expected 2 direct routes, got %d", len(report.Routes))
+	}
+	if rt := report.Routes[1]; rt.Pattern != "/users/{id}" || rt.Timeout != time.Second || rt.TimeoutSource != "override" {
+		t.Errorf("Unexpected route report: %+v", rt)
+	}
+
+	if len(report.Groups) != 1 || len(report.Groups[0].Groups) != 1 {
+		t.Fatalf("Expected a group with a child group, got %+v", report.Groups)
+	}
+	apiReport, adminReport := report.Groups[0], report.Groups[0].Groups[0]
+	if apiReport.Middleware != 1 || apiReport.ErrorHandlerSource != "inherited" {
+		t.Errorf("Unexpected group report: %+v", apiReport)
+	}
+	if rt := apiReport.Routes[0]; rt.Pattern != "/api/items/{id}" || rt.Middleware != 1 {
+		t.Errorf("Unexpected group route report: %+v", rt)
+	}
+	if adminReport.Prefix != "/api/admin" || adminReport.Middleware != 2 || adminReport.ErrorHandlerSource != "override" {
+		t.Errorf("Unexpected child group report: %+v", adminReport)
+	}
+
+	// The report encodes to JSON
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Failed to encode report: %v", err)
+	}
+	var decoded RouterReport
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Groups[0].Groups[0].Routes[0].Pattern != "/api/admin/stats" {
+		t.Errorf("Failed to round-trip the report: %v\n%s", err, data)
+	}
+
+	// Build failures are reported
+	r.Get("/health", handler)
+	if err := r.Build(); err == nil {
+		t.Fatal("Expected a duplicate route error")
+	}
+	if report := r.Report(); report.Build.Built || report.Build.Error == "" {
+		t.Errorf("Expected a failed build, got %+v", report.Build)
+	}
+}
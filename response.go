@@ -1,29 +1,260 @@
 package router
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // responseWriter is an extension of http.ResponseWriter that tracks the write status of the response.
+//
+// The handler and the timeout handler, which runs on another goroutine, must never both write
+// the response. Writes are serialized by mu, and the response belongs to whichever commits it
+// first: once the timeout path has claimed it (see claimForTimeout), later writes of the handler
+// are dropped and report http.ErrHandlerTimeout; once the handler has written, the timeout
+// handler is not called.
 type responseWriter struct {
 	http.ResponseWriter
-	written bool
-	status  int
+	mu       sync.Mutex
+	written  atomic.Bool // Whether the final status has been committed
+	timedOut bool        // Whether the response was claimed by the timeout path (protected by mu)
+	status   int
+	size     int
+
+	// staleHeader is returned by Header once the response has been claimed by the timeout path,
+	// so that a handler that is still running does not modify the headers being sent.
+	staleHeader http.Header
+
+	// discardBody drops body bytes while still counting them, so that GET handlers serving
+	// HEAD requests do not need method checks. The status and headers are sent by finish,
+	// with a Content-Length computed from the discarded body unless the handler set one.
+	discardBody   bool
+	pendingHeader bool
+
+	// buffer holds the response of a route in buffered mode until it is committed (see Route.WithBufferedResponse).
+	buffer *responseBuffer
+
+	// cacheControl is the Cache-Control value of the route, set on successful responses
+	// that do not set their own (see Route.WithCacheControl).
+	cacheControl string
+
+	// retryAfter is the Retry-After delay of the route, set on 429 and 5xx responses
+	// that do not set their own (see Route.WithErrorRetryAfter).
+	retryAfter time.Duration
+}
+
+// Status returns the HTTP status code of the response.
+func (rw *responseWriter) Status() int {
+	return rw.status
+}
+
+// Size returns the number of body bytes written by the handler, including discarded bytes.
+func (rw *responseWriter) Size() int {
+	return rw.size
+}
+
+// Header implements http.ResponseWriter.
+// While the response is buffered, it returns the header of the buffered response.
+func (rw *responseWriter) Header() http.Header {
+	if rw.buffer != nil {
+		return rw.buffer.header
+	}
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.timedOut {
+		if rw.staleHeader == nil {
+			rw.staleHeader = make(http.Header)
+		}
+		return rw.staleHeader
+	}
+	return rw.ResponseWriter.Header()
+}
+
+// WriteHeader implements http.ResponseWriter and tracks the status code.
+// Informational (1xx) responses such as 103 Early Hints are sent immediately and may be written
+// several times before the final status; they do not mark the response as written.
+func (rw *responseWriter) WriteHeader(code int) {
+	if rw.buffer != nil && !isInformational(code) {
+		if rw.buffer.status == 0 {
+			rw.buffer.status = code
+		}
+		return
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.timedOut {
+		return
+	}
+	if isInformational(code) {
+		rw.writeInformational(code)
+		return
+	}
+	rw.writeHeader(code)
+}
+
+// Write implements http.ResponseWriter and tracks that the response has been written.
+// When the body is discarded, the bytes are counted but not sent.
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if rw.buffer != nil {
+		if rw.buffer.status == 0 {
+			rw.buffer.status = http.StatusOK
+		}
+		return rw.buffer.body.Write(b)
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	return rw.writeBody(b)
+}
+
+// writeBody writes or, for HEAD requests, discards the response body. The caller must hold mu.
+func (rw *responseWriter) writeBody(b []byte) (int, error) {
+	if rw.discardBody {
+		if !rw.written.Load() {
+			// Delay the header so that Content-Length can be computed from the whole body
+			rw.written.Store(true)
+			rw.pendingHeader = true
+		}
+		rw.size += len(b)
+		return len(b), nil
+	}
+	return rw.write(b)
 }
 
 // writeHeader sets the HTTP status code.
-// It does nothing if the response has already been written.
+// It does nothing if the response has already been written. The caller must hold mu.
 func (rw *responseWriter) writeHeader(code int) {
-	if !rw.written {
+	if !rw.written.Load() {
 		rw.status = code
+		rw.applyCacheControl(code)
+		rw.applyRetryAfter(code)
 		rw.ResponseWriter.WriteHeader(code)
-		rw.written = true
+		rw.written.Store(true)
 	}
 }
 
 // write writes the response body.
-// Writing is tracked by setting the written flag.
+// Writing is tracked by setting the written flag. The caller must hold mu.
 func (rw *responseWriter) write(b []byte) (int, error) {
-	if !rw.written {
-		rw.written = true
+	if !rw.written.Load() {
+		rw.written.Store(true)
+		rw.applyCacheControl(rw.status)
+		rw.applyRetryAfter(rw.status)
 	}
-	return rw.ResponseWriter.Write(b)
+	n, err := rw.ResponseWriter.Write(b)
+	rw.size += n
+	return n, err
+}
+
+// writeInformational sends a 1xx response with the current headers.
+// It does nothing once the final status has been sent. The caller must hold mu.
+func (rw *responseWriter) writeInformational(code int) {
+	if rw.written.Load() && !rw.pendingHeader {
+		return
+	}
+	if rw.buffer != nil {
+		// The headers of the buffered response are the ones the handler set for the hint
+		header := rw.ResponseWriter.Header()
+		clear(header)
+		for k, v := range rw.buffer.header {
+			header[k] = v
+		}
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// isInformational reports whether the status is a 1xx response that precedes the final status.
+// 101 Switching Protocols ends the HTTP exchange, so it is treated as a final status.
+func isInformational(code int) bool {
+	return code >= 100 && code < 200 && code != http.StatusSwitchingProtocols
+}
+
+// finish sends the header delayed by a discarded body.
+// It is called once the handler and any error handling have completed.
+func (rw *responseWriter) finish() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if !rw.pendingHeader || rw.timedOut {
+		return
+	}
+	rw.pendingHeader = false
+	if header := rw.ResponseWriter.Header(); header.Get("Content-Length") == "" {
+		header.Set("Content-Length", strconv.Itoa(rw.size))
+	}
+	rw.applyCacheControl(rw.status)
+	rw.applyRetryAfter(rw.status)
+	rw.ResponseWriter.WriteHeader(rw.status)
+}
+
+// Flush sends any buffered data to the client.
+// It implements http.Flusher so that streaming handlers work through the router;
+// it does nothing if the underlying ResponseWriter cannot flush or the response is buffered.
+func (rw *responseWriter) Flush() {
+	if rw.buffer != nil {
+		return
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.timedOut {
+		return
+	}
+	if rw.pendingHeader {
+		// The body length is unknown once the response is streamed
+		rw.pendingHeader = false
+		rw.applyCacheControl(rw.status)
+		rw.applyRetryAfter(rw.status)
+		rw.ResponseWriter.WriteHeader(rw.status)
+	}
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		rw.written.Store(true) // Flushing commits the response headers
+		f.Flush()
+	}
+}
+
+// claimForTimeout hands the response over to the timeout path and reports whether it may write.
+// It fails if the handler has already committed the response or another timeout claimed it;
+// afterwards, writes of the handler are dropped.
+func (rw *responseWriter) claimForTimeout() bool {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.written.Load() || rw.timedOut {
+		return false
+	}
+	rw.timedOut = true
+	return true
+}
+
+// applyCacheControl sets the Cache-Control value of the route on a successful (2xx or 304)
+// response, unless the handler set its own.
+func (rw *responseWriter) applyCacheControl(status int) {
+	if rw.cacheControl == "" || (status/100 != 2 && status != http.StatusNotModified) {
+		return
+	}
+	if h := rw.ResponseWriter.Header(); h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", rw.cacheControl)
+	}
+}
+
+// applyRetryAfter sets the Retry-After delay of the route on a 429 or 5xx response, unless the
+// handler set its own. The delay is sent in whole seconds, rounded up.
+func (rw *responseWriter) applyRetryAfter(status int) {
+	if rw.retryAfter <= 0 || (status != http.StatusTooManyRequests && status/100 != 5) {
+		return
+	}
+	if h := rw.ResponseWriter.Header(); h.Get("Retry-After") == "" {
+		h.Set("Retry-After", strconv.FormatInt(int64((rw.retryAfter+time.Second-1)/time.Second), 10))
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter.
+// It is used by http.ResponseController to access optional interfaces.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
 }
@@ -0,0 +1,195 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHeadBodySuppression tests that a GET handler serving a HEAD request sends no body
+// but keeps the status and the Content-Length of the body it would have sent
+func TestHeadBodySuppression(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	page := func(w http.ResponseWriter, req *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		_, err := io.WriteString(w, "hello, world")
+		return err
+	}
+	body := func(w http.ResponseWriter, req *http.Request) error {
+		io.WriteString(w, "hello, ")
+		_, err := io.WriteString(w, "world")
+		return err
+	}
+	r.Head("/page", page)
+	r.Head("/body/{id}", body)
+	r.Get("/body/{id}", body)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method, path  string
+		status        int
+		body          string
+		contentLength string
+	}{
+		{http.MethodHead, "/page", http.StatusAccepted, "", ""},
+		{http.MethodHead, "/body/1", http.StatusOK, "", "12"},
+		{http.MethodGet, "/body/1", http.StatusOK, "hello, world", ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.status, w.Code)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s %s: expected body %q, got %q", tt.method, tt.path, tt.body, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Length"); got != tt.contentLength {
+			t.Errorf("%s %s: expected Content-Length %q, got %q", tt.method, tt.path, tt.contentLength, got)
+		}
+	}
+}
+
+// TestInformationalAndTrailers tests 1xx responses before the final status and trailer propagation
+func TestInformationalAndTrailers(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		w.Header().Add("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Add("Link", "</app.js>; rel=preload; as=script")
+		w.WriteHeader(http.StatusEarlyHints)
+
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, "body")
+		w.Header().Set("Grpc-Status", "0")
+		return nil
+	}
+	r.Get("/direct", handler)
+	r.Get("/buffered", handler).WithBufferedResponse()
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	for _, path := range []string{"/direct", "/buffered"} {
+		hints := 0
+		trace := &httptrace.ClientTrace{
+			Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+				if code == http.StatusEarlyHints {
+					hints++
+				}
+				return nil
+			},
+		}
+		req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, srv.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if hints != 2 {
+			t.Errorf("%s: expected 2 early hints, got %d", path, hints)
+		}
+		if resp.StatusCode != http.StatusAccepted || string(body) != "body" {
+			t.Errorf("%s: unexpected response %d %q", path, resp.StatusCode, body)
+		}
+		if got := resp.Trailer.Get("Grpc-Status"); got != "0" {
+			t.Errorf("%s: expected trailer Grpc-Status 0, got %q", path, got)
+		}
+	}
+}
+
+// TestSingleWriter tests that the handler and the timeout handler never both write the response.
+// Run with -race to check the synchronization of the two goroutines.
+func TestSingleWriter(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	timedOut := make(chan struct{}, 1)
+	lateWrite := make(chan error, 1)
+	r.SetTimeoutHandler(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "timeout", http.StatusServiceUnavailable)
+		timedOut <- struct{}{}
+	})
+
+	// The handler writes after the timeout handler has responded
+	r.Get("/late", func(w http.ResponseWriter, req *http.Request) error {
+		<-timedOut
+		w.Header().Set("X-Late", "1")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte("late"))
+		lateWrite <- err
+		return nil
+	}).WithTimeout(10 * time.Millisecond)
+
+	// The handler writes before the deadline and keeps running past it
+	r.Get("/early", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("early"))
+		<-req.Context().Done()
+		return nil
+	}).WithTimeout(10 * time.Millisecond)
+
+	// The handler and the timeout race to write the response
+	r.Get("/race", func(w http.ResponseWriter, req *http.Request) error {
+		<-req.Context().Done()
+		w.Write([]byte("handler"))
+		return nil
+	}).WithTimeout(time.Millisecond)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/late", nil))
+	if err := <-lateWrite; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("Expected http.ErrHandlerTimeout for a late write, got %v", err)
+	}
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "timeout\n" || w.Header().Get("X-Late") != "" {
+		t.Errorf("Expected only the timeout response, got %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/early", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "early" {
+		t.Errorf("Expected only the handler response, got %d %q", w.Code, w.Body.String())
+	}
+	select {
+	case <-timedOut:
+		t.Error("Expected the timeout handler not to be called after the handler wrote")
+	default:
+	}
+
+	for range 50 {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/race", nil))
+		select {
+		case <-timedOut:
+			if w.Code != http.StatusServiceUnavailable || strings.Contains(w.Body.String(), "handler") {
+				t.Fatalf("Expected only the timeout response, got %d %q", w.Code, w.Body.String())
+			}
+		default:
+			if w.Code != http.StatusOK || w.Body.String() != "handler" {
+				t.Fatalf("Expected only the handler response, got %d %q", w.Code, w.Body.String())
+			}
+		}
+	}
+}
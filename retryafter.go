@@ -0,0 +1,48 @@
+package router
+
+import "time"
+
+// WithErrorRetryAfter sets the Retry-After header of the 429 Too Many Requests and 5xx responses
+// of the route, unless the response sets its own, so that clients back off consistently whether
+// the response comes from the error handler, the timeout handler, or rate limiting and circuit
+// breaking middleware. The delay is sent in whole seconds, rounded up; successful responses and
+// other client errors are not affected.
+//
+// 例: r.Post("/reports", generate).WithErrorRetryAfter(30 * time.Second)
+func (r *Route) WithErrorRetryAfter(d time.Duration) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.errorRetryAfter = d
+	return r
+}
+
+// GetErrorRetryAfter returns the Retry-After delay of the error responses of the route.
+// If the route has no delay of its own, the delay of its group is returned (0 if neither is set).
+func (r *Route) GetErrorRetryAfter() time.Duration {
+	if r.errorRetryAfter > 0 || r.group == nil {
+		return r.errorRetryAfter
+	}
+	return r.group.GetErrorRetryAfter()
+}
+
+// WithErrorRetryAfter sets the Retry-After delay of the error responses of the routes of the
+// group and its child groups, so that a class of routes shares one backoff policy. Routes can
+// override it with Route.WithErrorRetryAfter.
+func (g *Group) WithErrorRetryAfter(d time.Duration) *Group {
+	g.retryAfter = d
+	return g
+}
+
+// GetErrorRetryAfter returns the Retry-After delay of the group, including a delay inherited
+// from parent groups (0 if none is set).
+func (g *Group) GetErrorRetryAfter() time.Duration {
+	for current := g; current != nil; current = current.parent {
+		if current.retryAfter > 0 {
+			return current.retryAfter
+		}
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestErrorRetryAfter tests setting Retry-After on the error responses of routes and groups
+func TestErrorRetryAfter(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	failing := func(w http.ResponseWriter, req *http.Request) error {
+		return errors.New("backend unavailable")
+	}
+	r.Get("/fail", failing).WithErrorRetryAfter(1500 * time.Millisecond)
+	r.Get("/buffered", failing).WithErrorRetryAfter(2 * time.Second).WithBufferedResponse()
+	r.Get("/ok", func(w http.ResponseWriter, req *http.Request) error {
+		return nil
+	}).WithErrorRetryAfter(time.Second)
+	r.Get("/missing", func(w http.ResponseWriter, req *http.Request) error {
+		http.Error(w, "missing", http.StatusNotFound)
+		return nil
+	}).WithErrorRetryAfter(time.Second)
+	r.Get("/throttled", func(w http.ResponseWriter, req *http.Request) error {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+		return nil
+	}).WithErrorRetryAfter(time.Second)
+	r.Get("/slow", func(w http.ResponseWriter, req *http.Request) error {
+		<-req.Context().Done()
+		return req.Context().Err()
+	}).WithTimeout(10 * time.Millisecond).WithErrorRetryAfter(5 * time.Second)
+
+	batch := r.Group("/batch").WithErrorRetryAfter(10 * time.Second)
+	batch.Group("/jobs").Get("/run", func(w http.ResponseWriter, req *http.Request) error {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		return nil
+	})
+	batch.Get("/own", failing).WithErrorRetryAfter(3 * time.Second)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		status     int
+		retryAfter string
+	}{
+		{"/fail", http.StatusInternalServerError, "2"},
+		{"/buffered", http.StatusInternalServerError, "2"},
+		{"/ok", http.StatusOK, ""},
+		{"/missing", http.StatusNotFound, ""},
+		{"/throttled", http.StatusTooManyRequests, "60"},
+		{"/slow", http.StatusServiceUnavailable, "5"},
+		{"/batch/jobs/run", http.StatusServiceUnavailable, "10"},
+		{"/batch/own", http.StatusInternalServerError, "3"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status || w.Header().Get("Retry-After") != tt.retryAfter {
+			t.Errorf("%s: expected %d with Retry-After %q, got %d with %q", tt.path, tt.status, tt.retryAfter, w.Code, w.Header().Get("Retry-After"))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package router
+
+// RouteCache stores the results of route matching by key, so that repeated requests for the
+// same method and path skip the static trie and the dynamic trees. The router uses a sharded
+// in-memory map by default; RouterOptions.NewRouteCache replaces it, so that large gateways can
+// experiment with other implementations, such as per-CPU caches, without forking the router.
+//
+// Implementations must be safe for concurrent use. They may drop entries at any time, since
+// a miss only makes the router match the request again.
+type RouteCache interface {
+	// Get returns the entry stored for the key.
+	Get(key uint64) (CachedRoute, bool)
+	// Set stores the entry for the key, replacing any previous entry.
+	Set(key uint64, entry CachedRoute)
+	// Invalidate drops all entries. It is called whenever routes are registered.
+	Invalidate()
+	// Stats returns the usage counters of the cache.
+	Stats() CacheStats
+	// Close releases the resources of the cache. It is called by Router.Shutdown and may be called
+	// more than once.
+	Close() error
+}
+
+// CachedRoute is a route matching result stored in a RouteCache.
+// Implementations store it as an opaque value.
+type CachedRoute struct {
+	handler HandlerFunc       // Handler of the matched route
+	route   *Route            // Route definition (nil for routes registered with Handle)
+	stats   *routeStats       // Usage statistics of the route
+	params  map[string]string // Parameters of a dynamic route (nil for static routes)
+}
+
+// CacheStats are the usage counters of a RouteCache.
+type CacheStats struct {
+	Entries   int    `json:"entries"`   // Number of entries stored
+	Hits      uint64 `json:"hits"`      // Lookups that found an entry
+	Misses    uint64 `json:"misses"`    // Lookups that found no entry
+	Evictions uint64 `json:"evictions"` // Entries dropped to make room or because they expired
+}
+
+// CacheStats returns the usage counters of the route cache of the router.
+func (r *Router) CacheStats() CacheStats {
+	return r.routeCache.Stats()
+}
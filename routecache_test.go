@@ -0,0 +1,138 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// mapRouteCache is a minimal RouteCache backed by a single map
+type mapRouteCache struct {
+	mu           sync.Mutex
+	entries      map[uint64]CachedRoute
+	hits, misses uint64
+	closed       bool
+}
+
+func (c *mapRouteCache) Get(key uint64) (CachedRoute, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return e, ok
+}
+
+func (c *mapRouteCache) Set(key uint64, entry CachedRoute) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
+
+func (c *mapRouteCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+func (c *mapRouteCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Entries: len(c.entries), Hits: c.hits, Misses: c.misses}
+}
+
+func (c *mapRouteCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+// TestCustomRouteCache tests replacing the built-in route cache
+func TestCustomRouteCache(t *testing.T) {
+	var caches []*mapRouteCache
+	opts := defaultRouterOptions()
+	opts.CacheMaxEntries = 64
+	opts.NewRouteCache = func(maxEntries int) RouteCache {
+		if maxEntries != 64 {
+			t.Errorf("Expected the configured size, got %d", maxEntries)
+		}
+		c := &mapRouteCache{entries: make(map[uint64]CachedRoute)}
+		caches = append(caches, c)
+		return c
+	}
+	r := NewRouterWithOptions(opts)
+
+	var source MatchOrigin
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		source = MatchSource(req.Context())
+		id, _ := GetParams(req.Context()).Get("id")
+		w.Write([]byte(id))
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	for i, want := range []MatchOrigin{MatchFromDynamic, MatchFromCache} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+		if source != want || w.Body.String() != "7" {
+			t.Errorf("Request %d: expected %v with the parameter, got %v %q", i, want, source, w.Body.String())
+		}
+	}
+	if stats := r.CacheStats(); stats.Entries != 1 || stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Expected 1 entry, 1 hit and 1 miss, got %+v", stats)
+	}
+	if report := r.Report(); !report.Cache.Custom || report.Cache.Stats.Entries != 1 {
+		t.Errorf("Expected the report to describe the custom cache, got %+v", report.Cache)
+	}
+
+	// Registering a route invalidates the cache
+	r.Get("/users/{id}/posts", func(w http.ResponseWriter, req *http.Request) error { return nil })
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	if stats := r.CacheStats(); stats.Entries != 0 {
+		t.Errorf("Expected the cache to be invalidated, got %d entries", stats.Entries)
+	}
+
+	// Clones get a cache of their own
+	clone, err := r.Clone()
+	if err != nil {
+		t.Fatalf("Failed to clone router: %v", err)
+	}
+	if len(caches) != 2 || clone.routeCache == r.routeCache {
+		t.Errorf("Expected the clone to create its own cache, got %d caches", len(caches))
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+	if !caches[0].closed {
+		t.Error("Expected Shutdown to close the cache")
+	}
+	clone.routeCache.Close()
+}
+
+// TestCacheStats tests the usage counters of the built-in route cache
+func TestCacheStats(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Get("/items", func(w http.ResponseWriter, req *http.Request) error { return nil })
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	for range 3 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+	}
+	if stats := r.CacheStats(); stats.Entries != 1 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Expected 1 entry, 2 hits and 1 miss, got %+v", stats)
+	}
+}
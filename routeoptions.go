@@ -0,0 +1,225 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is the error of requests rejected by the rate limit of RouteOptions.
+// It is returned wrapped in a StatusError with status 429 Too Many Requests.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RouteOptions declares the built-in policies of a route in one value (see Route.With).
+// Zero fields are not applied.
+type RouteOptions struct {
+	Timeout   time.Duration // Handler timeout (see Route.WithTimeout)
+	MaxBody   int64         // Maximum size of the request body in bytes (see Route.WithMaxBodySize)
+	RateLimit string        // Requests allowed per client address and period, such as "100/m" or "10/30s"
+	Auth      string        // Name of the authentication middleware registered with Router.RegisterAuth
+
+	// UpstreamRateLimit makes the RateLimit headers of a route with RateLimit report the limit of
+	// the upstream service instead of the route's own when the upstream's is stricter, for routes
+	// that forward requests, such as reverse proxies (see ParseRateLimit).
+	UpstreamRateLimit bool
+}
+
+// With applies the policies declared by opts to the route, so that route declarations stay
+// compact and the policies of every route can be audited in one place (see Options).
+// Timeout and MaxBody are applied like WithTimeout and WithMaxBodySize. RateLimit and Auth are
+// expanded into middleware at Build, which runs before the middleware of the route: the rate
+// limit first, so that rejected clients do not reach the authentication, then the middleware
+// registered under the Auth name. Build reports an invalid rate limit or an unknown Auth name.
+//
+// The rate limit counts the requests of each client address (see ClientIP) in fixed windows of
+// the period, sends the RateLimit headers (see RateLimitInfo), and rejects the requests over the
+// limit with 429 Too Many Requests and a StatusError wrapping ErrRateLimited. With
+// UpstreamRateLimit, the headers are sent when the response is written instead, and report the
+// limit the handler's response carries (typically copied from an upstream response by a proxy)
+// if it leaves fewer requests than the route's, so that clients get one consistent signal.
+//
+// 例: r.Post("/orders", create).With(router.RouteOptions{Timeout: 5 * time.Second, MaxBody: 1 << 20, RateLimit: "100/m", Auth: "jwt"})
+func (r *Route) With(opts RouteOptions) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	if opts.Timeout > 0 {
+		r.timeout = opts.Timeout
+	}
+	if opts.MaxBody > 0 {
+		r.maxBodyBytes = opts.MaxBody
+	}
+	r.options = opts
+	return r
+}
+
+// Options returns the options declared with With.
+func (r *Route) Options() RouteOptions {
+	return r.options
+}
+
+// RegisterAuth registers authentication middleware under a name that routes refer to with
+// RouteOptions.Auth, such as "jwt" or "session". Registering a name again replaces the middleware
+// for the routes built afterwards.
+//
+// 例: r.RegisterAuth("jwt", jwtMiddleware(keys))
+func (r *Router) RegisterAuth(name string, mw MiddlewareFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.auths == nil {
+		r.auths = make(map[string]MiddlewareFunc)
+	}
+	r.auths[name] = mw
+}
+
+// authMiddleware returns the authentication middleware registered under the name (nil if none).
+// Tenant overlays use the middleware of the router they belong to.
+func (r *Router) authMiddleware(name string) MiddlewareFunc {
+	if r.tenantParent != nil {
+		return r.tenantParent.authMiddleware(name)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.auths[name]
+}
+
+// expandOptions returns the middleware that RateLimit and Auth of the route's options expand to,
+// in the order applyMiddlewareChain expects: the authentication first, so that the rate limit,
+// applied last, runs first.
+func (r *Route) expandOptions() ([]MiddlewareFunc, error) {
+	var middleware []MiddlewareFunc
+	if r.options.Auth != "" {
+		auth := r.router.authMiddleware(r.options.Auth)
+		if auth == nil {
+			return nil, &RouterError{Code: ErrInvalidConfig, Message: "unknown auth " + strconv.Quote(r.options.Auth) + ": " + r.method + " " + r.fullPath()}
+		}
+		middleware = append(middleware, auth)
+	}
+	if r.options.RateLimit != "" {
+		limit, window, err := parseRate(r.options.RateLimit)
+		if err != nil {
+			return nil, &RouterError{Code: ErrInvalidConfig, Message: "invalid rate limit " + strconv.Quote(r.options.RateLimit) + ": " + r.method + " " + r.fullPath(), Err: err}
+		}
+		limiter := &rateLimiter{limit: limit, window: window, upstream: r.options.UpstreamRateLimit}
+		middleware = append(middleware, limiter.middleware(r))
+	}
+	return middleware, nil
+}
+
+// parseRate parses a rate such as "100/m": a number of requests, a slash, and the period, which
+// is "s", "m", "h", or a duration such as "30s".
+func parseRate(rate string) (int, time.Duration, error) {
+	count, period, ok := strings.Cut(rate, "/")
+	if !ok {
+		return 0, 0, errors.New("expected requests/period")
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || limit <= 0 {
+		return 0, 0, errors.New("the number of requests must be a positive integer")
+	}
+	period = strings.TrimSpace(period)
+	switch period {
+	case "s", "m", "h":
+		period = "1" + period
+	}
+	window, err := time.ParseDuration(period)
+	if err != nil || window <= 0 {
+		return 0, 0, errors.New("the period must be s, m, h, or a positive duration")
+	}
+	return limit, window, nil
+}
+
+// rateLimiter counts the requests of each client in fixed windows.
+type rateLimiter struct {
+	limit    int           // Requests allowed per window
+	window   time.Duration // Length of a window
+	upstream bool          // Whether stricter limits of the handler's responses are relayed
+
+	mu     sync.Mutex
+	start  time.Time      // Start of the current window
+	counts map[string]int // Requests per client in the current window
+}
+
+// take counts a request of the client and reports whether it is within the limit.
+// The counts are dropped when a new window starts, so memory is bounded by the clients of a window.
+func (l *rateLimiter) take(client string, now time.Time) (RateLimitInfo, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.counts == nil || now.Sub(l.start) >= l.window {
+		l.start = now.Truncate(l.window)
+		l.counts = make(map[string]int)
+	}
+	l.counts[client]++
+	count := l.counts[client]
+	info := RateLimitInfo{Limit: l.limit, Remaining: l.limit - count, Reset: l.start.Add(l.window).Sub(now)}
+	return info, count <= l.limit
+}
+
+// middleware returns middleware that rejects the requests of clients over the limit.
+func (l *rateLimiter) middleware(route *Route) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			info, ok := l.take(ClientIP(r), time.Now())
+			if !ok {
+				info.SetHeaders(w.Header())
+				w.Header().Set("Retry-After", w.Header().Get("RateLimit-Reset"))
+				return route.reject(w, &StatusError{Status: http.StatusTooManyRequests, Err: ErrRateLimited})
+			}
+			if !l.upstream {
+				info.SetHeaders(w.Header())
+				return next(w, r)
+			}
+
+			lw := &rateLimitWriter{ResponseWriter: w, info: info}
+			err := next(lw, r)
+			// Responses written after the handler returned, such as error pages, carry the route's limit
+			lw.commit()
+			return err
+		}
+	}
+}
+
+// rateLimitWriter sends the RateLimit headers when the response is written, reporting the
+// stricter of the route's limit and the limit in the headers of the handler's response.
+type rateLimitWriter struct {
+	http.ResponseWriter
+	info      RateLimitInfo // Throttling state of the route's own limit
+	committed bool
+}
+
+// WriteHeader sets the RateLimit headers before the final status is written.
+func (w *rateLimitWriter) WriteHeader(code int) {
+	if !isInformational(code) {
+		w.commit()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write sets the RateLimit headers before the body is written.
+func (w *rateLimitWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter.
+func (w *rateLimitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// commit replaces the RateLimit headers of the response with the stricter limit. It only acts once.
+func (w *rateLimitWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	info := w.info
+	if upstream, ok := ParseRateLimit(w.Header()); ok && upstream.Remaining < info.Remaining {
+		info = upstream
+	}
+	info.SetHeaders(w.Header())
+}
@@ -0,0 +1,201 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRouteWith tests expanding the declared options into the built-in policies
+func TestRouteWith(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var order []string
+	r.RegisterAuth("jwt", func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			order = append(order, "auth")
+			if req.Header.Get("Authorization") == "" {
+				http.Error(w, "missing token", http.StatusUnauthorized)
+				return nil
+			}
+			return next(w, req)
+		}
+	})
+	route := r.Post("/orders", func(w http.ResponseWriter, req *http.Request) error {
+		order = append(order, "handler")
+		return nil
+	}).With(RouteOptions{Timeout: 5 * time.Second, MaxBody: 8, RateLimit: "2/m", Auth: "jwt"}).
+		WithMiddleware(func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) error {
+				order = append(order, "route")
+				return next(w, req)
+			}
+		})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	if route.timeout != 5*time.Second || route.maxBodyBytes != 8 {
+		t.Errorf("Expected the timeout and body limit to be applied, got %v and %d", route.timeout, route.maxBodyBytes)
+	}
+	if got := route.Options(); got.RateLimit != "2/m" || got.Auth != "jwt" {
+		t.Errorf("Expected the declared options, got %+v", got)
+	}
+
+	serve := func(body string, token bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		if token {
+			req.Header.Set("Authorization", "Bearer x")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// The auth middleware runs before the route middleware
+	w := serve("", true)
+	if w.Code != http.StatusOK || strings.Join(order, ",") != "auth,route,handler" {
+		t.Errorf("Expected auth, route middleware and handler in order, got %d %v", w.Code, order)
+	}
+	if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("Expected the rate limit headers, got %v", w.Header())
+	}
+	if w := serve("", false); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d without a token, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	// The rate limit rejects the client before authentication
+	order = nil
+	if w := serve("", true); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected status %d with Retry-After, got %d %v", http.StatusTooManyRequests, w.Code, w.Header())
+	}
+	if len(order) != 0 {
+		t.Errorf("Expected the rejected request not to reach the auth middleware, got %v", order)
+	}
+}
+
+// TestRouteWithUpstreamRateLimit tests relaying the stricter limit of an upstream service through a proxy route
+func TestRouteWithUpstreamRateLimit(t *testing.T) {
+	var upstreamRemaining atomic.Int64
+	upstreamRemaining.Store(50)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("RateLimit-Limit", "100")
+		w.Header().Set("RateLimit-Remaining", strconv.FormatInt(upstreamRemaining.Load(), 10))
+		w.Header().Set("RateLimit-Reset", "30")
+		w.Write([]byte("upstream"))
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	r := NewRouter()
+	defer r.cache.stop()
+
+	forward := func(w http.ResponseWriter, req *http.Request) error {
+		proxy.ServeHTTP(w, req)
+		return nil
+	}
+	r.Get("/proxy", forward).With(RouteOptions{RateLimit: "3/m", UpstreamRateLimit: true})
+	r.Get("/failing", func(w http.ResponseWriter, req *http.Request) error {
+		return errors.New("failed")
+	}).With(RouteOptions{RateLimit: "3/m", UpstreamRateLimit: true})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// The route's limit is stricter, and replaces the upstream headers
+	w := serve("/proxy")
+	if w.Body.String() != "upstream" || w.Header().Values("RateLimit-Limit")[0] != "3" || len(w.Header().Values("RateLimit-Remaining")) != 1 || w.Header().Get("RateLimit-Remaining") != "2" {
+		t.Errorf("Expected the route's limit, got %v", w.Header())
+	}
+
+	// The upstream's limit is stricter
+	upstreamRemaining.Store(0)
+	w = serve("/proxy")
+	if w.Header().Get("RateLimit-Limit") != "100" || w.Header().Get("RateLimit-Remaining") != "0" || w.Header().Get("RateLimit-Reset") != "30" {
+		t.Errorf("Expected the upstream's limit, got %v", w.Header())
+	}
+
+	// Responses written after the handler returned carry the route's limit
+	w = serve("/failing")
+	if w.Code != http.StatusInternalServerError || w.Header().Get("RateLimit-Remaining") != "2" {
+		t.Errorf("Expected the route's limit on the error response, got %d %v", w.Code, w.Header())
+	}
+
+	// Requests over the route's limit are rejected before reaching the upstream
+	serve("/proxy")
+	if w := serve("/proxy"); w.Code != http.StatusTooManyRequests || w.Header().Get("RateLimit-Limit") != "3" {
+		t.Errorf("Expected status %d with the route's limit, got %d %v", http.StatusTooManyRequests, w.Code, w.Header())
+	}
+}
+
+// TestRouteWithBodyLimit tests that MaxBody limits the request body
+func TestRouteWithBodyLimit(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Post("/upload", func(w http.ResponseWriter, req *http.Request) error {
+		return nil
+	}).With(RouteOptions{MaxBody: 4})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("too large")))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
+
+// TestRouteWithInvalid tests that Build reports invalid options
+func TestRouteWithInvalid(t *testing.T) {
+	for _, opts := range []RouteOptions{
+		{RateLimit: "100"},
+		{RateLimit: "0/m"},
+		{RateLimit: "10/week"},
+		{Auth: "unknown"},
+	} {
+		r := NewRouter()
+		r.Get("/items", func(w http.ResponseWriter, req *http.Request) error { return nil }).With(opts)
+		err := r.Build()
+		var routerErr *RouterError
+		if !errors.As(err, &routerErr) || routerErr.Code != ErrInvalidConfig {
+			t.Errorf("%+v: expected an invalid config error, got %v", opts, err)
+		}
+		r.cache.stop()
+	}
+}
+
+// TestParseRate tests parsing rate limits
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		rate   string
+		limit  int
+		window time.Duration
+	}{
+		{"100/m", 100, time.Minute},
+		{"5/s", 5, time.Second},
+		{"1000/h", 1000, time.Hour},
+		{"10/30s", 10, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		limit, window, err := parseRate(tt.rate)
+		if err != nil || limit != tt.limit || window != tt.window {
+			t.Errorf("%s: expected %d per %v, got %d per %v (%v)", tt.rate, tt.limit, tt.window, limit, window, err)
+		}
+	}
+}
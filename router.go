@@ -2,8 +2,10 @@ package router
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/netip"
 	"reflect"
 	"slices"
 	"strconv"
@@ -18,33 +20,49 @@ import (
 // providing high-speed route matching and caching mechanism.
 type Router struct {
 	// Routing-related
-	static  *doubleArrayTrie // High-speed trie structure for static routes
+	static  *doubleArrayTrie // High-speed trie structure for static routes (keyed by method and path, see methodKey)
 	dynamic [8]*node         // Radix tree for dynamic routes for each HTTP method (index corresponds to methodToUint8)
-	cache   *cache           // cache route matching results for performance
+	cache   *cache           // Built-in route cache (nil if RouterOptions.NewRouteCache is set)
 	routes  []*Route         // Directly registered routes
 	groups  []*Group         // Registered groups
 
+	routeCache      RouteCache                      // cache route matching results for performance (the built-in cache by default)
+	cacheMaxEntries int                             // Maximum number of entries of the route cache
+	newRouteCache   func(maxEntries int) RouteCache // Constructor of a custom route cache (nil for the built-in cache)
+
 	// Handler-related
 	// 各ハンドラーは異なる状況や目的に対応するために個別に存在しています：
 	// - errorHandler: ルートハンドラー内で発生したエラーを処理します（アプリケーションロジックのエラー）
 	// - shutdownHandler: サーバーがシャットダウン中の場合のリクエスト処理を担当します
 	// - timeoutHandler: リクエスト処理がタイムアウトした場合の処理を担当します
 	// - notFoundHandler: 存在しないルートへのリクエストを処理します
+	// - panicHandler: ミドルウェアやハンドラーで発生したパニックを処理します
 	// これらを分離することで、各状況に応じた適切な処理を個別に定義でき、コードの保守性と拡張性が向上します。
 	errorHandler    func(http.ResponseWriter, *http.Request, error) // Error handling function
 	shutdownHandler http.HandlerFunc                                // Request processing function during shutdown
 	timeoutHandler  http.HandlerFunc                                // Timeout handling function
 	notFoundHandler http.HandlerFunc                                // Not found handler
+	methodHandler   http.HandlerFunc                                // Method not allowed handler (nil uses the default 405 response)
+	errorPages      map[int]HandlerFunc                             // Error page renderers per status (see SetErrorPage)
+	mounts          []*mountPoint                                   // Handlers mounted under path prefixes, longest first (see Mount)
+	panicHandler    func(http.ResponseWriter, *http.Request, any)   // Panic handling function (nil uses the error handling)
 
 	// Middleware-related
 	middleware atomic.Value // List of middleware functions (atomic.Value used for thread-safe updates)
 	cleanupMws atomic.Value // List of cleanupable middleware
 
+	middlewareGen atomic.Uint64      // Incremented whenever the middleware changes (invalidates cached chains)
+	stacks        []*MiddlewareStack // Shared middleware stacks the router is attached to (see UseStack)
+
 	// Synchronization-related
-	mu             sync.RWMutex   // Mutex for protection from concurrent access
-	activeRequests sync.WaitGroup // Track the number of active requests
-	wgMu           sync.Mutex     // Mutex for protecting access to activeRequests
-	shuttingDown   atomic.Bool    // Flag indicating whether shutting down
+	mu             sync.RWMutex  // Mutex for protection from concurrent access
+	activeRequests atomic.Int64  // Number of admitted requests that have not completed (see beginRequest)
+	drainMu        sync.Mutex    // Orders request admission against the start of a shutdown
+	drained        chan struct{} // Closed when no request is active after shutdown began (protected by drainMu)
+	shuttingDown   atomic.Bool   // Flag indicating whether shutting down
+	frozen         atomic.Bool   // Whether the routes can no longer change (see Freeze)
+	cleanupOnce    sync.Once     // Runs the cleanup middleware once, however often Shutdown is called
+	cleanupErr     error         // Errors of the cleanup middleware (set by cleanupOnce)
 
 	// Timeout settings
 	requestTimeout time.Duration // Request processing timeout time (0 means no timeout)
@@ -52,9 +70,47 @@ type Router struct {
 
 	// Parameter-related
 	paramsPool *ParamsPool // URL parameter object pool (specific to each router instance)
+	fills      matchFlight // Dynamic matches in progress, shared by concurrent requests for the same path
 
 	// Configuration options
-	allowRouteOverride bool // Allow duplicate route registration
+	override       OverrideStrategy // Resolution of duplicate route registrations
+	maxPathLength  int              // Maximum length of the request path (0 means no limit)
+	maxSegments    int              // Maximum number of path segments (0 means no limit)
+	maxHeaderCount int              // Maximum number of request header values (0 means no limit)
+	maxHeaderBytes int              // Maximum total size of the request headers (0 means no limit)
+	maxBodyBytes   int64            // Maximum size of the request bodies (0 means no limit)
+	maxRegexEvals  int              // Maximum number of regex evaluations per request (0 means no limit)
+
+	perRequestMiddleware bool                      // Resolve group middleware per request instead of at Build
+	maxDispatchDepth     int                       // Maximum number of nested Dispatch calls per request
+	devMode              bool                      // Development diagnostics (see RouterOptions.DevMode)
+	strict               bool                      // Fail Build on warning-level issues (see RouterOptions.Strict)
+	trieInitialSize      int                       // Initial length of the static trie arrays
+	segmentChars         SegmentCharset            // Characters allowed in static segments of patterns
+	recordSites          bool                      // Record the call site of route registrations (see RouterOptions.RecordRouteSites)
+	logger               *slog.Logger              // Logger of warnings and errors (nil uses the log package)
+	expectCheck          func(*http.Request) error // Check of "Expect: 100-continue" requests (see RouterOptions.ExpectCheck)
+
+	patternCache  *patternCache      // Learned pattern plans (nil unless RouterOptions.CacheByPattern)
+	firstSegments *firstSegmentIndex // First segments of the routes (nil unless RouterOptions.FirstSegmentIndex)
+	latencies     bool               // Record latency histograms (see RouterOptions.LatencyHistograms)
+	gate          *inFlightGate      // Concurrency limit (nil unless RouterOptions.MaxInFlight)
+	proxies       []netip.Prefix     // Trusted reverse proxies (see RouterOptions.TrustedProxies)
+	buildStatus   BuildReport        // Outcome of the last Build (protected by mu)
+	buildChecks   []BuildCheck       // Policies checked for every route at Build (see AddBuildCheck)
+
+	tenantExtractor func(*http.Request) string // Identifies the tenant of a request (see Tenant)
+	tenants         map[string]*Router         // Route overlays per tenant (see ForTenant)
+	tenantParent    *Router                    // Router whose settings an overlay inherits (nil unless an overlay)
+
+	routeStats  map[string]*routeStats    // Usage statistics per "METHOD pattern" (protected by mu)
+	slowRequest *slowRequestHook          // Slow request callback (see OnSlowRequest)
+	handled     []handledRoute            // Routes registered with Handle (replayed by Clone)
+	hooks       lifecycleHooks            // Lifecycle callbacks (see OnRouteRegistered, protected by mu)
+	selectRoute func(*Route) bool         // Routes built by the Build in progress (nil for all, see BuildOnly)
+	auths       map[string]MiddlewareFunc // Authentication middleware per name (see RegisterAuth, protected by mu)
+
+	shutdownNotified atomic.Bool // Whether the OnShutdown hooks have been called
 }
 
 // HandlerFunc is a function type for processing HTTP requests and returning an error.
@@ -81,29 +137,87 @@ func NewRouterWithOptions(opts RouterOptions) *Router {
 		requestTimeout = opts.RequestTimeout
 	}
 
+	// Dispatch depth verification
+	maxDispatchDepth := defaultMaxDispatchDepth
+	if opts.MaxDispatchDepth > 0 {
+		maxDispatchDepth = opts.MaxDispatchDepth
+	}
+
+	// Memory tuning verification
+	trieInitialSize := initialTrieSize
+	if opts.TrieInitialSize > 0 {
+		trieInitialSize = opts.TrieInitialSize
+	}
+	trieGrowthFactor := growthFactor
+	if opts.TrieGrowthFactor > 1 {
+		trieGrowthFactor = opts.TrieGrowthFactor
+	}
+	paramsCapacity := initialParamsCapacity
+	if opts.ParamsCapacityHint > 0 {
+		paramsCapacity = opts.ParamsCapacityHint
+	}
+
 	r := &Router{
-		static:             newDoubleArrayTrie(),
-		cache:              newCacheWithMaxEntries(cacheMaxEntries),
-		errorHandler:       defaultErrorHandler,
-		shutdownHandler:    defaultShutdownHandler,
-		timeoutHandler:     defaultTimeoutHandler,
-		notFoundHandler:    nil,             // Default to nil, will use http.NotFound
-		paramsPool:         newParamsPool(), // Initialize parameter pool
-		routes:             make([]*Route, 0),
-		groups:             make([]*Group, 0),
-		requestTimeout:     requestTimeout,
-		allowRouteOverride: opts.AllowRouteOverride,
+		static:          newDoubleArrayTrieWithSize(trieInitialSize),
+		errorHandler:    defaultErrorHandler,
+		shutdownHandler: defaultShutdownHandler,
+		timeoutHandler:  defaultTimeoutHandler,
+		notFoundHandler: nil,                                       // Default to nil, will use http.NotFound
+		paramsPool:      newParamsPoolWithCapacity(paramsCapacity), // Initialize parameter pool
+		routes:          make([]*Route, 0),
+		groups:          make([]*Group, 0),
+		requestTimeout:  requestTimeout,
+		override:        overrideStrategyOf(opts),
+		maxPathLength:   opts.MaxPathLength,
+		maxHeaderCount:  opts.MaxHeaderCount,
+		maxHeaderBytes:  opts.MaxHeaderBytes,
+		maxBodyBytes:    opts.MaxBodyBytes,
+		maxSegments:     opts.MaxSegments,
+		maxRegexEvals:   opts.MaxRegexEvaluations,
+
+		perRequestMiddleware: opts.PerRequestMiddleware,
+		maxDispatchDepth:     maxDispatchDepth,
+		devMode:              opts.DevMode,
+		strict:               opts.Strict,
+		trieInitialSize:      trieInitialSize,
+		segmentChars:         opts.StaticSegmentChars,
+		recordSites:          opts.RecordRouteSites,
+		expectCheck:          opts.ExpectCheck,
+	}
+	r.cacheMaxEntries = cacheMaxEntries
+	if opts.NewRouteCache != nil {
+		r.newRouteCache = opts.NewRouteCache
+		r.routeCache = opts.NewRouteCache(cacheMaxEntries)
+	} else {
+		r.cache = newCacheWithMaxEntries(cacheMaxEntries)
+		r.routeCache = r.cache
+	}
+	if opts.CacheByPattern {
+		r.patternCache = newPatternCache()
+	}
+	if opts.FirstSegmentIndex {
+		r.firstSegments = newFirstSegmentIndex()
+	}
+	r.latencies = opts.LatencyHistograms
+	r.logger = opts.Logger
+	r.proxies = r.parseTrustedProxies(opts.TrustedProxies)
+	if opts.MaxInFlight > 0 {
+		r.gate = newInFlightGate(opts.MaxInFlight, max(opts.QueueTimeout, 0))
+	}
+	r.static.growth = trieGrowthFactor
+	if opts.DevMode {
+		r.errorHandler = devErrorHandler
 	}
 	// Initialize middleware list (using atomic.Value)
 	r.middleware.Store(make([]MiddlewareFunc, 0, 8))
 	// Initialize cleanupable middleware list
-	r.cleanupMws.Store(make([]cleanupMiddleware, 0, 8))
+	r.cleanupMws.Store(make([]CleanupMiddleware, 0, 8))
 	// shuttingDown is default false but explicitly set
 	r.shuttingDown.Store(false)
 
 	// Initialize dynamic route trees for each HTTP method
 	for i := range r.dynamic {
-		r.dynamic[i] = newNode("")
+		r.dynamic[i] = newRootNode()
 	}
 
 	return r
@@ -133,10 +247,19 @@ func newCleanupMiddleware(mw MiddlewareFunc, cleanup func() error) *cleanupMiddl
 // RouterOptions are options to set up the router's behavior.
 type RouterOptions struct {
 	// AllowRouteOverride specifies how to handle duplicate route registration.
-	// true: The later registered route overwrites the existing route.
+	// true: The later registered route overwrites the existing route (OverrideLastWins).
 	// false: If a duplicate route is detected, an error is returned (default).
+	// OverrideStrategy takes precedence when it is set.
 	AllowRouteOverride bool
 
+	// OverrideStrategy selects how duplicate route registrations are resolved, both by Build and
+	// by Handle: OverrideError returns an error, OverrideLastWins replaces the earlier route,
+	// OverrideFirstWins keeps it, and OverridePanic panics. Overrides are logged as warnings in
+	// the same format for routes registered with the router and with groups, and strict mode
+	// rejects them.
+	// Default: OverrideError (OverrideLastWins if AllowRouteOverride is set)
+	OverrideStrategy OverrideStrategy
+
 	// RequestTimeout is the default timeout time for request processing.
 	// A value of 0 or less disables the timeout.
 	// Default: 0 seconds (no timeout)
@@ -145,6 +268,168 @@ type RouterOptions struct {
 	// CacheMaxEntries is the maximum number of entries in the route cache.
 	// Default: 1000
 	CacheMaxEntries int
+
+	// MaxPathLength is the maximum length of the request path in bytes.
+	// Longer paths are rejected with 414 URI Too Long before route matching.
+	// A value of 0 or less disables the limit.
+	// Default: 0 (no limit)
+	MaxPathLength int
+
+	// MaxSegments is the maximum number of segments in the request path.
+	// Paths with more segments are rejected with 414 URI Too Long before route matching.
+	// A value of 0 or less disables the limit.
+	// Default: 0 (no limit)
+	MaxSegments int
+
+	// MaxHeaderCount is the maximum number of request header values (a header with several values
+	// counts once per value). Requests with more are rejected with 431 Request Header Fields Too
+	// Large before route matching. Groups can set stricter limits with Group.WithHeaderLimits.
+	// A value of 0 or less disables the limit.
+	// Default: 0 (no limit)
+	MaxHeaderCount int
+
+	// MaxHeaderBytes is the maximum total size of the request header names and values in bytes.
+	// Unlike http.Server.MaxHeaderBytes, it applies to this router only, so routers embedded in a
+	// larger server can have their own limit. Larger headers are rejected with 431 Request Header
+	// Fields Too Large before route matching. A value of 0 or less disables the limit.
+	// Default: 0 (no limit)
+	MaxHeaderBytes int
+
+	// MaxBodyBytes is the maximum size of request bodies in bytes. Requests whose Content-Length
+	// exceeds it are rejected with 413 Content Too Large after route matching and before the
+	// middleware run, so that clients sending "Expect: 100-continue" are refused before they
+	// upload the body. Bodies of unknown length are cut off with http.MaxBytesReader. Routes can
+	// set their own limit with Route.WithMaxBodySize. A value of 0 or less disables the limit.
+	// Default: 0 (no limit)
+	MaxBodyBytes int64
+
+	// ExpectCheck checks the requests that carry "Expect: 100-continue" after route matching and
+	// before the middleware run, such as by verifying credentials, so that large uploads to
+	// endpoints behind authentication are refused before the client sends the body. A returned
+	// StatusError sets the status of the rejection (for example 401), and other errors reject the
+	// request with 417 Expectation Failed. Routes can add checks with Route.WithExpectCheck.
+	// Default: nil (no check)
+	ExpectCheck func(*http.Request) error
+
+	// MaxRegexEvaluations is the maximum number of regex segment evaluations per request.
+	// When the budget is exhausted, matching stops and the request is treated as not found (404),
+	// which bounds the worst-case matching latency of routers with many regex routes.
+	// A value of 0 or less disables the limit.
+	// Default: 0 (no limit)
+	MaxRegexEvaluations int
+
+	// PerRequestMiddleware resolves group middleware for every request from atomic snapshots,
+	// in the same way as the router's global middleware, instead of composing it into the
+	// route handler at Build. Group.Use and Group.SetMiddleware then take effect immediately,
+	// which allows middleware to be managed at runtime at the cost of composing the chain per request.
+	// Default: false (group middleware is composed at Build)
+	PerRequestMiddleware bool
+
+	// MaxDispatchDepth is the maximum number of nested Dispatch calls within one request,
+	// which stops alias loops from recursing forever.
+	// Default: 10
+	MaxDispatchDepth int
+
+	// DevMode enables diagnostics intended for local development:
+	//   - errors without an error handler or error page render an HTML 500 page with the error chain,
+	//     and handler panics are recovered and rendered with their stack trace,
+	//   - Build logs the route table and warns about routes shadowed by other routes,
+	//   - requests whose middleware takes longer than 100ms before the handler are logged,
+	//   - the route cache is bypassed, so every request is matched against the route trees,
+	//   - responses carry X-Router-Matched (pattern), X-Router-Source (static, dynamic, ...),
+	//     and X-Router-Elapsed-Match (time spent matching) headers.
+	// None of these are active when DevMode is false; it should not be enabled in production.
+	// Default: false
+	DevMode bool
+
+	// Strict makes Build fail on warning-level issues instead of logging them:
+	//   - duplicate route registrations, even when an OverrideStrategy resolves them,
+	//   - routes that can never be matched because another route shadows them,
+	//   - groups that opted into an error handler with RequireErrorHandler but have none.
+	// It is intended for CI, to gate the correctness of the route table.
+	// Default: false
+	Strict bool
+
+	// StaticSegmentChars selects the characters allowed in the static segments of route patterns.
+	// The default allows only ASCII letters, digits, '-', '_', and '.'; PermissiveSegmentChars also
+	// allows the other characters that are legal in URL paths, for routes such as /@alice or
+	// /v1.0:batch, and UnicodeSegmentChars allows non-ASCII letters and digits. They can be combined.
+	// Default: StrictSegmentChars
+	StaticSegmentChars SegmentCharset
+
+	// Logger receives the warnings and errors of the router, such as duplicate routes, recovered
+	// panics, and Build diagnostics, at slog.LevelInfo, LevelWarn, or LevelError. Libraries that
+	// embed the router can pass SilentLogger() to keep it quiet.
+	// Default: nil (messages are written with the standard log package)
+	Logger *slog.Logger
+
+	// RecordRouteSites records the file and line of every Get, Post, Route, etc. call, so that
+	// Build reports both registration sites of a duplicate route along with their group chains.
+	// It costs a stack walk per registration, which large applications may want to skip in production.
+	// Default: false
+	RecordRouteSites bool
+
+	// TrieInitialSize is the initial length of the arrays of the static route trie.
+	// Embedded or low-memory deployments with few static routes can lower it;
+	// very large gateways can raise it to avoid repeated expansion while routes are added.
+	// Default: 1024
+	TrieInitialSize int
+
+	// TrieGrowthFactor is the factor by which the static route trie arrays grow when full.
+	// Smaller factors waste less memory but expand more often. Values of 1 or less use the default.
+	// Default: 1.5
+	TrieGrowthFactor float64
+
+	// ParamsCapacityHint is the number of URL parameters that pooled parameter objects have
+	// room for before they need to grow. Set it to the typical number of parameters per route.
+	// Default: 8
+	ParamsCapacityHint int
+
+	// NewRouteCache creates the route cache of the router instead of the built-in sharded map,
+	// with the configured CacheMaxEntries (see RouteCache). It is called once per router,
+	// including clones and tenant overlays, which never share a cache.
+	// Default: nil (built-in cache)
+	NewRouteCache func(maxEntries int) RouteCache
+
+	// CacheByPattern caches dynamic matches by route pattern instead of by concrete URL.
+	// The route cache otherwise holds one entry per URL, which explodes for routes such as
+	// /users/{id}. With this option, the first request to a pattern compiles a parameter
+	// extraction plan, and later requests of the same shape (e.g. /users/2 after /users/1)
+	// are matched by the plan without traversing the dynamic tree. Patterns that could be
+	// confused with another dynamic route of the method are always matched by the tree.
+	// Default: false
+	CacheByPattern bool
+
+	// FirstSegmentIndex keeps the set of first path segments of the registered routes per method,
+	// and rejects requests whose first segment matches nothing with 404 in O(1), without touching
+	// the static trie, the dynamic trees, or the route cache. This protects gateways that receive
+	// lots of junk scanning traffic (e.g. /wp-admin/...). It has no effect for methods that have a
+	// route starting with a parameter, since any first segment can match those.
+	// Default: false
+	FirstSegmentIndex bool
+
+	// LatencyHistograms records the latency of every request in a rolling histogram per route,
+	// readable with Router.Latencies, so a debug endpoint can show percentiles without an external
+	// metrics system. Each histogram covers the last minute and uses a few kilobytes.
+	// Default: false
+	LatencyHistograms bool
+
+	// MaxInFlight limits the number of requests handled concurrently, protecting downstream
+	// services during traffic spikes. Excess requests wait up to QueueTimeout for a slot and
+	// then receive 503 Service Unavailable. Requests that match no route are not limited.
+	// Default: 0 (no limit)
+	MaxInFlight int
+
+	// QueueTimeout is how long a request waits for a slot when MaxInFlight requests are in flight.
+	// Default: 0 (excess requests are rejected immediately)
+	QueueTimeout time.Duration
+
+	// TrustedProxies lists the IP addresses and CIDR prefixes of the reverse proxies in front of the
+	// router. ClientIP takes the client address from X-Forwarded-For only when the request came
+	// through these proxies, so that clients cannot spoof their address. Invalid entries are logged
+	// and ignored.
+	// Default: nil (the address of the connection is the client address)
+	TrustedProxies []string
 }
 
 // defaultRouterOptions returns the default router options.
@@ -153,6 +438,10 @@ func defaultRouterOptions() RouterOptions {
 		AllowRouteOverride: false,
 		RequestTimeout:     0 * time.Second, // no timeout
 		CacheMaxEntries:    defaultCacheMaxEntries,
+		MaxDispatchDepth:   defaultMaxDispatchDepth,
+		TrieInitialSize:    initialTrieSize,
+		TrieGrowthFactor:   growthFactor,
+		ParamsCapacityHint: initialParamsCapacity,
 	}
 }
 
@@ -174,7 +463,10 @@ func defaultShutdownHandler(w http.ResponseWriter, r *http.Request) {
 // which returns 503 Service Unavailable.
 func defaultTimeoutHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "close")
-	w.Header().Set("Retry-After", "60") // Recommend retrying after 60 seconds
+	// The delay of the route takes precedence (see Route.WithErrorRetryAfter)
+	if rw, ok := w.(*responseWriter); !ok || rw.retryAfter <= 0 {
+		w.Header().Set("Retry-After", "60") // Recommend retrying after 60 seconds
+	}
 	http.Error(w, "Request processing timed out", http.StatusServiceUnavailable)
 }
 
@@ -222,11 +514,13 @@ func (r *Router) SetNotFoundHandler(h http.HandlerFunc) {
 // builds the middleware chain, and handles errors.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// Create a response wrapper to track write status
-	rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+	// HEAD requests run the handler normally but never send a body
+	rw := &responseWriter{ResponseWriter: w, status: http.StatusOK, discardBody: req.Method == http.MethodHead}
+	defer rw.finish()
 
 	// Declare timeout-related variables at the beginning of the function
 	var cancel context.CancelFunc
-	var done chan struct{}
+	var done, exited chan struct{}
 	var timeoutOccurred atomic.Bool // Track whether a timeout occurred
 
 	// Clean up resources even if a panic occurs
@@ -236,28 +530,180 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 		if done != nil {
 			close(done) // Terminate the timeout monitoring goroutine
+			<-exited    // Wait so that the timeout handler never runs after ServeHTTP returns
 		}
 	}()
 
-	// Find handler and route
-	handler, route, found := r.findHandlerAndRoute(req.Method, req.URL.Path)
+	// Reject pathological paths before they reach the matchers and the cache
+	if !r.withinPathLimits(req.URL.Path) {
+		http.Error(rw, http.StatusText(http.StatusRequestURITooLong), http.StatusRequestURITooLong)
+		return
+	}
+	if !withinHeaderLimits(req.Header, HeaderLimits{MaxCount: r.maxHeaderCount, MaxBytes: r.maxHeaderBytes}) {
+		http.Error(rw, http.StatusText(http.StatusRequestHeaderFieldsTooLarge), http.StatusRequestHeaderFieldsTooLarge)
+		return
+	}
+
+	// Find handler and route (requests that no route can match are rejected by the first segment index)
+	// Requests of a tenant are matched against the tenant's overlay first
+	var matchStart time.Time
+	if r.devMode {
+		matchStart = time.Now()
+	}
+	var match routeMatch
+	found := false
+	tenant, overlay := r.tenantOf(req)
+	if overlay != nil {
+		match, found = overlay.findRoute(req.Method, req.URL.Path)
+	}
+	if !found && (r.firstSegments == nil || r.firstSegments.allows(methodToUint8(req.Method), req.URL.Path)) {
+		match, found = r.findRoute(req.Method, req.URL.Path)
+	}
 	if !found {
-		// 404 handling with custom handler if set
-		r.mu.RLock()
-		notFoundHandler := r.notFoundHandler
-		r.mu.RUnlock()
+		match, found = r.findMount(req.URL.Path)
+	}
+	handler, route, stats := match.handler, match.route, match.stats
+	if found && route != nil && route.window != nil {
+		// Routes outside their active window are unmatched before it and gone after it
+		switch route.window.status(time.Now()) {
+		case http.StatusNotFound:
+			found = false
+		case http.StatusGone:
+			http.Error(rw, http.StatusText(http.StatusGone), http.StatusGone)
+			return
+		}
+	}
+	if !found {
+		// Dispatch CORS preflight requests to the middleware of the requested route
+		if r.servePreflight(rw, req, overlay) {
+			return
+		}
 
-		if notFoundHandler != nil {
-			notFoundHandler(rw, req)
-		} else {
-			http.NotFound(rw, req)
+		// A path routed for other methods is answered with 405 and the methods it allows
+		if allowed := r.allowedMethods(overlay, req.URL.Path); len(allowed) > 0 {
+			r.serveMethodNotAllowed(rw, req, allowed)
+			return
+		}
+
+		r.serveNotFound(rw, req)
+		return
+	}
+	if r.devMode {
+		setDevMatchHeaders(rw.Header(), match, time.Since(matchStart))
+	}
+
+	// Apply the header limits of the group of the route before any work is done for the request
+	if route != nil && route.group != nil {
+		if limits := route.group.GetHeaderLimits(); !withinHeaderLimits(req.Header, limits) {
+			http.Error(rw, http.StatusText(http.StatusRequestHeaderFieldsTooLarge), http.StatusRequestHeaderFieldsTooLarge)
+			return
 		}
+	}
+
+	// Refuse bodies the route does not accept before the client sends them
+	if !r.admitBody(rw, req, route) {
 		return
 	}
 
+	// Wait for a slot when the number of requests in flight is limited. A request dispatched by a
+	// request of this router runs in the slot of its parent, which would otherwise wait for itself
+	if parent, _ := req.Context().Value(activeRequestKey{}).(*activeRequest); r.gate != nil && (route == nil || !route.ungated) && !parent.holds(r) {
+		if !r.gate.acquire(req.Context()) {
+			rw.Header().Set("Retry-After", "1")
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		defer r.gate.release()
+	}
+
+	// Routes excluded from observability are neither counted nor timed nor reported (see WithObservability)
+	observed := route.observed()
+	if !observed {
+		stats = nil
+	}
+
+	// Record the access to the route
+	if stats != nil {
+		stats.hit()
+		if stats.latency != nil {
+			start := time.Now()
+			defer func() { stats.latency.record(time.Since(start)) }()
+		}
+	}
+
+	// Report slow requests once processing has finished
+	var requestParams map[string]string
+	if hook := r.getSlowRequestHook(); hook != nil && observed {
+		start := time.Now()
+		defer func() {
+			info := SlowRequestInfo{
+				Method:   req.Method,
+				Path:     req.URL.Path,
+				Duration: time.Since(start),
+				Params:   requestParams,
+				TimedOut: timeoutOccurred.Load(),
+			}
+			if stats != nil {
+				info.Pattern = stats.pattern
+			}
+			hook.report(info)
+		}()
+	}
+
 	// set processing time limit
 	ctx := req.Context()
 
+	// Make the match source and the matched route definition available to middleware and handlers
+	ctx = context.WithValue(ctx, matchSourceKey{}, match.source)
+	if len(r.proxies) > 0 {
+		ctx = context.WithValue(ctx, trustedProxiesKey{}, r.proxies)
+	}
+	if tenant != "" {
+		ctx = context.WithValue(ctx, tenantKey{}, tenant)
+	}
+	if route != nil {
+		ctx = contextWithRoute(ctx, route)
+	}
+	req = req.WithContext(ctx)
+
+	// Track the request phase so that timeouts can be attributed to the middleware or the handler
+	tracker := &timeoutTracker{tracked: route != nil}
+	ctx = context.WithValue(ctx, timeoutTrackerKey{}, tracker)
+	req = req.WithContext(ctx)
+	defer tracker.finish()
+
+	// The timeout handler writes to the client through its own writer, since the handler may
+	// still be running: once the timeout claims the response, the writes of the handler are
+	// dropped, and a buffered response is kept in memory the handler may still be using
+	buffered := route != nil && route.IsBufferedResponse()
+	if route != nil {
+		rw.cacheControl = route.GetCacheControl()
+		rw.retryAfter = route.GetErrorRetryAfter()
+	}
+	timeoutWriter := &responseWriter{ResponseWriter: w, status: http.StatusOK, discardBody: rw.discardBody, retryAfter: rw.retryAfter}
+
+	// onTimeout calls the timeout handler with the timeout information in the request context
+	onTimeout := func(req *http.Request, info TimeoutInfo) {
+		timeoutOccurred.Store(true)
+
+		// Process only if the handler hasn't written the response yet
+		if rw.claimForTimeout() {
+			defer timeoutWriter.finish()
+			r.mu.RLock()
+			timeoutHandler := r.timeoutHandler
+			r.mu.RUnlock()
+
+			req = req.WithContext(context.WithValue(req.Context(), timeoutInfoKey{}, info))
+			defer r.recoverTimeoutHandler(timeoutWriter, req)
+			if timeoutHandler != nil {
+				timeoutHandler(timeoutWriter, req)
+			} else {
+				// Default timeout processing
+				http.Error(timeoutWriter, "Request timeout", http.StatusGatewayTimeout)
+			}
+		}
+	}
+
 	// Apply the configured timeout if no existing deadline
 	if _, ok := ctx.Deadline(); !ok {
 		// get timeout setting (use route-specific setting if available)
@@ -272,114 +718,189 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 		// Apply timeout only if it's set
 		if timeout > 0 {
-			ctx, cancel = context.WithTimeout(ctx, timeout)
+			ctx, cancel = newTimeoutContext(ctx, timeout)
 			defer cancel() // Prevent context leak
 			req = req.WithContext(ctx)
+			timeoutReq := req
+			timeoutCtx := ctx // ctx is reassigned below, so the goroutine gets its own copy
 
 			// Monitor context cancellation
 			done = make(chan struct{})
+			exited = make(chan struct{})
 
 			// Timeout monitoring goroutine
 			go func() {
+				defer close(exited)
 				select {
-				case <-ctx.Done():
-					if ctx.Err() == context.DeadlineExceeded {
-						// If timeout, call timeout handler
-						timeoutOccurred.Store(true)
-
-						// Process only if response hasn't been written yet
-						if !rw.written {
-							r.mu.RLock()
-							timeoutHandler := r.timeoutHandler
-							r.mu.RUnlock()
-							if timeoutHandler != nil {
-								timeoutHandler(rw, req)
-							} else {
-								// Default timeout processing
-								http.Error(rw, "Request timeout", http.StatusGatewayTimeout)
-							}
-						}
-					}
+				case <-timeoutCtx.Done():
 				case <-done:
 					// Normal processing completed
 				}
+				// Checked after both cases, since a handler returning the deadline error
+				// can complete processing at the same moment the deadline fires
+				if timeoutCtx.Err() == context.DeadlineExceeded {
+					// If timeout, call timeout handler
+					onTimeout(timeoutReq, newTimeoutInfo(route, tracker.current(), timeout))
+				}
 			}()
 		}
 	}
 
-	// If shutting down, call shutdown handler
-	// Since atomic.Bool is used, reading is synchronized
-	// Copy shuttingDown flag to local variable to prevent data race
-	isShuttingDown := r.shuttingDown.Load()
-	if isShuttingDown {
+	// Apply the middleware timeout, which ends when the route handler is called
+	if route != nil && route.GetMiddlewareTimeout() > 0 {
+		middlewareTimeout := route.GetMiddlewareTimeout()
+
+		var cancelMiddleware context.CancelCauseFunc
+		ctx, cancelMiddleware = context.WithCancelCause(ctx)
+		defer cancelMiddleware(nil)
+		req = req.WithContext(ctx)
+		timeoutReq := req
+
+		timer := time.AfterFunc(middlewareTimeout, func() {
+			if tracker.expireMiddleware() {
+				cancelMiddleware(errMiddlewareTimeout)
+				onTimeout(timeoutReq, newTimeoutInfo(route, TimeoutPhaseMiddleware, middlewareTimeout))
+			}
+		})
+		defer timer.Stop()
+	}
+
+	// Count the request as active, unless the router is shutting down (then call the shutdown handler)
+	ctx, active, admitted := r.beginRequest(ctx)
+	if !admitted {
 		r.mu.RLock()
 		shutdownHandler := r.shutdownHandler
 		r.mu.RUnlock()
 		shutdownHandler(rw, req)
 		return
 	}
-
-	// Count active requests
-	// sync.WaitGroup is internally synchronized,
-	// but mutex is used to prevent simultaneous access from multiple goroutines
-	r.wgMu.Lock()
-	r.activeRequests.Add(1)
-	r.wgMu.Unlock()
-
-	defer func() {
-		r.activeRequests.Done() // Call Done without mutex
-	}()
+	req = req.WithContext(ctx)
+	defer active.release()
 
 	// get URL parameters
-	params, paramsFound := r.cache.GetParams(generateRouteKey(methodToUint8(req.Method), normalizePath(req.URL.Path)))
+	params, paramsFound := match.params, match.params != nil
+	requestParams = params
 	if paramsFound && len(params) > 0 {
 		// If parameters could be retrieved from cache
 		ps := r.paramsPool.Get()
 		for k, v := range params {
 			ps.Add(k, v)
 		}
+		route.transformParams(ps)
 		ctx = contextWithParams(ctx, ps)
 		req = req.WithContext(ctx)
 		defer r.paramsPool.Put(ps)
 	}
 
-	// Build middleware chain and execute
-	h := r.buildMiddlewareChain(handler)
-	err := h(rw, req)
+	// Build middleware chain (cached per route) and execute
+	h := r.routeChain(handler, route)
+	if buffered {
+		rw.startBuffering()
+	}
+	var err error
+	if r.devMode {
+		middlewareStart := time.Now()
+		err = callRecovering(h, rw, req)
+		if route != nil {
+			r.warnSlowMiddleware(req, route.fullPath(), middlewareStart, tracker)
+		}
+	} else {
+		err = callRecovering(h, rw, req)
+	}
+	if pe := (*panicError)(nil); errors.As(err, &pe) {
+		r.logf(slog.LevelError, "Handler panic: %s %s: %v\n%s", req.Method, req.URL.Path, pe.value, pe.stack)
+		// A partially written response cannot be replaced, so the connection is aborted
+		// to signal the truncated response to the client
+		if rw.written.Load() && !buffered && !timeoutOccurred.Load() {
+			panic(http.ErrAbortHandler)
+		}
+	}
+
+	// Send a buffered response only if the handler succeeded in time
+	if buffered {
+		if err != nil || timeoutOccurred.Load() {
+			rw.discard()
+		} else {
+			rw.commit()
+		}
+	}
+
+	// Handlers that all passed the request on (see Chain and FirstOf) leave it unhandled
+	if errors.Is(err, ErrNext) && !rw.written.Load() && !timeoutOccurred.Load() {
+		r.serveNotFound(rw, req)
+		return
+	}
 
 	// If an error occurs, call error handler
 	if err != nil {
-		// If timeout has already occurred, do not process
-		if timeoutOccurred.Load() {
+		if stats != nil {
+			stats.errors.Add(1)
+		}
+
+		// If timeout has already occurred, do not process; the timeout handler is responsible for the response
+		if timeoutOccurred.Load() || (done != nil && ctx.Err() == context.DeadlineExceeded) || context.Cause(ctx) == errMiddlewareTimeout {
 			return
 		}
 
 		// Process only if response hasn't been written yet
-		if !rw.written {
-			// Handle panic in error handler
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("Error handler panic: %v", r)
-					if !rw.written {
-						http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-					}
-				}
-			}()
+		if !rw.written.Load() {
+			r.serveError(rw, req, route, err)
+		}
+	}
+}
 
-			// Use route-specific error handler if available
-			var errorHandler func(http.ResponseWriter, *http.Request, error)
-			if route != nil && route.errorHandler != nil {
-				errorHandler = route.errorHandler
-			} else {
-				r.mu.RLock()
-				errorHandler = r.errorHandler
-				r.mu.RUnlock()
+// serveError responds to an error returned by the middleware chain or the handler of the route
+// (nil if the request has no route): panics go to the panic handler if one is set; other errors
+// go to the error handler of the route or group, then the error page for the status, then the
+// router's error handler. A panic in the error handling is answered with 500.
+func (r *Router) serveError(rw *responseWriter, req *http.Request, route *Route, err error) {
+	// Handle panic in error handler
+	defer func() {
+		if v := recover(); v != nil {
+			r.logf(slog.LevelError, "Error handler panic: %v", v)
+			if !rw.written.Load() {
+				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			}
+		}
+	}()
 
-			// Call error handler
-			errorHandler(rw, req, err)
+	// Panics go to the panic handler if one is set
+	if pe := (*panicError)(nil); errors.As(err, &pe) {
+		if panicHandler := r.getPanicHandler(); panicHandler != nil {
+			panicHandler(rw, req, pe.value)
+			return
 		}
 	}
+
+	// Use route-specific (or group-specific) error handler if available,
+	// then the error page for the status, then the router's error handler
+	var errorHandler func(http.ResponseWriter, *http.Request, error)
+	if route != nil {
+		errorHandler = route.ownErrorHandler()
+	}
+	if errorHandler == nil {
+		if r.serveErrorPage(rw, req, statusFromError(err), err) {
+			return
+		}
+		errorHandler = r.GetErrorHandler()
+	}
+
+	// Call error handler
+	errorHandler(rw, req, err)
+}
+
+// serveNotFound responds to a request that no route handles,
+// with the custom handler if set, then the error page, then the default 404 response.
+func (r *Router) serveNotFound(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	notFoundHandler := r.notFoundHandler
+	r.mu.RUnlock()
+
+	if notFoundHandler != nil {
+		notFoundHandler(w, req)
+	} else if !r.serveErrorPage(w, req, http.StatusNotFound, nil) {
+		http.NotFound(w, req)
+	}
 }
 
 // buildMiddlewareChain applies all middleware to a handler function,
@@ -389,71 +910,220 @@ func (r *Router) buildMiddlewareChain(final HandlerFunc) HandlerFunc {
 	return applyMiddlewareChain(final, middleware)
 }
 
-// findHandlerAndRoute searches for a handler and route that matches the request path and method.
+// routeMatch is the result of a route lookup.
+type routeMatch struct {
+	handler HandlerFunc       // Handler of the matched route
+	route   *Route            // Route definition (nil for routes registered with Handle)
+	stats   *routeStats       // Usage statistics of the route
+	source  MatchOrigin       // Where the route was found
+	params  map[string]string // Parameters of a dynamic route (nil for static routes)
+}
+
+// findRoute searches for the handler, route, and usage statistics that match the request path and method.
 // It uses cache for fast search and falls back to static routes and dynamic routes if not in cache.
-func (r *Router) findHandlerAndRoute(method, path string) (HandlerFunc, *Route, bool) {
+// A HEAD request without a HEAD route of its own is answered by the GET route of the path, as
+// required by RFC 9110; the response writer discards the body of HEAD responses.
+func (r *Router) findRoute(method, path string) (routeMatch, bool) {
 	// Normalize path
 	path = normalizePath(path)
 
 	// Convert HTTP method to value
 	methodIndex := methodToUint8(method)
 	if methodIndex == 0 {
-		return nil, nil, false
+		return routeMatch{}, false
 	}
 
+	match, found := r.findMethodRoute(methodIndex, path)
+	if !found && methodIndex == headMethodIndex {
+		return r.findMethodRoute(getMethodIndex, path)
+	}
+	return match, found
+}
+
+// findMethodRoute is findRoute for the method index and the normalized path, without the HEAD fallback.
+func (r *Router) findMethodRoute(methodIndex uint8, path string) (routeMatch, bool) {
 	// Generate cache key
 	key := generateRouteKey(methodIndex, path)
 
-	// Check cache
-	if handler, found := r.cache.get(key); found {
-		// cache hit
-		return handler, nil, true
+	// Check cache (bypassed in development mode)
+	if !r.devMode {
+		if cached, found := r.routeCache.Get(key); found {
+			// cache hit
+			return routeMatch{handler: cached.handler, route: cached.route, stats: cached.stats, source: MatchFromCache, params: cached.params}, true
+		}
 	}
 
 	// search static route
-	if handler := r.static.search(path); handler != nil {
+	if handler, route, stats := r.static.searchMethodRoute(methodIndex, path); handler != nil {
 		// If static route is found, add to cache
-		r.cache.set(key, handler, nil)
-		return handler, nil, true
+		if !r.devMode {
+			r.routeCache.Set(key, CachedRoute{handler: handler, route: route, stats: stats})
+		}
+		return routeMatch{handler: handler, route: route, stats: stats, source: MatchFromStatic}, true
+	}
+
+	// Match learned pattern plans before traversing the tree
+	if r.patternCache != nil && !r.devMode {
+		if matchedNode, params, ok := r.patternCache.match(methodIndex, path); ok {
+			return routeMatch{
+				handler: matchedNode.handler,
+				route:   matchedNode.route,
+				stats:   matchedNode.stats,
+				source:  MatchFromPattern,
+				params:  params,
+			}, true
+		}
 	}
 
 	// search dynamic route
-	nodeIndex := methodIndex - 1
-	node := r.dynamic[nodeIndex]
-	if node != nil {
-		// get parameter object from pool
-		params := r.paramsPool.Get()
-		handler, matched := node.match(path, params)
-		if matched && handler != nil {
-			// If dynamic route is found, add to cache
-			// Convert parameters to map
-			paramsMap := make(map[string]string, params.Len())
-			for i := 0; i < params.Len(); i++ {
-				key, val := params.data[i].key, params.data[i].value
-				paramsMap[key] = val
-			}
-			r.cache.set(key, handler, paramsMap)
+	node := r.dynamic[methodIndex-1]
+	if node == nil {
+		// Route not found
+		return routeMatch{}, false
+	}
+	if r.devMode {
+		return r.matchDynamic(node, methodIndex, key, path)
+	}
+	// Concurrent misses of the same path share one traversal and cache fill
+	return r.fills.do(key, func() (routeMatch, bool) {
+		return r.matchDynamic(node, methodIndex, key, path)
+	})
+}
 
-			// Return parameter object to pool
-			r.paramsPool.Put(params)
-			return handler, nil, true
-		}
-		// Return parameter object to pool
-		r.paramsPool.Put(params)
+// matchDynamic matches the path against the dynamic tree of the method and caches the result.
+func (r *Router) matchDynamic(node *node, methodIndex uint8, key uint64, path string) (routeMatch, bool) {
+	// get parameter object from pool
+	params := r.paramsPool.Get()
+	defer r.paramsPool.Put(params)
+	params.regexLimit = r.maxRegexEvals
+	matchedNode, matched := node.matchNode(path, params)
+	if !matched || matchedNode.handler == nil {
+		// Route not found
+		return routeMatch{}, false
+	}
+
+	// If dynamic route is found, add to cache
+	// Convert parameters to map
+	paramsMap := make(map[string]string, params.Len())
+	for i := 0; i < params.Len(); i++ {
+		key, val := params.data[i].key, params.data[i].value
+		paramsMap[key] = val
+	}
+	// With pattern caching, the pattern is learned instead of caching the URL
+	switch {
+	case r.devMode:
+	case r.patternCache != nil:
+		r.patternCache.learn(methodIndex, node, matchedNode)
+	default:
+		r.routeCache.Set(key, CachedRoute{handler: matchedNode.handler, route: matchedNode.route, stats: matchedNode.stats, params: paramsMap})
+	}
+
+	return routeMatch{
+		handler: matchedNode.handler,
+		route:   matchedNode.route,
+		stats:   matchedNode.stats,
+		source:  MatchFromDynamic,
+		params:  paramsMap,
+	}, true
+}
+
+// Match reports the route pattern that would handle the specified method and path,
+// without executing any handler or touching the route cache.
+// It is intended for tests and tooling that only care about which route matches.
+func (r *Router) Match(method, path string) (string, bool) {
+	params := r.paramsPool.Get()
+	defer r.paramsPool.Put(params)
+	pattern, _, ok := r.matchServedRoute(method, path, params)
+	return pattern, ok
+}
+
+// matchServedRoute works like matchRoute, but falls back from HEAD to the GET route like
+// findRoute, so that it reports the route that serves the request.
+func (r *Router) matchServedRoute(method, path string, params *Params) (string, *Route, bool) {
+	pattern, route, ok := r.matchRoute(method, path, params)
+	if !ok && method == http.MethodHead {
+		params.reset()
+		return r.matchRoute(http.MethodGet, path, params)
+	}
+	return pattern, route, ok
+}
+
+// matchPattern searches the static and dynamic routes and returns the matched route pattern.
+// Parameters of a dynamic route are added to params.
+func (r *Router) matchPattern(method, path string, params *Params) (string, bool) {
+	pattern, _, ok := r.matchRoute(method, path, params)
+	return pattern, ok
+}
+
+// matchRoute works like matchPattern but also returns the route definition of the match
+// (nil for routes registered with Handle).
+func (r *Router) matchRoute(method, path string, params *Params) (string, *Route, bool) {
+	// Normalize path
+	path = normalizePath(path)
+
+	// Convert HTTP method to value
+	methodIndex := methodToUint8(method)
+	if methodIndex == 0 {
+		return "", nil, false
 	}
 
-	// Route not found
-	return nil, nil, false
+	// search static route (the pattern of a static route is the path itself)
+	if handler, route, _ := r.static.searchMethodRoute(methodIndex, path); handler != nil {
+		return path, route, true
+	}
+
+	// search dynamic route
+	node := r.dynamic[methodIndex-1]
+	if node == nil {
+		return "", nil, false
+	}
+	params.regexLimit = r.maxRegexEvals
+	matchedNode, matched := node.matchNode(path, params)
+	if !matched {
+		return "", nil, false
+	}
+	return matchedNode.pattern, matchedNode.route, true
 }
 
 // Handle registers a new route. If the pattern is static, it registers in doubleArrayTrie,
 // if it contains dynamic parameters, it registers in Radix tree.
 // It also validates the pattern, HTTP method, and handler function.
-// If static routes and dynamic routes conflict, static routes take precedence.
-// Route processing is determined by the allowRouteOverride option:
-// - true: The later registered route overwrites the existing route.
-// - false: If a duplicate route is detected, an error is returned (default).
+// Like a dynamic route, a static route answers only its own method: a request for a path whose
+// routes are all for other methods is answered with 405 (see SetMethodNotAllowedHandler).
+// A HEAD request for a path without a HEAD route is answered by its GET route, without the body.
+// Static and dynamic routes can overlap regardless of the order they are registered in:
+// for /users/admin and /users/{id}, the static route answers /users/admin and the dynamic route
+// every other user. Within dynamic routes, static segments take precedence over parameters at the
+// same position, so /users/admin/{tab} answers /users/admin/posts before /users/{id}/{tab}.
+// An anonymous segment {} matches any one segment without capturing a parameter, which is
+// cheaper than a named parameter when the value is not needed, as in /api/{}/health;
+// {:regex} constrains an anonymous segment.
+// A catch-all segment {*name}, which must be the last segment, matches one or more remaining
+// segments and captures them with their slashes under name ({*} captures them as "*"):
+// /assets/{*filepath} answers /assets/css/site.css with filepath "css/site.css".
+// Duplicate routes are resolved by RouterOptions.OverrideStrategy:
+// by default an error is returned, and OverrideLastWins overwrites the existing route.
 func (r *Router) Handle(method, pattern string, h HandlerFunc) error {
+	if err := r.checkMutable("Handle"); err != nil {
+		return err
+	}
+	if err := r.handle(method, pattern, h, nil); err != nil {
+		return err
+	}
+
+	// Keep the registration so that Clone can replay it
+	r.mu.Lock()
+	r.handled = append(r.handled, handledRoute{method: method, pattern: pattern, handler: h})
+	r.mu.Unlock()
+
+	r.routeRegistered(RouteInfo{Method: method, Pattern: normalizePath(pattern)})
+	return nil
+}
+
+// handle is the implementation of Handle.
+// route is the Route definition that produced the handler; it is made available to
+// the request context at serving time (nil for routes registered with Handle).
+func (r *Router) handle(method, pattern string, h HandlerFunc, route *Route) error {
 	// Validate pattern
 	if pattern == "" {
 		return &RouterError{Code: ErrInvalidPattern, Message: "empty pattern"}
@@ -469,7 +1139,11 @@ func (r *Router) Handle(method, pattern string, h HandlerFunc) error {
 	if err := validateMethod(method); err != nil {
 		return err
 	}
-	if err := validatePattern(pattern); err != nil {
+	if err := validatePatternWith(pattern, r.segmentChars); err != nil {
+		return err
+	}
+	pattern, err := decodeStaticSegments(pattern)
+	if err != nil {
 		return err
 	}
 
@@ -482,50 +1156,53 @@ func (r *Router) Handle(method, pattern string, h HandlerFunc) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Static route case
+	// New routes can make cached matches and learned pattern plans ambiguous
+	r.routeCache.Invalidate()
+	if r.patternCache != nil {
+		r.patternCache.reset()
+	}
+	if r.firstSegments != nil {
+		r.firstSegments.add(methodIndex, pattern)
+	}
+
+	// Static route case (static routes are keyed by method and path, see methodKey)
 	if isStatic {
+		key := methodKey(methodIndex, pattern)
 		// Duplicate check for static route
-		existingHandler := r.static.search(pattern)
+		existingHandler := r.static.search(key)
 		if existingHandler != nil {
 			// If duplicate is found
-			if !r.allowRouteOverride {
-				return &RouterError{Code: ErrInvalidPattern, Message: "duplicate static route: " + pattern}
-			}
-			// If overwrite mode, overwrite existing route
-			return r.static.Add(pattern, h)
-		}
-
-		// Dynamic route and static route conflict check
-		nodeIndex := methodIndex - 1
-		node := r.dynamic[nodeIndex]
-		if node != nil {
-			params := NewParams()
-			existingHandler, matched := node.match(pattern, params)
-			PutParams(params) // Return parameter object to pool
-
-			// If dynamic route already exists
-			if matched && existingHandler != nil {
-				if !r.allowRouteOverride {
-					return &RouterError{Code: ErrInvalidPattern, Message: "route already registered as dynamic route: " + pattern}
-				}
-				// If overwrite mode, prioritize static route (overwrite dynamic route)
+			switch r.override {
+			case OverrideLastWins:
+				// Overwrite the existing route
+				return r.static.addRoute(key, h, route, r.routeStatsFor(method, pattern))
+			case OverrideFirstWins:
+				// Keep the existing route
+				return nil
 			}
+			return r.duplicateError(&RouterError{Code: ErrInvalidPattern, Message: "duplicate static route: " + pattern, Err: ErrDuplicateRoute})
 		}
 
+		// A dynamic route matching the path, such as /users/{id} for /users/admin, does not conflict:
+		// the static route answers its own path and the dynamic route remains the fallback
 		// Register new static route
-		return r.static.Add(pattern, h)
+		return r.static.addRoute(key, h, route, r.routeStatsFor(method, pattern))
 	}
 
 	// Dynamic route case
 	// Static route and dynamic route conflict check
-	existingHandler := r.static.search(pattern)
+	existingHandler := r.static.search(methodKey(methodIndex, pattern))
 	if existingHandler != nil {
 		// If static route already exists
-		if !r.allowRouteOverride {
-			return &RouterError{Code: ErrInvalidPattern, Message: "route already registered as static route: " + pattern}
+		switch r.override {
+		case OverrideLastWins:
+			// Static routes take precedence, so they cannot be overwritten by a dynamic route
+			return &RouterError{Code: ErrInvalidPattern, Message: "cannot override static route with dynamic route: " + pattern, Err: ErrDuplicateRoute}
+		case OverrideFirstWins:
+			// Keep the static route
+			return nil
 		}
-		// If overwrite mode, prioritize static route (return error)
-		return &RouterError{Code: ErrInvalidPattern, Message: "cannot override static route with dynamic route: " + pattern}
+		return r.duplicateError(&RouterError{Code: ErrInvalidPattern, Message: "route already registered as static route: " + pattern, Err: ErrDuplicateRoute})
 	}
 
 	// Register dynamic route
@@ -533,20 +1210,37 @@ func (r *Router) Handle(method, pattern string, h HandlerFunc) error {
 	node := r.dynamic[nodeIndex]
 	if node == nil {
 		// Initialize dynamic route tree for this HTTP method
-		node = newNode("")
+		node = newRootNode()
 		r.dynamic[nodeIndex] = node
 	}
 
 	// Check existing dynamic route
-	if r.allowRouteOverride {
-		// If overwrite mode, remove existing route before adding
-		node.removeRoute(segments)
+	switch r.override {
+	case OverrideLastWins:
+		// Replace the handler of the identical pattern in place; sibling patterns are distinct routes
+		if existing := node.findRoute(segments); existing != nil && existing.handler != nil {
+			existing.handler = h
+			existing.route = route
+			existing.stats = r.routeStatsFor(method, pattern)
+			return nil
+		}
+	case OverrideFirstWins:
+		if existing := node.findRoute(segments); existing != nil && existing.handler != nil {
+			// Keep the existing route
+			return nil
+		}
 	}
 
 	// Add route
 	if err := node.addRoute(segments, h); err != nil {
+		if IsDuplicate(err) {
+			return r.duplicateError(err)
+		}
 		return err
 	}
+	registered := node.findRoute(segments)
+	registered.route = route
+	registered.stats = r.routeStatsFor(method, pattern)
 
 	return nil
 }
@@ -578,6 +1272,12 @@ func isDynamicSeg(seg string) bool {
 	return seg[0] == '{' && seg[len(seg)-1] == '}'
 }
 
+// isCatchAllSeg determines whether a segment is a catch-all parameter ({*name} or {*} format),
+// which matches the rest of the path and must be the last segment of a pattern.
+func isCatchAllSeg(seg string) bool {
+	return len(seg) >= 3 && seg[0] == '{' && seg[1] == '*' && seg[len(seg)-1] == '}'
+}
+
 // generateRouteKey generates a cache key from HTTP method and path.
 // It uses FNV-1a hashing algorithm for fast unique key generation.
 func generateRouteKey(method uint8, path string) uint64 {
@@ -603,6 +1303,15 @@ func generateRouteKey(method uint8, path string) uint64 {
 	return hash
 }
 
+// Indexes of the methods a HEAD request falls back between (see findRoute).
+const (
+	getMethodIndex  = 1
+	headMethodIndex = 6
+)
+
+// maxMethodIndex is the largest index assigned by methodToUint8.
+const maxMethodIndex = 7
+
 // methodToUint8 converts the HTTP method string to its internal numeric representation.
 // It assigns values 1-7 to each method and returns 0 for unsupported methods.
 // This value is used as the index in the dynamic array.
@@ -627,6 +1336,29 @@ func methodToUint8(m string) uint8 {
 	}
 }
 
+// uint8ToMethod converts the internal numeric representation of a method back to its string
+// (the empty string for indexes not assigned by methodToUint8).
+func uint8ToMethod(m uint8) string {
+	switch m {
+	case 1:
+		return http.MethodGet
+	case 2:
+		return http.MethodPost
+	case 3:
+		return http.MethodPut
+	case 4:
+		return http.MethodDelete
+	case 5:
+		return http.MethodPatch
+	case 6:
+		return http.MethodHead
+	case 7:
+		return http.MethodOptions
+	default:
+		return ""
+	}
+}
+
 // contextWithParams adds URL parameters to the request context.
 // This allows accessing parameters in handler functions using GetParams(r.Context()).
 func contextWithParams(ctx context.Context, ps *Params) context.Context {
@@ -636,35 +1368,58 @@ func contextWithParams(ctx context.Context, ps *Params) context.Context {
 // Shutdown gracefully shuts down the router.
 // It stops accepting new requests and waits for existing requests to complete.
 // If the specified context is canceled, it stops waiting and returns an error.
+// The cleanup middleware (see Cleanups) is cleaned up after the wait, so that the requests still
+// running never lose the resources it holds, such as database pools. Every cleanup runs even if
+// an earlier one fails, and only on the first call; the errors are returned with the wait error.
+//
+// Every request is either admitted before Shutdown is called, and then runs to completion while
+// Shutdown waits for it, or receives the shutdown handler; no handler starts after Shutdown has
+// returned. Requests that admitted requests dispatch (see Dispatch) are still served.
+//
+// A handler of the router may call Shutdown, for example on an admin "/shutdown" endpoint, with a
+// context derived from its request context: the calling request (and any request that dispatched
+// it) is then not waited for. Use context.WithoutCancel to wait longer than the request timeout.
+//
+// 例: r.Post("/admin/shutdown", func(w http.ResponseWriter, req *http.Request) error { return r.Shutdown(req.Context()) })
 func (r *Router) Shutdown(ctx context.Context) error {
-	// set shuttingDown flag
-	r.shuttingDown.Store(true)
+	// Stop admitting new requests; requests admitted before are waited for
+	drained := r.beginShutdown()
+	r.notifyShutdown()
 
 	// stop cache cleanup loop
-	r.cache.stop()
-
-	// Clean up cleanupable middleware
-	cleanupMws := r.cleanupMws.Load().([]cleanupMiddleware)
-	for _, cm := range cleanupMws {
-		if err := cm.Cleanup(); err != nil {
-			return err
-		}
+	r.routeCache.Close()
+	r.mu.RLock()
+	for _, overlay := range r.tenants {
+		overlay.routeCache.Close()
 	}
+	r.mu.RUnlock()
 
-	// Wait for active requests to complete
-	waitCh := make(chan struct{})
-	go func() {
-		r.activeRequests.Wait()
-		close(waitCh)
-	}()
+	// Wait for active requests to complete, except the requests calling Shutdown
+	r.releaseCallers(ctx)
 
 	// Wait for context cancellation or all requests to complete
+	var waitErr error
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
-	case <-waitCh:
-		return nil
+		waitErr = ctx.Err()
+	case <-drained:
 	}
+
+	// Clean up cleanupable middleware in reverse registration order, once the handlers using
+	// their resources have completed (or the wait has been given up)
+	r.cleanupOnce.Do(func() {
+		var errs []error
+		for _, cm := range r.Cleanups() {
+			if err := cm.Cleanup(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		r.cleanupErr = errors.Join(errs...)
+	})
+	if r.cleanupErr != nil {
+		return errors.Join(waitErr, r.cleanupErr)
+	}
+	return waitErr
 }
 
 // shutdownWithTimeoutContext gracefully shuts down the router with a timeout.
@@ -689,6 +1444,8 @@ func (r *Router) MustHandle(method, pattern string, h HandlerFunc) {
 // If static routes and dynamic routes conflict, static routes take precedence.
 // Other duplicate patterns (e.g., duplicate registration of the same path) are errors.
 func (r *Router) Route(method, pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	r.mustBeMutable("Route")
+
 	// Normalize path
 	pattern = normalizePath(pattern)
 
@@ -708,6 +1465,9 @@ func (r *Router) Route(method, pattern string, h HandlerFunc, middleware ...Midd
 	if len(middleware) > 0 {
 		route.middleware = append(route.middleware, middleware...)
 	}
+	if r.recordSites {
+		route.site = callSite()
+	}
 
 	// Add route to router
 	r.routes = append(r.routes, route)
@@ -753,50 +1513,48 @@ func (r *Router) Options(pattern string, h HandlerFunc, middleware ...Middleware
 
 // Build registers all routes.
 // This method must be explicitly called.
-// Route processing is determined by the router's allowRouteOverride option:
-// - true: The later registered route overwrites the existing route.
-// - false: If a duplicate route is detected, an error is returned (default).
+// Duplicate routes are resolved by RouterOptions.OverrideStrategy:
+// by default an error is returned, and OverrideLastWins lets the later route overwrite the earlier one.
 func (r *Router) Build() error {
-	// Global duplicate check map
-	globalRouteMap := make(map[string]string)
-
-	// Temporarily save directly registered routes
-	directRoutes := make([]*Route, len(r.routes))
-	copy(directRoutes, r.routes)
+	return r.buildSelected(nil)
+}
 
-	// Collect routes for groups
-	var allGroupRoutes []*Route
-	for i, group := range r.groups {
-		groupID := "group" + strconv.Itoa(i)
-		groupRoutes, err := r.collectGroupRoutes(group, globalRouteMap, groupID)
-		if err != nil && !r.allowRouteOverride {
-			return err
-		}
-		allGroupRoutes = append(allGroupRoutes, groupRoutes...)
+// buildSelected is the implementation of Build, BuildOnly, and BuildExcept.
+// Only the routes for which selected returns true are built (all routes if selected is nil).
+func (r *Router) buildSelected(selected func(*Route) bool) error {
+	if err := r.checkMutable("Build"); err != nil {
+		return err
+	}
+	r.selectRoute = selected
+	err := r.build()
+	r.selectRoute = nil
+	if err == nil {
+		err = r.buildTenants(selected)
 	}
 
-	// Pre-check all routes (check for duplicates and invalid patterns)
-	for _, route := range directRoutes {
-		// Generate route information in advance
-		routeKey := route.method + ":" + route.subPath
+	// Record the outcome for Report
+	r.mu.Lock()
+	r.buildStatus = BuildReport{Built: err == nil, Time: time.Now()}
+	if err != nil {
+		r.buildStatus.Error = err.Error()
+	}
+	report := r.buildStatus
+	r.mu.Unlock()
 
-		// Duplicate check
-		if existingRoute, exists := globalRouteMap[routeKey]; exists {
-			if !r.allowRouteOverride {
-				return &RouterError{
-					Code:    ErrInvalidPattern,
-					Message: "duplicate route definition: " + route.method + " " + route.subPath + " (conflicts with " + existingRoute + ")",
-				}
-			}
-			// If overwrite mode, output warning
-			log.Printf("Warning: overriding route: %s %s (previously defined as %s)",
-				route.method, route.subPath, existingRoute)
-		}
+	r.buildCompleted(report)
+	return err
+}
 
-		// Add route information to map
-		routeInfo := "router:" + route.method + " " + route.subPath
-		globalRouteMap[routeKey] = routeInfo
+// build is the implementation of Build.
+func (r *Router) build() error {
+	// Collect the routes, resolving duplicates by the override strategy
+	directRoutes, allGroupRoutes, err := r.collectRoutes()
+	if err != nil {
+		return err
+	}
 
+	// Pre-check all routes (check for invalid patterns)
+	for _, route := range slices.Concat(directRoutes, allGroupRoutes) {
 		// Apply middleware to handler
 		var handler HandlerFunc
 		if len(route.middleware) > 0 {
@@ -806,69 +1564,86 @@ func (r *Router) Build() error {
 		}
 
 		// Route validation (actually not registered)
-		if err := r.validateRoute(route.method, route.subPath, handler); err != nil {
+		if err := r.validateRoute(route.method, route.fullPath(), handler); err != nil {
 			return err
 		}
 	}
 
-	// Pre-check routes for groups
-	for _, route := range allGroupRoutes {
-		// Calculate full path
-		var fullPath string
-		if route.group != nil {
-			fullPath = joinPath(route.group.prefix, normalizePath(route.subPath))
-		} else {
-			fullPath = route.subPath
-		}
+	// Enforce the registered policies before anything is registered
+	if err := r.runBuildChecks(slices.Concat(directRoutes, allGroupRoutes)); err != nil {
+		return err
+	}
 
-		// Generate route information in advance
-		routeKey := route.method + ":" + fullPath
+	// If all checks pass, actually register
+	for _, route := range slices.Concat(directRoutes, allGroupRoutes) {
+		if err := route.build(); err != nil && !r.toleratesBuildError(err) {
+			return err
+		}
+	}
 
-		// Duplicate check
-		if existingRoute, exists := globalRouteMap[routeKey]; exists {
-			if !r.allowRouteOverride {
-				return &RouterError{
-					Code:    ErrInvalidPattern,
-					Message: "duplicate route definition: " + route.method + " " + fullPath + " (conflicts with " + existingRoute + ")",
-				}
+	// Apply route priorities once the whole tree is known
+	builtRoutes := slices.Concat(directRoutes, allGroupRoutes)
+	for _, route := range builtRoutes {
+		if route.priority == 0 {
+			continue
+		}
+		if !r.applyRoutePriority(route.method, route.fullPath(), route.priority) {
+			return &RouterError{
+				Code:    ErrInvalidPattern,
+				Message: "priority has no effect on route without overlapping dynamic routes: " + route.method + " " + route.fullPath(),
 			}
-			// If overwrite mode, output warning
-			log.Printf("Warning: overriding route: %s %s (previously defined as %s)",
-				route.method, fullPath, existingRoute)
 		}
+	}
 
-		// Add route information to map
-		routeInfo := "group:" + route.method + " " + fullPath
-		globalRouteMap[routeKey] = routeInfo
+	// Shrink the static trie now that all routes are registered
+	before, after := r.static.Compact()
+	if r.devMode && after < before {
+		r.logf(slog.LevelInfo, "Static route trie compacted: %d -> %d slots", before, after)
+	}
 
-		// Apply middleware to handler
-		var handler HandlerFunc
-		if len(route.middleware) > 0 {
-			handler = applyMiddlewareChain(route.handler, route.middleware)
-		} else {
-			handler = route.handler
-		}
+	if err := r.checkRouteTable(builtRoutes); err != nil {
+		return err
+	}
+	if r.devMode {
+		r.reportDevDiagnostics(builtRoutes)
+	}
 
-		// Route validation (actually not registered)
-		if err := r.validateRoute(route.method, fullPath, handler); err != nil {
-			return err
-		}
+	return nil
+}
+
+// applyRoutePriority assigns the priority to the dynamic segments of the route.
+// It returns false if no segment of the route overlaps with another dynamic route.
+func (r *Router) applyRoutePriority(method, pattern string, priority int) bool {
+	methodIndex := methodToUint8(method)
+	if methodIndex == 0 {
+		return false
 	}
 
-	// If all checks pass, actually register
-	for _, route := range directRoutes {
-		if err := route.build(); err != nil && !r.allowRouteOverride {
-			return err
-		}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	node := r.dynamic[methodIndex-1]
+	if node == nil {
+		return false
 	}
+	return node.setPriority(parseSegments(pattern), priority)
+}
 
-	for _, route := range allGroupRoutes {
-		if err := route.build(); err != nil && !r.allowRouteOverride {
-			return err
+// allGroups returns all groups registered with the router, including nested child groups.
+// Parent groups are listed before their children.
+func (r *Router) allGroups() []*Group {
+	var groups []*Group
+	var walk func(g *Group)
+	walk = func(g *Group) {
+		groups = append(groups, g)
+		for _, child := range g.children {
+			walk(child)
 		}
 	}
-
-	return nil
+	for _, g := range r.groups {
+		walk(g)
+	}
+	return groups
 }
 
 // validateRoute checks the route but does not actually register it.
@@ -893,33 +1668,72 @@ func (r *Router) validateRoute(method, pattern string, h HandlerFunc) error {
 	return nil
 }
 
-// collectGroupRoutes collects all routes in a group and performs global duplicate check.
-func (r *Router) collectGroupRoutes(group *Group, globalRouteMap map[string]string, groupID string) ([]*Route, error) {
-	var routes []*Route
-
-	// Collect routes in group
-	for _, route := range group.routes {
-		if route.applied {
-			continue
+// collectRoutes collects the routes registered directly with the router and the unapplied routes
+// of the groups, and resolves duplicate registrations by the override strategy (see
+// resolveDuplicate), dropping the routes that lose. Group routes are collected first, so a route
+// registered directly is the later registration of a duplicate between the two.
+func (r *Router) collectRoutes() (direct, grouped []*Route, err error) {
+	type registration struct {
+		route *Route
+		desc  string // Owner and description of the route, such as "group0:GET /api/users"
+	}
+	seen := make(map[string]registration)
+	dropped := make(map[*Route]bool)
+	add := func(route *Route, owner string) error {
+		fullPath := route.fullPath()
+		key := route.method + ":" + fullPath
+		desc := route.method + " " + fullPath + route.details()
+		existing, exists := seen[key]
+		if !exists {
+			seen[key] = registration{route: route, desc: owner + ":" + desc}
+			return nil
 		}
+		replace, err := r.resolveDuplicate(desc, existing.desc)
+		if err != nil {
+			return err
+		}
+		if replace {
+			dropped[existing.route] = true
+			seen[key] = registration{route: route, desc: owner + ":" + desc}
+		} else {
+			dropped[route] = true
+		}
+		return nil
+	}
 
-		// Calculate full path
-		fullPath := joinPath(group.prefix, normalizePath(route.subPath))
-		routeKey := route.method + ":" + fullPath
-
-		// Global duplicate check
-		if existingRoute, exists := globalRouteMap[routeKey]; exists {
-			return nil, &RouterError{
-				Code:    ErrInvalidPattern,
-				Message: "duplicate route definition: " + route.method + " " + fullPath + " (conflicts with " + existingRoute + ")",
+	for i, group := range r.allGroups() {
+		groupID := "group" + strconv.Itoa(i)
+		if group.module != "" {
+			groupID = "module " + strconv.Quote(group.module)
+		}
+		for _, route := range group.routes {
+			if route.applied || (r.selectRoute != nil && !r.selectRoute(route)) {
+				continue
+			}
+			if err := add(route, groupID); err != nil {
+				return nil, nil, err
 			}
+			grouped = append(grouped, route)
 		}
-		globalRouteMap[routeKey] = groupID + ":" + route.method + " " + fullPath
-
-		routes = append(routes, route)
 	}
+	for _, route := range r.routes {
+		if r.selectRoute != nil && !r.selectRoute(route) {
+			continue
+		}
+		if err := add(route, "router"); err != nil {
+			return nil, nil, err
+		}
+		direct = append(direct, route)
+	}
+
+	isDropped := func(route *Route) bool { return dropped[route] }
+	return slices.DeleteFunc(direct, isDropped), slices.DeleteFunc(grouped, isDropped), nil
+}
 
-	return routes, nil
+// toleratesBuildError reports whether Build continues after a route failed to register, which
+// is the case for duplicates that the override strategy resolves (outside strict mode).
+func (r *Router) toleratesBuildError(err error) bool {
+	return r.override.overrides() && !r.strict && IsDuplicate(err)
 }
 
 // SetRequestTimeout sets the request processing timeout time.
@@ -932,6 +1746,9 @@ func (r *Router) SetRequestTimeout(timeout time.Duration) {
 
 // GetRequestTimeout returns the currently set request processing timeout time.
 func (r *Router) GetRequestTimeout() time.Duration {
+	if r.tenantParent != nil {
+		return r.tenantParent.GetRequestTimeout()
+	}
 	r.timeoutMu.RLock()
 	defer r.timeoutMu.RUnlock()
 	return r.requestTimeout
@@ -939,6 +1756,8 @@ func (r *Router) GetRequestTimeout() time.Duration {
 
 // TimeoutSettings returns the timeout settings for the router, group, and route as a string.
 // It shows the inheritance relationship and override status.
+//
+// Deprecated: Use Report, which returns the same information as a machine-readable structure.
 func (r *Router) TimeoutSettings() string {
 	var result strings.Builder
 
@@ -992,6 +1811,11 @@ func buildGroupTimeoutSettings(group *Group, indent int) string {
 		result.WriteString(routeInfo)
 	}
 
+	// Child group setting
+	for _, child := range group.children {
+		result.WriteString(buildGroupTimeoutSettings(child, indent+1))
+	}
+
 	return result.String()
 }
 
@@ -1012,6 +1836,9 @@ func buildRouteTimeoutSettings(route *Route, indent int) string {
 // GetErrorHandler returns the default error handler for the router.
 // If no error handler is set, it returns the default error handler.
 func (r *Router) GetErrorHandler() func(http.ResponseWriter, *http.Request, error) {
+	if r.tenantParent != nil {
+		return r.tenantParent.GetErrorHandler()
+	}
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	if r.errorHandler != nil {
@@ -1022,6 +1849,8 @@ func (r *Router) GetErrorHandler() func(http.ResponseWriter, *http.Request, erro
 
 // ErrorHandlerSettings returns the error handler settings for the router, group, and route as a string.
 // It shows the inheritance relationship and override status.
+//
+// Deprecated: Use Report, which returns the same information as a machine-readable structure.
 func (r *Router) ErrorHandlerSettings() string {
 	var result strings.Builder
 
@@ -1083,6 +1912,11 @@ func buildGroupErrorHandlerSettings(group *Group, indent int) string {
 		result.WriteString(routeInfo)
 	}
 
+	// Child group settings
+	for _, child := range group.children {
+		result.WriteString(buildGroupErrorHandlerSettings(child, indent+1))
+	}
+
 	return result.String()
 }
 
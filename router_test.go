@@ -5,6 +5,7 @@ package router
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -122,8 +123,8 @@ func TestBasicFunctionality(t *testing.T) {
 			name:           "Method not allowed",
 			method:         http.MethodDelete,
 			path:           prefix + "/home",
-			expectedStatus: http.StatusOK,
-			expectedBody:   "Home",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method Not Allowed\n",
 		},
 	}
 
@@ -350,7 +351,7 @@ func TestParamsExtraction(t *testing.T) {
 // TestDynamicRouting tests dynamic routing
 func TestDynamicRouting(t *testing.T) {
 	// Create a new node
-	node := newNode("")
+	node := newRootNode()
 
 	// Test handler function
 	handler := func(w http.ResponseWriter, r *http.Request) error {
@@ -1430,6 +1431,19 @@ func TestMustHandle(t *testing.T) {
 			return nil
 		})
 	})
+
+	// 無効な正規表現（パニックが発生することを期待）
+	t.Run("Invalid regex", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("MustHandle should panic with an invalid regex")
+			}
+		}()
+
+		r.MustHandle(http.MethodGet, prefix+"/regex/{id:[z-a]}", func(w http.ResponseWriter, r *http.Request) error {
+			return nil
+		})
+	})
 }
 
 // TestErrorHandlerSettings tests the error handler settings functionality
@@ -1684,11 +1698,6 @@ func TestNilHandlerRegistration(t *testing.T) {
 
 // TestInvalidRegexPattern tests registration of invalid regex patterns
 func TestInvalidRegexPattern(t *testing.T) {
-	// レースディテクタが有効な場合はスキップ（パニックを防ぐため）
-	if isRaceDetectorEnabled() {
-		t.Skip("Skipping invalid regex pattern tests in race mode")
-	}
-
 	r := NewRouter()
 	prefix := getTestPathPrefix()
 
@@ -1701,10 +1710,6 @@ func TestInvalidRegexPattern(t *testing.T) {
 			name:    "Unclosed bracket in regex",
 			pattern: prefix + "/users/{id:[0-9+}",
 		},
-		{
-			name:    "Invalid regex syntax",
-			pattern: prefix + "/users/{id:[0-9++]}",
-		},
 		{
 			name:    "Empty regex pattern",
 			pattern: prefix + "/users/{id:}",
@@ -1713,22 +1718,11 @@ func TestInvalidRegexPattern(t *testing.T) {
 			name:    "Invalid character class",
 			pattern: prefix + "/users/{id:[z-a]}",
 		},
-		{
-			name:    "Unescaped special character",
-			pattern: prefix + "/users/{id:[.*+]}",
-		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			// パニックをキャッチする
-			defer func() {
-				if r := recover(); r != nil {
-					// パニックが発生した場合は成功とみなす
-					t.Logf("Got expected panic for invalid regex pattern: %v", r)
-				}
-			}()
-
+			// Handle はパニックせずにエラーを返す
 			err := r.Handle(http.MethodGet, tc.pattern, func(w http.ResponseWriter, r *http.Request) error {
 				return nil
 			})
@@ -1738,11 +1732,24 @@ func TestInvalidRegexPattern(t *testing.T) {
 				t.Errorf("Expected error for invalid regex pattern %q, but got nil", tc.pattern)
 				return
 			}
+			var routerErr *RouterError
+			if !errors.As(err, &routerErr) || routerErr.Code != ErrInvalidPattern {
+				t.Errorf("Expected a RouterError with code ErrInvalidPattern, got %v", err)
+			}
 
 			// エラーメッセージを確認（正規表現エラーは様々な形式があるため、特定のメッセージではなくエラーが発生することだけを確認）
 			t.Logf("Got expected error for invalid regex pattern: %v", err)
 		})
 	}
+
+	// 量指定子や特殊文字を含む文字クラスは有効な正規表現として登録できる
+	for _, pattern := range []string{prefix + "/items/{id:[0-9++]}", prefix + "/tags/{id:[.*+]}"} {
+		if err := r.Handle(http.MethodGet, pattern, func(w http.ResponseWriter, r *http.Request) error {
+			return nil
+		}); err != nil {
+			t.Errorf("Expected valid character class %q to be accepted, got %v", pattern, err)
+		}
+	}
 }
 
 // TestDuplicateRouteRegistration tests registration of duplicate routes
@@ -1853,7 +1860,7 @@ func TestNotFoundHandler(t *testing.T) {
 			name:           "Valid path with invalid method",
 			method:         http.MethodPost,
 			path:           prefix + "/valid",
-			expectedStatus: http.StatusNotFound,
+			expectedStatus: http.StatusMethodNotAllowed,
 		},
 		{
 			name:           "Similar but non-matching path",
@@ -1868,10 +1875,10 @@ func TestNotFoundHandler(t *testing.T) {
 			expectedStatus: http.StatusNotFound,
 		},
 		{
-			name:           "Path with trailing slash",
+			name:           "Path with trailing slash", // normalizePath removes the trailing slash
 			method:         http.MethodGet,
 			path:           prefix + "/valid/",
-			expectedStatus: http.StatusNotFound,
+			expectedStatus: http.StatusOK,
 		},
 	}
 
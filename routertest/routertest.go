@@ -0,0 +1,167 @@
+// Package routertest provides utilities for testing handlers and routers
+// built with github.com/nissy/router.
+package routertest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nissy/router"
+)
+
+// UpdateGolden controls whether AssertGolden rewrites golden files instead of comparing them.
+// It is enabled when the ROUTERTEST_UPDATE environment variable is set to a non-empty value.
+var UpdateGolden = os.Getenv("ROUTERTEST_UPDATE") != ""
+
+// Result is the outcome of serving a request through a router.
+type Result struct {
+	Status int         // HTTP status code written by the handler
+	Header http.Header // Response headers
+	Body   []byte      // Response body
+	Route  string      // Pattern of the matched route (empty if no route matched)
+}
+
+// NewRequest creates a new incoming server request for testing handlers directly.
+// The URL parameters defined by pattern are extracted from path and stored in the
+// request context, so handlers can read them with router.GetParams as usual.
+// It panics if path does not have the same number of segments as pattern, or fewer than pattern
+// if it ends with a catch-all segment ({*name}), which captures the rest of the path.
+func NewRequest(method, pattern, path string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+
+	params, err := extractParams(pattern, req.URL.Path)
+	if err != nil {
+		panic("routertest: " + err.Error())
+	}
+	return req.WithContext(router.WithParams(req.Context(), params))
+}
+
+// extractParams extracts the parameters defined in pattern from path.
+// Regular expression constraints are not evaluated.
+func extractParams(pattern, path string) (*router.Params, error) {
+	patternSegments := splitPath(pattern)
+	pathSegments := splitPath(path)
+
+	// A catch-all segment ({*name}) is the last one and captures one or more remaining segments
+	catchAll := ""
+	if n := len(patternSegments); n > 0 && strings.HasPrefix(patternSegments[n-1], "{*") && strings.HasSuffix(patternSegments[n-1], "}") {
+		catchAll = patternSegments[n-1][2 : len(patternSegments[n-1])-1]
+		if catchAll == "" {
+			catchAll = "*"
+		}
+		patternSegments = patternSegments[:n-1]
+		if len(pathSegments) <= len(patternSegments) {
+			return nil, fmt.Errorf("path %q does not match pattern %q", path, pattern)
+		}
+	} else if len(patternSegments) != len(pathSegments) {
+		return nil, fmt.Errorf("path %q does not match pattern %q", path, pattern)
+	}
+
+	params := router.NewParams()
+	for i, seg := range patternSegments {
+		if len(seg) < 2 || seg[0] != '{' || seg[len(seg)-1] != '}' {
+			if seg != pathSegments[i] {
+				return nil, fmt.Errorf("path %q does not match pattern %q", path, pattern)
+			}
+			continue
+		}
+
+		// The part before the colon is the parameter name; anonymous segments ({} and
+		// {:regex}) match like parameters but bind nothing, as in the router
+		name := seg[1 : len(seg)-1]
+		if colonIdx := strings.IndexByte(name, ':'); colonIdx >= 0 {
+			name = name[:colonIdx]
+		}
+		if name != "" {
+			params.Add(name, pathSegments[i])
+		}
+	}
+	if catchAll != "" {
+		params.Add(catchAll, strings.Join(pathSegments[len(patternSegments):], "/"))
+	}
+	return params, nil
+}
+
+// splitPath splits a path into segments, ignoring the leading and trailing slashes.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// Serve executes the request against the router and returns the recorded result.
+func Serve(r *router.Router, req *http.Request) *Result {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// The route is resolved like ServeHTTP, including tenant overlays, mounts, and active windows
+	info, _, _ := r.LookupRequest(req)
+	return &Result{
+		Status: w.Code,
+		Header: w.Header(),
+		Body:   w.Body.Bytes(),
+		Route:  info.Pattern,
+	}
+}
+
+// BodyString returns the response body as a string.
+func (res *Result) BodyString() string {
+	return string(res.Body)
+}
+
+// Dump returns a stable textual representation of the response,
+// consisting of the status line, the sorted headers, an empty line, and the body.
+func (res *Result) Dump() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(strconv.Itoa(res.Status) + " " + http.StatusText(res.Status) + "\n")
+
+	keys := make([]string, 0, len(res.Header))
+	for k := range res.Header {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range res.Header[k] {
+			buf.WriteString(k + ": " + v + "\n")
+		}
+	}
+
+	buf.WriteString("\n")
+	buf.Write(res.Body)
+	return buf.Bytes()
+}
+
+// AssertGolden compares the response with the contents of the golden file at path.
+// If UpdateGolden is enabled, the golden file is (re)written instead.
+func (res *Result) AssertGolden(t testing.TB, path string) {
+	t.Helper()
+
+	got := res.Dump()
+	if UpdateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("routertest: failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("routertest: failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("routertest: failed to read golden file (set ROUTERTEST_UPDATE=1 to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("routertest: response does not match golden file %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
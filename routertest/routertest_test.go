@@ -0,0 +1,133 @@
+package routertest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nissy/router"
+)
+
+// TestNewRequest tests that URL parameters are available to handlers called directly
+func TestNewRequest(t *testing.T) {
+	req := NewRequest(http.MethodGet, "/users/{id:[0-9]+}/posts/{slug}", "/users/42/posts/hello")
+
+	params := router.GetParams(req.Context())
+	if id, ok := params.Get("id"); !ok || id != "42" {
+		t.Errorf("Value of parameter id is different. Expected: %s, Actual: %s", "42", id)
+	}
+	if slug, ok := params.Get("slug"); !ok || slug != "hello" {
+		t.Errorf("Value of parameter slug is different. Expected: %s, Actual: %s", "hello", slug)
+	}
+}
+
+// TestNewRequestAnonymous tests that anonymous segments match any segment without binding a parameter
+func TestNewRequestAnonymous(t *testing.T) {
+	req := NewRequest(http.MethodGet, "/api/{}/health/{id}/{:[a-z]+}", "/api/v2/health/7/full")
+
+	params := router.GetParams(req.Context())
+	if id, ok := params.Get("id"); !ok || id != "7" {
+		t.Errorf("Value of parameter id is different. Expected: %s, Actual: %s", "7", id)
+	}
+	if params.Len() != 1 {
+		t.Errorf("Expected only the named parameter, got %d parameters", params.Len())
+	}
+}
+
+// TestNewRequestCatchAll tests that a catch-all segment captures the rest of the path
+func TestNewRequestCatchAll(t *testing.T) {
+	req := NewRequest(http.MethodGet, "/assets/{*filepath}", "/assets/css/site.css")
+	if path, ok := router.GetParams(req.Context()).Get("filepath"); !ok || path != "css/site.css" {
+		t.Errorf("Value of parameter filepath is different. Expected: %s, Actual: %s", "css/site.css", path)
+	}
+
+	req = NewRequest(http.MethodGet, "/files/{*}", "/files/a/b")
+	if path, ok := router.GetParams(req.Context()).Get("*"); !ok || path != "a/b" {
+		t.Errorf("Value of parameter * is different. Expected: %s, Actual: %s", "a/b", path)
+	}
+
+	// The catch-all segment needs at least one segment
+	defer func() {
+		if recover() == nil {
+			t.Error("NewRequest should panic when the catch-all segment has nothing to capture")
+		}
+	}()
+	NewRequest(http.MethodGet, "/assets/{*filepath}", "/assets")
+}
+
+// TestNewRequestMismatch tests that a path not matching the pattern panics
+func TestNewRequestMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewRequest should panic when the path does not match the pattern")
+		}
+	}()
+	NewRequest(http.MethodGet, "/users/{id}", "/posts/1")
+}
+
+// TestServe tests the result returned by Serve
+func TestServe(t *testing.T) {
+	r := router.NewRouter()
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		id, _ := router.GetParams(r.Context()).Get("id")
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "User ID: %s", id)
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	res := Serve(r, NewRequest(http.MethodGet, "/users/{id}", "/users/7"))
+	if res.Status != http.StatusOK {
+		t.Errorf("Status code is different. Expected: %d, Actual: %d", http.StatusOK, res.Status)
+	}
+	if res.BodyString() != "User ID: 7" {
+		t.Errorf("Response body is different. Expected: %q, Actual: %q", "User ID: 7", res.BodyString())
+	}
+	if res.Route != "/users/{id}" {
+		t.Errorf("Matched route is different. Expected: %s, Actual: %s", "/users/{id}", res.Route)
+	}
+	res.AssertGolden(t, "testdata/serve.golden")
+
+	// Unmatched routes have an empty pattern
+	res = Serve(r, NewRequest(http.MethodGet, "/missing", "/missing"))
+	if res.Status != http.StatusNotFound || res.Route != "" {
+		t.Errorf("Expected 404 without a matched route, got %d %q", res.Status, res.Route)
+	}
+}
+
+// TestServeResolvedRoute tests that Serve reports the route that served the request
+func TestServeResolvedRoute(t *testing.T) {
+	r := router.NewRouter()
+	r.Tenant(func(req *http.Request) string { return req.Header.Get("X-Tenant") })
+	ok := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	r.Get("/users/{id}", ok)
+	r.ForTenant("acme").Get("/users/{name:[a-z]+}", ok)
+	r.Get("/promo", ok).WithActiveWindow(time.Now().Add(time.Hour), time.Time{})
+	if err := r.Mount("/legacy", http.NotFoundHandler(), router.MountOptions{}); err != nil {
+		t.Fatalf("Failed to mount handler: %v", err)
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tenantReq := NewRequest(http.MethodGet, "/users/{name}", "/users/bob")
+	tenantReq.Header.Set("X-Tenant", "acme")
+	tests := []struct {
+		name  string
+		req   *http.Request
+		route string
+	}{
+		{"tenant overlay", tenantReq, "/users/{name:[a-z]+}"},
+		{"inactive route", httptest.NewRequest(http.MethodGet, "/promo", nil), ""},
+		{"mount", httptest.NewRequest(http.MethodGet, "/legacy/page", nil), "/legacy"},
+	}
+	for _, tt := range tests {
+		if res := Serve(r, tt.req); res.Route != tt.route {
+			t.Errorf("%s: expected route %q, got %q", tt.name, tt.route, res.Route)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// SegmentCharset selects the characters allowed in the static segments of route patterns.
+// The charsets other than StrictSegmentChars are flags that can be combined with |.
+type SegmentCharset uint8
+
+const (
+	// StrictSegmentChars allows ASCII letters, ASCII digits, '-', '_', and '.' in static segments.
+	StrictSegmentChars SegmentCharset = 0
+	// PermissiveSegmentChars additionally allows the characters RFC 3986 permits unescaped in a
+	// path segment: '~', ':', '@', and the sub-delimiters ! $ & ' ( ) * + , ; =, as well as
+	// percent-encoded octets such as %20. Percent-encodings are decoded when the route is
+	// registered, since requests are matched by their decoded path; an encoded slash (%2F) is
+	// rejected because it cannot be told apart from a segment separator after decoding.
+	PermissiveSegmentChars SegmentCharset = 1 << 0
+	// UnicodeSegmentChars additionally allows non-ASCII letters and digits, for routes such as
+	// /記事 or /café. They are registered as UTF-8 and match the decoded request path, so clients
+	// may send them percent-encoded, as browsers do.
+	UnicodeSegmentChars SegmentCharset = 1 << 1
+)
+
+// String returns the names of the charset flags, such as "permissive|unicode".
+func (c SegmentCharset) String() string {
+	if c == StrictSegmentChars {
+		return "strict"
+	}
+	var names []string
+	if c&PermissiveSegmentChars != 0 {
+		names = append(names, "permissive")
+	}
+	if c&UnicodeSegmentChars != 0 {
+		names = append(names, "unicode")
+	}
+	if c&^(PermissiveSegmentChars|UnicodeSegmentChars) != 0 {
+		names = append(names, "unknown")
+	}
+	return strings.Join(names, "|")
+}
+
+// allows reports whether the charset allows the character unescaped in a static segment.
+func (c SegmentCharset) allows(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '-', r == '_', r == '.':
+		return true
+	case r > unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+		return c&UnicodeSegmentChars != 0
+	}
+	return c&PermissiveSegmentChars != 0 && strings.ContainsRune("~:@!$&'()*+,;=", r)
+}
+
+// decodeStaticSegments decodes the percent-encodings in the static segments of a pattern
+// validated with PermissiveSegmentChars, so that they match the decoded request path.
+func decodeStaticSegments(pattern string) (string, error) {
+	if !strings.Contains(pattern, "%") {
+		return pattern, nil
+	}
+	segments := parseSegments(pattern)
+	for i, seg := range segments {
+		if isDynamicSeg(seg) || !strings.Contains(seg, "%") {
+			continue
+		}
+		decoded, err := url.PathUnescape(seg)
+		if err != nil || strings.Contains(decoded, "/") {
+			return "", &RouterError{Code: ErrInvalidPattern, Message: "invalid percent-encoding in static segment: " + seg}
+		}
+		segments[i] = decoded
+	}
+	return "/" + strings.Join(segments, "/"), nil
+}
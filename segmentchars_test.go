@@ -0,0 +1,148 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestStaticSegmentChars tests the allowed characters of static segments under each charset
+func TestStaticSegmentChars(t *testing.T) {
+	patterns := []struct {
+		pattern    string
+		strict     bool // Valid with StrictSegmentChars
+		permissive bool // Valid with PermissiveSegmentChars
+	}{
+		{"/api/v1.0/users_list", true, true},
+		{"/@alice", false, true},
+		{"/v1.0:batch", false, true},
+		{"/~bob/files", false, true},
+		{"/search/a+b", false, true},
+		{"/items;v=2/{id}", false, true},
+		{"/docs/hello%20world", false, true},
+		{"/docs/caf%c3%A9", false, true},
+		{"/docs/100%", false, false},
+		{"/docs/%zz", false, false},
+		{"/docs/a%2", false, false},
+		{"/users/{id}/a{b", false, false},
+		{"/users/a b", false, false},
+		{"/users/a#b", false, false},
+		{"/users/a?b", false, false},
+	}
+	for _, p := range patterns {
+		if err := validatePatternWith(p.pattern, StrictSegmentChars); (err == nil) != p.strict {
+			t.Errorf("strict %s: expected valid=%v, got %v", p.pattern, p.strict, err)
+		}
+		if err := validatePatternWith(p.pattern, PermissiveSegmentChars); (err == nil) != p.permissive {
+			t.Errorf("permissive %s: expected valid=%v, got %v", p.pattern, p.permissive, err)
+		}
+	}
+}
+
+// TestPermissiveSegmentRoutes tests routing real-world path shapes with PermissiveSegmentChars
+func TestPermissiveSegmentRoutes(t *testing.T) {
+	// The default charset rejects them
+	strict := NewRouter()
+	defer strict.cache.stop()
+	if err := strict.Handle(http.MethodGet, "/@alice", func(w http.ResponseWriter, r *http.Request) error { return nil }); err == nil {
+		t.Error("Expected the default charset to reject '@'")
+	}
+
+	opts := defaultRouterOptions()
+	opts.StaticSegmentChars = PermissiveSegmentChars
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	respond := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			id, _ := GetParams(req.Context()).Get("id")
+			w.Write([]byte(body + id))
+			return nil
+		}
+	}
+	r.Get("/@alice", respond("profile"))
+	r.Post("/v1.0:batch", respond("batch"))
+	r.Get("/~bob/files/{id}", respond("file "))
+	r.Get("/docs/caf%C3%A9", respond("cafe"))
+	if err := r.Handle(http.MethodGet, "/docs/hello%20world", respond("hello")); err != nil {
+		t.Fatalf("Failed to register route: %v", err)
+	}
+	if err := r.Handle(http.MethodGet, "/docs/a%2Fb", respond("")); err == nil {
+		t.Error("Expected an encoded slash to be rejected")
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{http.MethodGet, "/@alice", "profile"},
+		{http.MethodPost, "/v1.0:batch", "batch"},
+		{http.MethodGet, "/~bob/files/42", "file 42"},
+		{http.MethodGet, "/docs/caf%C3%A9", "cafe"},
+		{http.MethodGet, "/docs/hello%20world", "hello"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != tt.body {
+			t.Errorf("%s %s: expected 200 %q, got %d %q", tt.method, tt.path, tt.body, w.Code, w.Body.String())
+		}
+	}
+
+	// The charset is kept by Clone, which registers the routes again
+	c, err := r.Clone()
+	if err != nil {
+		t.Fatalf("Failed to clone router: %v", err)
+	}
+	defer c.cache.stop()
+	if c.segmentChars != PermissiveSegmentChars {
+		t.Errorf("Expected the clone to keep the charset, got %v", c.segmentChars)
+	}
+}
+
+// TestUnicodeSegmentChars tests that non-ASCII letters and digits in static segments are opt-in
+func TestUnicodeSegmentChars(t *testing.T) {
+	patterns := []struct {
+		pattern string
+		chars   SegmentCharset
+		valid   bool
+	}{
+		{"/記事/{id}", StrictSegmentChars, false},
+		{"/記事/{id}", UnicodeSegmentChars, true},
+		{"/café", UnicodeSegmentChars, true},
+		{"/@ユーザー", UnicodeSegmentChars, false},
+		{"/@ユーザー", UnicodeSegmentChars | PermissiveSegmentChars, true},
+		{"/a b", UnicodeSegmentChars, false}, // No-break space is not a letter
+	}
+	for _, p := range patterns {
+		if err := validatePatternWith(p.pattern, p.chars); (err == nil) != p.valid {
+			t.Errorf("%v %s: expected valid=%v, got %v", p.chars, p.pattern, p.valid, err)
+		}
+	}
+	if s := (UnicodeSegmentChars | PermissiveSegmentChars).String(); s != "permissive|unicode" {
+		t.Errorf("Expected %q, got %q", "permissive|unicode", s)
+	}
+
+	opts := defaultRouterOptions()
+	opts.StaticSegmentChars = UnicodeSegmentChars
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+	r.Get("/café/menu", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("menu"))
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// Browsers send the path percent-encoded
+	for _, path := range []string{"/café/menu", "/caf%C3%A9/menu"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != "menu" {
+			t.Errorf("%s: expected 200 %q, got %d %q", path, "menu", w.Code, w.Body.String())
+		}
+	}
+}
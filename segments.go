@@ -0,0 +1,72 @@
+package router
+
+import "strings"
+
+// SegmentKind is the kind of a segment of a route pattern.
+type SegmentKind int
+
+const (
+	// SegmentStatic is a literal segment such as "users".
+	SegmentStatic SegmentKind = iota
+	// SegmentParam is a parameter segment such as "{id}" or "{id:[0-9]+}".
+	SegmentParam
+	// SegmentCatchAll is a catch-all segment such as "{*filepath}", which takes the rest of the path.
+	SegmentCatchAll
+)
+
+// String returns the name of the segment kind.
+func (k SegmentKind) String() string {
+	switch k {
+	case SegmentStatic:
+		return "static"
+	case SegmentParam:
+		return "param"
+	case SegmentCatchAll:
+		return "catchall"
+	default:
+		return "unknown"
+	}
+}
+
+// SegmentInfo describes a segment of a route pattern.
+type SegmentInfo struct {
+	Kind  SegmentKind // Static, parameter, or catch-all segment
+	Value string      // Literal value of a static segment, or the raw parameter segment (e.g. "{id:[0-9]+}")
+	Name  string      // Parameter name ("" for static and anonymous segments)
+	Regex string      // Regular expression constraining the parameter ("" if unconstrained or static)
+}
+
+// Segments returns the parsed segments of the full pattern of the route, including the group
+// prefix, so that external tools such as client SDK generators and typed URL builders can be
+// written without reparsing pattern strings. The root pattern "/" has no segments.
+//
+// 例: /users/{id:[0-9]+} → [{static users} {param {id:[0-9]+} id [0-9]+}]
+func (r *Route) Segments() []SegmentInfo {
+	return patternSegments(r.fullPath())
+}
+
+// patternSegments parses the segments of a route pattern.
+func patternSegments(pattern string) []SegmentInfo {
+	pattern = normalizePath(pattern)
+	if pattern == "/" {
+		return nil
+	}
+
+	segments := parseSegments(pattern)
+	infos := make([]SegmentInfo, len(segments))
+	for i, seg := range segments {
+		if !isDynamicSeg(seg) {
+			infos[i] = SegmentInfo{Kind: SegmentStatic, Value: seg}
+			continue
+		}
+		if isCatchAllSeg(seg) {
+			infos[i] = SegmentInfo{Kind: SegmentCatchAll, Value: seg, Name: extractParamName(seg)}
+			continue
+		}
+		infos[i] = SegmentInfo{Kind: SegmentParam, Value: seg, Name: extractParamName(seg)}
+		if colon := strings.IndexByte(seg, ':'); colon > 0 {
+			infos[i].Regex = seg[colon+1 : len(seg)-1]
+		}
+	}
+	return infos
+}
@@ -0,0 +1,53 @@
+package router
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// TestRouteSegments tests the parsed representation of route patterns
+func TestRouteSegments(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	root := r.Get("/", handler)
+	direct := r.Get("/users/{id:[0-9]+}", handler)
+	grouped := r.Group("/api/v1").Get("/posts/{slug}/comments", handler)
+
+	if segments := root.Segments(); len(segments) != 0 {
+		t.Errorf("Expected no segments for the root, got %v", segments)
+	}
+
+	want := []SegmentInfo{
+		{Kind: SegmentStatic, Value: "users"},
+		{Kind: SegmentParam, Value: "{id:[0-9]+}", Name: "id", Regex: "[0-9]+"},
+	}
+	if got := direct.Segments(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	want = []SegmentInfo{
+		{Kind: SegmentStatic, Value: "api"},
+		{Kind: SegmentStatic, Value: "v1"},
+		{Kind: SegmentStatic, Value: "posts"},
+		{Kind: SegmentParam, Value: "{slug}", Name: "slug"},
+		{Kind: SegmentStatic, Value: "comments"},
+	}
+	if got := grouped.Segments(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	want = []SegmentInfo{
+		{Kind: SegmentStatic, Value: "assets"},
+		{Kind: SegmentCatchAll, Value: "{*filepath}", Name: "filepath"},
+	}
+	if got := r.Get("/assets/{*filepath}", handler).Segments(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if SegmentParam.String() != "param" || SegmentStatic.String() != "static" || SegmentCatchAll.String() != "catchall" {
+		t.Error("Unexpected segment kind names")
+	}
+}
@@ -0,0 +1,202 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout is the default time Server waits for requests to drain.
+const defaultShutdownTimeout = 30 * time.Second
+
+// Server binds multiple Routers to multiple listeners (for example public, admin, and metrics
+// ports) and runs them with a single lifecycle: Serve starts all of them, and shutting down
+// drains every listener and router together.
+type Server struct {
+	// ShutdownTimeout is the maximum time to wait for active requests when shutting down.
+	// Default: 30 seconds
+	ShutdownTimeout time.Duration
+
+	// UnixSocket configures the socket files created for "unix://" addresses.
+	UnixSocket UnixSocketOptions
+
+	mu       sync.Mutex
+	bindings []*serverBinding
+	started  bool
+	closed   chan struct{} // Closed when Shutdown is called (see closedChan)
+
+	shutdownOnce sync.Once
+	shutdownErr  error // Result of the first Shutdown, returned by later calls
+}
+
+// serverBinding is a router bound to an address or listener.
+type serverBinding struct {
+	addr     string
+	listener net.Listener
+	router   *Router
+	server   *http.Server
+}
+
+// NewServer creates an empty Server.
+func NewServer() *Server {
+	return &Server{ShutdownTimeout: defaultShutdownTimeout}
+}
+
+// Bind serves the router on the address when Serve is called. A router may be bound to several addresses.
+// The address is one of:
+//   - a TCP address, e.g. ":8080"
+//   - a Unix domain socket, e.g. "unix:///run/app.sock" (see Server.UnixSocket)
+//   - a systemd socket activation listener by name or zero-based index, e.g. "systemd://admin" or "systemd://0"
+func (s *Server) Bind(addr string, r *Router) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bindings = append(s.bindings, &serverBinding{addr: addr, router: r})
+	return s
+}
+
+// BindListener serves the router on an existing listener when Serve is called.
+func (s *Server) BindListener(l net.Listener, r *Router) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bindings = append(s.bindings, &serverBinding{addr: l.Addr().String(), listener: l, router: r})
+	return s
+}
+
+// Addrs returns the addresses of the listeners. Addresses bound with Bind are resolved
+// once Serve has opened them, which is useful with ":0".
+func (s *Server) Addrs() []net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addrs := make([]net.Addr, 0, len(s.bindings))
+	for _, b := range s.bindings {
+		if b.listener != nil {
+			addrs = append(addrs, b.listener.Addr())
+		}
+	}
+	return addrs
+}
+
+// Serve opens all listeners and serves the bound routers until ctx is done, a listener fails,
+// or Shutdown is called.
+// It then shuts everything down (see Shutdown) and returns the first error that occurred,
+// or nil after a clean shutdown. The listeners are open when Addrs returns them.
+func (s *Server) Serve(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return errors.New("router: server already started")
+	}
+	s.started = true
+
+	// Open all listeners before serving, so a bad address fails fast
+	for i, b := range s.bindings {
+		if b.listener != nil {
+			continue
+		}
+		l, err := s.listen(b.addr)
+		if err != nil {
+			for _, opened := range s.bindings[:i] {
+				opened.listener.Close()
+			}
+			s.mu.Unlock()
+			return err
+		}
+		b.listener = l
+	}
+
+	errCh := make(chan error, len(s.bindings))
+	for _, b := range s.bindings {
+		b.server = &http.Server{Handler: b.router}
+		go func(b *serverBinding) {
+			if err := b.server.Serve(b.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}(b)
+	}
+	closed := s.closedChan()
+	s.mu.Unlock()
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case serveErr = <-errCh:
+	case <-closed:
+	}
+
+	timeout := s.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return errors.Join(serveErr, s.Shutdown(shutdownCtx))
+}
+
+// ListenAndServe serves like Serve and shuts down gracefully on SIGINT or SIGTERM.
+func (s *Server) ListenAndServe() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return s.Serve(ctx)
+}
+
+// Shutdown stops accepting connections on all listeners, waits for active requests to drain,
+// and then shuts down each bound router once (running its cleanup middleware).
+// If ctx is done first, it returns the context error. Only the first call shuts down;
+// later calls wait for it and return its result.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() {
+		s.mu.Lock()
+		close(s.closedChan())
+		bindings := append([]*serverBinding(nil), s.bindings...)
+		s.mu.Unlock()
+
+		s.shutdownErr = s.shutdown(ctx, bindings)
+	})
+	return s.shutdownErr
+}
+
+// closedChan returns the channel closed by Shutdown, creating it if needed. s.mu must be held.
+func (s *Server) closedChan() chan struct{} {
+	if s.closed == nil {
+		s.closed = make(chan struct{})
+	}
+	return s.closed
+}
+
+// shutdown stops the listeners and shuts down the routers of the bindings.
+func (s *Server) shutdown(ctx context.Context, bindings []*serverBinding) error {
+	// Stop all listeners concurrently so that the drain time is shared
+	var wg sync.WaitGroup
+	errs := make([]error, len(bindings))
+	for i, b := range bindings {
+		if b.server == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, b *serverBinding) {
+			defer wg.Done()
+			errs[i] = b.server.Shutdown(ctx)
+		}(i, b)
+	}
+	wg.Wait()
+
+	// Shut down each router once, even if it is bound to several listeners
+	done := make(map[*Router]bool, len(bindings))
+	for _, b := range bindings {
+		if done[b.router] {
+			continue
+		}
+		done[b.router] = true
+		errs = append(errs, b.router.Shutdown(ctx))
+	}
+
+	return errors.Join(errs...)
+}
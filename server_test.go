@@ -0,0 +1,123 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestServer tests serving several routers with one lifecycle
+func TestServer(t *testing.T) {
+	newRouter := func(body string) *Router {
+		r := NewRouter()
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+			_, err := io.WriteString(w, body)
+			return err
+		})
+		if err := r.Build(); err != nil {
+			t.Fatalf("Failed to build router: %v", err)
+		}
+		return r
+	}
+
+	cleaned := false
+	admin := newRouter("admin")
+	admin.AddCleanupMiddleware(newCleanupMiddleware(func(next HandlerFunc) HandlerFunc { return next }, func() error {
+		cleaned = true
+		return nil
+	}))
+
+	s := NewServer().
+		Bind("127.0.0.1:0", newRouter("public")).
+		Bind("127.0.0.1:0", admin)
+	s.ShutdownTimeout = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ctx) }()
+
+	// Wait for the listeners to be opened
+	var addrs []string
+	for deadline := time.Now().Add(time.Second); len(addrs) < 2 && time.Now().Before(deadline); {
+		addrs = addrs[:0]
+		for _, addr := range s.Addrs() {
+			addrs = append(addrs, addr.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(addrs) != 2 {
+		t.Fatalf("Expected 2 listeners, got %d", len(addrs))
+	}
+
+	for i, want := range []string{"public", "admin"} {
+		resp, err := http.Get("http://" + addrs[i] + "/")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != want {
+			t.Errorf("Expected body %q, got %q", want, body)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve returned an error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve did not return after cancellation")
+	}
+	if !cleaned {
+		t.Error("Routers were not shut down")
+	}
+}
+
+// TestServerShutdown tests that calling Shutdown directly ends Serve and shuts the routers down once
+func TestServerShutdown(t *testing.T) {
+	r := NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+	var cleanups atomic.Int32
+	r.AddCleanupMiddleware(newCleanupMiddleware(func(next HandlerFunc) HandlerFunc { return next }, func() error {
+		cleanups.Add(1)
+		return nil
+	}))
+
+	s := NewServer().Bind("127.0.0.1:0", r)
+	s.ShutdownTimeout = time.Second
+
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(context.Background()) }()
+	for deadline := time.Now().Add(time.Second); len(s.Addrs()) == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if len(s.Addrs()) == 0 {
+		t.Fatal("Expected the listener to be opened")
+	}
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown returned an error: %v", err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve returned an error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve did not return after Shutdown")
+	}
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Errorf("Repeated Shutdown returned an error: %v", err)
+	}
+	if n := cleanups.Load(); n != 1 {
+		t.Errorf("Expected the cleanup to run once, ran %d times", n)
+	}
+}
@@ -0,0 +1,169 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+)
+
+// shadowBodyLimit is the maximum size of a request body that is copied for the shadow handler.
+// Requests with larger bodies are not mirrored.
+const shadowBodyLimit = 1 << 20
+
+// shadowMaxInFlight is the maximum number of mirrored requests of a route that run at once.
+// Requests sampled while the limit is reached are not mirrored.
+const shadowMaxInFlight = 64
+
+// shadowTarget is a handler that receives a sample of the requests of a route.
+type shadowTarget struct {
+	handler HandlerFunc
+	percent int           // Percentage of requests that are mirrored (1-100)
+	slots   chan struct{} // One element per mirrored request in flight
+}
+
+// WithShadow mirrors a sample of the requests of the route to a second handler, for testing a new
+// implementation (or, through a handler wrapping httputil.ReverseProxy, another service) against
+// production traffic. samplePercent (clamped to 0-100) is the percentage of requests mirrored.
+//
+// The shadow handler runs asynchronously after the route's handler, with a clone of the request:
+// the same URL parameters and context values, a context that is not canceled when the request
+// finishes, and a copy of the body (requests with bodies larger than 1 MiB are not mirrored).
+// Its response is discarded and its errors are ignored, so it never affects the client.
+// At most 64 mirrored requests of the route run at once, and Router.Shutdown waits for them
+// before cleaning up the middleware.
+//
+// 例: r.Post("/orders", createOrder).WithShadow(createOrderV2, 10)
+func (r *Route) WithShadow(h HandlerFunc, samplePercent int) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	samplePercent = min(max(samplePercent, 0), 100)
+	if h == nil || samplePercent == 0 {
+		r.shadow = nil
+		return r
+	}
+	r.shadow = &shadowTarget{handler: h, percent: samplePercent, slots: make(chan struct{}, shadowMaxInFlight)}
+	return r
+}
+
+// mirror returns a handler that calls next and mirrors a sample of the requests to the shadow handler.
+func (s *shadowTarget) mirror(router *Router, next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if s.percent < 100 && rand.IntN(100) >= s.percent {
+			return next(w, r)
+		}
+
+		// Skip mirroring while the shadow handler is saturated
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			return next(w, r)
+		}
+
+		body, ok := teeBody(r)
+		if !ok {
+			<-s.slots
+			return next(w, r)
+		}
+		shadowReq := cloneForShadow(r, body)
+
+		err := next(w, r)
+
+		// The mirrored request is counted as active, so that Shutdown waits for it; it is admitted
+		// during a shutdown as long as the request mirroring it is
+		_, active, admitted := router.beginRequest(r.Context())
+		if !admitted {
+			<-s.slots
+			return err
+		}
+		go s.serve(router, shadowReq, active)
+		return err
+	}
+}
+
+// serve calls the shadow handler, discarding the response.
+func (s *shadowTarget) serve(router *Router, r *http.Request, active *activeRequest) {
+	defer func() {
+		active.release()
+		<-s.slots
+	}()
+	defer func() {
+		if p := recover(); p != nil {
+			router.logf(slog.LevelError, "shadow handler for %s %s panicked: %v", r.Method, r.URL.Path, p)
+		}
+	}()
+	s.handler(&discardResponseWriter{header: make(http.Header)}, r)
+}
+
+// teeBody reads the request body so that it can be given to both handlers, and replaces the body
+// of the request with the copy. It returns false if the body exceeds shadowBodyLimit; the body
+// of the request is then restored unchanged.
+func teeBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	if r.ContentLength > shadowBodyLimit {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, shadowBodyLimit+1))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || len(body) > shadowBodyLimit {
+		return nil, false
+	}
+	return body, true
+}
+
+// readCloser combines a reader with the Closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// cloneForShadow clones the request for the shadow handler with its own copy of the body
+// and of the URL parameters, which are returned to the pool when the request finishes.
+func cloneForShadow(r *http.Request, body []byte) *http.Request {
+	var ctx context.Context = shadowContext{context.WithoutCancel(r.Context())}
+	if ps := GetParams(ctx); ps != nil {
+		ctx = WithParams(ctx, &Params{data: slices.Clone(ps.data)})
+	}
+
+	clone := r.Clone(ctx)
+	if body != nil {
+		clone.Body = io.NopCloser(bytes.NewReader(body))
+	} else {
+		clone.Body = http.NoBody
+	}
+	return clone
+}
+
+// shadowContext is the context of a mirrored request. It keeps the values of the original request
+// but hides the state the router tracks the original request with, such as its timeout and its
+// drain accounting, so that Poll or Dispatch in the shadow handler do not act on them.
+type shadowContext struct {
+	context.Context
+}
+
+// Value returns the values of the original request, except the state of the router.
+func (c shadowContext) Value(key any) any {
+	switch key.(type) {
+	case activeRequestKey, timeoutContextKey, timeoutTrackerKey, timeoutInfoKey:
+		return nil
+	}
+	return c.Context.Value(key)
+}
+
+// discardResponseWriter is a ResponseWriter that discards the response.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
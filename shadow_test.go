@@ -0,0 +1,126 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestShadow tests mirroring requests to a shadow handler
+func TestShadow(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	type mirrored struct{ id, body string }
+	shadowed := make(chan mirrored, 1)
+	primary := func(w http.ResponseWriter, req *http.Request) error {
+		body, _ := io.ReadAll(req.Body)
+		w.Write([]byte("primary:" + string(body)))
+		return nil
+	}
+	shadow := func(w http.ResponseWriter, req *http.Request) error {
+		body, _ := io.ReadAll(req.Body)
+		id, _ := GetParams(req.Context()).Get("id")
+		w.Write([]byte("shadow"))
+		if req.Context().Err() != nil {
+			t.Error("Expected the shadow context not to be canceled")
+		}
+		shadowed <- mirrored{id, string(body)}
+		return nil
+	}
+	r.Post("/orders/{id}", primary).WithShadow(shadow, 100)
+	r.Post("/unsampled/{id}", primary).WithShadow(shadow, 0)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders/42", strings.NewReader("payload")))
+	if w.Body.String() != "primary:payload" {
+		t.Errorf("Expected the primary response, got %q", w.Body.String())
+	}
+	select {
+	case got := <-shadowed:
+		if got.id != "42" || got.body != "payload" {
+			t.Errorf("Expected the shadow to receive id 42 and the body, got %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the shadow handler to be called")
+	}
+
+	// Bodies over the limit are passed to the primary handler only
+	large := strings.Repeat("x", shadowBodyLimit+1)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders/43", strings.NewReader(large)))
+	if w.Body.Len() != len("primary:")+len(large) {
+		t.Errorf("Expected the primary handler to read the whole body, got %d bytes", w.Body.Len())
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/unsampled/1", nil))
+	select {
+	case got := <-shadowed:
+		t.Errorf("Expected no mirrored request, got %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestShadowLifecycle tests that mirrored requests are limited, isolated from the state of the
+// original request, and waited for by Shutdown
+func TestShadowLifecycle(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var started atomic.Int32
+	release := make(chan struct{})
+	shadow := func(w http.ResponseWriter, req *http.Request) error {
+		started.Add(1)
+		ctx := req.Context()
+		if ctx.Value(activeRequestKey{}) != nil || ctx.Value(timeoutContextKey{}) != nil || ctx.Value(timeoutTrackerKey{}) != nil {
+			t.Error("Expected the shadow context to hide the state of the original request")
+		}
+		<-release
+		return nil
+	}
+	primary := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Post("/orders", primary).WithShadow(shadow, 100).WithTimeout(time.Second)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// Requests beyond the limit are served but not mirrored
+	for range shadowMaxInFlight + 1 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	}
+	for deadline := time.Now().Add(time.Second); started.Load() < shadowMaxInFlight && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if n := started.Load(); n != shadowMaxInFlight {
+		t.Errorf("Expected %d mirrored requests, got %d", shadowMaxInFlight, n)
+	}
+	if n := r.InFlight(); n != shadowMaxInFlight {
+		t.Errorf("Expected the mirrored requests to be in flight, got %d", n)
+	}
+
+	// Shutdown waits for the mirrored requests
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- r.Shutdown(context.Background()) }()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Expected Shutdown to wait for the mirrored requests, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Errorf("Shutdown returned an error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown did not return after the mirrored requests completed")
+	}
+}
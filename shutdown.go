@@ -0,0 +1,107 @@
+package router
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// activeRequestKey is the context key for the request being served by a router.
+type activeRequestKey struct{}
+
+// activeRequest is a request counted in Router.activeRequests until it completes.
+type activeRequest struct {
+	router   *Router
+	parent   *activeRequest // Request that dispatched this one (nil for a top-level request)
+	released atomic.Bool
+}
+
+// beginRequest admits the request, counting it as active and marking it in the context so that
+// Shutdown called by the request itself does not wait for it. The returned request must be
+// released when the request completes.
+//
+// Admission and the start of a shutdown are serialized by drainMu, which defines the shutdown
+// semantics precisely: a request admitted before Shutdown is called runs to completion and
+// Shutdown waits for it, and a request arriving afterwards is not admitted (ok is false) and
+// receives the shutdown handler, so no handler starts once Shutdown has stopped waiting.
+// Requests dispatched by an admitted request of the router (see Dispatch) are part of it and
+// are admitted even during shutdown.
+func (r *Router) beginRequest(ctx context.Context) (_ context.Context, _ *activeRequest, ok bool) {
+	parent, _ := ctx.Value(activeRequestKey{}).(*activeRequest)
+
+	r.drainMu.Lock()
+	if r.shuttingDown.Load() && !parent.holds(r) {
+		r.drainMu.Unlock()
+		return ctx, nil, false
+	}
+	r.activeRequests.Add(1)
+	r.drainMu.Unlock()
+
+	active := &activeRequest{router: r, parent: parent}
+	return context.WithValue(ctx, activeRequestKey{}, active), active, true
+}
+
+// beginShutdown stops admitting requests (see beginRequest) and returns a channel that is
+// closed once no request is active. It can be called more than once.
+func (r *Router) beginShutdown() <-chan struct{} {
+	r.drainMu.Lock()
+	defer r.drainMu.Unlock()
+	r.shuttingDown.Store(true)
+	if r.drained == nil {
+		r.drained = make(chan struct{})
+		if r.activeRequests.Load() == 0 {
+			close(r.drained)
+		}
+	}
+	return r.drained
+}
+
+// signalDrained closes the drained channel after the last active request completed during a
+// shutdown. Requests completing before the shutdown began are covered by beginShutdown, which
+// checks the count after setting the flag that this request checked after its decrement.
+func (r *Router) signalDrained() {
+	r.drainMu.Lock()
+	defer r.drainMu.Unlock()
+	if r.drained != nil && r.activeRequests.Load() == 0 {
+		select {
+		case <-r.drained:
+		default:
+			close(r.drained)
+		}
+	}
+}
+
+// InFlight returns the number of requests the router is serving, including the requests they
+// dispatch (see Dispatch). It is intended for monitoring, for example while draining on shutdown.
+func (r *Router) InFlight() int {
+	return int(r.activeRequests.Load())
+}
+
+// holds reports whether the request or a request that dispatched it is still counted as
+// active by the router, which keeps the router waiting while requests it dispatches run.
+func (a *activeRequest) holds(r *Router) bool {
+	for ; a != nil; a = a.parent {
+		if a.router == r && !a.released.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// release stops counting the request as active. It is safe to call more than once.
+func (a *activeRequest) release() {
+	if !a.released.Swap(true) && a.router.activeRequests.Add(-1) == 0 && a.router.shuttingDown.Load() {
+		a.router.signalDrained()
+	}
+}
+
+// releaseCallers stops counting the requests of the router that ctx belongs to, including the
+// requests that dispatched it. A handler that calls Shutdown, such as an admin endpoint, would
+// otherwise wait for itself to complete.
+func (r *Router) releaseCallers(ctx context.Context) {
+	active, _ := ctx.Value(activeRequestKey{}).(*activeRequest)
+	for ; active != nil; active = active.parent {
+		if active.router == r {
+			active.release()
+		}
+	}
+}
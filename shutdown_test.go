@@ -0,0 +1,310 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestShutdownFromHandler tests that a handler can shut down its own router
+func TestShutdownFromHandler(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var slowDone time.Time
+	r.Get("/slow", func(w http.ResponseWriter, req *http.Request) error {
+		close(started)
+		<-release
+		slowDone = time.Now()
+		return nil
+	})
+	var shutdownErr error
+	var shutdownDone time.Time
+	r.Post("/admin/shutdown", func(w http.ResponseWriter, req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+		shutdownErr = r.Shutdown(ctx)
+		shutdownDone = time.Now()
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	// The other request in flight is waited for, the calling one is not
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-started
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/shutdown", nil))
+	wg.Wait()
+	if shutdownErr != nil {
+		t.Fatalf("Expected the shutdown to complete, got %v", shutdownErr)
+	}
+	if shutdownDone.Before(slowDone) {
+		t.Error("Expected the shutdown to wait for the request in flight")
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+// TestShutdownFromDispatchedHandler tests that the requests dispatching to the caller of Shutdown are not waited for
+func TestShutdownFromDispatchedHandler(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var shutdownErr error
+	r.Post("/admin/shutdown", func(w http.ResponseWriter, req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+		shutdownErr = r.Shutdown(ctx)
+		return nil
+	})
+	r.Post("/admin/dispatch", func(w http.ResponseWriter, req *http.Request) error {
+		return r.Dispatch(w, req, "/admin/shutdown")
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/admin/dispatch", nil))
+	if shutdownErr != nil {
+		t.Errorf("Expected the shutdown to complete, got %v", shutdownErr)
+	}
+}
+
+// TestShutdownAdmission tests that every request either runs to completion before Shutdown
+// returns or receives the shutdown handler, under concurrent requests
+func TestShutdownAdmission(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var drained, running atomic.Bool
+	var inFlight, late atomic.Int32
+	r.Get("/work", func(w http.ResponseWriter, req *http.Request) error {
+		running.Store(true)
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		if drained.Load() {
+			late.Add(1)
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var served, rejected atomic.Int32
+	stop := make(chan struct{})
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+				switch w.Code {
+				case http.StatusOK:
+					served.Add(1)
+				case http.StatusServiceUnavailable:
+					rejected.Add(1)
+				default:
+					t.Errorf("Unexpected status %d", w.Code)
+				}
+			}
+		}()
+	}
+	for !running.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+	drained.Store(true)
+	if n := inFlight.Load(); n != 0 {
+		t.Errorf("Expected no handler running after Shutdown returned, got %d", n)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	if late.Load() != 0 {
+		t.Errorf("Expected no handler to start after Shutdown returned, got %d", late.Load())
+	}
+	if served.Load() == 0 || rejected.Load() == 0 {
+		t.Errorf("Expected requests both before and after shutdown, got %d served and %d rejected", served.Load(), rejected.Load())
+	}
+}
+
+// TestShutdownDispatchInFlight tests that a request admitted before shutdown can still dispatch
+func TestShutdownDispatchInFlight(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r.Get("/page", func(w http.ResponseWriter, req *http.Request) error {
+		close(started)
+		<-release
+		return r.Dispatch(w, req, "/fragment")
+	})
+	r.Get("/fragment", func(w http.ResponseWriter, req *http.Request) error {
+		w.Write([]byte("fragment"))
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil))
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- r.Shutdown(context.Background()) }()
+	for !r.shuttingDown.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// New requests are rejected
+	rejected := httptest.NewRecorder()
+	r.ServeHTTP(rejected, httptest.NewRequest(http.MethodGet, "/fragment", nil))
+	if rejected.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d for a new request, got %d", http.StatusServiceUnavailable, rejected.Code)
+	}
+
+	close(release)
+	<-done
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "fragment" {
+		t.Errorf("Expected the dispatched response, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+// TestInFlight tests counting the requests in flight and draining them on shutdown
+func TestInFlight(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r.Get("/slow", func(w http.ResponseWriter, req *http.Request) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+		<-started
+	}
+	if n := r.InFlight(); n != 3 {
+		t.Errorf("Expected 3 requests in flight, got %d", n)
+	}
+
+	// The shutdown times out while requests are in flight, and completes once they drain
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the shutdown to time out, got %v", err)
+	}
+	close(release)
+	wg.Wait()
+	if n := r.InFlight(); n != 0 {
+		t.Errorf("Expected no request in flight, got %d", n)
+	}
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected the shutdown to complete, got %v", err)
+	}
+}
+
+// TestShutdownCleanupAfterDrain tests that the cleanup middleware is cleaned up only after the
+// requests in flight have completed
+func TestShutdownCleanupAfterDrain(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var closed atomic.Bool
+	r.AddCleanupMiddleware(newCleanupMiddleware(func(next HandlerFunc) HandlerFunc { return next }, func() error {
+		closed.Store(true)
+		return nil
+	}))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var closedDuringRequest atomic.Bool
+	r.Get("/query", func(w http.ResponseWriter, req *http.Request) error {
+		close(started)
+		<-release
+		closedDuringRequest.Store(closed.Load())
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/query", nil))
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- r.Shutdown(context.Background()) }()
+	for !r.shuttingDown.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if closed.Load() {
+		t.Error("Expected no cleanup while a request is in flight")
+	}
+
+	close(release)
+	<-done
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+	if closedDuringRequest.Load() {
+		t.Error("Expected the request to complete before the cleanup")
+	}
+	if !closed.Load() {
+		t.Error("Expected the cleanup to run after the drain")
+	}
+}
@@ -0,0 +1,54 @@
+package router
+
+import (
+	"maps"
+	"time"
+)
+
+// SlowRequestInfo describes a request that took longer than the slow request threshold.
+type SlowRequestInfo struct {
+	Method   string            // HTTP method
+	Path     string            // Request path
+	Pattern  string            // Pattern of the matched route
+	Duration time.Duration     // Time taken to process the request
+	Params   map[string]string // URL parameters
+	TimedOut bool              // Whether the request eventually timed out
+}
+
+// slowRequestHook is a registered slow request callback.
+type slowRequestHook struct {
+	threshold time.Duration
+	fn        func(SlowRequestInfo)
+}
+
+// OnSlowRequest registers a function that is called whenever a matched request takes longer
+// than threshold to process. The function is called asynchronously in its own goroutine, so it
+// does not delay the response; it is a cheap alternative to full tracing for small services.
+// Passing a nil function removes the hook.
+func (r *Router) OnSlowRequest(threshold time.Duration, fn func(SlowRequestInfo)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fn == nil {
+		r.slowRequest = nil
+		return
+	}
+	r.slowRequest = &slowRequestHook{threshold: threshold, fn: fn}
+}
+
+// getSlowRequestHook returns the registered slow request hook (nil if none).
+func (r *Router) getSlowRequestHook() *slowRequestHook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.slowRequest
+}
+
+// report calls the hook asynchronously if the request exceeded the threshold.
+// params is copied because the map may be shared with the route cache.
+func (h *slowRequestHook) report(info SlowRequestInfo) {
+	if info.Duration < h.threshold {
+		return
+	}
+	info.Params = maps.Clone(info.Params)
+	go h.fn(info)
+}
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestOnSlowRequest tests that the slow request hook fires only for slow requests
+func TestOnSlowRequest(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	infos := make(chan SlowRequestInfo, 2)
+	r.OnSlowRequest(20*time.Millisecond, func(info SlowRequestInfo) {
+		infos <- info
+	})
+
+	r.Get("/reports/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		if id, _ := GetParams(req.Context()).Get("id"); id == "slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		return nil
+	})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports/fast", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports/slow", nil))
+
+	select {
+	case info := <-infos:
+		if info.Pattern != "/reports/{id}" || info.Params["id"] != "slow" || info.Duration < 20*time.Millisecond || info.TimedOut {
+			t.Errorf("Unexpected slow request info %+v", info)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Slow request hook was not called")
+	}
+
+	select {
+	case info := <-infos:
+		t.Errorf("Unexpected slow request %+v", info)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,50 @@
+package router
+
+import "context"
+
+// MatchOrigin identifies where the router found the route of a request.
+type MatchOrigin uint8
+
+const (
+	// MatchNone means the request was not matched by the router.
+	MatchNone MatchOrigin = iota
+	// MatchFromCache means the route was served from the route cache (warm path).
+	MatchFromCache
+	// MatchFromStatic means the route was found in the static route trie.
+	MatchFromStatic
+	// MatchFromDynamic means the route was found in the dynamic route tree.
+	MatchFromDynamic
+	// MatchFromPattern means the route was matched by a learned pattern plan (see RouterOptions.CacheByPattern).
+	MatchFromPattern
+	// MatchFromMount means the request was matched by a mounted handler (see Router.Mount).
+	MatchFromMount
+)
+
+// String returns the name of the match origin.
+func (s MatchOrigin) String() string {
+	switch s {
+	case MatchFromCache:
+		return "cache"
+	case MatchFromStatic:
+		return "static"
+	case MatchFromDynamic:
+		return "dynamic"
+	case MatchFromPattern:
+		return "pattern"
+	case MatchFromMount:
+		return "mount"
+	default:
+		return "none"
+	}
+}
+
+// matchSourceKey is the context key for the match source.
+type matchSourceKey struct{}
+
+// MatchSource returns where the router found the route of the current request
+// (route cache, static trie, or dynamic tree), so that performance investigations and
+// metrics can segment latency by match path. It returns MatchNone outside the router.
+func MatchSource(ctx context.Context) MatchOrigin {
+	source, _ := ctx.Value(matchSourceKey{}).(MatchOrigin)
+	return source
+}
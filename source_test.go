@@ -0,0 +1,44 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestMatchSource tests that the match origin of a request is available in the context
+func TestMatchSource(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var got MatchOrigin
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		got = MatchSource(req.Context())
+		return nil
+	}
+	r.Get("/static", handler)
+	r.Get("/users/{id}", handler)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want MatchOrigin
+	}{
+		{"/static", MatchFromStatic},
+		{"/static", MatchFromCache},
+		{"/users/1", MatchFromDynamic},
+		{"/users/1", MatchFromCache},
+	}
+	for _, tt := range tests {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, got)
+		}
+	}
+
+	if source := MatchSource(httptest.NewRequest(http.MethodGet, "/", nil).Context()); source != MatchNone {
+		t.Errorf("Expected %v outside the router, got %v", MatchNone, source)
+	}
+}
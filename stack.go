@@ -0,0 +1,108 @@
+package router
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// MiddlewareStack is a list of middleware that can be built once and attached to multiple routers,
+// for example to a public router and an admin router hosted by the same process.
+// The list is updated with copy-on-write semantics: changes made with Use or Set publish a new
+// snapshot that all attached routers pick up, so cross-cutting concerns such as logging and
+// metrics stay consistent between routers. Middleware added to a router itself does not affect the stack.
+type MiddlewareStack struct {
+	middleware atomic.Pointer[[]MiddlewareFunc] // Current snapshot of the middleware list
+	mu         sync.Mutex                       // Mutex for protecting updates and the router list
+	routers    []*Router                        // Routers the stack is attached to
+}
+
+// NewMiddlewareStack creates a middleware stack with the given middleware.
+func NewMiddlewareStack(middleware ...MiddlewareFunc) *MiddlewareStack {
+	s := &MiddlewareStack{}
+	s.store(slices.Clone(middleware))
+	return s
+}
+
+// Use appends middleware to the stack.
+// The change takes effect for all routers the stack is attached to.
+func (s *MiddlewareStack) Use(middleware ...MiddlewareFunc) *MiddlewareStack {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.Middleware()
+	newMiddleware := make([]MiddlewareFunc, len(current)+len(middleware))
+	copy(newMiddleware, current)
+	copy(newMiddleware[len(current):], middleware)
+
+	s.store(newMiddleware)
+	s.invalidate()
+	return s
+}
+
+// Set replaces the middleware of the stack.
+// The change takes effect for all routers the stack is attached to.
+func (s *MiddlewareStack) Set(middleware ...MiddlewareFunc) *MiddlewareStack {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(slices.Clone(middleware))
+	s.invalidate()
+	return s
+}
+
+// Middleware returns the current snapshot of the middleware list.
+// The returned slice must not be modified.
+func (s *MiddlewareStack) Middleware() []MiddlewareFunc {
+	if mw := s.middleware.Load(); mw != nil {
+		return *mw
+	}
+	return nil
+}
+
+// store atomically replaces the middleware snapshot.
+func (s *MiddlewareStack) store(middleware []MiddlewareFunc) {
+	s.middleware.Store(&middleware)
+}
+
+// invalidate discards the middleware chains cached by the attached routers.
+// The caller must hold s.mu.
+func (s *MiddlewareStack) invalidate() {
+	for _, r := range s.routers {
+		r.middlewareGen.Add(1)
+	}
+}
+
+// compose wraps the handler in the current middleware of the stack.
+// Like the router middleware, middleware added later is executed first.
+func (s *MiddlewareStack) compose(next HandlerFunc) HandlerFunc {
+	return applyMiddlewareChain(next, s.Middleware())
+}
+
+// UseStack attaches a shared middleware stack to the router.
+// The stack is inserted into the router's middleware list at the current position as a single
+// middleware, and later changes to the stack are reflected in the router.
+func (r *Router) UseStack(stack *MiddlewareStack) {
+	r.mustBeMutable("UseStack")
+
+	stack.attach(r)
+
+	r.mu.Lock()
+	if !slices.Contains(r.stacks, stack) {
+		r.stacks = append(r.stacks, stack)
+	}
+	r.mu.Unlock()
+
+	r.Use(stack.compose)
+}
+
+// attach registers the router with the stack, so that changes to the stack invalidate the
+// middleware chains cached by the router. Clones are registered with it as well, since they
+// inherit the middleware that composes the stack without calling UseStack.
+func (s *MiddlewareStack) attach(r *Router) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.routers, r) {
+		s.routers = append(s.routers, r)
+	}
+}
@@ -0,0 +1,61 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// TestMiddlewareStack tests sharing a middleware stack between routers
+func TestMiddlewareStack(t *testing.T) {
+	tag := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) error {
+				w.Header().Add("X-Middleware", name)
+				return next(w, req)
+			}
+		}
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	stack := NewMiddlewareStack(tag("logging"))
+
+	public := NewRouter()
+	defer public.cache.stop()
+	admin := NewRouter()
+	defer admin.cache.stop()
+
+	public.UseStack(stack)
+	admin.UseStack(stack)
+	admin.Use(tag("admin"))
+
+	for _, r := range []*Router{public, admin} {
+		r.Get("/status", handler)
+		if err := r.Build(); err != nil {
+			t.Fatalf("Failed to build router: %v", err)
+		}
+	}
+
+	serve := func(r *Router) []string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+		return w.Header().Values("X-Middleware")
+	}
+
+	if got, want := serve(public), []string{"logging"}; !slices.Equal(got, want) {
+		t.Errorf("Expected public middleware %v, got %v", want, got)
+	}
+	if got, want := serve(admin), []string{"admin", "logging"}; !slices.Equal(got, want) {
+		t.Errorf("Expected admin middleware %v, got %v", want, got)
+	}
+
+	// Changes to the stack are reflected in all routers
+	stack.Use(tag("metrics"))
+	if got, want := serve(public), []string{"metrics", "logging"}; !slices.Equal(got, want) {
+		t.Errorf("Expected public middleware %v, got %v", want, got)
+	}
+	if got, want := serve(admin), []string{"admin", "metrics", "logging"}; !slices.Equal(got, want) {
+		t.Errorf("Expected admin middleware %v, got %v", want, got)
+	}
+}
@@ -2,17 +2,25 @@ package router
 
 import (
 	"math"
+	"slices"
 	"sync"
 )
 
 // doubleArrayTrie is a data structure that enables fast string matching.
 // Each node is represented by an array, using base and check values to manage transitions.
 // It specializes in searching static route patterns, balancing memory efficiency and search speed.
+//
+// The router keys its static routes by path and method (see methodKey), so that a route answers
+// only its own method like a dynamic route does. The routes of a path share the nodes of the
+// path and differ only in the two transitions that follow it.
 type doubleArrayTrie struct {
 	base    []int32       // Base value for each node. Used for transitions to child nodes
-	check   []int32       // Used to verify parent-child relationships. 0 indicates unused
+	check   []int32       // Parent index + 1 of each node, used to verify parent-child relationships. 0 indicates unused
 	handler []HandlerFunc // Handler functions associated with each node
+	route   []*Route      // Route definitions associated with each node (nil for routes registered with Handle)
+	stats   []*routeStats // Usage statistics of the route at each node
 	size    int32         // Number of nodes in use
+	growth  float64       // Growth factor when expanding (growthFactor if 0)
 	mu      sync.RWMutex  // Mutex for protection from concurrent access
 }
 
@@ -27,10 +35,18 @@ const (
 // newDoubleArrayTrie initializes and returns a new doubleArrayTrie instance.
 // It allocates arrays with the initial size and sets the base value for the root node.
 func newDoubleArrayTrie() *doubleArrayTrie {
+	return newDoubleArrayTrieWithSize(initialTrieSize)
+}
+
+// newDoubleArrayTrieWithSize initializes and returns a new doubleArrayTrie instance
+// whose arrays have the specified initial size.
+func newDoubleArrayTrieWithSize(size int) *doubleArrayTrie {
 	t := &doubleArrayTrie{
-		base:    make([]int32, initialTrieSize),
-		check:   make([]int32, initialTrieSize),
-		handler: make([]HandlerFunc, initialTrieSize),
+		base:    make([]int32, size),
+		check:   make([]int32, size),
+		handler: make([]HandlerFunc, size),
+		route:   make([]*Route, size),
+		stats:   make([]*routeStats, size),
 		size:    1, // Root node exists, so start from 1
 	}
 
@@ -42,6 +58,13 @@ func newDoubleArrayTrie() *doubleArrayTrie {
 // Add adds a path and handler function to the trie.
 // Returns an error if the same path is already registered.
 func (t *doubleArrayTrie) Add(path string, h HandlerFunc) error {
+	return t.addRoute(path, h, nil, nil)
+}
+
+// addRoute adds a path, handler function, the route definition that produced it,
+// and the route's usage statistics to the trie.
+// Returns an error if the same path is already registered.
+func (t *doubleArrayTrie) addRoute(path string, h HandlerFunc, route *Route, stats *routeStats) error {
 	if len(path) == 0 {
 		return &RouterError{
 			Code:    ErrInvalidPattern,
@@ -69,6 +92,7 @@ func (t *doubleArrayTrie) Add(path string, h HandlerFunc) error {
 		return &RouterError{
 			Code:    ErrInvalidPattern,
 			Message: "duplicate static route: " + path,
+			Err:     ErrDuplicateRoute,
 		}
 	}
 
@@ -78,159 +102,215 @@ func (t *doubleArrayTrie) Add(path string, h HandlerFunc) error {
 	// Process the path character by character
 	currentNode := rootNode
 	for i := 0; i < len(path); i++ {
-		c := path[i]
-		baseVal := t.base[currentNode]
-
-		// If the current node doesn't have any child nodes yet
-		if baseVal == 0 {
-			// Calculate the new base value
-			nextNode := currentNode + int32(c) + 1
-
-			// Expand the base array if needed
-			if nextNode >= int32(len(t.base)) {
-				// Calculate array size (at least double, or larger if needed)
-				newSize := int32(len(t.base)) * 2
-				if nextNode >= newSize {
-					newSize = nextNode + 1024 // Add extra space
-				}
-
-				// Expand the array
-				if err := t.expand(newSize); err != nil {
-					return err
-				}
-			}
+		nextNode, err := t.addTransition(currentNode, path[i])
+		if err != nil {
+			return err
+		}
+		currentNode = nextNode
+	}
 
-			// set the new transition
-			t.base[currentNode] = nextNode - int32(c)
-			t.check[nextNode] = currentNode
-			currentNode = nextNode
-		} else {
-			// Calculate the next node using the existing base value
-			nextNode := baseVal + int32(c)
-
-			// Expand the base array if needed
-			if nextNode >= int32(len(t.base)) {
-				// Calculate array size (at least double, or larger if needed)
-				newSize := int32(len(t.base)) * 2
-				if nextNode >= newSize {
-					newSize = nextNode + 1024 // Add extra space
-				}
-
-				// Expand the array
-				if err := t.expand(newSize); err != nil {
-					return err
-				}
-			}
+	// set the handler at the terminal node
+	t.handler[currentNode] = h
+	t.route[currentNode] = route
+	t.stats[currentNode] = stats
 
-			// Check if the transition destination is unused
-			if t.check[nextNode] == 0 {
-				// If unused, set it
-				t.check[nextNode] = currentNode
-				currentNode = nextNode
-			} else if t.check[nextNode] == currentNode {
-				// If already transitioning from the same parent with the same character, no problem
-				currentNode = nextNode
-			} else {
-				// If a collision occurs, find a new base value
-				newBase := t.findBase([]byte(path[i:]))
-				if newBase < 0 {
-					return &RouterError{
-						Code:    ErrInternalError,
-						Message: "failed to find new base value",
-					}
-				}
-
-				// Move existing child nodes to new positions
-				oldBase := t.base[currentNode]
-				for ch := byte(0); ch < 128; ch++ { // Support ASCII characters only
-					oldNext := oldBase + int32(ch)
-					if oldNext < int32(len(t.check)) && t.check[oldNext] == currentNode {
-						// Found an existing child node
-						newNext := newBase + int32(ch)
-
-						// Expand the array if needed
-						if newNext >= int32(len(t.base)) {
-							newSize := int32(len(t.base)) * 2
-							if newNext >= newSize {
-								newSize = newNext + 1024
-							}
-							if err := t.expand(newSize); err != nil {
-								return err
-							}
-						}
-
-						// Move the child node to the new position
-						t.base[newNext] = t.base[oldNext]
-						t.check[newNext] = currentNode
-
-						// Clear the old position
-						t.check[oldNext] = 0
-					}
-				}
-
-				// Update the base of the current node
-				t.base[currentNode] = newBase
-
-				// Add the new transition
-				nextNode = newBase + int32(c)
-				t.check[nextNode] = currentNode
-				currentNode = nextNode
-			}
+	return nil
+}
+
+// methodKey returns the key of the static route of the method and path: the path followed by a
+// 0 byte and the method index (see methodToUint8). Lookups append the same two bytes to the
+// request path (see searchMethodRoute), so a key never matches another route's path, even if the
+// request path itself contains a 0 byte.
+func methodKey(methodIndex uint8, path string) string {
+	return path + string([]byte{0, methodIndex})
+}
+
+// searchMethodNode returns the index of the terminal node of the static route of the method and
+// path, or -1 if there is no such route. It is searchNode(methodKey(methodIndex, path)) without
+// building the key. The caller must hold the lock.
+func (t *doubleArrayTrie) searchMethodNode(methodIndex uint8, path string) int32 {
+	node := t.searchNode(path)
+	if node < 0 {
+		return -1
+	}
+	if node = t.transition(node, 0); node < 0 {
+		return -1
+	}
+	return t.transition(node, methodIndex)
+}
+
+// searchMethodRoute searches for the handler function, the route definition, and the usage
+// statistics of the static route of the method and path.
+// Returns nil if the path has no static route for the method.
+func (t *doubleArrayTrie) searchMethodRoute(methodIndex uint8, path string) (HandlerFunc, *Route, *routeStats) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	node := t.searchMethodNode(methodIndex, path)
+	if node < 0 {
+		return nil, nil, nil
+	}
+	return t.handler[node], t.route[node], t.stats[node]
+}
+
+// isMethodLeaf reports whether the node is the terminal node of a key built by methodKey for the
+// method, that is, whether it is reached with the method index from a node reached with 0.
+func (t *doubleArrayTrie) isMethodLeaf(node int32, methodIndex uint8) bool {
+	parent := t.check[node] - 1
+	if methodIndex == 0 || parent < 0 || parent >= t.size || t.base[parent]+int32(methodIndex) != node {
+		return false
+	}
+	grand := t.check[parent] - 1
+	return grand >= 0 && grand < t.size && t.base[grand] == parent
+}
+
+// transition returns the node reached from the current node with character c.
+// Returns -1 if the transition does not exist.
+func (t *doubleArrayTrie) transition(currentNode int32, c byte) int32 {
+	baseVal := t.base[currentNode]
+	if baseVal == 0 {
+		return -1 // No child nodes
+	}
+
+	nextNode := baseVal + int32(c)
+	if nextNode >= int32(len(t.check)) || t.check[nextNode] != currentNode+1 {
+		return -1 // No matching transition
+	}
+	return nextNode
+}
+
+// addTransition returns the node reached from the current node with character c,
+// creating the transition if it does not exist yet.
+// If the slot for the new transition is already used by another node,
+// all child nodes of the current node are relocated to a new base value.
+func (t *doubleArrayTrie) addTransition(currentNode int32, c byte) (int32, error) {
+	// Reuse the existing transition
+	if nextNode := t.transition(currentNode, c); nextNode >= 0 {
+		return nextNode, nil
+	}
+
+	baseVal := t.base[currentNode]
+
+	// If the current node doesn't have any child nodes yet, find a free base value
+	if baseVal == 0 {
+		newBase := t.findBase([]byte{c})
+		if newBase < 0 {
+			return 0, &RouterError{Code: ErrInternalError, Message: "failed to find new base value"}
 		}
+		t.base[currentNode] = newBase
+		return t.claim(currentNode, newBase+int32(c)), nil
 	}
 
-	// set the handler at the terminal node
-	if int(currentNode) >= len(t.handler) {
-		// Expand the handler array as well
-		newHandlers := make([]HandlerFunc, len(t.base))
-		copy(newHandlers, t.handler)
-		t.handler = newHandlers
+	// Expand the arrays if needed
+	nextNode := baseVal + int32(c)
+	if nextNode >= int32(len(t.check)) {
+		if err := t.expand(nextNode + 1); err != nil {
+			return 0, err
+		}
 	}
-	t.handler[currentNode] = h
 
-	// Update the number of nodes in use
-	if currentNode >= t.size {
-		t.size = currentNode + 1
+	// If the transition destination is unused, use it
+	if t.check[nextNode] == 0 {
+		return t.claim(currentNode, nextNode), nil
 	}
 
-	return nil
+	// If a collision occurs, find a new base value that fits all existing children and the new character
+	labels := append(t.childLabels(currentNode), c)
+	newBase := t.findBase(labels)
+	if newBase < 0 {
+		return 0, &RouterError{Code: ErrInternalError, Message: "failed to find new base value"}
+	}
+
+	// Move existing child nodes to new positions
+	for _, ch := range labels[:len(labels)-1] {
+		t.relocate(baseVal+int32(ch), newBase+int32(ch))
+	}
+
+	// Update the base of the current node and add the new transition
+	t.base[currentNode] = newBase
+	return t.claim(currentNode, newBase+int32(c)), nil
+}
+
+// claim marks the node as a child of the parent node and returns it.
+func (t *doubleArrayTrie) claim(parent, node int32) int32 {
+	t.check[node] = parent + 1
+	if node >= t.size {
+		t.size = node + 1
+	}
+	return node
+}
+
+// childLabels returns the characters of all transitions from the node.
+func (t *doubleArrayTrie) childLabels(node int32) []byte {
+	baseVal := t.base[node]
+	if baseVal == 0 {
+		return nil
+	}
+
+	labels := make([]byte, 0, 8)
+	for ch := 0; ch < 256; ch++ {
+		next := baseVal + int32(ch)
+		if next < int32(len(t.check)) && t.check[next] == node+1 {
+			labels = append(labels, byte(ch))
+		}
+	}
+	return labels
+}
+
+// relocate moves a node from oldNode to newNode.
+// The base value, handler, and parent are copied, and the check values of
+// the node's own children are updated to point to the new position.
+func (t *doubleArrayTrie) relocate(oldNode, newNode int32) {
+	t.base[newNode] = t.base[oldNode]
+	t.check[newNode] = t.check[oldNode]
+	t.handler[newNode] = t.handler[oldNode]
+	t.route[newNode] = t.route[oldNode]
+	t.stats[newNode] = t.stats[oldNode]
+	if newNode >= t.size {
+		t.size = newNode + 1
+	}
+
+	// Children of the moved node must now refer to the new position
+	for _, ch := range t.childLabels(oldNode) {
+		t.check[t.base[oldNode]+int32(ch)] = newNode + 1
+	}
+
+	// Clear the old position
+	t.base[oldNode] = 0
+	t.check[oldNode] = 0
+	t.handler[oldNode] = nil
+	t.route[oldNode] = nil
+	t.stats[oldNode] = nil
 }
 
 // searchWithoutLock searches for a path without locking.
 // Intended for internal use only.
 func (t *doubleArrayTrie) searchWithoutLock(path string) HandlerFunc {
-	if len(path) == 0 {
+	node := t.searchNode(path)
+	if node < 0 {
 		return nil
 	}
+	return t.handler[node]
+}
+
+// searchNode returns the index of the terminal node of the path, or -1 if the path does not exist.
+// The caller must hold the lock.
+func (t *doubleArrayTrie) searchNode(path string) int32 {
+	if len(path) == 0 {
+		return -1
+	}
 
 	// Start from the root node
 	currentNode := rootNode
 
 	// Process the path character by character
 	for i := 0; i < len(path); i++ {
-		c := path[i]
-
-		// Calculate the next node
-		if t.base[currentNode] == 0 {
-			return nil // No matching path
+		currentNode = t.transition(currentNode, path[i])
+		if currentNode < 0 {
+			return -1 // No matching path
 		}
-
-		nextNode := t.base[currentNode] + int32(c)
-
-		// Check if the transition is valid
-		if t.check[nextNode] != currentNode {
-			return nil // No matching path
-		}
-
-		currentNode = nextNode
 	}
-
-	// Check if there is a handler at the terminal node
-	if int(currentNode) < len(t.handler) && t.handler[currentNode] != nil {
-		return t.handler[currentNode]
-	}
-
-	return nil
+	return currentNode
 }
 
 // search searches for a handler function that matches the path.
@@ -246,18 +326,18 @@ func (t *doubleArrayTrie) search(path string) HandlerFunc {
 	return t.searchWithoutLock(path)
 }
 
-// findBase searches for an appropriate base value for the specified character set.
-// It searches until it finds a position with no conflicts for all characters in the character set.
-func (t *doubleArrayTrie) findBase(suffix []byte) int32 {
-	// get the maximum character code in the suffix
+// findBase searches for an appropriate base value for the specified set of characters.
+// It searches until it finds a position with no conflicts for all characters in the set.
+func (t *doubleArrayTrie) findBase(labels []byte) int32 {
+	// get the maximum character code in the set
 	var maxCharCode int32 = 0
-	for _, char := range suffix {
+	for _, char := range labels {
 		if int32(char) > maxCharCode {
 			maxCharCode = int32(char)
 		}
 	}
 
-	// Start with a base value candidate of 1
+	// Start with a base value candidate of 1 (0 means "no child nodes")
 	baseCandidate := int32(1)
 
 	// search until a base value with no conflicts is found
@@ -272,11 +352,11 @@ func (t *doubleArrayTrie) findBase(suffix []byte) int32 {
 			}
 		}
 
-		// Check for conflicts
+		// Check for conflicts (the root node can never be a child)
 		hasCollision := false
-		for _, char := range suffix {
+		for _, char := range labels {
 			nextPos := baseCandidate + int32(char)
-			if t.check[nextPos] != 0 { // Position already in use
+			if nextPos == rootNode || t.check[nextPos] != 0 { // Position already in use
 				hasCollision = true
 				break
 			}
@@ -296,7 +376,11 @@ func (t *doubleArrayTrie) findBase(suffix []byte) int32 {
 // The new size is calculated as a multiple of the current size.
 func (t *doubleArrayTrie) expand(requiredSize int32) error {
 	// Calculate the new size (either a multiple of the current size or the required size, whichever is larger)
-	newSize := int32(math.Max(float64(len(t.base))*growthFactor, float64(requiredSize)))
+	growth := t.growth
+	if growth <= 1 {
+		growth = growthFactor
+	}
+	newSize := int32(math.Max(float64(len(t.base))*growth, float64(requiredSize)))
 
 	// Check size limit
 	if newSize > 1<<30 { // About 1 billion nodes
@@ -307,6 +391,8 @@ func (t *doubleArrayTrie) expand(requiredSize int32) error {
 	newBase := make([]int32, newSize)
 	newCheck := make([]int32, newSize)
 	newHandler := make([]HandlerFunc, newSize)
+	newRoute := make([]*Route, newSize)
+	newStats := make([]*routeStats, newSize)
 
 	// Copy existing data
 	copy(newBase, t.base)
@@ -314,11 +400,71 @@ func (t *doubleArrayTrie) expand(requiredSize int32) error {
 	if t.handler != nil {
 		copy(newHandler, t.handler)
 	}
+	copy(newRoute, t.route)
+	copy(newStats, t.stats)
 
 	// set new array
 	t.base = newBase
 	t.check = newCheck
 	t.handler = newHandler
+	t.route = newRoute
+	t.stats = newStats
 
 	return nil
 }
+
+// Compact shrinks the arrays of the trie to the minimal size.
+// Repeated additions leave the arrays larger than needed, because they grow by growthFactor
+// and relocations during findBase leave unused slots behind. Compact rebuilds the trie by
+// inserting all paths again in sorted order, which packs the nodes densely, and truncates the
+// arrays to the nodes in use. It returns the array length before and after compaction.
+func (t *doubleArrayTrie) Compact() (before, after int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	before = len(t.base)
+
+	// Collect all terminal nodes with their paths (childLabels returns them in sorted order)
+	type entry struct {
+		path    string
+		handler HandlerFunc
+		route   *Route
+		stats   *routeStats
+	}
+	var entries []entry
+	var walk func(node int32, path []byte)
+	walk = func(node int32, path []byte) {
+		if t.handler[node] != nil {
+			entries = append(entries, entry{string(path), t.handler[node], t.route[node], t.stats[node]})
+		}
+		for _, c := range t.childLabels(node) {
+			walk(t.base[node]+int32(c), append(path, c))
+		}
+	}
+	walk(rootNode, nil)
+
+	// Rebuild, and keep whichever of the rebuilt and the current layout is smaller
+	rebuilt := newDoubleArrayTrieWithSize(int(baseOffset) + 1)
+	rebuilt.growth = t.growth
+	for _, e := range entries {
+		if err := rebuilt.addRoute(e.path, e.handler, e.route, e.stats); err != nil {
+			rebuilt = nil
+			break
+		}
+	}
+	source := t
+	if rebuilt != nil && rebuilt.size < t.size {
+		source = rebuilt
+	}
+
+	// Truncate the arrays to the nodes in use (copies release the old backing arrays)
+	n := source.size
+	t.base = slices.Clone(source.base[:n])
+	t.check = slices.Clone(source.check[:n])
+	t.handler = slices.Clone(source.handler[:n])
+	t.route = slices.Clone(source.route[:n])
+	t.stats = slices.Clone(source.stats[:n])
+	t.size = n
+
+	return before, len(t.base)
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -220,3 +221,148 @@ func TestNilHandlerAddition(t *testing.T) {
 		t.Errorf("Error message is different. Expected: %s, Actual: %s", expectedMsg, routerErr.Message)
 	}
 }
+
+// TestTrieCompact tests shrinking the trie arrays to the minimal size
+func TestTrieCompact(t *testing.T) {
+	trie := newDoubleArrayTrie()
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	var paths []string
+	for i := 0; i < 500; i++ {
+		paths = append(paths, fmt.Sprintf("/api/v%d/resource%d/items", i%7, i))
+	}
+	for _, path := range paths {
+		if err := trie.Add(path, handler); err != nil {
+			t.Fatalf("Failed to add route: %v", err)
+		}
+	}
+
+	before, after := trie.Compact()
+	if after >= before {
+		t.Errorf("Expected the arrays to shrink, got %d -> %d", before, after)
+	}
+	if after != len(trie.base) || after != len(trie.check) || after != len(trie.handler) || int32(after) != trie.size {
+		t.Errorf("Expected all arrays to be truncated to the size %d", after)
+	}
+
+	// All paths are still found, and only those
+	for _, path := range paths {
+		if trie.search(path) == nil {
+			t.Errorf("Path %s not found after compaction", path)
+		}
+	}
+	if trie.search("/api/v0/resource0") != nil {
+		t.Error("Expected an intermediate node not to match")
+	}
+
+	// Paths can still be added after compaction
+	if err := trie.Add("/zzz/after", handler); err != nil {
+		t.Fatalf("Failed to add route after compaction: %v", err)
+	}
+	if trie.search("/zzz/after") == nil || trie.search(paths[0]) == nil {
+		t.Error("Expected paths to be found after adding to a compacted trie")
+	}
+}
+
+// TestMemoryTuningOptions tests the trie and parameter capacity options
+func TestMemoryTuningOptions(t *testing.T) {
+	opts := defaultRouterOptions()
+	opts.TrieInitialSize = 64
+	opts.TrieGrowthFactor = 2
+	opts.ParamsCapacityHint = 2
+	r := NewRouterWithOptions(opts)
+	defer r.cache.stop()
+
+	if len(r.static.base) != 64 {
+		t.Errorf("Expected trie arrays of length 64, got %d", len(r.static.base))
+	}
+	if err := r.static.expand(65); err != nil {
+		t.Fatalf("Failed to expand trie: %v", err)
+	}
+	if len(r.static.base) != 128 {
+		t.Errorf("Expected the trie to double, got length %d", len(r.static.base))
+	}
+	ps := r.paramsPool.Get()
+	if cap(ps.data) != 2 {
+		t.Errorf("Expected params capacity 2, got %d", cap(ps.data))
+	}
+	r.paramsPool.Put(ps)
+
+	// Clones keep the settings
+	clone, err := r.Clone()
+	if err != nil {
+		t.Fatalf("Failed to clone router: %v", err)
+	}
+	defer clone.cache.stop()
+	if len(clone.static.base) != 64 || clone.static.growth != 2 || clone.paramsPool.capacity != 2 {
+		t.Errorf("Expected the clone to keep the memory settings")
+	}
+
+	// Invalid values fall back to the defaults
+	r2 := NewRouterWithOptions(RouterOptions{TrieGrowthFactor: 0.5})
+	defer r2.cache.stop()
+	if len(r2.static.base) != initialTrieSize || r2.static.growth != growthFactor || r2.paramsPool.capacity != initialParamsCapacity {
+		t.Errorf("Expected default memory settings")
+	}
+}
+
+// TestStaticRouteMethods tests that static routes answer only their own method
+func TestStaticRouteMethods(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	respond := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			w.Write([]byte(body))
+			return nil
+		}
+	}
+	r.Get("/home", respond("get home"))
+	r.Post("/home", respond("post home"))
+	r.Delete("/users/{id}", respond("delete user"))
+	r.Get("/users/admin", respond("get admin"))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+		allow        string
+	}{
+		{http.MethodGet, "/home", http.StatusOK, "get home", ""},
+		{http.MethodPost, "/home", http.StatusOK, "post home", ""},
+		{http.MethodDelete, "/home", http.StatusMethodNotAllowed, "Method Not Allowed\n", "GET, POST, HEAD"},
+		{http.MethodGet, "/users/admin", http.StatusOK, "get admin", ""},
+		// The static route of another method does not hide the dynamic route
+		{http.MethodDelete, "/users/admin", http.StatusOK, "delete user", ""},
+		{http.MethodPut, "/users/admin", http.StatusMethodNotAllowed, "Method Not Allowed\n", "GET, DELETE, HEAD"},
+		// HEAD requests are answered by the GET route, without the body
+		{http.MethodHead, "/home", http.StatusOK, "", ""},
+		{http.MethodHead, "/users/admin", http.StatusOK, "", ""},
+	}
+	for _, tt := range tests {
+		// Twice, so that the second request is served from the route cache
+		for range 2 {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.status || w.Body.String() != tt.body {
+				t.Errorf("%s %s: expected %d %q, got %d %q", tt.method, tt.path, tt.status, tt.body, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("%s %s: expected Allow %q, got %q", tt.method, tt.path, tt.allow, got)
+			}
+		}
+	}
+
+	// Registering the same static path for another method is not a duplicate
+	if err := r.Handle(http.MethodPut, "/home", respond("put home")); err != nil {
+		t.Errorf("Expected no error for another method, got %v", err)
+	}
+	if err := r.Handle(http.MethodGet, "/home", respond("get home")); !IsDuplicate(err) {
+		t.Errorf("Expected a duplicate route error, got %v", err)
+	}
+}
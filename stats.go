@@ -0,0 +1,84 @@
+package router
+
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// RouteStats is a snapshot of the usage statistics of a route.
+type RouteStats struct {
+	Method     string    // HTTP method
+	Pattern    string    // Route pattern
+	Hits       uint64    // Number of requests served by the route
+	Errors     uint64    // Number of requests for which the handler chain returned an error
+	LastAccess time.Time // Time of the last request (zero if the route has never been hit)
+}
+
+// routeStats holds the usage counters of a route.
+// It is attached to static trie entries and dynamic nodes, and updated with atomic operations only.
+type routeStats struct {
+	method     string
+	pattern    string
+	hits       atomic.Uint64
+	errors     atomic.Uint64
+	lastAccess atomic.Int64 // Unix nanoseconds
+
+	latency *latencyHistogram // Latency histogram (nil unless RouterOptions.LatencyHistograms)
+}
+
+// hit records a request to the route.
+func (s *routeStats) hit() {
+	s.hits.Add(1)
+	s.lastAccess.Store(time.Now().UnixNano())
+}
+
+// snapshot returns the current values of the counters.
+func (s *routeStats) snapshot() RouteStats {
+	stats := RouteStats{
+		Method:  s.method,
+		Pattern: s.pattern,
+		Hits:    s.hits.Load(),
+		Errors:  s.errors.Load(),
+	}
+	if lastAccess := s.lastAccess.Load(); lastAccess != 0 {
+		stats.LastAccess = time.Unix(0, lastAccess)
+	}
+	return stats
+}
+
+// routeStatsFor returns the statistics of the route, creating them on first registration.
+// Statistics survive when a route is overridden, so counts are kept per method and pattern.
+// The caller must hold r.mu.
+func (r *Router) routeStatsFor(method, pattern string) *routeStats {
+	key := method + " " + pattern
+	if stats, ok := r.routeStats[key]; ok {
+		return stats
+	}
+	if r.routeStats == nil {
+		r.routeStats = make(map[string]*routeStats)
+	}
+	stats := &routeStats{method: method, pattern: pattern}
+	if r.latencies {
+		stats.latency = &latencyHistogram{}
+	}
+	r.routeStats[key] = stats
+	return stats
+}
+
+// Stats returns the usage statistics of all registered routes, sorted by pattern and method.
+// Routes with zero hits or an old LastAccess are candidates for removal.
+func (r *Router) Stats() []RouteStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make([]RouteStats, 0, len(r.routeStats))
+	for _, s := range r.routeStats {
+		stats = append(stats, s.snapshot())
+	}
+	slices.SortFunc(stats, func(a, b RouteStats) int {
+		return cmp.Or(cmp.Compare(a.Pattern, b.Pattern), cmp.Compare(a.Method, b.Method))
+	})
+	return stats
+}
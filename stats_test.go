@@ -0,0 +1,57 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRouterStats tests per-route hit and error counts
+func TestRouterStats(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		if GetParams(r.Context()).Len() > 0 {
+			if id, _ := GetParams(r.Context()).Get("id"); id == "0" {
+				return errors.New("not found")
+			}
+		}
+		return nil
+	})
+	if err := r.Handle(http.MethodGet, "/legacy", func(w http.ResponseWriter, r *http.Request) error { return nil }); err != nil {
+		t.Fatalf("Failed to register route: %v", err)
+	}
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	for _, path := range []string{"/health", "/health", "/users/1", "/users/0", "/users/0", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	stats := r.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 routes, got %d", len(stats))
+	}
+	want := map[string][2]uint64{
+		"/health":     {2, 0},
+		"/legacy":     {0, 0},
+		"/users/{id}": {3, 2},
+	}
+	for _, s := range stats {
+		counts, ok := want[s.Pattern]
+		if !ok {
+			t.Errorf("Unexpected route %s", s.Pattern)
+			continue
+		}
+		if s.Hits != counts[0] || s.Errors != counts[1] {
+			t.Errorf("%s: expected %d hits and %d errors, got %d and %d", s.Pattern, counts[0], counts[1], s.Hits, s.Errors)
+		}
+		if (s.Hits > 0) == s.LastAccess.IsZero() {
+			t.Errorf("%s: unexpected last access %v", s.Pattern, s.LastAccess)
+		}
+	}
+}
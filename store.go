@@ -0,0 +1,183 @@
+package router
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Store is a small key-value storage interface with expiration, shared by middleware that
+// needs to keep state between requests (request deduplication, idempotency keys, rate limits,
+// sessions, response caches). Implementations must be safe for concurrent use.
+// A TTL of 0 or less means the value does not expire.
+type Store interface {
+	// Get returns the value for the key. It returns false if the key does not exist or has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value for the key with the TTL, replacing any existing value.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// AtomicStore is a Store that can add a key only if it does not exist yet.
+// Replay protection relies on it to reject duplicates without a race between Get and Set.
+type AtomicStore interface {
+	Store
+	// Add stores the value only if the key does not exist (or has expired) and reports whether it was stored.
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+const (
+	storeShardCount             = 16
+	defaultStoreCleanupInterval = time.Minute
+)
+
+// MemoryStore is a sharded in-memory AtomicStore.
+// Expired entries are removed lazily on access and periodically by a background goroutine.
+// MemoryStore implements CleanupMiddleware, so adding it with Router.AddCleanupMiddleware
+// stops the background goroutine when the router shuts down.
+type MemoryStore struct {
+	shards   [storeShardCount]*storeShard
+	stopChan chan struct{}
+	stopped  atomic.Bool
+}
+
+type storeShard struct {
+	sync.Mutex
+	entries map[string]storeEntry
+}
+
+type storeEntry struct {
+	value     []byte
+	expiresAt int64 // Unix nanoseconds (0 means no expiration)
+}
+
+// NewMemoryStore creates a MemoryStore that removes expired entries at the given interval.
+// If the interval is 0 or less, the default of one minute is used.
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	if cleanupInterval <= 0 {
+		cleanupInterval = defaultStoreCleanupInterval
+	}
+	s := &MemoryStore{stopChan: make(chan struct{})}
+	for i := range s.shards {
+		s.shards[i] = &storeShard{entries: make(map[string]storeEntry)}
+	}
+	go s.cleanupLoop(cleanupInterval)
+	return s
+}
+
+// shard returns the shard responsible for the key.
+func (s *MemoryStore) shard(key string) *storeShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return s.shards[h.Sum32()%storeShardCount]
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	sh := s.shard(key)
+	sh.Lock()
+	defer sh.Unlock()
+
+	e, ok := sh.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if e.expired(time.Now().UnixNano()) {
+		delete(sh.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set implements Store.
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	sh := s.shard(key)
+	sh.Lock()
+	sh.entries[key] = newStoreEntry(value, ttl)
+	sh.Unlock()
+	return nil
+}
+
+// Add implements AtomicStore.
+func (s *MemoryStore) Add(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	sh := s.shard(key)
+	sh.Lock()
+	defer sh.Unlock()
+
+	if e, ok := sh.entries[key]; ok && !e.expired(time.Now().UnixNano()) {
+		return false, nil
+	}
+	sh.entries[key] = newStoreEntry(value, ttl)
+	return true, nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	sh := s.shard(key)
+	sh.Lock()
+	delete(sh.entries, key)
+	sh.Unlock()
+	return nil
+}
+
+// Middleware implements CleanupMiddleware. The store does not wrap handlers.
+func (s *MemoryStore) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return next
+	}
+}
+
+// Cleanup implements CleanupMiddleware by stopping the background cleanup goroutine.
+// The store remains usable afterwards, but expired entries are only removed on access.
+func (s *MemoryStore) Cleanup() error {
+	if s.stopped.CompareAndSwap(false, true) {
+		close(s.stopChan)
+	}
+	return nil
+}
+
+// cleanupLoop periodically removes expired entries.
+func (s *MemoryStore) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.removeExpired()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// removeExpired removes expired entries from all shards.
+func (s *MemoryStore) removeExpired() {
+	now := time.Now().UnixNano()
+	for _, sh := range s.shards {
+		sh.Lock()
+		for key, e := range sh.entries {
+			if e.expired(now) {
+				delete(sh.entries, key)
+			}
+		}
+		sh.Unlock()
+	}
+}
+
+// newStoreEntry creates an entry that expires after ttl.
+func newStoreEntry(value []byte, ttl time.Duration) storeEntry {
+	e := storeEntry{value: value}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+	return e
+}
+
+// expired reports whether the entry has expired at now (Unix nanoseconds).
+func (e storeEntry) expired(now int64) bool {
+	return e.expiresAt != 0 && now >= e.expiresAt
+}
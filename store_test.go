@@ -0,0 +1,54 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestMemoryStore tests the in-memory store
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(10 * time.Millisecond)
+	ctx := context.Background()
+
+	if err := s.Set(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	if value, ok, _ := s.Get(ctx, "a"); !ok || string(value) != "1" {
+		t.Errorf("Expected value 1, got %q (found %v)", value, ok)
+	}
+
+	// Add does not replace an existing key
+	if added, _ := s.Add(ctx, "a", []byte("2"), 0); added {
+		t.Error("Add should not replace an existing key")
+	}
+	if added, _ := s.Add(ctx, "b", []byte("2"), 20*time.Millisecond); !added {
+		t.Error("Add should store a new key")
+	}
+
+	// Expired keys are not returned and can be added again
+	time.Sleep(50 * time.Millisecond)
+	if _, ok, _ := s.Get(ctx, "b"); ok {
+		t.Error("Expired key should not be returned")
+	}
+	if added, _ := s.Add(ctx, "b", []byte("3"), 0); !added {
+		t.Error("Add should store an expired key")
+	}
+
+	if err := s.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, ok, _ := s.Get(ctx, "a"); ok {
+		t.Error("Deleted key should not be returned")
+	}
+
+	// The store is cleaned up with the router
+	r := NewRouter()
+	r.AddCleanupMiddleware(s)
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatalf("Failed to shutdown router: %v", err)
+	}
+	if !s.stopped.Load() {
+		t.Error("Store cleanup was not called on shutdown")
+	}
+}
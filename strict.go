@@ -0,0 +1,36 @@
+package router
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// buildWarning reports a warning-level issue found by Build.
+// In strict mode the issue is returned as an error so that Build fails; otherwise it is logged.
+func (r *Router) buildWarning(message string) error {
+	if r.strict {
+		return &RouterError{Code: ErrInvalidPattern, Message: "strict mode: " + message}
+	}
+	r.logf(slog.LevelWarn, "%s", message)
+	return nil
+}
+
+// checkRouteTable reports the warning-level issues of the built route table.
+// Unreachable routes are only searched for in strict mode, since the search matches a sample
+// path for every dynamic route (development mode logs them separately).
+func (r *Router) checkRouteTable(routes []*Route) error {
+	if r.strict {
+		if shadowed := r.shadowedRoutes(routes); len(shadowed) > 0 {
+			return &RouterError{Code: ErrInvalidPattern, Message: "strict mode: " + strings.Join(shadowed, "; ")}
+		}
+	}
+
+	for _, group := range r.allGroups() {
+		if group.requireError && group.errorHandler == nil {
+			if err := r.buildWarning("group " + group.prefix + " requires an error handler but has none"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
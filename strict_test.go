@@ -0,0 +1,88 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestStrictMode tests that strict mode turns build warnings into errors
+func TestStrictMode(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	errorHandler := func(w http.ResponseWriter, r *http.Request, err error) {}
+
+	tests := []struct {
+		name   string
+		setup  func(r *Router)
+		errMsg string // expected in strict mode ("" means Build succeeds)
+	}{
+		{
+			name: "duplicate route with override",
+			setup: func(r *Router) {
+				r.Get("/users/{id}", handler)
+				r.Get("/users/{id}", handler)
+			},
+			errMsg: "overriding route: GET /users/{id}",
+		},
+		{
+			name: "duplicate group route with override",
+			setup: func(r *Router) {
+				api := r.Group("/api")
+				api.Get("/items/{id}", handler)
+				api.Get("/items/{id}", handler)
+			},
+			errMsg: "overriding route: GET /api/items/{id}",
+		},
+		{
+			name: "shadowed route",
+			setup: func(r *Router) {
+				r.Get("/users/{id}/posts", handler)
+				r.Get("/users/{name}/posts", handler)
+			},
+			errMsg: "/posts is shadowed by /users/{",
+		},
+		{
+			name: "missing required error handler",
+			setup: func(r *Router) {
+				r.Group("/admin").RequireErrorHandler().Get("/stats", handler)
+			},
+			errMsg: "group /admin requires an error handler",
+		},
+		{
+			name: "required error handler set",
+			setup: func(r *Router) {
+				r.Group("/admin").RequireErrorHandler().WithErrorHandler(errorHandler).Get("/stats", handler)
+				r.Get("/users/{id}", handler)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without strict mode the issues are only warnings
+			opts := defaultRouterOptions()
+			opts.AllowRouteOverride = true
+			r := NewRouterWithOptions(opts)
+			defer r.cache.stop()
+			tt.setup(r)
+			if err := r.Build(); err != nil {
+				t.Errorf("Expected Build to succeed without strict mode, got %v", err)
+			}
+
+			opts.Strict = true
+			strict := NewRouterWithOptions(opts)
+			defer strict.cache.stop()
+			tt.setup(strict)
+			err := strict.Build()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Expected Build to succeed, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected an error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package router
+
+import "slices"
+
+// WithTags labels the route, so that one codebase can build different subsets of its routes per
+// deployment with Router.BuildOnly and Router.BuildExcept, for example a public edge without the
+// routes tagged "internal". Tags of the route's groups apply as well (see Group.WithTags).
+//
+// 例: r.Get("/admin/users", listUsers).WithTags("internal")
+func (r *Route) WithTags(tags ...string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.tags = append(r.tags, tags...)
+	return r
+}
+
+// Tags returns the tags of the route, including the tags of its groups.
+func (r *Route) Tags() []string {
+	tags := slices.Clone(r.tags)
+	for g := r.group; g != nil; g = g.parent {
+		tags = append(tags, g.tags...)
+	}
+	return tags
+}
+
+// hasAnyTag reports whether the route or one of its groups has one of the tags.
+func (r *Route) hasAnyTag(tags []string) bool {
+	return slices.ContainsFunc(r.Tags(), func(tag string) bool { return slices.Contains(tags, tag) })
+}
+
+// WithTags labels the routes of the group and its child groups (see Route.WithTags).
+func (g *Group) WithTags(tags ...string) *Group {
+	g.tags = append(g.tags, tags...)
+	return g
+}
+
+// BuildOnly is like Build, but registers only the routes that have at least one of the tags
+// (see Route.WithTags). The other routes, including untagged ones, are left out of the route
+// table and of the duplicate checks, so a deployment can build the subset of the routes it serves.
+// Routes registered immediately with Handle are not affected.
+//
+// 例: err := r.BuildOnly("public")
+func (r *Router) BuildOnly(tags ...string) error {
+	return r.buildSelected(func(route *Route) bool { return route.hasAnyTag(tags) })
+}
+
+// BuildExcept is like Build, but leaves out the routes that have any of the tags
+// (see Route.WithTags), for example the administrative routes on a public edge.
+//
+// 例: err := r.BuildExcept("internal", "beta")
+func (r *Router) BuildExcept(tags ...string) error {
+	return r.buildSelected(func(route *Route) bool { return !route.hasAnyTag(tags) })
+}
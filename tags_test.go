@@ -0,0 +1,70 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// TestBuildOnlyExcept tests building subsets of the routes by tag
+func TestBuildOnlyExcept(t *testing.T) {
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	setup := func() *Router {
+		r := NewRouter()
+		r.Get("/", handler).WithTags("public")
+		r.Get("/beta", handler).WithTags("public", "beta")
+		r.Get("/untagged", handler)
+		admin := r.Group("/admin").WithTags("internal")
+		admin.Get("/users", handler)
+		admin.Group("/debug").Get("/vars", handler).WithTags("beta")
+		return r
+	}
+	paths := []string{"/", "/beta", "/untagged", "/admin/users", "/admin/debug/vars"}
+
+	tests := []struct {
+		name  string
+		build func(r *Router) error
+		want  []string
+	}{
+		{"all", (*Router).Build, paths},
+		{"only public", func(r *Router) error { return r.BuildOnly("public") }, []string{"/", "/beta"}},
+		{"only internal", func(r *Router) error { return r.BuildOnly("internal") }, []string{"/admin/users", "/admin/debug/vars"}},
+		{"except internal and beta", func(r *Router) error { return r.BuildExcept("internal", "beta") }, []string{"/", "/untagged"}},
+	}
+	for _, tt := range tests {
+		r := setup()
+		if err := tt.build(r); err != nil {
+			t.Fatalf("%s: failed to build router: %v", tt.name, err)
+		}
+		for _, path := range paths {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if served := w.Code == http.StatusOK; served != slices.Contains(tt.want, path) {
+				t.Errorf("%s: %s served %v, expected %v", tt.name, path, served, !served)
+			}
+		}
+		r.cache.stop()
+	}
+
+	// Duplicates in routes left out are not reported
+	r := NewRouter()
+	defer r.cache.stop()
+	r.Get("/status", handler).WithTags("edge")
+	r.Get("/status", handler).WithTags("internal")
+	if err := r.BuildExcept("internal"); err != nil {
+		t.Errorf("Expected the excluded duplicate to be ignored, got %v", err)
+	}
+}
+
+// TestRouteTags tests that routes inherit the tags of their groups
+func TestRouteTags(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	route := r.Group("/admin").WithTags("internal").Group("/beta").WithTags("beta").
+		Get("/x", func(w http.ResponseWriter, req *http.Request) error { return nil }).WithTags("v2")
+	if tags := route.Tags(); !slices.Equal(tags, []string{"v2", "beta", "internal"}) {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+}
@@ -0,0 +1,107 @@
+package router
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// tenantKey is the context key for the tenant of the request.
+type tenantKey struct{}
+
+// Tenant sets the function that identifies the tenant of a request (for example from the Host
+// header, a subdomain, or an authenticated claim). It enables the overlays defined with ForTenant:
+// requests of a tenant are first matched against the routes of the tenant's overlay, and fall back
+// to the routes of the router if the overlay has no matching route.
+// An empty result means that the request has no tenant.
+//
+// 例: r.Tenant(func(req *http.Request) string { return strings.Split(req.Host, ".")[0] })
+func (r *Router) Tenant(extractor func(*http.Request) string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenantExtractor = extractor
+}
+
+// ForTenant returns a group for defining the routes that override or extend the routes of the
+// router for one tenant, so that a SaaS deployment can customize a few routes per tenant without
+// separate routers. The overlay is built together with the router by Build.
+//
+// Overlay routes run behind the global middleware of the router and inherit its request timeout
+// and error handler, exactly like the routes of the router. Calling ForTenant again for the same
+// tenant returns another group of the same overlay. The overlays are frozen together with the
+// router (see Freeze), so their groups, including groups obtained before Freeze, reject changes.
+//
+// 例: r.ForTenant("acme").Get("/dashboard", acmeDashboard)
+func (r *Router) ForTenant(tenant string) *Group {
+	r.mustBeMutable("ForTenant")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	overlay, ok := r.tenants[tenant]
+	if !ok {
+		overlay = NewRouterWithOptions(r.options())
+		overlay.tenantParent = r
+		if r.tenants == nil {
+			r.tenants = make(map[string]*Router)
+		}
+		r.tenants[tenant] = overlay
+	}
+	return overlay.Group("/")
+}
+
+// TenantFromContext returns the tenant of the request as identified by the function set with
+// Router.Tenant, or an empty string if the request has no tenant.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// buildTenants builds the overlays of the tenants with the build checks of the router, selecting
+// the same routes as the Build of the router (see buildSelected).
+func (r *Router) buildTenants(selected func(*Route) bool) error {
+	r.mu.RLock()
+	tenants := maps.Clone(r.tenants)
+	checks := slices.Clone(r.buildChecks)
+	r.mu.RUnlock()
+
+	for _, tenant := range slices.Sorted(maps.Keys(tenants)) {
+		overlay := tenants[tenant]
+		overlay.mu.Lock()
+		overlay.buildChecks = checks
+		overlay.mu.Unlock()
+
+		if err := overlay.buildSelected(selected); err != nil {
+			return wrapTenantError(tenant, err)
+		}
+	}
+	return nil
+}
+
+// wrapTenantError adds the tenant to the message of a build error of its overlay, keeping its cause.
+func wrapTenantError(tenant string, err error) error {
+	if routerErr, ok := err.(*RouterError); ok {
+		return &RouterError{Code: routerErr.Code, Message: "tenant " + tenant + ": " + routerErr.Message, Err: routerErr.Err}
+	}
+	return &RouterError{Code: ErrInternalError, Message: "tenant " + tenant + ": " + err.Error()}
+}
+
+// tenantOf identifies the tenant of the request and returns its overlay (nil if it has none).
+func (r *Router) tenantOf(req *http.Request) (string, *Router) {
+	r.mu.RLock()
+	extractor := r.tenantExtractor
+	r.mu.RUnlock()
+	if extractor == nil {
+		return "", nil
+	}
+
+	tenant := extractor(req)
+	if tenant == "" {
+		return "", nil
+	}
+	r.mu.RLock()
+	overlay := r.tenants[tenant]
+	r.mu.RUnlock()
+	return tenant, overlay
+}
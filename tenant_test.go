@@ -0,0 +1,112 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestTenantOverlay tests resolving per-tenant routes at request time
+func TestTenantOverlay(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Tenant(func(req *http.Request) string { return req.Header.Get("X-Tenant") })
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			w.Header().Set("X-Global", "yes")
+			return next(w, req)
+		}
+	})
+	r.SetErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {
+		http.Error(w, "router error", http.StatusTeapot)
+	})
+	respond := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			text := body
+			if id, ok := GetParams(req.Context()).Get("id"); ok {
+				text += ":" + id
+			}
+			w.Write([]byte(text + "@" + TenantFromContext(req.Context())))
+			return nil
+		}
+	}
+	r.Get("/dashboard", respond("default"))
+	r.Get("/users/{id}", respond("user"))
+	acme := r.ForTenant("acme")
+	acme.Get("/dashboard", respond("acme"))
+	acme.Get("/users/{id}", respond("acme-user"))
+	acme.Get("/fail", func(w http.ResponseWriter, req *http.Request) error { return errors.New("failed") })
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		tenant string
+		path   string
+		status int
+		body   string
+	}{
+		{"", "/dashboard", http.StatusOK, "default@"},
+		{"acme", "/dashboard", http.StatusOK, "acme@acme"},
+		{"acme", "/dashboard", http.StatusOK, "acme@acme"},
+		{"globex", "/dashboard", http.StatusOK, "default@globex"},
+		{"acme", "/users/7", http.StatusOK, "acme-user:7@acme"},
+		{"acme", "/users/8", http.StatusOK, "acme-user:8@acme"},
+		{"globex", "/users/7", http.StatusOK, "user:7@globex"},
+		{"", "/fail", http.StatusNotFound, "404 page not found\n"},
+		{"acme", "/fail", http.StatusTeapot, "router error\n"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("X-Tenant", tt.tenant)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%s %s: expected %d %q, got %d %q", tt.tenant, tt.path, tt.status, tt.body, w.Code, w.Body.String())
+		}
+		if tt.status == http.StatusOK && w.Header().Get("X-Global") != "yes" {
+			t.Errorf("%s %s: expected the global middleware to run", tt.tenant, tt.path)
+		}
+	}
+}
+
+// TestTenantOverlayBuildError tests that Build reports errors of tenant overlays
+func TestTenantOverlayBuildError(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.AddBuildCheck(func(ri RouteInfo) error {
+		if strings.HasPrefix(ri.Pattern, "/internal") {
+			return errors.New("internal routes are not allowed")
+		}
+		return nil
+	})
+	r.ForTenant("acme").Get("/internal/debug", handler)
+	err := r.Build()
+	if err == nil || !strings.Contains(err.Error(), "tenant acme: GET /internal/debug") {
+		t.Errorf("Expected the overlay build check to fail, got %v", err)
+	}
+}
+
+// TestTenantOverlayDuplicate tests that a duplicate route of a tenant overlay is reported as a duplicate
+func TestTenantOverlayDuplicate(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	acme := r.ForTenant("acme")
+	acme.Get("/dashboard", handler)
+	acme.Get("/dashboard", handler)
+	err := r.Build()
+	if !IsDuplicate(err) || !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected a duplicate route error, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "tenant acme:") {
+		t.Errorf("Expected the error to name the tenant, got %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutPhase identifies the part of request processing that was running when a timeout occurred.
+type TimeoutPhase int
+
+const (
+	// TimeoutPhaseUnknown is used when the phase cannot be determined
+	// (for example, for handlers registered directly with Router.Handle).
+	TimeoutPhaseUnknown TimeoutPhase = iota
+	// TimeoutPhaseMiddleware means the timeout occurred before the route handler was called.
+	TimeoutPhaseMiddleware
+	// TimeoutPhaseHandler means the timeout occurred while the route handler was running.
+	TimeoutPhaseHandler
+)
+
+// String returns the name of the timeout phase.
+func (p TimeoutPhase) String() string {
+	switch p {
+	case TimeoutPhaseMiddleware:
+		return "middleware"
+	case TimeoutPhaseHandler:
+		return "handler"
+	default:
+		return "unknown"
+	}
+}
+
+// TimeoutInfo describes a timeout that occurred while processing a request.
+// It is available to the timeout handler through GetTimeoutInfo.
+type TimeoutInfo struct {
+	Phase   TimeoutPhase  // Phase that exceeded the budget
+	Timeout time.Duration // Budget that was exceeded
+	Method  string        // HTTP method of the matched route
+	Pattern string        // Pattern of the matched route (empty for routes registered with Router.Handle)
+}
+
+// errMiddlewareTimeout is the cause of the context cancellation when the middleware phase times out.
+var errMiddlewareTimeout = errors.New("router: middleware timeout exceeded")
+
+// timeoutInfoKey is the context key for TimeoutInfo.
+type timeoutInfoKey struct{}
+
+// GetTimeoutInfo returns information about the timeout that occurred while processing the request.
+// It is intended to be called from the timeout handler (see SetTimeoutHandler) so that logs can
+// tell whether the middleware or the handler exceeded the budget.
+func GetTimeoutInfo(ctx context.Context) (TimeoutInfo, bool) {
+	info, ok := ctx.Value(timeoutInfoKey{}).(TimeoutInfo)
+	return info, ok
+}
+
+// WithMiddlewareTimeout sets a timeout for the middleware phase of the route, that is,
+// the time from the start of the request until the route handler is called.
+// It is independent of the handler timeout (WithTimeout), so slow middleware such as remote
+// authentication checks can be bounded separately. If the timeout is 0 or less, it is disabled.
+func (r *Route) WithMiddlewareTimeout(timeout time.Duration) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	// set middleware timeout
+	r.middlewareTimeout = timeout
+
+	return r
+}
+
+// GetMiddlewareTimeout returns the route's middleware timeout (0 if disabled).
+func (r *Route) GetMiddlewareTimeout() time.Duration {
+	return r.middlewareTimeout
+}
+
+// Request phases tracked by timeoutTracker.
+const (
+	phaseMiddleware int32 = iota // Middleware is running
+	phaseHandler                 // The route handler has been called
+	phaseExpired                 // The middleware phase timed out
+	phaseFinished                // Request processing has finished
+)
+
+// timeoutTracker tracks the phase of a request so that timeouts can be attributed.
+type timeoutTracker struct {
+	phase        atomic.Int32
+	tracked      bool      // Whether the route handler reports when it is called
+	handlerStart time.Time // When the route handler was called (zero until then)
+}
+
+// timeoutTrackerKey is the context key for timeoutTracker.
+type timeoutTrackerKey struct{}
+
+// enterHandler switches the request to the handler phase.
+// It returns false if the middleware phase has already timed out.
+func (t *timeoutTracker) enterHandler() bool {
+	if !t.phase.CompareAndSwap(phaseMiddleware, phaseHandler) {
+		return false
+	}
+	t.handlerStart = time.Now()
+	return true
+}
+
+// expireMiddleware marks the middleware phase as timed out.
+// It returns false if the handler has already been called or the request has finished.
+func (t *timeoutTracker) expireMiddleware() bool {
+	return t.phase.CompareAndSwap(phaseMiddleware, phaseExpired)
+}
+
+// finish marks the request as finished so that a pending middleware timeout is ignored.
+func (t *timeoutTracker) finish() {
+	t.phase.CompareAndSwap(phaseMiddleware, phaseFinished)
+}
+
+// current returns the phase that is running.
+func (t *timeoutTracker) current() TimeoutPhase {
+	if !t.tracked {
+		return TimeoutPhaseUnknown
+	}
+	if t.phase.Load() == phaseHandler {
+		return TimeoutPhaseHandler
+	}
+	return TimeoutPhaseMiddleware
+}
+
+// trackHandlerPhase wraps the route handler so that the timeout tracker of the request
+// knows when the middleware phase ends. It must be the innermost wrapper of the handler.
+func trackHandlerPhase(next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if tracker, ok := r.Context().Value(timeoutTrackerKey{}).(*timeoutTracker); ok && !tracker.enterHandler() {
+			// The middleware phase has already timed out
+			return context.Cause(r.Context())
+		}
+		return next(w, r)
+	}
+}
+
+// newTimeoutInfo creates a TimeoutInfo for the route.
+func newTimeoutInfo(route *Route, phase TimeoutPhase, timeout time.Duration) TimeoutInfo {
+	info := TimeoutInfo{Phase: phase, Timeout: timeout}
+	if route != nil {
+		info.Method = route.method
+		info.Pattern = route.fullPath()
+	}
+	return info
+}
+
+// timeoutContextKey is the context key under which a timeoutContext returns itself.
+type timeoutContextKey struct{}
+
+// timeoutContext is the context of a request bounded by the route timeout. It behaves like the
+// context of context.WithTimeout, except that its deadline can be suspended while the request
+// long-polls (see Poll), so that waiting for an event does not consume the budget of the route.
+type timeoutContext struct {
+	context.Context // Parent context
+
+	mu         sync.Mutex
+	deadline   time.Time            // Deadline (zero while suspended)
+	remaining  time.Duration        // Budget left when the deadline was suspended
+	suspended  int                  // Number of polls suspending the deadline
+	timer      *time.Timer          // Timer that expires the context at the deadline
+	done       chan struct{}        // Closed when the context is canceled or expires
+	err        error                // Set when done is closed
+	stopParent func() bool          // Stops propagating the cancellation of the parent
+	afterFuncs map[*func()]struct{} // Functions to call when done is closed (see AfterFunc)
+}
+
+// newTimeoutContext returns a context that expires with context.DeadlineExceeded after timeout
+// unless it is suspended. The returned function cancels it and must be called to release it.
+func newTimeoutContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	c := &timeoutContext{
+		Context:  parent,
+		deadline: time.Now().Add(timeout),
+		done:     make(chan struct{}),
+	}
+	// Both callbacks may run at once (for example if the parent is already done), so they are
+	// set up under the lock, which cancel waits for
+	c.mu.Lock()
+	c.timer = time.AfterFunc(timeout, func() { c.cancel(context.DeadlineExceeded) })
+	c.stopParent = context.AfterFunc(parent, func() { c.cancel(parent.Err()) })
+	c.mu.Unlock()
+
+	// A standard context on top reports the cause of the expiry through context.Cause
+	ctx, cancel := context.WithCancel(c)
+	return ctx, func() {
+		cancel()
+		c.cancel(context.Canceled)
+	}
+}
+
+// Deadline returns the deadline, or no deadline while the deadline is suspended.
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.suspended > 0 {
+		return c.Context.Deadline()
+	}
+	return c.deadline, true
+}
+
+// Done returns a channel that is closed when the context is canceled or expires.
+func (c *timeoutContext) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns context.DeadlineExceeded once the context expired, or the error of its cancellation.
+func (c *timeoutContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Value returns the context itself for timeoutContextKey and the values of the parent otherwise.
+func (c *timeoutContext) Value(key any) any {
+	if key == (timeoutContextKey{}) {
+		return c
+	}
+	return c.Context.Value(key)
+}
+
+// AfterFunc arranges to call f once the context is done, and returns a function that stops the
+// call. It lets the contexts derived from the context propagate its cancellation synchronously
+// instead of watching it from a goroutine (see context.AfterFunc).
+func (c *timeoutContext) AfterFunc(f func()) func() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		go f()
+		return func() bool { return false }
+	}
+	if c.afterFuncs == nil {
+		c.afterFuncs = make(map[*func()]struct{})
+	}
+	key := &f
+	c.afterFuncs[key] = struct{}{}
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, ok := c.afterFuncs[key]
+		delete(c.afterFuncs, key)
+		return ok
+	}
+}
+
+// cancel closes the context with err. Only the first call has an effect.
+func (c *timeoutContext) cancel(err error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.err = err
+	close(c.done)
+	c.timer.Stop()
+	afterFuncs := c.afterFuncs
+	c.afterFuncs = nil
+	stopParent := c.stopParent
+	c.mu.Unlock()
+
+	if stopParent != nil {
+		stopParent()
+	}
+	for f := range afterFuncs {
+		(*f)()
+	}
+}
+
+// suspend stops the deadline, keeping the remaining budget for resume. Suspensions nest.
+func (c *timeoutContext) suspend() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.suspended++
+	if c.suspended == 1 && c.err == nil {
+		c.timer.Stop()
+		c.remaining = time.Until(c.deadline)
+		c.deadline = time.Time{}
+	}
+}
+
+// resume restarts the deadline with the budget that remained when it was suspended.
+func (c *timeoutContext) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.suspended--
+	if c.suspended == 0 && c.err == nil {
+		c.deadline = time.Now().Add(c.remaining)
+		c.timer.Reset(c.remaining)
+	}
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestMiddlewareTimeout tests that the middleware phase has its own timeout
+// and that the timeout handler can tell which phase exceeded the budget
+func TestMiddlewareTimeout(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	infos := make(chan TimeoutInfo, 1)
+	r.SetTimeoutHandler(func(w http.ResponseWriter, req *http.Request) {
+		info, _ := GetTimeoutInfo(req.Context())
+		infos <- info
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	// Slow middleware such as a remote authentication check
+	slowAuth := func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			if req.URL.Query().Get("slow") == "auth" {
+				<-req.Context().Done()
+				return req.Context().Err()
+			}
+			return next(w, req)
+		}
+	}
+	handler := func(w http.ResponseWriter, req *http.Request) error {
+		if req.URL.Query().Get("slow") == "handler" {
+			<-req.Context().Done()
+			return req.Context().Err()
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	r.Get("/api/{id}", handler, slowAuth).
+		WithMiddlewareTimeout(20 * time.Millisecond).
+		WithTimeout(200 * time.Millisecond)
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		url     string
+		phase   TimeoutPhase
+		timeout time.Duration
+	}{
+		{"/api/1?slow=auth", TimeoutPhaseMiddleware, 20 * time.Millisecond},
+		{"/api/1?slow=handler", TimeoutPhaseHandler, 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+		select {
+		case info := <-infos:
+			if info.Phase != tt.phase || info.Timeout != tt.timeout || info.Pattern != "/api/{id}" {
+				t.Errorf("%s: unexpected timeout info %+v", tt.url, info)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: timeout handler was not called", tt.url)
+		}
+	}
+
+	// A fast request is not affected by the middleware timeout
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/1", nil))
+	time.Sleep(40 * time.Millisecond)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	select {
+	case info := <-infos:
+		t.Errorf("Unexpected timeout %+v", info)
+	default:
+	}
+}
+
+// TestTimeoutContextDoneParent tests creating a timeout context whose parent is already done
+func TestTimeoutContextDoneParent(t *testing.T) {
+	for range 100 {
+		parent, cancelParent := context.WithCancel(context.Background())
+		cancelParent()
+		ctx, cancel := newTimeoutContext(parent, 0)
+		<-ctx.Done()
+		if err := ctx.Err(); err != context.Canceled && err != context.DeadlineExceeded {
+			t.Fatalf("Expected the context to be done, got %v", err)
+		}
+		cancel()
+	}
+}
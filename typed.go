@@ -0,0 +1,267 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// StatusError is an error that carries the HTTP status code to respond with.
+// Typed handlers (see TypedHandler) return it to control the error response.
+type StatusError struct {
+	Status int   // HTTP status code
+	Err    error // Underlying error
+}
+
+// NewStatusError creates a StatusError with the given status code and message.
+func NewStatusError(status int, message string) *StatusError {
+	return &StatusError{Status: status, Err: errors.New(message)}
+}
+
+func (e *StatusError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Status)
+	}
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCoder is implemented by response types of typed handlers that need
+// a status code other than 200 OK (for example 201 Created).
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// TypedHandler adapts a function with typed request and response values to a HandlerFunc.
+//
+// The request value is decoded as follows:
+//   - the JSON request body (if any) is decoded into it,
+//   - fields tagged `path:"name"` are set from the URL parameters,
+//   - fields tagged `query:"name"` are set from the query string,
+//   - fields tagged `header:"Name"` are set from the request headers.
+//
+// Tagged fields may be strings, booleans, integers, floating point numbers,
+// or slices of these (query and header only). Req must be a struct type.
+//
+// The response value is encoded as JSON with the status 200 OK, or the status returned by
+// its StatusCode method if it implements StatusCoder. Errors are mapped to status codes:
+// decoding errors become 400 Bad Request (413 Request Entity Too Large for a body over its limit,
+// see http.MaxBytesReader), a StatusError uses its own status,
+// context.DeadlineExceeded becomes 504 Gateway Timeout, and anything else 500 Internal Server Error.
+// Error responses are written as JSON objects of the form {"error": "message"};
+// the message of 5xx errors is replaced by the status text so that internal details are not exposed.
+//
+// TypedHandler panics if Req is not a struct type or has a tagged field of an unsupported type.
+func TypedHandler[Req, Res any](fn func(context.Context, Req) (Res, error)) HandlerFunc {
+	binder := newRequestBinder(reflect.TypeFor[Req]())
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req Req
+		if err := binder.bind(r, reflect.ValueOf(&req).Elem()); err != nil {
+			// A body over the limit of the route (see WithMaxBodySize) is not malformed input
+			status := http.StatusBadRequest
+			if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeTypedError(w, &StatusError{Status: status, Err: err})
+			return nil
+		}
+
+		res, err := fn(r.Context(), req)
+		if err != nil {
+			writeTypedError(w, err)
+			return nil
+		}
+
+		status := http.StatusOK
+		if coder, ok := any(res).(StatusCoder); ok {
+			status = coder.StatusCode()
+		}
+		return writeJSON(w, status, res)
+	}
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = w.Write(append(body, '\n'))
+	return err
+}
+
+// writeTypedError writes the error response of a typed handler.
+func writeTypedError(w http.ResponseWriter, err error) {
+	status := statusFromError(err)
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		message = http.StatusText(status)
+	}
+	_ = writeJSON(w, status, map[string]string{"error": message})
+}
+
+// statusFromError returns the HTTP status code for an error:
+// a StatusError uses its own status, context.DeadlineExceeded becomes 504 Gateway Timeout,
+// and anything else 500 Internal Server Error.
+func statusFromError(err error) int {
+	var statusErr *StatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Status
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// requestBinder decodes requests into values of a struct type.
+// The field layout is analyzed once when the typed handler is created.
+type requestBinder struct {
+	fields []boundField
+}
+
+// boundField is a struct field that is set from a part of the request.
+type boundField struct {
+	index  []int  // Field index for reflect.Value.FieldByIndex
+	source string // "path", "query", or "header"
+	name   string // Parameter, query, or header name
+}
+
+// bindSources are the struct tags recognized by requestBinder, in the order they are applied.
+var bindSources = []string{"path", "query", "header"}
+
+// newRequestBinder analyzes the struct type t.
+func newRequestBinder(t reflect.Type) *requestBinder {
+	if t.Kind() != reflect.Struct {
+		panic("router: TypedHandler request type must be a struct, got " + t.String())
+	}
+
+	b := &requestBinder{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+		for _, source := range bindSources {
+			name, ok := field.Tag.Lookup(source)
+			if !ok {
+				continue
+			}
+			if !isBindableType(field.Type, source != "path") {
+				panic(fmt.Sprintf("router: unsupported type %s for field %s tagged %q", field.Type, field.Name, source))
+			}
+			b.fields = append(b.fields, boundField{index: field.Index, source: source, name: name})
+		}
+	}
+	return b
+}
+
+// isBindableType reports whether a field of type t can be set from request strings.
+func isBindableType(t reflect.Type, allowSlice bool) bool {
+	if allowSlice && t.Kind() == reflect.Slice {
+		return isBindableType(t.Elem(), false)
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// bind decodes the request into v.
+func (b *requestBinder) bind(r *http.Request, v reflect.Value) error {
+	// Decode the JSON body first, so that path, query, and header values take precedence
+	if r.Body != nil && r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(v.Addr().Interface()); err != nil && err != io.EOF {
+			return fmt.Errorf("invalid request body: %w", err)
+		}
+	}
+
+	params := GetParams(r.Context())
+	query := r.URL.Query()
+	for _, field := range b.fields {
+		var values []string
+		switch field.source {
+		case "path":
+			if value, ok := params.Get(field.name); ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[field.name]
+		case "header":
+			values = r.Header.Values(field.name)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setField(v.FieldByIndex(field.index), values); err != nil {
+			return fmt.Errorf("invalid %s parameter %q: %w", field.source, field.name, err)
+		}
+	}
+	return nil
+}
+
+// setField sets a field from request values.
+// Slices receive all values; other fields receive the first value.
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setScalar(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setScalar(field, values[0])
+}
+
+// setScalar parses value according to the kind of the field and sets it.
+func setScalar(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	}
+	return nil
+}
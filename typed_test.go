@@ -0,0 +1,113 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type updateUserRequest struct {
+	ID      int      `path:"id"`
+	Notify  bool     `query:"notify"`
+	Tags    []string `query:"tag"`
+	TraceID string   `header:"X-Trace-Id"`
+	Name    string   `json:"name"`
+}
+
+type updateUserResponse struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Notify  bool     `json:"notify"`
+	Tags    []string `json:"tags"`
+	TraceID string   `json:"trace_id"`
+}
+
+// TestTypedHandler tests decoding, encoding, and error mapping of typed handlers
+func TestTypedHandler(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	r.Put("/users/{id}", TypedHandler(func(ctx context.Context, req updateUserRequest) (updateUserResponse, error) {
+		switch req.ID {
+		case 404:
+			return updateUserResponse{}, NewStatusError(http.StatusNotFound, "user not found")
+		case 500:
+			return updateUserResponse{}, errors.New("database password is wrong")
+		}
+		return updateUserResponse{ID: req.ID, Name: req.Name, Notify: req.Notify, Tags: req.Tags, TraceID: req.TraceID}, nil
+	}))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set("X-Trace-Id", "abc")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/users/42?notify=true&tag=a&tag=b", `{"name":"gopher"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var res updateUserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if res.ID != 42 || res.Name != "gopher" || !res.Notify || len(res.Tags) != 2 || res.TraceID != "abc" {
+		t.Errorf("Unexpected response %+v", res)
+	}
+
+	tests := []struct {
+		path, body string
+		status     int
+		message    string
+	}{
+		{"/users/abc", "", http.StatusBadRequest, ""},
+		{"/users/1", "{", http.StatusBadRequest, ""},
+		{"/users/404", "", http.StatusNotFound, "user not found"},
+		{"/users/500", "", http.StatusInternalServerError, "Internal Server Error"},
+	}
+	for _, tt := range tests {
+		w := serve(tt.path, tt.body)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, w.Code)
+		}
+		var errRes map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &errRes); err != nil {
+			t.Errorf("%s: failed to decode error response: %v", tt.path, err)
+		}
+		if tt.message != "" && errRes["error"] != tt.message {
+			t.Errorf("%s: expected error %q, got %q", tt.path, tt.message, errRes["error"])
+		}
+	}
+}
+
+// TestTypedHandlerBodyLimit tests that a body over its limit is answered with 413 rather than 400
+func TestTypedHandlerBodyLimit(t *testing.T) {
+	h := TypedHandler(func(ctx context.Context, req updateUserRequest) (updateUserResponse, error) {
+		return updateUserResponse{Name: req.Name}, nil
+	})
+
+	for body, want := range map[string]int{
+		`{"name":"a very long name that exceeds the limit"}`: http.StatusRequestEntityTooLarge,
+		`{"name":`: http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/users/1", nil)
+		req.Body = http.MaxBytesReader(w, io.NopCloser(strings.NewReader(body)), 16)
+		if err := h(w, req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if w.Code != want {
+			t.Errorf("%s: expected status %d, got %d", body, want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// WithName names the route, so that typed URL builders can be generated for it (see GenerateURLs).
+// Names are free-form, such as "user.show"; they must be unique within the router.
+func (r *Route) WithName(name string) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied {
+		return r
+	}
+
+	r.name = name
+	return r
+}
+
+// Name returns the name of the route ("" if unnamed).
+func (r *Route) Name() string {
+	return r.name
+}
+
+// GenerateURLs returns Go source of package pkg that declares a path builder for every named route,
+// so that link construction is checked at compile time across large codebases. The identifier is the
+// name in CamelCase ("user.show" becomes UserShow): routes without parameters get a string constant,
+// and routes with parameters a function taking one string argument per parameter, in pattern order,
+// that escapes the values:
+//
+//	const UserIndex = "/users"
+//	func UserShow(id string) string { return "/users/" + url.PathEscape(id) }
+//
+// Parameter values are not checked against regular expressions of the pattern.
+// An error is returned if two routes have the same name or identifier.
+//
+// 例: src, err := r.GenerateURLs("urls")
+func (r *Router) GenerateURLs(pkg string) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, &RouterError{Code: ErrInternalError, Message: "invalid package name: " + pkg}
+	}
+
+	r.mu.RLock()
+	routes := slices.Clone(r.routes)
+	for _, g := range r.allGroups() {
+		routes = append(routes, g.routes...)
+	}
+	r.mu.RUnlock()
+
+	// Collect the named routes by identifier
+	named := make(map[string]*Route)
+	for _, route := range routes {
+		if route.name == "" {
+			continue
+		}
+		ident := exportedIdentifier(route.name)
+		if other, ok := named[ident]; ok {
+			return nil, &RouterError{
+				Code:    ErrInvalidPattern,
+				Message: "route names " + other.name + " and " + route.name + " both generate " + ident,
+			}
+		}
+		named[ident] = route
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by Router.GenerateURLs. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	idents := slices.Sorted(maps.Keys(named))
+	if slices.ContainsFunc(idents, func(ident string) bool { return !isAllStatic(parseSegments(named[ident].fullPath())) }) {
+		b.WriteString("import \"net/url\"\n\n")
+	}
+	for _, ident := range idents {
+		writeURLBuilder(&b, ident, named[ident])
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// writeURLBuilder writes the constant or function that builds the path of the route.
+func writeURLBuilder(b *bytes.Buffer, ident string, route *Route) {
+	pattern := route.fullPath()
+	segments := route.Segments()
+	if isAllStatic(parseSegments(pattern)) {
+		fmt.Fprintf(b, "// %s is the path of the route %q (%s %s).\n", ident, route.name, route.method, pattern)
+		fmt.Fprintf(b, "const %s = %q\n\n", ident, pattern)
+		return
+	}
+	fmt.Fprintf(b, "// %s returns the path of the route %q (%s %s).\n", ident, route.name, route.method, pattern)
+
+	var params, parts []string
+	literal := ""
+	used := make(map[string]bool)
+	for _, seg := range segments {
+		if seg.Kind == SegmentStatic {
+			literal += "/" + seg.Value
+			continue
+		}
+		param := parameterIdentifier(seg.Name, used)
+		params = append(params, param)
+		if seg.Kind == SegmentCatchAll {
+			// The rest of the path keeps its slashes
+			parts = append(parts, fmt.Sprintf("%q", literal+"/"), "(&url.URL{Path: "+param+"}).EscapedPath()")
+		} else {
+			parts = append(parts, fmt.Sprintf("%q", literal+"/"), "url.PathEscape("+param+")")
+		}
+		literal = ""
+	}
+	if literal != "" {
+		parts = append(parts, fmt.Sprintf("%q", literal))
+	}
+	fmt.Fprintf(b, "func %s(%s string) string {\n\treturn %s\n}\n\n", ident, strings.Join(params, ", "), strings.Join(parts, " + "))
+}
+
+// exportedIdentifier converts a route name into an exported Go identifier ("user.show" → "UserShow").
+func exportedIdentifier(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, isNotIdentifierRune) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	ident := b.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "Route" + ident
+	}
+	return ident
+}
+
+// parameterIdentifier converts a parameter name into a unique unexported Go identifier
+// that does not clash with keywords or the url package.
+func parameterIdentifier(name string, used map[string]bool) string {
+	var b strings.Builder
+	for i, word := range strings.FieldsFunc(name, isNotIdentifierRune) {
+		runes := []rune(word)
+		if i == 0 {
+			runes[0] = unicode.ToLower(runes[0])
+		} else {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		b.WriteString(string(runes))
+	}
+	ident := b.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) || token.IsKeyword(ident) || ident == "url" {
+		ident = "p" + exportedIdentifier(ident)
+	}
+	for base, i := ident, 2; used[ident]; i++ {
+		ident = fmt.Sprintf("%s%d", base, i)
+	}
+	used[ident] = true
+	return ident
+}
+
+// isNotIdentifierRune reports whether the rune separates the words of a name.
+func isNotIdentifierRune(c rune) bool {
+	return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestGenerateURLs tests generating typed path builders for named routes
+func TestGenerateURLs(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/users", handler).WithName("user.index")
+	r.Get("/users/{id:[0-9]+}", handler).WithName("user.show")
+	r.Group("/api").Get("/files/{type}/{file-name}/raw", handler).WithName("api.file-raw")
+	r.Get("/assets/{*filepath}", handler).WithName("asset")
+	r.Get("/health", handler)
+
+	src, err := r.GenerateURLs("urls")
+	if err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	for _, want := range []string{
+		"package urls",
+		`import "net/url"`,
+		`const UserIndex = "/users"`,
+		`func UserShow(id string) string {
+	return "/users/" + url.PathEscape(id)
+}`,
+		`func ApiFileRaw(pType, fileName string) string {
+	return "/api/files/" + url.PathEscape(pType) + "/" + url.PathEscape(fileName) + "/raw"
+}`,
+		`func Asset(filepath string) string {
+	return "/assets/" + (&url.URL{Path: filepath}).EscapedPath()
+}`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Expected generated source to contain %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(string(src), "health") {
+		t.Error("Expected unnamed routes to be skipped")
+	}
+
+	// Names that generate the same identifier are rejected
+	r.Get("/users/new", handler).WithName("user-index")
+	if _, err := r.GenerateURLs("urls"); err == nil {
+		t.Error("Expected an error for clashing names")
+	}
+	if _, err := r.GenerateURLs("not a package"); err == nil {
+		t.Error("Expected an error for an invalid package name")
+	}
+}
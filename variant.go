@@ -0,0 +1,147 @@
+package router
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// variantKey is the context key for the media type of the selected variant.
+type variantKey struct{}
+
+// variant is a handler for one representation of a route.
+type variant struct {
+	mediaType string
+	handler   HandlerFunc
+}
+
+// WithVariant adds a handler for one representation of the resource, so that a single route
+// can emit, for example, JSON and CSV. After the route matches, the Accept header of the request
+// is negotiated against the variants (honoring quality values), and the selected handler runs
+// behind the middleware of the route and its groups. Requests without an Accept header receive
+// the first variant; if no variant is acceptable, the response is 406 Not Acceptable.
+//
+// The media type of the variant is the default Content-Type of its responses, and it is
+// available to middleware and the handler through Variant. Once a route has variants,
+// the handler passed when the route was defined is no longer used.
+//
+// 例: r.Get("/reports/{id}", asJSON).WithVariant("application/json", asJSON).WithVariant("text/csv", asCSV)
+func (r *Route) WithVariant(mediaType string, h HandlerFunc) *Route {
+	// If the route has already been applied, return it as is
+	if r.applied || h == nil {
+		return r
+	}
+
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	r.variants = append(r.variants, variant{mediaType: mediaType, handler: h})
+	if !slices.Contains(r.produces, mediaType) {
+		r.produces = append(r.produces, mediaType)
+	}
+	return r
+}
+
+// Variant returns the media type of the variant selected for the request,
+// or an empty string if the route has no variants.
+func Variant(ctx context.Context) string {
+	mediaType, _ := ctx.Value(variantKey{}).(string)
+	return mediaType
+}
+
+// negotiateVariant returns a handler that selects the variant for the Accept header of the request
+// and calls its chain. chains holds the handler of each variant wrapped in the route middleware.
+func negotiateVariant(variants []variant, chains []HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		addVary(w.Header(), "Accept")
+
+		i := selectVariant(variants, r.Header.Values("Accept"))
+		if i < 0 {
+			http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+			return nil
+		}
+		w.Header().Set("Content-Type", variants[i].mediaType)
+		ctx := context.WithValue(r.Context(), variantKey{}, variants[i].mediaType)
+		return chains[i](w, r.WithContext(ctx))
+	}
+}
+
+// acceptRange is a media range of an Accept header.
+type acceptRange struct {
+	mediaType string  // "type/subtype", "type/*", or "*/*"
+	quality   float64 // Quality value (q parameter, 1 if absent)
+}
+
+// selectVariant returns the index of the variant with the highest quality for the Accept header
+// values, preferring earlier variants on ties, or -1 if no variant is acceptable.
+// The quality of a variant is that of the most specific range that matches it.
+func selectVariant(variants []variant, accept []string) int {
+	if len(accept) == 0 {
+		return 0
+	}
+	ranges := parseAccept(accept)
+
+	best, bestQuality := -1, 0.0
+	for i, v := range variants {
+		quality, specificity := 0.0, -1
+		for _, ar := range ranges {
+			if s := rangeSpecificity(ar.mediaType, v.mediaType); s > specificity {
+				quality, specificity = ar.quality, s
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = i, quality
+		}
+	}
+	return best
+}
+
+// parseAccept parses the media ranges of Accept header values.
+func parseAccept(values []string) []acceptRange {
+	var ranges []acceptRange
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil {
+					quality = v
+				}
+			}
+			ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+		}
+	}
+	return ranges
+}
+
+// rangeSpecificity returns how specifically the media range matches the media type
+// (2 for an exact match, 1 for "type/*", 0 for "*/*"), or -1 if it does not match.
+func rangeSpecificity(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	if t, _, _ := strings.Cut(mediaType, "/"); ok && t == prefix {
+		return 1
+	}
+	return -1
+}
+
+// addVary adds a field name to the Vary header unless it is already listed.
+func addVary(h http.Header, field string) {
+	for _, value := range h.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), field) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", field)
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestVariant tests selecting the representation of a route by content negotiation
+func TestVariant(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	var seen string
+	mw := func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			seen = Variant(req.Context())
+			return next(w, req)
+		}
+	}
+	variantHandler := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			w.Write([]byte(body))
+			return nil
+		}
+	}
+	r.Get("/reports/{id}", variantHandler("unused")).
+		WithMiddleware(mw).
+		WithVariant("application/json", variantHandler("json")).
+		WithVariant("text/csv", variantHandler("csv"))
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		accept      string
+		status      int
+		body        string
+		contentType string
+	}{
+		{"", http.StatusOK, "json", "application/json"},
+		{"text/csv", http.StatusOK, "csv", "text/csv"},
+		{"text/*", http.StatusOK, "csv", "text/csv"},
+		{"*/*", http.StatusOK, "json", "application/json"},
+		{"application/json;q=0.5, text/csv;q=0.9", http.StatusOK, "csv", "text/csv"},
+		{"text/csv;q=0, */*", http.StatusOK, "json", "application/json"},
+		{"*/*;q=0.1, application/json;q=0", http.StatusOK, "csv", "text/csv"},
+		{"application/xml", http.StatusNotAcceptable, "", ""},
+	}
+	for _, tt := range tests {
+		seen = ""
+		req := httptest.NewRequest(http.MethodGet, "/reports/1", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%q: expected status %d, got %d", tt.accept, tt.status, w.Code)
+			continue
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("%q: expected Vary: Accept, got %q", tt.accept, w.Header().Values("Vary"))
+		}
+		if tt.status != http.StatusOK {
+			if seen != "" {
+				t.Errorf("%q: expected the middleware not to run", tt.accept)
+			}
+			continue
+		}
+		if w.Body.String() != tt.body || w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%q: expected %s (%s), got %s (%s)", tt.accept, tt.body, tt.contentType, w.Body.String(), w.Header().Get("Content-Type"))
+		}
+		if seen != tt.contentType {
+			t.Errorf("%q: expected the middleware to see variant %q, got %q", tt.accept, tt.contentType, seen)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package router
+
+import (
+	"slices"
+	"strings"
+)
+
+// RouteSpec describes a route by its HTTP method and pattern.
+// It is used by tools that need route definitions without handlers.
+type RouteSpec struct {
+	Method   string // HTTP method
+	Pattern  string // Route pattern (e.g. /users/{id})
+	Priority int    // Priority among overlapping dynamic routes (see Route.WithPriority)
+}
+
+// VerifyAgainst replays the sample paths through the router's matchers and through a slow
+// reference implementation built from the given route specs, and reports any mismatch.
+// Each sample path is checked for every method that appears in the reference.
+// It returns nil if the router agrees with the reference for all samples.
+// The router must have been built before calling this method.
+func (r *Router) VerifyAgainst(reference []RouteSpec, samplePaths []string) error {
+	// Build a reference matcher for each method
+	matchers := make(map[string]*referenceMatcher)
+	methods := make([]string, 0, len(reference))
+	for _, spec := range reference {
+		m, ok := matchers[spec.Method]
+		if !ok {
+			m = &referenceMatcher{}
+			matchers[spec.Method] = m
+			methods = append(methods, spec.Method)
+		}
+		if err := m.add(spec.Pattern, spec.Priority); err != nil {
+			return err
+		}
+	}
+	slices.Sort(methods)
+
+	var mismatches []string
+	for _, path := range samplePaths {
+		for _, method := range methods {
+			wantPattern, wantParams, wantOK := matchers[method].match(normalizePath(path))
+
+			params := r.paramsPool.Get()
+			gotPattern, gotOK := r.matchPattern(method, path, params)
+
+			switch {
+			case gotOK != wantOK || gotPattern != wantPattern:
+				mismatches = append(mismatches, method+" "+path+": matched "+describeMatch(gotPattern, gotOK)+
+					", reference "+describeMatch(wantPattern, wantOK))
+			case gotOK && !slices.Equal(params.data, wantParams.data):
+				mismatches = append(mismatches, method+" "+path+": parameters differ from reference for "+gotPattern)
+			}
+			r.paramsPool.Put(params)
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return &RouterError{
+			Code:    ErrRouteMismatch,
+			Message: strings.Join(mismatches, "; "),
+		}
+	}
+	return nil
+}
+
+// describeMatch returns a human readable description of a match result.
+func describeMatch(pattern string, ok bool) string {
+	if !ok {
+		return "nothing"
+	}
+	return pattern
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestVerifyAgainst tests that the router agrees with the reference implementation
+func TestVerifyAgainst(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	specs := []RouteSpec{
+		{Method: http.MethodGet, Pattern: "/users"},
+		{Method: http.MethodGet, Pattern: "/users/{id}"},
+		{Method: http.MethodGet, Pattern: "/users/{id:[0-9]+}/posts"},
+		{Method: http.MethodGet, Pattern: "/files/{name}/raw"},
+	}
+	for _, spec := range specs {
+		if err := r.Handle(spec.Method, spec.Pattern, fuzzHandler); err != nil {
+			t.Fatalf("Failed to register %s: %v", spec.Pattern, err)
+		}
+	}
+
+	samples := []string{"/users", "/users/1", "/users/1/posts", "/users/abc/posts", "/files/a/raw", "/missing"}
+	if err := r.VerifyAgainst(specs, samples); err != nil {
+		t.Errorf("Expected no mismatch, got: %v", err)
+	}
+
+	// A reference containing a route the router does not know must be reported
+	extra := append(specs, RouteSpec{Method: http.MethodGet, Pattern: "/missing"})
+	err := r.VerifyAgainst(extra, samples)
+	if err == nil {
+		t.Fatal("Expected a mismatch error")
+	}
+	routerErr, ok := err.(*RouterError)
+	if !ok || routerErr.Code != ErrRouteMismatch {
+		t.Fatalf("Not the expected error: %v", err)
+	}
+	if !strings.Contains(routerErr.Message, "GET /missing") {
+		t.Errorf("Mismatch should mention the sample path, got: %s", routerErr.Message)
+	}
+}
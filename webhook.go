@@ -0,0 +1,185 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookStyle selects how webhook signatures are transmitted and computed.
+type WebhookStyle int
+
+const (
+	// WebhookGitHub verifies the X-Hub-Signature-256 header ("sha256=" + HMAC-SHA256 of the body).
+	// GitHub signatures carry no timestamp, so no replay window is checked.
+	WebhookGitHub WebhookStyle = iota
+	// WebhookStripe verifies the Stripe-Signature header ("t=<unix>,v1=<HMAC-SHA256 of t.body>").
+	WebhookStripe
+	// WebhookSlack verifies the X-Slack-Signature header ("v0=" + HMAC-SHA256 of "v0:<timestamp>:<body>")
+	// with the timestamp from X-Slack-Request-Timestamp.
+	WebhookSlack
+)
+
+// Default settings of webhook verification.
+const (
+	defaultWebhookTolerance    = 5 * time.Minute // Replay window
+	defaultWebhookMaxBodyBytes = 1 << 20         // 1 MiB
+)
+
+// Errors reported by webhook verification.
+var (
+	errWebhookSignature = errors.New("invalid webhook signature")
+	errWebhookTimestamp = errors.New("webhook timestamp outside the replay window")
+)
+
+// WebhookOptions configures webhook signature verification.
+type WebhookOptions struct {
+	Secret []byte       // Shared signing secret
+	Style  WebhookStyle // Signature style
+
+	// Tolerance is the maximum age (and clock skew) of a signed timestamp.
+	// Default: 5 minutes
+	Tolerance time.Duration
+
+	// MaxBodyBytes limits the size of the buffered payload. Larger requests are
+	// rejected with 413 Request Entity Too Large before the signature is checked.
+	// Default: 1 MiB
+	MaxBodyBytes int64
+
+	// Now returns the current time. Default: time.Now
+	Now func() time.Time
+}
+
+// webhookPayloadKey is the context key for the verified webhook payload.
+type webhookPayloadKey struct{}
+
+// WebhookPayload returns the raw payload verified by VerifyWebhook.
+func WebhookPayload(ctx context.Context) ([]byte, bool) {
+	payload, ok := ctx.Value(webhookPayloadKey{}).([]byte)
+	return payload, ok
+}
+
+// VerifyWebhook returns middleware that verifies HMAC signatures of webhook requests over the raw body.
+// The body is buffered (up to MaxBodyBytes) so that the signature can be computed over the exact
+// bytes that were sent; after successful verification the request body is replaced with the
+// buffered payload, which is also available through WebhookPayload.
+// Requests with a missing or invalid signature, or with a timestamp outside the replay window,
+// are rejected with 401 Unauthorized.
+func VerifyWebhook(opts WebhookOptions) MiddlewareFunc {
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = defaultWebhookTolerance
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = defaultWebhookMaxBodyBytes
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, opts.MaxBodyBytes))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+					return nil
+				}
+				return err
+			}
+
+			if err := opts.verify(r.Header, payload); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return nil
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(payload))
+			return next(w, r.WithContext(context.WithValue(r.Context(), webhookPayloadKey{}, payload)))
+		}
+	}
+}
+
+// verify checks the signature headers against the payload.
+func (o *WebhookOptions) verify(h http.Header, payload []byte) error {
+	switch o.Style {
+	case WebhookStripe:
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(h.Get("Stripe-Signature"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+		if err := o.checkTimestamp(timestamp); err != nil {
+			return err
+		}
+		expected := o.sign([]byte(timestamp), []byte("."), payload)
+		for _, signature := range signatures {
+			if hmacEqual(signature, expected) {
+				return nil
+			}
+		}
+		return errWebhookSignature
+
+	case WebhookSlack:
+		timestamp := h.Get("X-Slack-Request-Timestamp")
+		if err := o.checkTimestamp(timestamp); err != nil {
+			return err
+		}
+		signature, ok := strings.CutPrefix(h.Get("X-Slack-Signature"), "v0=")
+		if !ok || !hmacEqual(signature, o.sign([]byte("v0:"+timestamp+":"), payload)) {
+			return errWebhookSignature
+		}
+		return nil
+
+	default:
+		signature, ok := strings.CutPrefix(h.Get("X-Hub-Signature-256"), "sha256=")
+		if !ok || !hmacEqual(signature, o.sign(payload)) {
+			return errWebhookSignature
+		}
+		return nil
+	}
+}
+
+// checkTimestamp verifies that a Unix timestamp lies within the replay window.
+func (o *WebhookOptions) checkTimestamp(timestamp string) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errWebhookSignature
+	}
+	age := o.Now().Sub(time.Unix(seconds, 0))
+	if age > o.Tolerance || age < -o.Tolerance {
+		return errWebhookTimestamp
+	}
+	return nil
+}
+
+// sign computes the HMAC-SHA256 of the concatenated parts.
+func (o *WebhookOptions) sign(parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, o.Secret)
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return mac.Sum(nil)
+}
+
+// hmacEqual compares a hex-encoded signature with the expected MAC in constant time.
+func hmacEqual(signature string, expected []byte) bool {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(decoded, expected)
+}
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestVerifyWebhook tests webhook signature verification for each style
+func TestVerifyWebhook(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Unix(1700000000, 0)
+	sign := func(parts ...string) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(strings.Join(parts, "")))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	body := `{"event":"push"}`
+	ts := strconv.FormatInt(now.Unix(), 10)
+	old := strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name    string
+		style   WebhookStyle
+		headers map[string]string
+		body    string
+		status  int
+	}{
+		{"github valid", WebhookGitHub, map[string]string{"X-Hub-Signature-256": "sha256=" + sign(body)}, body, http.StatusOK},
+		{"github tampered", WebhookGitHub, map[string]string{"X-Hub-Signature-256": "sha256=" + sign(body)}, body + " ", http.StatusUnauthorized},
+		{"github missing", WebhookGitHub, nil, body, http.StatusUnauthorized},
+		{"stripe valid", WebhookStripe, map[string]string{"Stripe-Signature": "t=" + ts + ",v1=00,v1=" + sign(ts, ".", body)}, body, http.StatusOK},
+		{"stripe replayed", WebhookStripe, map[string]string{"Stripe-Signature": "t=" + old + ",v1=" + sign(old, ".", body)}, body, http.StatusUnauthorized},
+		{"slack valid", WebhookSlack, map[string]string{"X-Slack-Request-Timestamp": ts, "X-Slack-Signature": "v0=" + sign("v0:", ts, ":", body)}, body, http.StatusOK},
+		{"slack wrong secret", WebhookSlack, map[string]string{"X-Slack-Request-Timestamp": ts, "X-Slack-Signature": "v0=" + sign("v0:", ts, ":")}, body, http.StatusUnauthorized},
+		{"too large", WebhookGitHub, map[string]string{"X-Hub-Signature-256": "sha256=" + sign(strings.Repeat("x", 100))}, strings.Repeat("x", 100), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		r := NewRouter()
+		r.Post("/hooks", func(w http.ResponseWriter, req *http.Request) error {
+			payload, ok := WebhookPayload(req.Context())
+			read, _ := io.ReadAll(req.Body)
+			if !ok || string(payload) != tt.body || string(read) != tt.body {
+				t.Errorf("%s: payload %q, body %q", tt.name, payload, read)
+			}
+			return nil
+		}, VerifyWebhook(WebhookOptions{
+			Secret:       secret,
+			Style:        tt.style,
+			MaxBodyBytes: 64,
+			Now:          func() time.Time { return now },
+		}))
+		if err := r.Build(); err != nil {
+			t.Fatalf("Failed to build router: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(tt.body))
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		r.cache.stop()
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// wellKnownCacheControl is the Cache-Control of the endpoints registered by WellKnown.
+// Routes can override it with Route.WithCacheControl.
+const wellKnownCacheControl = "public, max-age=86400"
+
+// WellKnown registers the common endpoints of public-facing services, such as robots.txt and
+// the files under /.well-known/ (RFC 8615), from configuration values. The endpoints are GET
+// routes of the router that serve the generated content with the correct content type, an ETag
+// so that conditional requests are answered with 304 Not Modified, and a Cache-Control of one day.
+// Invalid configurations are reported by Build.
+type WellKnown struct {
+	router *Router
+}
+
+// WellKnown returns the registrar of the router's well-known endpoints.
+//
+// 例: r.WellKnown().Robots(router.Robots{Rules: []router.RobotsRule{{Disallow: []string{"/admin/"}}}})
+func (r *Router) WellKnown() *WellKnown {
+	return &WellKnown{router: r}
+}
+
+// SecurityTxt describes the security contact of a service (RFC 9116).
+// Contact and Expires are required.
+type SecurityTxt struct {
+	Contact            []string  // URIs to report vulnerabilities to, such as "mailto:security@example.com"
+	Expires            time.Time // Time after which the file is considered stale
+	Encryption         []string  // URIs of keys for encrypted reports
+	Acknowledgments    []string  // URIs of pages recognizing reporters
+	PreferredLanguages []string  // Language tags of the languages reports are preferred in
+	Canonical          []string  // URIs the file is published at
+	Policy             []string  // URIs of the vulnerability disclosure policy
+	Hiring             []string  // URIs of security-related job openings
+}
+
+// SecurityTxt registers /.well-known/security.txt. An expiry in the past is logged as a warning,
+// since clients ignore stale files.
+func (w *WellKnown) SecurityTxt(txt SecurityTxt) *Route {
+	var b strings.Builder
+	field := func(name string, values ...string) {
+		for _, v := range values {
+			b.WriteString(name + ": " + v + "\n")
+		}
+	}
+	field("Contact", txt.Contact...)
+	field("Expires", txt.Expires.UTC().Format(time.RFC3339))
+	field("Encryption", txt.Encryption...)
+	field("Acknowledgments", txt.Acknowledgments...)
+	if len(txt.PreferredLanguages) > 0 {
+		field("Preferred-Languages", strings.Join(txt.PreferredLanguages, ", "))
+	}
+	field("Canonical", txt.Canonical...)
+	field("Policy", txt.Policy...)
+	field("Hiring", txt.Hiring...)
+
+	route := w.File("security.txt", "text/plain; charset=utf-8", []byte(b.String()))
+	switch {
+	case len(txt.Contact) == 0:
+		route.err = &RouterError{Code: ErrInvalidConfig, Message: "security.txt requires a Contact"}
+	case txt.Expires.IsZero():
+		route.err = &RouterError{Code: ErrInvalidConfig, Message: "security.txt requires Expires"}
+	case txt.Expires.Before(time.Now()):
+		w.router.logf(slog.LevelWarn, "security.txt expired at %s", txt.Expires.Format(time.RFC3339))
+	}
+	return route
+}
+
+// RobotsRule is a group of robots.txt rules for a set of crawlers.
+type RobotsRule struct {
+	UserAgents []string // Crawlers the rule applies to (nil applies to all crawlers)
+	Allow      []string // Path prefixes the crawlers may access
+	Disallow   []string // Path prefixes the crawlers must not access
+}
+
+// Robots describes the robots.txt of a service (RFC 9309).
+type Robots struct {
+	Rules    []RobotsRule // Rules per crawler (nil allows all crawlers everything)
+	Sitemaps []string     // Absolute URLs of sitemaps
+}
+
+// Robots registers /robots.txt.
+func (w *WellKnown) Robots(robots Robots) *Route {
+	rules := robots.Rules
+	if len(rules) == 0 {
+		rules = []RobotsRule{{Disallow: []string{""}}}
+	}
+
+	var b strings.Builder
+	for i, rule := range rules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		agents := rule.UserAgents
+		if len(agents) == 0 {
+			agents = []string{"*"}
+		}
+		for _, agent := range agents {
+			b.WriteString("User-agent: " + agent + "\n")
+		}
+		for _, path := range rule.Allow {
+			b.WriteString("Allow: " + path + "\n")
+		}
+		for _, path := range rule.Disallow {
+			b.WriteString("Disallow: " + path + "\n")
+		}
+	}
+	if len(robots.Sitemaps) > 0 {
+		b.WriteString("\n")
+		for _, sitemap := range robots.Sitemaps {
+			b.WriteString("Sitemap: " + sitemap + "\n")
+		}
+	}
+	return w.serve("/robots.txt", "text/plain; charset=utf-8", []byte(b.String()))
+}
+
+// JSON registers /.well-known/name serving v encoded as JSON, such as assetlinks.json or
+// apple-app-site-association. An encoding error is reported by Build.
+func (w *WellKnown) JSON(name string, v any) *Route {
+	content, err := json.Marshal(v)
+	route := w.File(name, "application/json", content)
+	if err != nil {
+		route.err = &RouterError{Code: ErrInvalidConfig, Message: "cannot encode /.well-known/" + name, Err: err}
+	}
+	return route
+}
+
+// File registers /.well-known/name serving content with the content type.
+func (w *WellKnown) File(name, contentType string, content []byte) *Route {
+	return w.serve("/.well-known/"+strings.TrimPrefix(name, "/"), contentType, content)
+}
+
+// serve registers a GET route serving fixed content.
+func (w *WellKnown) serve(path, contentType string, content []byte) *Route {
+	sum := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	return w.router.Get(path, func(rw http.ResponseWriter, req *http.Request) error {
+		rw.Header().Set("Content-Type", contentType)
+		rw.Header().Set("ETag", etag)
+		http.ServeContent(rw, req, "", time.Time{}, bytes.NewReader(content))
+		return nil
+	}).WithCacheControl(wellKnownCacheControl)
+}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestWellKnown tests serving the well-known endpoints
+func TestWellKnown(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	wk := r.WellKnown()
+	wk.Robots(Robots{
+		Rules: []RobotsRule{
+			{Disallow: []string{"/admin/"}},
+			{UserAgents: []string{"BadBot"}, Disallow: []string{"/"}},
+		},
+		Sitemaps: []string{"https://example.com/sitemap.xml"},
+	})
+	wk.SecurityTxt(SecurityTxt{
+		Contact:            []string{"mailto:security@example.com"},
+		Expires:            time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC),
+		PreferredLanguages: []string{"en", "ja"},
+	})
+	wk.JSON("assetlinks.json", []map[string]any{{"relation": []string{"delegate_permission/common.handle_all_urls"}}})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path        string
+		contentType string
+		body        string
+	}{
+		{"/robots.txt", "text/plain; charset=utf-8", "User-agent: *\nDisallow: /admin/\n\nUser-agent: BadBot\nDisallow: /\n\nSitemap: https://example.com/sitemap.xml\n"},
+		{"/.well-known/security.txt", "text/plain; charset=utf-8", "Contact: mailto:security@example.com\nExpires: 2099-01-01T00:00:00Z\nPreferred-Languages: en, ja\n"},
+		{"/.well-known/assetlinks.json", "application/json", `[{"relation":["delegate_permission/common.handle_all_urls"]}]`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != tt.body {
+			t.Errorf("%s: unexpected response %d %q", tt.path, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: expected Content-Type %q, got %q", tt.path, tt.contentType, got)
+		}
+		if got := w.Header().Get("Cache-Control"); got != wellKnownCacheControl {
+			t.Errorf("%s: expected Cache-Control %q, got %q", tt.path, wellKnownCacheControl, got)
+		}
+
+		// Conditional requests are answered without the body
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("%s: expected status %d, got %d", tt.path, http.StatusNotModified, w.Code)
+		}
+	}
+}
+
+// TestWellKnownInvalid tests that Build reports invalid well-known configurations
+func TestWellKnownInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(*WellKnown)
+	}{
+		{"security.txt without contact", func(wk *WellKnown) { wk.SecurityTxt(SecurityTxt{Expires: time.Now().Add(time.Hour)}) }},
+		{"security.txt without expiry", func(wk *WellKnown) { wk.SecurityTxt(SecurityTxt{Contact: []string{"mailto:a@example.com"}}) }},
+		{"unencodable JSON", func(wk *WellKnown) { wk.JSON("app.json", func() {}) }},
+	}
+	for _, tt := range tests {
+		r := NewRouter()
+		tt.register(r.WellKnown())
+		err := r.Build()
+		if re, ok := err.(*RouterError); !ok || re.Code != ErrInvalidConfig {
+			t.Errorf("%s: expected an ErrInvalidConfig error, got %v", tt.name, err)
+		}
+		r.cache.stop()
+	}
+}
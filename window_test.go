@@ -0,0 +1,58 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestActiveWindow tests serving routes only within their active window
+func TestActiveWindow(t *testing.T) {
+	r := NewRouter()
+	defer r.cache.stop()
+
+	now := time.Now()
+	handler := func(w http.ResponseWriter, req *http.Request) error { return nil }
+	r.Get("/current", handler).WithActiveWindow(now.Add(-time.Hour), now.Add(time.Hour))
+	r.Get("/upcoming", handler).WithActiveWindow(now.Add(time.Hour), time.Time{})
+	r.Get("/ended/{id}", handler).WithActiveWindow(time.Time{}, now.Add(-time.Hour))
+	r.Get("/open", handler).WithActiveWindow(time.Time{}, time.Time{})
+	if err := r.Build(); err != nil {
+		t.Fatalf("Failed to build router: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/current", http.StatusOK},
+		{"/upcoming", http.StatusNotFound},
+		{"/ended/1", http.StatusGone},
+		{"/ended/1", http.StatusGone}, // Also when served from the cache
+		{"/open", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, w.Code)
+		}
+	}
+
+	// Windows are evaluated at request time
+	window := &activeWindow{from: now, to: now.Add(time.Minute)}
+	for _, tt := range []struct {
+		at     time.Time
+		status int
+	}{
+		{now.Add(-time.Second), http.StatusNotFound},
+		{now, 0},
+		{now.Add(time.Minute - time.Nanosecond), 0},
+		{now.Add(time.Minute), http.StatusGone},
+	} {
+		if status := window.status(tt.at); status != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.at.Sub(now), tt.status, status)
+		}
+	}
+}